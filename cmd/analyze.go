@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/bundle"
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/spf13/cobra"
+)
+
+var analyzeJSON bool
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze BUNDLE",
+	Short: "Run the checks and diagnoses against a bundle from 'debian-doctor collect'",
+	Long: `Run the selected checks and every diagnosis against a bundle written by
+'debian-doctor collect', as if on the machine it was collected from. Nothing
+on this machine is inspected or changed; fixes are only listed.
+
+The exit code follows the most severe check result, as in non-interactive
+mode.`,
+	Example: `  debian-doctor analyze debian-doctor-web1-20240102-150405.tar.gz
+  debian-doctor analyze web1.tar.gz --only packages,services --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runAnalyze(args[0]))
+	},
+}
+
+func init() {
+	analyzeCmd.Flags().BoolVar(&analyzeJSON, "json", false, "Print the analysis as JSON")
+	rootCmd.AddCommand(analyzeCmd)
+}
+
+// analyzedFix is a suggested fix as shown in an analysis
+type analyzedFix struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Risk         string   `json:"risk"`
+	RequiresRoot bool     `json:"requires_root"`
+	Commands     []string `json:"commands"`
+}
+
+// analyzedDiagnosis is the outcome of one diagnosis against a bundle
+type analyzedDiagnosis struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Findings []finding.Finding `json:"findings"`
+	Fixes    []analyzedFix     `json:"fixes"`
+}
+
+// bundleAnalysis is the JSON form of an analysis
+type bundleAnalysis struct {
+	Hostname    string              `json:"hostname"`
+	CollectedAt time.Time           `json:"collected_at"`
+	Root        bool                `json:"root"`
+	Checks      checks.Results      `json:"checks"`
+	Diagnoses   []analyzedDiagnosis `json:"diagnoses"`
+}
+
+func runAnalyze(path string) int {
+	if recordFile != "" || replayFile != "" {
+		fmt.Fprintln(os.Stderr, "Error: analyze cannot be combined with --record or --replay")
+		return exitFailure
+	}
+
+	b, err := bundle.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+	cfg.SetNonInteractive(true)
+	cfg.SetDryRun(true)
+	// Root-only checks can only run if the bundle was collected as root
+	cfg.IsRoot = b.Manifest.Root
+
+	selected, err := checks.GetChecks(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+
+	// The bundle's own hostname is hidden as well as this machine's
+	if redactor != nil {
+		redactor.AddTerm(b.Manifest.Hostname)
+	}
+
+	checks.SetMachine(b.Machine())
+	diagnose.SetMachine(b.Machine())
+
+	analysis := bundleAnalysis{
+		Hostname:    b.Manifest.Hostname,
+		CollectedAt: b.Manifest.CollectedAt,
+		Root:        b.Manifest.Root,
+		Checks:      checks.NewRunner(cfg).Run(context.Background(), selected),
+	}
+	for _, d := range diagnose.Diagnosers() {
+		diagnosis := d.Run()
+		result := analyzedDiagnosis{ID: d.ID, Name: d.Name, Findings: diagnosis.Findings, Fixes: []analyzedFix{}}
+		for _, fix := range diagnosis.Fixes {
+			result.Fixes = append(result.Fixes, analyzedFix{
+				ID:           fix.ID,
+				Title:        fix.Title,
+				Risk:         fix.RiskLevel.String(),
+				RequiresRoot: fix.RequiresRoot,
+				Commands:     fix.Commands,
+			})
+		}
+		analysis.Diagnoses = append(analysis.Diagnoses, result)
+	}
+
+	if analyzeJSON {
+		if err := printJSON(analysis); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitFailure
+		}
+	} else {
+		var report strings.Builder
+		printAnalysis(&report, &analysis)
+		fmt.Print(redactor.String(report.String()))
+	}
+
+	return exitCodeForSeverity(analysis.Checks.HighestSeverity())
+}
+
+func printAnalysis(w io.Writer, analysis *bundleAnalysis) {
+	fmt.Fprintln(w, "=====================================")
+	fmt.Fprintln(w, "   BUNDLE ANALYSIS")
+	fmt.Fprintln(w, "=====================================")
+	fmt.Fprintf(w, "Host:          %s\n", analysis.Hostname)
+	fmt.Fprintf(w, "Collected:     %s\n", analysis.CollectedAt.Local().Format("2006-01-02 15:04:05"))
+	if !analysis.Root {
+		fmt.Fprintln(w, "Collected without root: root-only checks are skipped")
+	}
+
+	fmt.Fprintln(w, "\nCHECK RESULTS:")
+	for _, result := range analysis.Checks.GetAllChecks() {
+		fmt.Fprintf(w, "  [%s] %s: %s\n", strings.ToUpper(result.Severity.String()), result.Name, result.Message)
+		for _, detail := range result.Lines() {
+			fmt.Fprintf(w, "      %s\n", detail)
+		}
+	}
+
+	fmt.Fprintln(w, "\nDIAGNOSES:")
+	for _, d := range analysis.Diagnoses {
+		fmt.Fprintf(w, "\n=== %s ===\n", strings.ToUpper(d.Name))
+		for _, summary := range finding.Summaries(d.Findings) {
+			fmt.Fprintf(w, "  - %s\n", summary)
+		}
+		for _, fix := range d.Fixes {
+			fmt.Fprintf(w, "  FIX: %s (%s risk)\n", fix.Title, fix.Risk)
+		}
+	}
+}
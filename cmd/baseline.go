@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/debian-doctor/debian-doctor/internal/baseline"
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	baselineFile string
+	baselineJSON bool
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Record a known-good state of this host and report drift from it",
+	Long: `A baseline records the installed packages and their versions, held
+packages, enabled and masked units, APT sources, listening sockets, setuid
+binaries and the permissions of key system files. It is kept in the state
+directory unless --file is given, so a baseline saved on one host can be
+checked on others.`,
+}
+
+var baselineSaveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Record the current state of this host as the baseline",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBaselineSave(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+var baselineCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report how this host has drifted from the baseline",
+	Long: `Compare the current state of this host with the saved baseline. The exit
+status is 0 when nothing drifted and 1 when something did.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		drifted, err := runBaselineCheck()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		if drifted {
+			os.Exit(exitWarning)
+		}
+	},
+}
+
+func init() {
+	baselineCmd.PersistentFlags().StringVar(&baselineFile, "file", "", "Baseline file (default <state_dir>/"+baseline.FileName+")")
+	baselineCheckCmd.Flags().BoolVar(&baselineJSON, "json", false, "Print the drift as JSON")
+	baselineCmd.AddCommand(baselineSaveCmd)
+	baselineCmd.AddCommand(baselineCheckCmd)
+	rootCmd.AddCommand(baselineCmd)
+}
+
+// baselinePath is the baseline file to use
+func baselinePath(cfg *config.Config) string {
+	if baselineFile != "" {
+		return baselineFile
+	}
+	return filepath.Join(cfg.StateDir, baseline.FileName)
+}
+
+// captureBaseline records the state of the machine checks run against
+func captureBaseline() *baseline.Baseline {
+	return baseline.Capture(probes.For(currentMachine), diagnose.KeyPaths())
+}
+
+func runBaselineSave() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	b := captureBaseline()
+	path := baselinePath(cfg)
+	if err := b.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Baseline of %s saved to %s\n", redactor.String(b.Hostname), redactor.String(path))
+	fmt.Printf("  %d packages, %d held, %d enabled and %d masked units\n",
+		len(b.Packages), len(b.Held), len(b.EnabledUnits), len(b.MaskedUnits))
+	fmt.Printf("  %d APT sources, %d listening sockets, %d setuid binaries, %d key paths\n",
+		len(b.APTSources), len(b.Listening), len(b.SUIDBinaries), len(b.FileModes))
+	for _, section := range b.Unavailable {
+		fmt.Printf("  Warning: %s could not be recorded and will not be checked\n", section)
+	}
+	return nil
+}
+
+func runBaselineCheck() (bool, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return false, err
+	}
+
+	base, err := baseline.Load(baselinePath(cfg))
+	if err != nil {
+		return false, err
+	}
+	current := captureBaseline()
+	drift := baseline.Check(base, current)
+
+	if baselineJSON {
+		if drift == nil {
+			drift = []baseline.Drift{}
+		}
+		return len(drift) > 0, printJSON(drift)
+	}
+
+	fmt.Printf("Baseline: %s, saved %s\n", redactor.String(base.Hostname), base.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	for _, section := range baseline.Sections {
+		if !base.Available(section) || !current.Available(section) {
+			fmt.Printf("\n%s: not checked, unavailable\n", section)
+		}
+	}
+
+	if len(drift) == 0 {
+		fmt.Printf("\nNo drift from the baseline.\n")
+		return false, nil
+	}
+
+	section := ""
+	for _, d := range drift {
+		if d.Section != section {
+			section = d.Section
+			fmt.Printf("\n%s:\n", section)
+		}
+		fmt.Printf("  %s\n", redactor.String(d.String()))
+	}
+	fmt.Printf("\n%d differences from the baseline.\n", len(drift))
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/spf13/cobra"
+)
+
+var checksListJSON bool
+
+var checksCmd = &cobra.Command{
+	Use:   "checks",
+	Short: "Inspect the available system checks",
+}
+
+var checksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the checks that would run, with their metadata",
+	Long: `List the checks selected by the configuration and by --only, --skip and
+--tags, with their category, tags, cost and the programs they need.
+Binaries missing from $PATH are marked with '!'. External plugins found in
+the checks.d directories are listed with IDs starting with 'plugin.'.`,
+	Example: `  debian-doctor checks list
+  debian-doctor checks list --tags storage
+  debian-doctor checks list --skip logs,filesystem --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := mustLoadConfig()
+		registry, err := checks.LoadRegistry(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		selected, err := checks.GetChecks(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+
+		if checksListJSON {
+			type listedCheck struct {
+				checks.Info
+				Name         string `json:"name"`
+				RequiresRoot bool   `json:"requires_root"`
+			}
+			listed := make([]listedCheck, len(selected))
+			for i, check := range selected {
+				listed[i] = listedCheck{Info: check.Info(), Name: check.Name(), RequiresRoot: check.RequiresRoot()}
+			}
+			if err := printJSON(listed); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitFailure)
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tCATEGORY\tCOST\tTAGS\tNEEDS")
+		for _, check := range selected {
+			info := check.Info()
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				info.ID, check.Name(), info.Category, info.Cost,
+				strings.Join(info.Tags, ","), checkNeeds(check, cfg.IsRoot))
+		}
+		w.Flush()
+
+		fmt.Printf("\n%d of %d checks selected\n", len(selected), len(registry.Checks()))
+	},
+}
+
+func init() {
+	checksListCmd.Flags().BoolVar(&checksListJSON, "json", false, "Print checks as JSON")
+	checksCmd.AddCommand(checksListCmd)
+	rootCmd.AddCommand(checksCmd)
+}
+
+// checkNeeds summarises what a check depends on, flagging what is missing
+func checkNeeds(check checks.Check, isRoot bool) string {
+	var needs []string
+	if check.RequiresRoot() {
+		if isRoot {
+			needs = append(needs, "root")
+		} else {
+			needs = append(needs, "root!")
+		}
+	}
+	for _, binary := range check.Info().Binaries {
+		if _, err := exec.LookPath(binary); err != nil {
+			binary += "!"
+		}
+		needs = append(needs, binary)
+	}
+	if len(needs) == 0 {
+		return "-"
+	}
+	return strings.Join(needs, ",")
+}
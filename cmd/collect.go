@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/debian-doctor/debian-doctor/internal/bundle"
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/spf13/cobra"
+)
+
+var collectOutput string
+
+var collectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "Capture this machine's state into a bundle for offline analysis",
+	Long: `Run every selected check and every diagnosis, recording each command they
+run and each file they read, and write it all to a gzipped tarball with a
+manifest. The bundle also holds os-release, meminfo, mounts, resolv.conf,
+dpkg --audit, failed units, recent journal errors and dmesg.
+
+Analyze the bundle on any machine with 'debian-doctor analyze'. Run collect
+as root so root-only checks are included. The bundle holds raw logs and
+configuration; review it before sending it anywhere, or collect with
+--redact to hide addresses, hostnames, users, emails and secrets.`,
+	Example: `  sudo debian-doctor collect
+  sudo debian-doctor collect -o /tmp/web1.tar.gz --skip filesystem
+  sudo debian-doctor collect --redact`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runCollect())
+	},
+}
+
+func init() {
+	collectCmd.Flags().StringVarP(&collectOutput, "output", "o", "", "Write the bundle to FILE (default debian-doctor-HOST-TIME.tar.gz)")
+	rootCmd.AddCommand(collectCmd)
+}
+
+func runCollect() int {
+	if recordFile != "" || replayFile != "" {
+		fmt.Fprintln(os.Stderr, "Error: collect cannot be combined with --record or --replay")
+		return exitFailure
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+	cfg.SetNonInteractive(true)
+
+	selected, err := checks.GetChecks(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+
+	collector := bundle.NewCollector(machine.Local(cfg.Runner.CheckTimeout))
+	checks.SetMachine(collector.Machine())
+	diagnose.SetMachine(collector.Machine())
+
+	fmt.Fprintln(os.Stderr, "Collecting system information...")
+	collector.CollectEssentials()
+
+	runner := checks.NewRunner(cfg)
+	runner.Progress = func(result checks.CheckResult, done, total int) {
+		fmt.Fprintf(os.Stderr, "  Check %d/%d: %s\n", done, total, result.Name)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	runner.Run(ctx, selected)
+	interrupted := ctx.Err() != nil
+	stop()
+
+	if !interrupted {
+		diagnosers := diagnose.Diagnosers()
+		for i, d := range diagnosers {
+			d.Run()
+			fmt.Fprintf(os.Stderr, "  Diagnosis %d/%d: %s\n", i+1, len(diagnosers), d.Name)
+		}
+	}
+
+	manifest := collector.Manifest(cfg.IsRoot)
+	if redactor != nil {
+		manifest.Redact(redactor)
+	}
+	path := collectOutput
+	if path == "" {
+		path = bundle.DefaultName(manifest)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to create bundle: %v\n", err)
+		return exitFailure
+	}
+	if err := bundle.Write(file, bundle.RootName(manifest), manifest); err != nil {
+		file.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write bundle: %v\n", err)
+		return exitFailure
+	}
+
+	commands, files := manifest.Counts()
+	fmt.Printf("Wrote %s (%d commands, %d files)\n", path, commands, files)
+	if interrupted {
+		fmt.Println("Collection was interrupted; the bundle only covers the checks that finished")
+	}
+	if !cfg.IsRoot {
+		fmt.Println("Not running as root: root-only checks were skipped and are missing from the bundle")
+	}
+	if redactor != nil {
+		fmt.Printf("Redacted %d distinct values; review the bundle before sharing\n", redactor.Count())
+	} else {
+		fmt.Println("The bundle contains raw logs and configuration files; review it before sharing")
+	}
+	return exitOK
+}
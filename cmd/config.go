@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/rules"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	configFile      string
+	configOverrides []string
+	checksOnly      []string
+	checksSkip      []string
+	checksTags      []string
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the debian-doctor configuration",
+	Long: `Settings are layered, later sources overriding earlier ones:
+
+  1. built-in defaults
+  2. ` + config.SystemConfigFile + `
+  3. ~/.config/debian-doctor/config.yaml
+  4. the file given with --config
+  5. ` + config.EnvPrefix + `* environment variables, e.g. ` + config.EnvName("thresholds.disk_warning_percent") + `
+  6. --set key=value flags, then --only, --skip and --tags`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective merged configuration",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := mustLoadConfig()
+		fmt.Printf("# Sources: %s\n", strings.Join(cfg.Sources, ", "))
+		if len(cfg.Ignored) > 0 {
+			fmt.Printf("# Ignored: %s\n", strings.Join(cfg.Ignored, ", "))
+		}
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Read settings from FILE after the system and user config files")
+	rootCmd.PersistentFlags().StringArrayVar(&configOverrides, "set", nil, "Override a setting, e.g. --set thresholds.disk_warning_percent=90 (repeatable)")
+	rootCmd.PersistentFlags().StringSliceVar(&checksOnly, "only", nil, "Run only these checks (IDs from 'debian-doctor checks list')")
+	rootCmd.PersistentFlags().StringSliceVar(&checksSkip, "skip", nil, "Do not run these checks")
+	rootCmd.PersistentFlags().StringSliceVar(&checksTags, "tags", nil, "Run only checks carrying one of these tags, e.g. storage,security")
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// loadConfig merges every configuration layer, applies the command line and
+// hands the thresholds, machine and diagnosis rules to the checks and
+// diagnoses
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, override := range configOverrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("--set %s: expected key=value", override)
+		}
+		if err := cfg.Set(strings.TrimSpace(key), value); err != nil {
+			return nil, fmt.Errorf("--set: %w", err)
+		}
+		cfg.Sources = append(cfg.Sources, "--set "+strings.TrimSpace(key))
+	}
+	if len(checksOnly) > 0 {
+		cfg.Checks.Enabled = checksOnly
+		cfg.Sources = append(cfg.Sources, "--only")
+	}
+	if len(checksSkip) > 0 {
+		cfg.Checks.Disabled = append(cfg.Checks.Disabled, checksSkip...)
+		cfg.Sources = append(cfg.Sources, "--skip")
+	}
+	if len(checksTags) > 0 {
+		cfg.Checks.Tags = checksTags
+		cfg.Sources = append(cfg.Sources, "--tags")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	for _, ignored := range cfg.Ignored {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring %s; only %s and the command line may set it\n", ignored, config.SystemConfigFile)
+	}
+
+	if verbose {
+		cfg.SetVerbose(true)
+	}
+	cfg.SetDryRun(dryRun)
+	if err := setupRedactor(cfg); err != nil {
+		return nil, err
+	}
+	if err := setupMachine(cfg); err != nil {
+		return nil, err
+	}
+
+	useThresholds(cfg.Thresholds)
+
+	ruleSet, err := rules.Load(cfg.RuleDirs)
+	if err != nil {
+		return nil, err
+	}
+	for _, skipped := range ruleSet.Skipped {
+		fmt.Fprintf(os.Stderr, "Warning: skipping %s\n", skipped)
+	}
+	if err := diagnose.SetRules(ruleSet); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// useThresholds sets the limits checks and diagnoses grade with. Only
+// loadConfig and commands that change the limits after it call this
+func useThresholds(t config.Thresholds) {
+	checks.SetThresholds(t)
+	diagnose.SetThresholds(t)
+}
+
+// mustLoadConfig is loadConfig for commands that cannot continue without it
+func mustLoadConfig() *config.Config {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	return cfg
+}
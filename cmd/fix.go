@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixAuto    bool
+	fixMaxRisk string
+)
+
+// Outcomes of a fix in the remediation summary, in display order
+const (
+	outcomeApplied    = "applied"
+	outcomeFailed     = "failed"
+	outcomeNeedsHuman = "needs human"
+	outcomeDenied     = "denied by policy"
+	outcomeSkipped    = "skipped"
+	outcomeDeclined   = "declined"
+	outcomePlanned    = "would apply"
+	outcomeNotNeeded  = "not needed"
+)
+
+var outcomeOrder = []string{outcomeApplied, outcomeFailed, outcomeNeedsHuman, outcomeDenied, outcomeSkipped, outcomeDeclined, outcomePlanned, outcomeNotNeeded}
+
+// fixOutcome records what happened to one suggested fix
+type fixOutcome struct {
+	diagnosis string
+	fix       *fixes.Fix
+	outcome   string
+	detail    string
+	runID     string
+}
+
+var fixCmd = &cobra.Command{
+	Use:   "fix [diagnosis...]",
+	Short: "Run diagnoses and apply the fixes they suggest",
+	Long: `Run the given diagnoses (all of them by default) and apply the suggested fixes.
+
+Each fix is confirmed interactively unless --auto is given. With --auto, only
+fixes for problems a diagnosis actually found are considered: each one at or
+below --max-risk is applied without a prompt, anything riskier is reported as
+needing a human, and a summary is printed at the end. Fixes that only gather
+information are never run by --auto.`,
+	Example: `  debian-doctor fix network
+  debian-doctor fix --auto --max-risk low
+  debian-doctor fix --auto --max-risk medium packages disk --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if cmd.Flags().Changed("max-risk") && !fixAuto {
+			fmt.Fprintln(os.Stderr, "Error: --max-risk only applies together with --auto")
+			os.Exit(exitFailure)
+		}
+		os.Exit(runFix(args))
+	},
+}
+
+func init() {
+	fixCmd.Flags().BoolVar(&fixAuto, "auto", false, "Apply fixes without confirmation, up to --max-risk")
+	fixCmd.Flags().StringVar(&fixMaxRisk, "max-risk", "low", "Highest risk level applied automatically (low, medium, high, critical)")
+	rootCmd.AddCommand(fixCmd)
+}
+
+func runFix(ids []string) int {
+	maxRisk, err := fixes.ParseRiskLevel(fixMaxRisk)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+
+	diagnosers, err := diagnose.LookupDiagnosers(ids)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+	cfg.SetNonInteractive(fixAuto)
+
+	log, err := newLogger(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting up logger: %v\n", err)
+		return exitFailure
+	}
+	defer log.Close()
+
+	executor := fixes.NewExecutor(cfg, log)
+	if fixAuto {
+		executor.SetAutoApprove(maxRisk)
+		if ceiling := executor.AutoApproveCeiling(); ceiling < maxRisk {
+			fmt.Fprintf(os.Stderr, "Warning: --max-risk %s is lowered to %s, the fix policy's max_unattended_risk\n",
+				strings.ToLower(maxRisk.String()), strings.ToLower(ceiling.String()))
+			maxRisk = ceiling
+		}
+	}
+
+	var outcomes []fixOutcome
+	seen := map[string]bool{}
+	for _, d := range diagnosers {
+		fmt.Printf("\n=== %s ===\n", strings.ToUpper(d.Name))
+		diagnosis := d.Run()
+		if len(diagnosis.Fixes) == 0 {
+			fmt.Println("No fixes suggested")
+			continue
+		}
+
+		found := fixesForFindings(diagnosis)
+		for _, fix := range diagnosis.Fixes {
+			key := fix.ID
+			if key == "" {
+				key = fix.Title
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			var outcome fixOutcome
+			switch {
+			case fixAuto && fix.Informational:
+				outcome = fixOutcome{fix: fix, outcome: outcomeNotNeeded, detail: "only gathers information"}
+			case fixAuto && !found[fix.ID]:
+				outcome = fixOutcome{fix: fix, outcome: outcomeNotNeeded, detail: "no finding calls for it"}
+			default:
+				outcome = applyFix(executor, fix)
+			}
+			outcome.diagnosis = d.ID
+			fmt.Printf("  %-16s %s", strings.ToUpper(outcome.outcome), fix.Title)
+			if outcome.detail != "" {
+				fmt.Printf(" (%s)", outcome.detail)
+			}
+			fmt.Println()
+			outcomes = append(outcomes, outcome)
+		}
+	}
+
+	printFixSummary(outcomes, maxRisk)
+	return fixExitCode(outcomes)
+}
+
+// fixesForFindings returns the IDs of the fixes linked from the problems a
+// diagnosis found. Informational findings do not ask for a fix
+func fixesForFindings(diagnosis diagnose.Diagnosis) map[string]bool {
+	ids := map[string]bool{}
+	for _, f := range diagnosis.Findings {
+		if f.Severity == finding.SeverityInfo {
+			continue
+		}
+		for _, id := range f.FixIDs {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// applyFix executes one fix and classifies the result
+func applyFix(executor *fixes.Executor, fix *fixes.Fix) fixOutcome {
+	outcome := fixOutcome{fix: fix}
+
+	run, err := executor.ExecuteFix(fix)
+	if run != nil {
+		outcome.runID = run.ID
+	}
+
+	var (
+		needsHuman *fixes.NeedsHumanError
+		denied     *fixes.PolicyError
+	)
+	switch {
+	case errors.As(err, &needsHuman):
+		outcome.outcome = outcomeNeedsHuman
+		outcome.detail = needsHuman.Reason
+	case errors.As(err, &denied):
+		outcome.outcome = outcomeDenied
+		reasons := make([]string, len(denied.Violations))
+		for i, v := range denied.Violations {
+			reasons[i] = v.String()
+		}
+		outcome.detail = strings.Join(reasons, "; ")
+	case err != nil && run == nil:
+		outcome.outcome = outcomeSkipped
+		outcome.detail = err.Error()
+	case err != nil:
+		outcome.outcome = outcomeFailed
+		outcome.detail = err.Error()
+	case run.Status == fixes.RunCancelled:
+		outcome.outcome = outcomeDeclined
+	case run.Status == fixes.RunDryRun:
+		outcome.outcome = outcomePlanned
+		if !fixAuto {
+			outcome.detail = "after confirmation"
+		}
+	default:
+		outcome.outcome = outcomeApplied
+	}
+	return outcome
+}
+
+func printFixSummary(outcomes []fixOutcome, maxRisk fixes.RiskLevel) {
+	fmt.Println()
+	fmt.Println("=====================================")
+	if fixAuto {
+		fmt.Printf("   REMEDIATION SUMMARY (max risk: %s)\n", strings.ToLower(maxRisk.String()))
+	} else {
+		fmt.Println("   REMEDIATION SUMMARY")
+	}
+	fmt.Println("=====================================")
+
+	if len(outcomes) == 0 {
+		fmt.Println("No fixes were suggested. Nothing to do.")
+		return
+	}
+
+	byOutcome := map[string][]fixOutcome{}
+	for _, o := range outcomes {
+		byOutcome[o.outcome] = append(byOutcome[o.outcome], o)
+	}
+
+	for _, name := range outcomeOrder {
+		if len(byOutcome[name]) > 0 {
+			fmt.Printf("  %-18s %d\n", capitalize(name)+":", len(byOutcome[name]))
+		}
+	}
+
+	for _, name := range outcomeOrder {
+		if name == outcomePlanned || name == outcomeNotNeeded || len(byOutcome[name]) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", strings.ToUpper(name))
+		for _, o := range byOutcome[name] {
+			fmt.Printf("  - [%s] %s (%s risk)", o.diagnosis, o.fix.Title, o.fix.RiskLevel)
+			if o.runID != "" {
+				fmt.Printf(" run %s", o.runID)
+			}
+			fmt.Println()
+			if o.detail != "" && name != outcomeApplied {
+				fmt.Printf("      %s\n", o.detail)
+			}
+		}
+	}
+}
+
+// fixExitCode is 0 when nothing is left to do, 1 when fixes still need
+// attention and 2 when a fix failed
+func fixExitCode(outcomes []fixOutcome) int {
+	code := exitOK
+	for _, o := range outcomes {
+		switch o.outcome {
+		case outcomeFailed:
+			return exitError
+		case outcomeNeedsHuman, outcomeDenied, outcomeSkipped:
+			code = exitWarning
+		}
+	}
+	return code
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
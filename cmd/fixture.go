@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+var (
+	recordFile string
+	replayFile string
+
+	// machineReady stops a second loadConfig from truncating the recording
+	machineReady bool
+
+	// currentMachine is the machine checks and diagnoses were pointed at
+	currentMachine *machine.Machine
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "Record every command output and file read to FILE as a replayable fixture")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "Answer commands and file reads from a FILE written by --record instead of this machine")
+}
+
+// setupMachine points checks and diagnoses at the real machine, a recorder
+// or a replayed fixture. Replaying never touches the system, so fixes are
+// only planned, root-only checks run from the fixture and the run is kept
+// out of this machine's history
+func setupMachine(cfg *config.Config) error {
+	if recordFile != "" && replayFile != "" {
+		return fmt.Errorf("--record and --replay cannot be used together")
+	}
+	if replayFile != "" {
+		cfg.SetDryRun(true)
+		cfg.IsRoot = true
+		cfg.History.Enabled = false
+	}
+	if machineReady {
+		return nil
+	}
+
+	var m *machine.Machine
+	switch {
+	case replayFile != "":
+		fixture, err := machine.LoadFixture(replayFile)
+		if err != nil {
+			return err
+		}
+		m = fixture.Machine()
+	case recordFile != "":
+		file, err := os.OpenFile(recordFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create fixture: %w", err)
+		}
+		// Left open for the life of the process; every record is a single write
+		m = machine.NewRecorder(machine.Local(cfg.Runner.CheckTimeout), file)
+	default:
+		m = machine.Local(cfg.Runner.CheckTimeout)
+	}
+
+	checks.SetMachine(m)
+	diagnose.SetMachine(m)
+	currentMachine = m
+	machineReady = true
+	return nil
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historySince string
+	historyLimit int
+	historyJSON  bool
+	diffJSON     bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show how the health score changed over past runs",
+	Long: `List the runs kept in the run history, newest last, with their health
+score, how it moved since the run before and how many problems were found.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runHistory(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [run1 [run2]]",
+	Short: "Compare two runs from the run history",
+	Long: `Show which checks regressed or recovered between two runs and how resource
+usage and package counts moved. Runs are named by ID, or as "latest" and
+"previous". Without arguments the last two runs are compared; with one, that
+run is compared with the latest.`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDiff(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only show runs since a date (2006-01-02) or age (24h, 7d)")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "Show at most this many of the latest runs (0 shows all)")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Print runs as JSON")
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Print the comparison as JSON")
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runHistory() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store := history.NewStore(cfg.StateDir)
+
+	runs, err := store.Runs()
+	if err != nil {
+		return err
+	}
+
+	// Score changes are measured against the run before, even when that run
+	// is filtered out below
+	changes := make(map[string]string, len(runs))
+	for i, run := range runs {
+		if i > 0 {
+			changes[run.ID] = fmt.Sprintf("%+d", run.HealthScore-runs[i-1].HealthScore)
+		}
+	}
+
+	if historySince != "" {
+		since, err := parseSince(historySince, time.Now())
+		if err != nil {
+			return err
+		}
+		filtered := runs[:0]
+		for _, run := range runs {
+			if !run.Timestamp.Before(since) {
+				filtered = append(filtered, run)
+			}
+		}
+		runs = filtered
+	}
+	if historyLimit > 0 && len(runs) > historyLimit {
+		runs = runs[len(runs)-historyLimit:]
+	}
+
+	if historyJSON {
+		return printJSON(runs)
+	}
+
+	if len(runs) == 0 {
+		fmt.Printf("No runs recorded in %s\n", store.Path())
+		return nil
+	}
+
+	fmt.Printf("%-20s  %-19s  %5s  %6s  %6s  %8s  %s\n", "RUN ID", "TIME", "SCORE", "CHANGE", "ERRORS", "WARNINGS", "TREND")
+	for _, run := range runs {
+		change := changes[run.ID]
+		if change == "" {
+			change = "-"
+		}
+		fmt.Printf("%-20s  %-19s  %5d  %6s  %6d  %8d  %s\n",
+			run.ID, run.Timestamp.Local().Format("2006-01-02 15:04:05"), run.HealthScore, change,
+			run.CriticalIssues, run.Warnings, scoreBar(run.HealthScore))
+	}
+	return nil
+}
+
+// scoreBar draws a health score as a ten-character bar
+func scoreBar(score int) string {
+	filled := score / 10
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > 10 {
+		filled = 10
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", 10-filled) + "]"
+}
+
+func runDiff(args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store := history.NewStore(cfg.StateDir)
+
+	fromID, toID := "previous", "latest"
+	switch len(args) {
+	case 1:
+		fromID = args[0]
+	case 2:
+		fromID, toID = args[0], args[1]
+	}
+
+	from, err := store.Find(fromID)
+	if err != nil {
+		return err
+	}
+	to, err := store.Find(toID)
+	if err != nil {
+		return err
+	}
+
+	comparison := history.Compare(*from, *to)
+	if diffJSON {
+		return printJSON(comparison)
+	}
+	printComparison(comparison)
+	return nil
+}
+
+func printComparison(c history.Comparison) {
+	fmt.Printf("From: %s (%s)\n", c.From.ID, c.From.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("To:   %s (%s)\n", c.To.ID, c.To.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("\nHealth score: %d -> %d (%+d)\n", c.From.HealthScore, c.To.HealthScore, c.ScoreChange)
+
+	printChanges := func(title string, changes []history.CheckChange) {
+		if len(changes) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		for _, change := range changes {
+			fmt.Printf("  %s: %s -> %s (%s)\n", change.Name, checkOutcome(change.From), checkOutcome(change.To), change.To.Message)
+		}
+	}
+	printChanges("Regressed checks", c.Regressed)
+	printChanges("Recovered checks", c.Recovered)
+
+	if len(c.Added) > 0 || len(c.Removed) > 0 {
+		fmt.Printf("\nChecks run:\n")
+		for _, check := range c.Added {
+			fmt.Printf("  + %s: %s\n", check.Name, checkOutcome(check))
+		}
+		for _, check := range c.Removed {
+			fmt.Printf("  - %s\n", check.Name)
+		}
+	}
+
+	printMetrics := func(title string, changes []history.MetricChange) {
+		if len(changes) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		for _, change := range changes {
+			fmt.Printf("  %-28s %10.1f -> %10.1f (%+.1f)\n", change.Name, change.From, change.To, change.Delta())
+		}
+	}
+	printMetrics("Resources", c.Resources)
+	printMetrics("Measurements", c.Metrics)
+
+	if len(c.Regressed) == 0 && len(c.Recovered) == 0 && len(c.Added) == 0 && len(c.Removed) == 0 {
+		fmt.Printf("\nNo check changed its outcome.\n")
+	}
+}
+
+// checkOutcome describes a check's result in a run
+func checkOutcome(check history.CheckState) string {
+	if check.Status != "" {
+		return string(check.Status)
+	}
+	return check.Severity.String()
+}
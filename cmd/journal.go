@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	journalSince string
+	journalJSON  bool
+)
+
+var journalCmd = &cobra.Command{
+	Use:   "journal [run-id]",
+	Short: "Show the fixes debian-doctor has applied on this system",
+	Long: `List the fix runs recorded in the execution journal, or show every step
+of a single run when a run ID is given.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runJournal(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+func init() {
+	journalCmd.Flags().StringVar(&journalSince, "since", "", "Only show runs since a date (2006-01-02) or age (24h, 7d)")
+	journalCmd.Flags().BoolVar(&journalJSON, "json", false, "Print runs as JSON")
+	rootCmd.AddCommand(journalCmd)
+}
+
+func runJournal(args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	journal := fixes.NewJournal(cfg.LogDir)
+
+	if len(args) == 1 {
+		run, err := journal.Find(args[0])
+		if err != nil {
+			return err
+		}
+		if journalJSON {
+			return printJSON(run)
+		}
+		printFixRun(run)
+		return nil
+	}
+
+	runs, err := journal.Runs()
+	if err != nil {
+		return err
+	}
+
+	if journalSince != "" {
+		since, err := parseSince(journalSince, time.Now())
+		if err != nil {
+			return err
+		}
+		filtered := runs[:0]
+		for _, run := range runs {
+			if !run.StartedAt.Before(since) {
+				filtered = append(filtered, run)
+			}
+		}
+		runs = filtered
+	}
+
+	if journalJSON {
+		return printJSON(runs)
+	}
+
+	if len(runs) == 0 {
+		fmt.Printf("No fix runs recorded in %s\n", journal.Path())
+		return nil
+	}
+
+	fmt.Printf("%-22s  %-19s  %-10s  %-5s  %s\n", "RUN ID", "STARTED", "STATUS", "STEPS", "FIX")
+	for _, run := range runs {
+		fmt.Printf("%-22s  %-19s  %-10s  %-5d  %s\n",
+			run.ID, run.StartedAt.Local().Format("2006-01-02 15:04:05"), run.Status, len(run.Steps), run.Title)
+	}
+	return nil
+}
+
+func printFixRun(run *fixes.FixRun) {
+	fmt.Printf("Run:      %s\n", run.ID)
+	fmt.Printf("Fix:      %s (%s)\n", run.Title, run.FixID)
+	fmt.Printf("Risk:     %s\n", run.RiskLevel)
+	fmt.Printf("Host:     %s (user %s)\n", run.Hostname, run.User)
+	fmt.Printf("Started:  %s\n", run.StartedAt.Local().Format(time.RFC3339))
+	fmt.Printf("Duration: %s\n", run.Duration().Round(time.Millisecond))
+	fmt.Printf("Status:   %s\n", run.Status)
+	if run.Error != "" {
+		fmt.Printf("Error:    %s\n", run.Error)
+	}
+	if len(run.Snapshots) > 0 {
+		fmt.Printf("Snapshot: %s (undo with 'debian-doctor rollback %s')\n", strings.Join(run.Snapshots, ", "), run.ID)
+	}
+
+	printSteps := func(steps []fixes.StepResult) {
+		for _, step := range steps {
+			fmt.Printf("\n  %d. %s\n", step.Step, step.Command)
+			if step.TimedOut {
+				fmt.Printf("     timed out after %s\n", step.EndedAt.Sub(step.StartedAt).Round(time.Millisecond))
+			} else {
+				fmt.Printf("     exit code %d after %s\n", step.ExitCode, step.EndedAt.Sub(step.StartedAt).Round(time.Millisecond))
+			}
+			if step.Stdout != "" {
+				fmt.Printf("     stdout:\n%s", indentOutput(step.Stdout))
+			}
+			if step.Stderr != "" {
+				fmt.Printf("     stderr:\n%s", indentOutput(step.Stderr))
+			}
+		}
+	}
+
+	fmt.Printf("\nSteps:")
+	printSteps(run.Steps)
+	fmt.Println()
+
+	if run.Reversal != nil {
+		fmt.Printf("\nReversal: attempted=%t succeeded=%t", run.Reversal.Attempted, run.Reversal.Succeeded)
+		printSteps(run.Reversal.Steps)
+		fmt.Println()
+	}
+}
+
+func indentOutput(output string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		b.WriteString("       ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(redactor.JSON(data)))
+	return nil
+}
+
+// parseSince accepts a date, an RFC 3339 timestamp or an age such as 24h or 7d
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return now.Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q (use 2006-01-02, an RFC 3339 time, or an age like 24h or 7d)", value)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/summary"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+var (
+	nagiosMode     bool
+	nagiosChecks   []string
+	nagiosWarning  []string
+	nagiosCritical []string
+)
+
+func init() {
+	rootCmd.Flags().BoolVar(&nagiosMode, "nagios", false, "Print a Nagios/Icinga plugin status line and exit with the plugin state")
+	rootCmd.Flags().StringSliceVar(&nagiosChecks, "check", nil, "Checks to run in --nagios mode (same as --only)")
+	rootCmd.Flags().StringSliceVarP(&nagiosWarning, "warning", "w", nil, "Warning level for --nagios performance data, e.g. disk_used_pct=90,upgradeable=20")
+	rootCmd.Flags().StringSliceVarP(&nagiosCritical, "critical", "c", nil, "Critical level for --nagios performance data, e.g. failed_units=0")
+}
+
+// runNagios runs the checks as a monitoring plugin. Plugins report on
+// stdout even when they fail, so every error becomes an UNKNOWN state
+func runNagios() {
+	report, state, err := nagiosReport()
+	if err != nil {
+		fmt.Printf("DEBIAN-DOCTOR UNKNOWN - %s\n", strings.ReplaceAll(err.Error(), "|", "/"))
+		os.Exit(summary.NagiosUnknown)
+	}
+	os.Stdout.Write(report)
+	os.Exit(state)
+}
+
+func nagiosReport() ([]byte, int, error) {
+	checksOnly = append(checksOnly, nagiosChecks...)
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, 0, err
+	}
+	cfg.SetNonInteractive(true)
+	// Monitoring runs every few minutes would push manual runs out of the
+	// history
+	cfg.History.Enabled = false
+
+	limits := summary.NagiosLimits{Warning: map[string]float64{}, Critical: map[string]float64{}}
+	if err := applyNagiosLimits(cfg, nagiosWarning, false, limits.Warning); err != nil {
+		return nil, 0, err
+	}
+	if err := applyNagiosLimits(cfg, nagiosCritical, true, limits.Critical); err != nil {
+		return nil, 0, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, 0, err
+	}
+	useThresholds(cfg.Thresholds)
+
+	selected, err := checks.GetChecks(cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	generator := summary.NewGenerator(cfg)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	results := checks.NewRunner(cfg).Run(ctx, selected)
+	stop()
+
+	systemSummary, err := generator.Generate(results)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to generate summary: %w", err)
+	}
+	systemSummary.Redact(redactor)
+	report, state := systemSummary.FormatNagios(limits, verbose)
+	return report, state, nil
+}
+
+// applyNagiosLimits parses label=value levels into levels. Levels on labels
+// backed by a threshold also set that threshold, so the check grades with it
+func applyNagiosLimits(cfg *config.Config, values []string, critical bool, levels map[string]float64) error {
+	flag := "--warning"
+	if critical {
+		flag = "--critical"
+	}
+	for _, value := range values {
+		label, level, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("%s %s: expected label=value", flag, value)
+		}
+		label, level = strings.TrimSpace(label), strings.TrimSpace(level)
+		parsed, err := strconv.ParseFloat(level, 64)
+		if err != nil {
+			return fmt.Errorf("%s %s: %q is not a number", flag, value, level)
+		}
+		key, err := summary.NagiosThresholdKey(label, critical)
+		if err != nil {
+			return fmt.Errorf("%s: %w", flag, err)
+		}
+		if key != "" {
+			if err := cfg.Set(key, level); err != nil {
+				return fmt.Errorf("%s: %w", flag, err)
+			}
+			cfg.Sources = append(cfg.Sources, flag+" "+label)
+		}
+		levels[label] = parsed
+	}
+	return nil
+}
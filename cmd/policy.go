@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var policyUnattended bool
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect the policy that decides which fixes may run",
+	Long: `Fix commands are evaluated against ` + fixes.DefaultPolicyPath + ` (or the
+policy_file setting), or built-in defaults when that file does not exist.`,
+}
+
+var policyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective fix policy",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		policy := loadPolicyOrExit()
+		fmt.Printf("# Source: %s\n", policy.Source)
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(policy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+var policyCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Evaluate the built-in fixes against the policy",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		policy := loadPolicyOrExit()
+		common := fixes.GetCommonFixes()
+
+		ids := make([]string, 0, len(common))
+		for id := range common {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		denied := 0
+		for _, id := range ids {
+			decision := policy.Evaluate(common[id], policyUnattended)
+			if decision.Allowed {
+				fmt.Printf("ALLOW  %s\n", id)
+				continue
+			}
+			denied++
+			fmt.Printf("DENY   %s\n", id)
+			for _, violation := range decision.Violations {
+				fmt.Printf("         %s\n", violation)
+			}
+		}
+
+		fmt.Printf("\n%d of %d fixes allowed by %s\n", len(ids)-denied, len(ids), policy.Source)
+	},
+}
+
+func init() {
+	policyCheckCmd.Flags().BoolVar(&policyUnattended, "unattended", false, "Also apply the unattended risk ceiling")
+	policyCmd.AddCommand(policyShowCmd)
+	policyCmd.AddCommand(policyCheckCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+func loadPolicyOrExit() *fixes.Policy {
+	policy, err := fixes.LoadConfiguredPolicy(mustLoadConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	return policy
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/redact"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"github.com/debian-doctor/debian-doctor/pkg/logger"
+)
+
+var (
+	redactOutput bool
+
+	// redactor is shared by every report, log and bundle of a run so a value
+	// gets the same placeholder in all of them. Nil when redaction is off
+	redactor *redact.Redactor
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&redactOutput, "redact", false, "Hide IPs, MACs, hostnames, home paths, emails and secrets in reports, logs and bundles")
+}
+
+// setupRedactor builds the run's redactor when redaction is enabled. The
+// local hostname is always hidden, with or without its domain
+func setupRedactor(cfg *config.Config) error {
+	if redactOutput {
+		cfg.Redact.Enabled = true
+	}
+	if !cfg.Redact.Enabled {
+		redactor = nil
+		return nil
+	}
+
+	terms := append([]string(nil), cfg.Redact.Terms...)
+	if hostname, err := os.Hostname(); err == nil {
+		short, _, _ := strings.Cut(hostname, ".")
+		terms = append(terms, hostname, short)
+	}
+	r, err := redact.New(cfg.Redact.Rules, terms...)
+	if err != nil {
+		return err
+	}
+	redactor = r
+	return nil
+}
+
+// newLogger opens the log file, redacting messages when redaction is on
+func newLogger(cfg *config.Config) (*logger.Logger, error) {
+	log, err := logger.NewFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		log.SetFilter(redactor.String)
+	}
+	return log, nil
+}
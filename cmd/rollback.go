@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackYes   bool
+	rollbackForce bool
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <run-id>",
+	Short: "Restore the files a fix run modified",
+	Long: `Restore every file that was snapshotted before a fix run, using the copies
+kept under the state directory. Run IDs are listed by 'debian-doctor journal'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRollback(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+func init() {
+	rollbackCmd.Flags().BoolVarP(&rollbackYes, "yes", "y", false, "Do not ask for confirmation")
+	rollbackCmd.Flags().BoolVar(&rollbackForce, "force", false, "Restore files even if they changed after the fix ran")
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+func runRollback(runID string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	log, err := newLogger(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to set up logger: %w", err)
+	}
+	defer log.Close()
+
+	executor := fixes.NewExecutor(cfg, log)
+	snapshot, err := executor.Snapshots().Load(runID)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Verify(); err != nil {
+		return err
+	}
+
+	conflicts := map[string]bool{}
+	for _, path := range snapshot.Conflicts() {
+		conflicts[path] = true
+	}
+
+	fmt.Printf("Rollback of run %s (%s), snapshot taken %s\n", snapshot.RunID, snapshot.FixID, snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	for _, file := range snapshot.Files {
+		action := "restore"
+		if !file.Existed {
+			action = "remove "
+		}
+		note := ""
+		if conflicts[file.Path] {
+			note = "  (changed since the fix ran)"
+		}
+		fmt.Printf("  %s %s%s\n", action, file.Path, note)
+	}
+
+	if dryRun {
+		fmt.Println("\nDry run only. No files were changed.")
+		return nil
+	}
+
+	if !rollbackYes {
+		response, _ := fixes.Terminal().Prompt("\nProceed with rollback? (y/N): ")
+		response = strings.ToLower(response)
+		if response != "y" && response != "yes" {
+			fmt.Println("Rollback cancelled.")
+			return nil
+		}
+	}
+
+	run, err := executor.Rollback(runID, rollbackForce)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nRollback complete (recorded as run %s)\n", run.ID)
+	return nil
+}
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/debian-doctor/debian-doctor/internal/checks"
 	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/debian-doctor/debian-doctor/internal/history"
+	"github.com/debian-doctor/debian-doctor/internal/summary"
 	"github.com/debian-doctor/debian-doctor/internal/tui"
 	"github.com/debian-doctor/debian-doctor/pkg/config"
-	"github.com/debian-doctor/debian-doctor/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +22,19 @@ var (
 	nonInteractive bool
 	verbose        bool
 	customIssue    string
+	outputFormat   string
+	outputFile     string
+	dryRun         bool
+)
+
+// Exit codes used in non-interactive mode. They follow the highest
+// severity reported by any check so that scripts can act on the result.
+const (
+	exitOK       = 0
+	exitWarning  = 1
+	exitError    = 2
+	exitCritical = 3
+	exitFailure  = 4 // debian-doctor itself could not complete the run
 )
 
 var rootCmd = &cobra.Command{
@@ -23,9 +43,11 @@ var rootCmd = &cobra.Command{
 	Long: `Debian Doctor performs automatic system health checks and provides 
 interactive problem diagnosis with fix suggestions for Debian-based systems.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if customIssue != "" {
+		if nagiosMode {
+			runNagios()
+		} else if customIssue != "" {
 			runCustomDiagnosis()
-		} else if nonInteractive {
+		} else if nonInteractive || cmd.Flags().Changed("format") || cmd.Flags().Changed("output") {
 			runNonInteractiveMode()
 		} else {
 			runTUI()
@@ -41,16 +63,18 @@ func init() {
 	rootCmd.Flags().BoolVarP(&nonInteractive, "non-interactive", "n", false, "Run in non-interactive mode")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.Flags().StringVarP(&customIssue, "issue", "i", "", "Describe a custom issue for troubleshooting")
+	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", summary.FormatText, "Report format for non-interactive mode (text, json, yaml, html, markdown, junit, prometheus)")
+	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write the non-interactive report to FILE instead of stdout")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show what fixes would do without executing anything")
 }
 
 func runTUI() {
 	// Set up configuration
-	cfg := config.New()
-	cfg.SetVerbose(verbose)
+	cfg := mustLoadConfig()
 	cfg.SetNonInteractive(nonInteractive)
 	
 	// Set up logger
-	log, err := logger.NewFromConfig(cfg)
+	log, err := newLogger(cfg)
 	if err != nil {
 		fmt.Printf("Error setting up logger: %v\n", err)
 		os.Exit(1)
@@ -59,6 +83,7 @@ func runTUI() {
 	
 	// Use simple text UI instead of Bubble Tea
 	ui := tui.NewSimpleUI(cfg, log)
+	ui.SetRedactor(redactor)
 	if err := ui.Run(); err != nil {
 		fmt.Printf("Error running UI: %v\n", err)
 		os.Exit(1)
@@ -66,15 +91,17 @@ func runTUI() {
 }
 
 func runCustomDiagnosis() {
+	cfg := mustLoadConfig()
+	
 	fmt.Printf("CUSTOM ISSUE DIAGNOSIS\n")
-	fmt.Printf("Issue: %s\n\n", customIssue)
+	fmt.Printf("Issue: %s\n\n", redactor.String(customIssue))
 	
 	diagnosis := diagnose.DiagnoseCustomIssue(customIssue)
 	
 	// Display findings
 	fmt.Println("ANALYSIS:")
-	for _, finding := range diagnosis.Findings {
-		fmt.Printf("  - %s\n", finding)
+	for _, summary := range diagnosis.Summaries() {
+		fmt.Printf("  - %s\n", redactor.String(summary))
 	}
 	
 	// Display troubleshooting suggestions
@@ -95,10 +122,10 @@ func runCustomDiagnosis() {
 				fmt.Printf("  ... and %d more (use interactive mode for full list)\n", len(diagnosis.Fixes)-10)
 				break
 			}
-			fmt.Printf("\n  %d. %s\n", i+1, fix.Title)
-			fmt.Printf("     %s\n", fix.Description)
+			fmt.Printf("\n  %d. %s\n", i+1, redactor.String(fix.Title))
+			fmt.Printf("     %s\n", redactor.String(fix.Description))
 			if len(fix.Commands) > 0 {
-				fmt.Printf("     Command: %s\n", fix.Commands[0])
+				fmt.Printf("     Command: %s\n", redactor.String(fix.Commands[0]))
 				if len(fix.Commands) > 1 {
 					fmt.Printf("     (+ %d more commands)\n", len(fix.Commands)-1)
 				}
@@ -110,10 +137,104 @@ func runCustomDiagnosis() {
 		}
 	}
 	
+	if dryRun && len(diagnosis.Fixes) > 0 {
+		showFixPlans(cfg, diagnosis.Fixes)
+	}
+	
 	fmt.Println("\nTIP: Run 'debian-doctor' without flags for interactive mode with more options")
 }
 
+// showFixPlans prints a dry-run plan for each fix without executing anything
+func showFixPlans(cfg *config.Config, fixList []*fixes.Fix) {
+	cfg.SetDryRun(true)
+	
+	log, err := newLogger(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting up logger: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	defer log.Close()
+	
+	executor := fixes.NewExecutor(cfg, log)
+	for _, fix := range fixList {
+		fmt.Println()
+		plan, err := executor.Plan(fix)
+		if err != nil {
+			fmt.Print(redactor.String(fmt.Sprintf("DRY RUN: %s\n  ! %v\n", fix.Title, err)))
+			continue
+		}
+		var rendered strings.Builder
+		plan.Render(&rendered)
+		fmt.Print(redactor.String(rendered.String()))
+	}
+}
+
 func runNonInteractiveMode() {
-	fmt.Println("Running system checks...")
-	// TODO: Implement non-interactive mode
-}
\ No newline at end of file
+	cfg := mustLoadConfig()
+	cfg.SetNonInteractive(true)
+
+	// Reject unknown formats before spending time on the checks
+	if _, err := (&summary.SystemSummary{}).Render(outputFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	selected, err := checks.GetChecks(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	generator := summary.NewGenerator(cfg)
+	runner := checks.NewRunner(cfg)
+	if verbose {
+		runner.Progress = func(result checks.CheckResult, done, total int) {
+			fmt.Fprintf(os.Stderr, "Finished check %d/%d: %s\n", done, total, result.Name)
+		}
+	}
+	
+	// Ctrl-C stops waiting for the remaining checks but still prints a report
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	results := runner.Run(ctx, selected)
+	stop()
+
+	systemSummary, err := generator.Generate(results)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating summary: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	if _, err := history.Record(cfg, systemSummary); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record run history: %v\n", err)
+	}
+
+	systemSummary.Redact(redactor)
+	report, err := systemSummary.Render(outputFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, report, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	} else {
+		os.Stdout.Write(report)
+	}
+
+	os.Exit(exitCodeForSeverity(results.HighestSeverity()))
+}
+
+// exitCodeForSeverity maps the worst check severity to a process exit code
+func exitCodeForSeverity(severity checks.Severity) int {
+	switch severity {
+	case checks.SeverityWarning:
+		return exitWarning
+	case checks.SeverityError:
+		return exitError
+	case checks.SeverityCritical:
+		return exitCritical
+	}
+	return exitOK
+}
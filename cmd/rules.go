@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/rules"
+	"github.com/spf13/cobra"
+)
+
+var rulesListJSON bool
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Inspect the declarative diagnosis rules",
+}
+
+var rulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the diagnosis rules in effect and where they come from",
+	Long: `List the built-in diagnosis rules and the rule packs found in the
+configured rule directories (/etc/debian-doctor/rules.d by default), with
+the diagnosis each belongs to and the condition it tests. Loading fails
+with the file and rule at fault when a pack is invalid.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		mustLoadConfig()
+		set := diagnose.Rules()
+
+		if rulesListJSON {
+			type listedFix struct {
+				ID    string `json:"id"`
+				Title string `json:"title"`
+			}
+			type listedRule struct {
+				ID        string            `json:"id"`
+				Diagnosis string            `json:"diagnosis"`
+				Severity  string            `json:"severity"`
+				Condition string            `json:"condition"`
+				Each      bool              `json:"each,omitempty"`
+				Params    map[string]string `json:"params,omitempty"`
+				Fixes     []listedFix       `json:"fixes,omitempty"`
+				Source    string            `json:"source"`
+			}
+			var listed []listedRule
+			for _, rule := range set.Rules() {
+				item := listedRule{
+					ID:        rule.ID,
+					Diagnosis: rule.Diagnosis,
+					Severity:  rule.Severity.String(),
+					Condition: rule.When.Describe(),
+					Each:      rule.Each,
+					Params:    rule.Params,
+					Source:    rule.Source,
+				}
+				for _, fix := range rule.Fixes {
+					item.Fixes = append(item.Fixes, listedFix{ID: fix.ID, Title: fix.Title})
+				}
+				listed = append(listed, item)
+			}
+			if err := printJSON(listed); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitFailure)
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tDIAGNOSIS\tSEVERITY\tWHEN\tFIXES\tSOURCE")
+		for _, rule := range set.Rules() {
+			fixIDs := make([]string, len(rule.Fixes))
+			for i, fix := range rule.Fixes {
+				fixIDs[i] = fix.ID
+			}
+			fixList := strings.Join(fixIDs, ",")
+			if fixList == "" {
+				fixList = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				rule.ID, rule.Diagnosis, rule.Severity, rule.When.Describe(), fixList, rule.Source)
+		}
+		w.Flush()
+	},
+}
+
+var rulesFactsCmd = &cobra.Command{
+	Use:   "facts",
+	Short: "List the facts diagnosis rules can test",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FACT\tKIND\tDESCRIPTION")
+		for _, fact := range rules.Facts() {
+			kind := "number"
+			if fact.IsList() {
+				kind = "list"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", fact.Name, kind, fact.Description)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rulesListCmd.Flags().BoolVar(&rulesListJSON, "json", false, "Print rules as JSON")
+	rulesCmd.AddCommand(rulesListCmd)
+	rulesCmd.AddCommand(rulesFactsCmd)
+	rootCmd.AddCommand(rulesCmd)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/summary"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	metricsAddr   string
+	serveInterval time.Duration
+)
+
+// minServeInterval keeps a slow check suite from running back to back
+const minServeInterval = 10 * time.Second
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the checks periodically and export the results to Prometheus",
+	Long: `Run the selected checks every --interval and serve the results of the
+latest run at /metrics in the Prometheus text format: the health score, the
+severity and duration of each check, package and unit counts, journal size,
+core dumps and resource usage. Periodic runs are not added to the run
+history.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runServe(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitFailure)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&metricsAddr, "metrics", ":9469", "Address to serve Prometheus metrics on")
+	serveCmd.Flags().DurationVar(&serveInterval, "interval", 5*time.Minute, "Time between check runs")
+	serveCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Report each finished check run on stderr")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// metricsExporter serves the metrics of the latest finished run
+type metricsExporter struct {
+	mu      sync.RWMutex
+	metrics []byte
+}
+
+func (e *metricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	metrics := e.metrics
+	e.mu.RUnlock()
+
+	if metrics == nil {
+		http.Error(w, "The first check run has not finished yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(metrics)
+}
+
+// update runs the checks once and publishes their metrics. A run cut short
+// by shutdown is not published
+func (e *metricsExporter) update(ctx context.Context, cfg *config.Config, runner *checks.Runner, selected []checks.Check) {
+	generator := summary.NewGenerator(cfg)
+	results := runner.Run(ctx, selected)
+	if ctx.Err() != nil {
+		return
+	}
+
+	systemSummary, err := generator.Generate(results)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating summary: %v\n", err)
+		return
+	}
+	systemSummary.Redact(redactor)
+	metrics := systemSummary.FormatPrometheus()
+
+	e.mu.Lock()
+	e.metrics = metrics
+	e.mu.Unlock()
+
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Check run finished in %s, health score %d\n",
+			systemSummary.Duration.Round(time.Millisecond), systemSummary.HealthScore)
+	}
+}
+
+func runServe() error {
+	if serveInterval < minServeInterval {
+		return fmt.Errorf("--interval must be at least %s", minServeInterval)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg.SetNonInteractive(true)
+	// A run every few minutes would push manual runs out of the history
+	cfg.History.Enabled = false
+
+	selected, err := checks.GetChecks(cfg)
+	if err != nil {
+		return err
+	}
+	runner := checks.NewRunner(cfg)
+
+	exporter := &metricsExporter{}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `<html><head><title>Debian Doctor</title></head><body><h1>Debian Doctor</h1><p><a href="/metrics">Metrics</a></p></body></html>`)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	// Listen before the first run so a taken port fails straight away
+	listener, err := net.Listen("tcp", metricsAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", metricsAddr, err)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	fmt.Printf("Serving metrics on http://%s/metrics, running checks every %s\n", listener.Addr(), serveInterval)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(serveInterval)
+	defer ticker.Stop()
+	for {
+		exporter.update(ctx, cfg, runner, selected)
+
+		select {
+		case <-ticker.C:
+		case err := <-serveErr:
+			if !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("metrics server failed: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdownCtx)
+		}
+	}
+}
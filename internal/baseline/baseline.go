@@ -0,0 +1,180 @@
+// Package baseline records a known-good state of a host: its packages, unit
+// states, APT sources, listening sockets, setuid binaries and the permissions
+// of key files. A host checked against a baseline reports what drifted
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/probes"
+)
+
+// FileName is the name of the baseline file inside the state directory
+const FileName = "baseline.json"
+
+// Sections of a baseline, in report order
+const (
+	SectionPackages     = "packages"
+	SectionHeld         = "held_packages"
+	SectionEnabledUnits = "enabled_units"
+	SectionMaskedUnits  = "masked_units"
+	SectionAPTSources   = "apt_sources"
+	SectionListening    = "listening_sockets"
+	SectionSUID         = "suid_binaries"
+	SectionFileModes    = "file_modes"
+)
+
+// Sections lists every section of a baseline in report order
+var Sections = []string{
+	SectionPackages, SectionHeld, SectionEnabledUnits, SectionMaskedUnits,
+	SectionAPTSources, SectionListening, SectionSUID, SectionFileModes,
+}
+
+// missingFile is the file mode recorded for a key path that does not exist
+const missingFile = "missing"
+
+// Baseline is the recorded state of a host
+type Baseline struct {
+	Hostname     string            `json:"hostname"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Packages     map[string]string `json:"packages"` // Installed version by package name
+	Held         []string          `json:"held_packages"`
+	EnabledUnits []string          `json:"enabled_units"`
+	MaskedUnits  []string          `json:"masked_units"`
+	APTSources   []string          `json:"apt_sources"`
+	Listening    []string          `json:"listening_sockets"`
+	SUIDBinaries []string          `json:"suid_binaries"`
+	FileModes    map[string]string `json:"file_modes"`            // Mode and owner by path, e.g. "-rw-r----- 0:42"
+	Unavailable  []string          `json:"unavailable,omitempty"` // Sections that could not be gathered
+}
+
+// Capture records the current state of the machine behind p. paths are the
+// key files and directories whose permissions are kept. A section whose
+// facts cannot be gathered, e.g. because ss is not installed, is listed as
+// unavailable and left out of later comparisons
+func Capture(p *probes.Probes, paths []string) *Baseline {
+	b := &Baseline{
+		Hostname:  hostname(p),
+		CreatedAt: time.Now(),
+		Packages:  make(map[string]string),
+		FileModes: make(map[string]string),
+	}
+	unavailable := func(section string, err error) bool {
+		if err != nil {
+			b.Unavailable = append(b.Unavailable, section)
+		}
+		return err != nil
+	}
+
+	packages, err := p.Packages()
+	if !unavailable(SectionPackages, err) {
+		for _, pkg := range packages {
+			if pkg.Installed() {
+				b.Packages[pkg.Name] = pkg.Version
+			}
+		}
+	}
+
+	b.Held, err = p.HeldPackages()
+	unavailable(SectionHeld, err)
+
+	b.EnabledUnits, err = p.UnitFilesInState("", "enabled")
+	if !unavailable(SectionEnabledUnits, err) {
+		b.MaskedUnits, _ = p.UnitFilesInState("", "masked")
+	} else {
+		b.Unavailable = append(b.Unavailable, SectionMaskedUnits)
+	}
+
+	b.APTSources, err = p.APTSources()
+	unavailable(SectionAPTSources, err)
+
+	b.Listening, err = p.ListeningSockets()
+	unavailable(SectionListening, err)
+
+	b.SUIDBinaries, err = p.SUIDBinaries()
+	unavailable(SectionSUID, err)
+
+	for _, path := range paths {
+		b.FileModes[path] = fileMode(p, path)
+	}
+
+	return b
+}
+
+// hostname names the machine behind p, which may be a replayed fixture
+func hostname(p *probes.Probes) string {
+	if data, err := p.Machine().ReadFile("/etc/hostname"); err == nil {
+		if name := strings.TrimSpace(string(data)); name != "" {
+			return name
+		}
+	}
+	name, _ := os.Hostname()
+	return name
+}
+
+// fileMode describes a path's mode and owner
+func fileMode(p *probes.Probes, path string) string {
+	info, err := p.Machine().Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return missingFile
+		}
+		return "unreadable"
+	}
+	return describeMode(info)
+}
+
+func describeMode(info fs.FileInfo) string {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fmt.Sprintf("%s %d:%d", info.Mode(), stat.Uid, stat.Gid)
+	}
+	return info.Mode().String()
+}
+
+// Available reports whether a section was gathered
+func (b *Baseline) Available(section string) bool {
+	for _, s := range b.Unavailable {
+		if s == section {
+			return false
+		}
+	}
+	return true
+}
+
+// Save writes the baseline as indented JSON, creating its directory
+func (b *Baseline) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create baseline directory: %w", err)
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Load reads a baseline written by Save
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no baseline at %s; run 'debian-doctor baseline save' first", path)
+		}
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("corrupt baseline %s: %w", path, err)
+	}
+	return &b, nil
+}
@@ -0,0 +1,85 @@
+package baseline
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
+)
+
+// host is a machine with two packages, one enabled unit and one socket
+func host() *machine.Fixture {
+	fixture := machine.NewFixture()
+	fixture.AddCommand("ii  bash  5.2.15-2  amd64  GNU Bourne Again SHell\nii  openssh-server  1:9.2p1-2  amd64  SSH server\n", 0, "dpkg", "-l")
+	fixture.AddCommand("", 0, "apt-mark", "showhold")
+	fixture.AddCommand("ssh.service enabled enabled\nbluetooth.service masked enabled\n", 0,
+		"systemctl", "list-unit-files", "--no-legend", "--no-pager")
+	fixture.AddFile("/etc/apt/sources.list", "deb http://deb.debian.org/debian bookworm main\n")
+	fixture.AddCommand("tcp LISTEN 0 128 0.0.0.0:22 0.0.0.0:*\n", 0, "ss", "-H", "-l", "-n", "-t", "-u")
+	fixture.AddCommand("/usr/bin/passwd\n/usr/bin/sudo\n", 1, "find",
+		append(append([]string{}, probes.SUIDDirs...), "-xdev", "-type", "f", "-perm", "-4000")...)
+	fixture.AddFile("/etc/hostname", "web01\n")
+	fixture.AddFile("/etc/passwd", "root:x:0:0:root:/root:/bin/bash\n")
+	return fixture
+}
+
+func TestCapture(t *testing.T) {
+	b := Capture(probes.New(host().Machine()), []string{"/etc/passwd", "/etc/shadow"})
+
+	if b.Hostname != "web01" {
+		t.Errorf("Hostname = %q", b.Hostname)
+	}
+	if want := map[string]string{"bash": "5.2.15-2", "openssh-server": "1:9.2p1-2"}; !reflect.DeepEqual(b.Packages, want) {
+		t.Errorf("Packages = %v", b.Packages)
+	}
+	if !reflect.DeepEqual(b.EnabledUnits, []string{"ssh.service"}) || !reflect.DeepEqual(b.MaskedUnits, []string{"bluetooth.service"}) {
+		t.Errorf("Units = %v, %v", b.EnabledUnits, b.MaskedUnits)
+	}
+	// find exits 1 on unreadable directories, but what it found counts
+	if !reflect.DeepEqual(b.SUIDBinaries, []string{"/usr/bin/passwd", "/usr/bin/sudo"}) {
+		t.Errorf("SUIDBinaries = %v", b.SUIDBinaries)
+	}
+	if b.FileModes["/etc/passwd"] != "-rw-r--r-- 0:0" || b.FileModes["/etc/shadow"] != missingFile {
+		t.Errorf("FileModes = %v", b.FileModes)
+	}
+	if len(b.Unavailable) != 0 {
+		t.Errorf("Unavailable = %v", b.Unavailable)
+	}
+}
+
+func TestSaveAndCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline", FileName)
+	if err := Capture(probes.New(host().Machine()), []string{"/etc/passwd"}).Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	base, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if drift := Check(base, Capture(probes.New(host().Machine()), []string{"/etc/passwd"})); len(drift) != 0 {
+		t.Errorf("Unchanged host drifted: %v", drift)
+	}
+
+	fixture := host()
+	fixture.AddCommand("ii  bash  5.2.15-3  amd64  GNU Bourne Again SHell\nii  telnetd  0.17-44  amd64  Telnet server\n", 0, "dpkg", "-l")
+	fixture.AddCommand("ssh.service enabled enabled\nbluetooth.service masked enabled\ntelnet.socket enabled enabled\n", 0,
+		"systemctl", "list-unit-files", "--no-legend", "--no-pager")
+	fixture.AddCommand("tcp LISTEN 0 128 0.0.0.0:23 0.0.0.0:*\n", 1, "ss", "-H", "-l", "-n", "-t", "-u")
+	current := Capture(probes.New(fixture.Machine()), []string{"/etc/passwd", "/etc/sudoers"})
+
+	want := []Drift{
+		{Section: SectionPackages, Item: "bash", Kind: Changed, Expected: "5.2.15-2", Actual: "5.2.15-3"},
+		{Section: SectionPackages, Item: "openssh-server", Kind: Removed, Expected: "1:9.2p1-2"},
+		{Section: SectionPackages, Item: "telnetd", Kind: Added, Actual: "0.17-44"},
+		{Section: SectionEnabledUnits, Item: "telnet.socket", Kind: Added},
+	}
+	if got := Check(base, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %+v\nwant %+v", got, want)
+	}
+	if current.Available(SectionListening) {
+		t.Error("Listening sockets should be unavailable when ss fails")
+	}
+}
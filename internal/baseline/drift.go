@@ -0,0 +1,116 @@
+package baseline
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Kinds of drift
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Drift is one difference between a baseline and the current state
+type Drift struct {
+	Section  string `json:"section"`
+	Item     string `json:"item"`
+	Kind     string `json:"kind"` // added, removed or changed
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+func (d Drift) String() string {
+	switch d.Kind {
+	case Added:
+		if d.Actual != "" {
+			return fmt.Sprintf("+ %s (%s)", d.Item, d.Actual)
+		}
+		return "+ " + d.Item
+	case Removed:
+		if d.Expected != "" {
+			return fmt.Sprintf("- %s (%s)", d.Item, d.Expected)
+		}
+		return "- " + d.Item
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", d.Item, d.Expected, d.Actual)
+}
+
+// Check reports how current has drifted from base, section by section in
+// report order. Sections unavailable on either side are skipped
+func Check(base, current *Baseline) []Drift {
+	var drift []Drift
+	for _, section := range Sections {
+		if !base.Available(section) || !current.Available(section) {
+			continue
+		}
+		var found []Drift
+		switch section {
+		case SectionPackages:
+			found = compareMaps(base.Packages, current.Packages)
+		case SectionHeld:
+			found = compareSets(base.Held, current.Held)
+		case SectionEnabledUnits:
+			found = compareSets(base.EnabledUnits, current.EnabledUnits)
+		case SectionMaskedUnits:
+			found = compareSets(base.MaskedUnits, current.MaskedUnits)
+		case SectionAPTSources:
+			found = compareSets(base.APTSources, current.APTSources)
+		case SectionListening:
+			found = compareSets(base.Listening, current.Listening)
+		case SectionSUID:
+			found = compareSets(base.SUIDBinaries, current.SUIDBinaries)
+		case SectionFileModes:
+			// Only the paths the baseline kept are compared, so a newer
+			// debian-doctor checking more paths does not report them all
+			kept := make(map[string]string, len(base.FileModes))
+			for path := range base.FileModes {
+				if mode, ok := current.FileModes[path]; ok {
+					kept[path] = mode
+				}
+			}
+			found = compareMaps(base.FileModes, kept)
+		}
+		for i := range found {
+			found[i].Section = section
+		}
+		drift = append(drift, found...)
+	}
+	return drift
+}
+
+// compareSets reports the items added to or removed from a list
+func compareSets(expected, actual []string) []Drift {
+	was := make(map[string]string, len(expected))
+	for _, item := range expected {
+		was[item] = ""
+	}
+	is := make(map[string]string, len(actual))
+	for _, item := range actual {
+		is[item] = ""
+	}
+	return compareMaps(was, is)
+}
+
+// compareMaps reports the keys added, removed or whose value changed,
+// sorted by key
+func compareMaps(expected, actual map[string]string) []Drift {
+	var drift []Drift
+	for key, want := range expected {
+		got, ok := actual[key]
+		switch {
+		case !ok:
+			drift = append(drift, Drift{Item: key, Kind: Removed, Expected: want})
+		case got != want:
+			drift = append(drift, Drift{Item: key, Kind: Changed, Expected: want, Actual: got})
+		}
+	}
+	for key, got := range actual {
+		if _, ok := expected[key]; !ok {
+			drift = append(drift, Drift{Item: key, Kind: Added, Actual: got})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Item < drift[j].Item })
+	return drift
+}
@@ -0,0 +1,371 @@
+// Package bundle captures what checks and diagnoses observe on a machine
+// into a compressed tarball, and opens such a tarball as a replayable
+// machine so the same analysis can run somewhere else
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/internal/redact"
+)
+
+// Format is the bundle layout version written to the manifest
+const Format = 1
+
+// ManifestName is the manifest's file name inside the bundle
+const ManifestName = "manifest.json"
+
+// Limits on what Read accepts, after decompression
+var (
+	maxFileSize  = 64 << 20
+	maxTotalSize = 512 << 20
+)
+
+// Manifest describes a bundle and indexes every recorded interaction
+type Manifest struct {
+	Format      int       `json:"format"`
+	Hostname    string    `json:"hostname"`
+	CollectedAt time.Time `json:"collected_at"`
+	Root        bool      `json:"root"` // Whether root-only checks could run
+	Entries     []Entry   `json:"entries"`
+}
+
+// Entry is a recorded interaction. Command output and file contents are
+// stored as separate files in the bundle, named here, so the bundle can be
+// browsed without debian-doctor
+type Entry struct {
+	machine.Record
+	StdoutFile string `json:"stdout_file,omitempty"`
+	StderrFile string `json:"stderr_file,omitempty"`
+	DataFile   string `json:"data_file,omitempty"`
+}
+
+// Counts returns how many commands and files the bundle holds
+func (m *Manifest) Counts() (commands, files int) {
+	for _, entry := range m.Entries {
+		switch entry.Kind {
+		case machine.KindCommand:
+			commands++
+		case machine.KindFile:
+			files++
+		}
+	}
+	return commands, files
+}
+
+// Redact anonymises the hostname and every recorded interaction. Command
+// lines and paths are redacted along with output, and r maps each value to
+// the same placeholder everywhere, so the bundle still replays consistently
+func (m *Manifest) Redact(r *redact.Redactor) {
+	m.Hostname = r.String(m.Hostname)
+	for i := range m.Entries {
+		m.Entries[i].Record = redactRecord(r, m.Entries[i].Record)
+	}
+}
+
+func redactRecord(r *redact.Redactor, rec machine.Record) machine.Record {
+	dir := rec.Name
+	rec.Name = r.String(rec.Name)
+	rec.Args = r.Strings(append([]string(nil), rec.Args...))
+	rec.Stdout = r.String(rec.Stdout)
+	rec.Stderr = r.String(rec.Stderr)
+	rec.Data = r.String(rec.Data)
+	rec.Path = r.String(rec.Path)
+
+	if rec.Info != nil {
+		info := *rec.Info
+		info.Name = path.Base(rec.Name)
+		rec.Info = &info
+	}
+	// Entry names are redacted as full paths so /home/alice lists user-1
+	if rec.Entries != nil {
+		entries := make([]machine.FileInfo, len(rec.Entries))
+		for i, entry := range rec.Entries {
+			entry.Name = path.Base(r.String(path.Join(dir, entry.Name)))
+			entries[i] = entry
+		}
+		rec.Entries = entries
+	}
+	if rec.Interfaces != nil {
+		ifaces := make([]machine.Interface, len(rec.Interfaces))
+		for i, iface := range rec.Interfaces {
+			iface.Addrs = r.Strings(append([]string(nil), iface.Addrs...))
+			ifaces[i] = iface
+		}
+		rec.Interfaces = ifaces
+	}
+	return rec
+}
+
+// Bundle is an opened bundle
+type Bundle struct {
+	Manifest *Manifest
+	Fixture  *machine.Fixture
+}
+
+// Machine returns a machine that answers from the bundle
+func (b *Bundle) Machine() *machine.Machine {
+	return b.Fixture.Machine()
+}
+
+// Write stores the manifest and the payload of each of its entries as a
+// gzipped tarball. Everything is placed under a top-level directory named
+// after root. The manifest comes first, so Read knows which files to expect
+func Write(w io.Writer, root string, manifest *Manifest) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	index := *manifest
+	index.Format = Format
+	index.Entries = make([]Entry, len(manifest.Entries))
+	names := newNamer()
+
+	type payload struct {
+		name string
+		data string
+	}
+	var payloads []payload
+	for i, entry := range manifest.Entries {
+		rec := entry.Record
+		var out Entry
+
+		if rec.Stdout != "" {
+			out.StdoutFile = names.command(rec, ".stdout")
+			payloads = append(payloads, payload{out.StdoutFile, rec.Stdout})
+		}
+		if rec.Stderr != "" {
+			out.StderrFile = names.command(rec, ".stderr")
+			payloads = append(payloads, payload{out.StderrFile, rec.Stderr})
+		}
+		if rec.Data != "" {
+			out.DataFile = names.file(rec.Name)
+			payloads = append(payloads, payload{out.DataFile, rec.Data})
+		}
+
+		rec.Stdout, rec.Stderr, rec.Data = "", "", ""
+		out.Record = rec
+		index.Entries[i] = out
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := addFile(tw, root, ManifestName, manifest.CollectedAt, append(data, '\n')); err != nil {
+		return err
+	}
+	for _, p := range payloads {
+		if err := addFile(tw, root, p.name, manifest.CollectedAt, []byte(p.data)); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return gz.Close()
+}
+
+func addFile(tw *tar.Writer, root, name string, modTime time.Time, data []byte) error {
+	header := &tar.Header{
+		Name:    path.Join(root, name),
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	return nil
+}
+
+// namer picks readable, unique names for payload files
+type namer struct {
+	used map[string]bool
+}
+
+func newNamer() *namer {
+	return &namer{used: make(map[string]bool)}
+}
+
+// command names output after the command line, e.g.
+// commands/dpkg_--audit.stdout
+func (n *namer) command(rec machine.Record, suffix string) string {
+	words := append([]string{path.Base(rec.Name)}, rec.Args...)
+	base := sanitize(strings.Join(words, " "))
+	if len(base) > 100 {
+		base = base[:100]
+	}
+	return n.unique("commands/"+base, suffix)
+}
+
+// file mirrors the file's own path, e.g. files/etc/os-release
+func (n *namer) file(name string) string {
+	clean := strings.TrimPrefix(path.Clean("/"+name), "/")
+	return n.unique("files/"+clean, "")
+}
+
+func (n *namer) unique(base, suffix string) string {
+	name := base + suffix
+	for i := 2; n.used[name]; i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, suffix)
+	}
+	n.used[name] = true
+	return name
+}
+
+func sanitize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == '@':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// listedFiles returns the manifest and the payload files it names
+func listedFiles(data []byte) (map[string]bool, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+	listed := map[string]bool{ManifestName: true}
+	for _, entry := range manifest.Entries {
+		for _, name := range []string{entry.StdoutFile, entry.StderrFile, entry.DataFile} {
+			if name != "" {
+				listed[name] = true
+			}
+		}
+	}
+	return listed, nil
+}
+
+// Open reads a bundle written by Write
+func Open(name string) (*Bundle, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+
+	b, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", name, err)
+	}
+	return b, nil
+}
+
+// Read parses a bundle from r. Files the manifest does not list are
+// skipped, and no file may be larger than 64 MiB or the files together
+// larger than 512 MiB, so a hostile bundle cannot exhaust memory
+func Read(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	// Payload names in the manifest are relative to the top-level directory.
+	// Bundles written before the manifest came first list it last, so files
+	// seen before it are kept and weeded out once it is read
+	contents := make(map[string][]byte)
+	var listed map[string]bool
+	total := 0
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if _, rest, ok := strings.Cut(name, "/"); ok {
+			name = rest
+		}
+		if listed != nil && !listed[name] {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, int64(maxFileSize)+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxFileSize {
+			return nil, fmt.Errorf("%s is larger than %d bytes", name, maxFileSize)
+		}
+		if total += len(data); total > maxTotalSize {
+			return nil, fmt.Errorf("bundle contents are larger than %d bytes", maxTotalSize)
+		}
+		contents[name] = data
+
+		if name == ManifestName {
+			if listed, err = listedFiles(data); err != nil {
+				return nil, err
+			}
+			for seen := range contents {
+				if !listed[seen] {
+					total -= len(contents[seen])
+					delete(contents, seen)
+				}
+			}
+		}
+	}
+
+	data, ok := contents[ManifestName]
+	if !ok {
+		return nil, fmt.Errorf("no %s found; not a debian-doctor bundle", ManifestName)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("unsupported bundle format %d (expected %d)", manifest.Format, Format)
+	}
+
+	fixture := machine.NewFixture()
+	for _, entry := range manifest.Entries {
+		rec := entry.Record
+		for _, payload := range []struct {
+			name  string
+			field *string
+		}{
+			{entry.StdoutFile, &rec.Stdout},
+			{entry.StderrFile, &rec.Stderr},
+			{entry.DataFile, &rec.Data},
+		} {
+			if payload.name == "" {
+				continue
+			}
+			content, ok := contents[payload.name]
+			if !ok {
+				return nil, fmt.Errorf("%s lists %s, which is missing", ManifestName, payload.name)
+			}
+			*payload.field = string(content)
+		}
+		fixture.Add(rec)
+	}
+
+	return &Bundle{Manifest: &manifest, Fixture: fixture}, nil
+}
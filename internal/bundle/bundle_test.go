@@ -0,0 +1,267 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os/exec"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/internal/redact"
+)
+
+func sourceMachine() *machine.Machine {
+	f := machine.NewFixture()
+	f.AddFile("/etc/os-release", "NAME=\"Debian GNU/Linux\"\nVERSION=\"12 (bookworm)\"\n")
+	f.AddFile("/proc/meminfo", "MemTotal: 1000 kB\nMemAvailable: 100 kB\n")
+	f.AddCommand("iU  libfoo 1.0 amd64 broken\n", 0, "dpkg", "-l")
+	f.Add(machine.Record{Kind: machine.KindCommand, Name: "apt", Args: []string{"update", "--dry-run"}, Stderr: "E: Malformed line\n", ExitCode: 100})
+	f.Add(machine.Record{Kind: machine.KindStatfs, Name: "/", Statfs: &machine.Statfs{Blocks: 100, Bavail: 5, Bsize: 4096, Files: 10, Ffree: 5}})
+	return f.Machine()
+}
+
+func TestCollectWriteRead(t *testing.T) {
+	collector := NewCollector(sourceMachine())
+	m := collector.Machine()
+	m.ReadFile("/etc/os-release")
+	m.ReadFile("/etc/os-release") // Repeated reads are stored once
+	m.Output("dpkg", "-l")
+	m.CombinedOutput("apt", "update", "--dry-run")
+	m.Statfs("/")
+	m.ReadFile("/etc/missing")
+	collector.CollectEssentials()
+
+	manifest := collector.Manifest(true)
+	manifest.Hostname = "web1/../x"
+	manifest.CollectedAt = time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, RootName(manifest), manifest); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := DefaultName(manifest); got != "debian-doctor-web1_.._x-20240102-150405.tar.gz" {
+		t.Errorf("DefaultName() = %s", got)
+	}
+
+	names := tarNames(t, buf.Bytes())
+	for _, want := range []string{
+		"debian-doctor-web1_.._x-20240102-150405/manifest.json",
+		"debian-doctor-web1_.._x-20240102-150405/files/etc/os-release",
+		"debian-doctor-web1_.._x-20240102-150405/files/proc/meminfo",
+		"debian-doctor-web1_.._x-20240102-150405/commands/dpkg_-l.stdout",
+		"debian-doctor-web1_.._x-20240102-150405/commands/apt_update_--dry-run.stderr",
+	} {
+		if !contains(names, want) {
+			t.Errorf("Bundle missing %s; has %v", want, names)
+		}
+	}
+
+	b, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !b.Manifest.Root || b.Manifest.Hostname != "web1/../x" {
+		t.Errorf("Manifest = %+v", b.Manifest)
+	}
+	if commands, files := b.Manifest.Counts(); commands != 2+len(essentialCommands) || files != 1+len(essentialFiles) {
+		t.Errorf("Counts() = %d commands, %d files", commands, files)
+	}
+
+	replay := b.Machine()
+	if data, err := replay.ReadFile("/etc/os-release"); err != nil || !strings.Contains(string(data), "bookworm") {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+	if out, err := replay.Output("dpkg", "-l"); err != nil || string(out) != "iU  libfoo 1.0 amd64 broken\n" {
+		t.Errorf("Output() = %q, %v", out, err)
+	}
+	if out, err := replay.CombinedOutput("apt", "update", "--dry-run"); err == nil || string(out) != "E: Malformed line\n" {
+		t.Errorf("CombinedOutput() = %q, %v", out, err)
+	}
+	if stat, err := replay.Statfs("/"); err != nil || stat.Bavail != 5 {
+		t.Errorf("Statfs() = %+v, %v", stat, err)
+	}
+	if _, err := replay.ReadFile("/etc/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Missing file replayed as %v", err)
+	}
+	// Essentials absent on the source machine stay absent
+	if err := replay.Run("ip", "addr"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Run(ip addr) = %v", err)
+	}
+}
+
+func TestReadRejectsOtherArchives(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"no manifest", map[string]string{"x/readme": "hi"}, "not a debian-doctor bundle"},
+		{"future format", map[string]string{"x/manifest.json": `{"format":99}`}, "unsupported bundle format 99"},
+		{"missing payload", map[string]string{"x/manifest.json": `{"format":1,"entries":[{"kind":"file","name":"/etc/hosts","data_file":"files/etc/hosts"}]}`}, "files/etc/hosts, which is missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(makeTarball(t, tt.files)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Read(strings.NewReader("plain text")); err == nil {
+		t.Error("Expected an error for a file that is not gzipped")
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	manifest := `{"format":1,"entries":[{"kind":"file","name":"/etc/hosts","data_file":"files/hosts"},{"kind":"file","name":"/etc/motd","data_file":"files/motd"}]}`
+	defer func(file, total int) { maxFileSize, maxTotalSize = file, total }(maxFileSize, maxTotalSize)
+	maxFileSize, maxTotalSize = len(manifest), len(manifest)+30
+
+	// A file the manifest does not list is never read
+	b, err := Read(bytes.NewReader(makeTarball(t, map[string]string{
+		"x/files/hosts":   "hosts",
+		"x/files/motd":    "motd",
+		"x/manifest.json": manifest,
+		"x/zzz-unlisted":  strings.Repeat("x", 2*len(manifest)),
+	})))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if data, err := b.Machine().ReadFile("/etc/hosts"); err != nil || string(data) != "hosts" {
+		t.Errorf("ReadFile(/etc/hosts) = %q, %v", data, err)
+	}
+
+	tests := []struct {
+		name  string
+		hosts string
+		want  string
+	}{
+		{"file too large", strings.Repeat("x", len(manifest)+1), "files/hosts is larger than"},
+		{"too large in total", strings.Repeat("x", 31), "bundle contents are larger than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"x/files/hosts": tt.hosts, "x/files/motd": "", "x/manifest.json": manifest}
+			_, err := Read(bytes.NewReader(makeTarball(t, files)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func tarNames(t *testing.T, data []byte) []string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func makeTarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names) // So tests control which files come first
+	for _, name := range names {
+		content := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRedactedBundleReplaysConsistently(t *testing.T) {
+	f := machine.NewFixture()
+	f.AddFile("/etc/resolv.conf", "nameserver 10.0.0.53\n")
+	f.AddCommand("64 bytes from 10.0.0.53\n", 0, "ping", "-c", "1", "10.0.0.53")
+	f.Add(machine.Record{Kind: machine.KindDir, Name: "/home", Entries: []machine.FileInfo{{Name: "alice", Mode: fs.ModeDir | 0755}}})
+	f.Add(machine.Record{Kind: machine.KindInterfaces, Interfaces: []machine.Interface{{Name: "eth0", Up: true, Addrs: []string{"10.0.0.7/24"}}}})
+
+	collector := NewCollector(f.Machine())
+	m := collector.Machine()
+	m.ReadFile("/etc/resolv.conf")
+	m.Output("ping", "-c", "1", "10.0.0.53")
+	m.ReadDir("/home")
+	m.Interfaces()
+
+	r, err := redact.New(nil, "web1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := collector.Manifest(true)
+	manifest.Hostname = "web1"
+	manifest.Redact(r)
+	if manifest.Hostname != "host-1" {
+		t.Errorf("Hostname = %s", manifest.Hostname)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, RootName(manifest), manifest); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "10.0.0") {
+		t.Error("Bundle still contains an address")
+	}
+	b, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	// A check that pings the nameserver it read still finds the recording
+	replay := b.Machine()
+	data, _ := replay.ReadFile("/etc/resolv.conf")
+	if string(data) != "nameserver ip-1\n" {
+		t.Errorf("ReadFile() = %q", data)
+	}
+	if out, err := replay.Output("ping", "-c", "1", "ip-1"); err != nil || string(out) != "64 bytes from ip-1\n" {
+		t.Errorf("Output() = %q, %v", out, err)
+	}
+	if entries, err := replay.ReadDir("/home"); err != nil || len(entries) != 1 || entries[0].Name() != "user-1" {
+		t.Errorf("ReadDir() = %v, %v", entries, err)
+	}
+	if ifaces, err := replay.Interfaces(); err != nil || ifaces[0].Addrs[0] != "ip-2/24" {
+		t.Errorf("Interfaces() = %+v, %v", ifaces, err)
+	}
+}
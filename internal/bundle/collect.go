@@ -0,0 +1,119 @@
+package bundle
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+)
+
+// essentialFiles are always collected, whichever checks run, so support
+// staff can read them even when no check looks at them
+var essentialFiles = []string{
+	"/etc/os-release",
+	"/etc/debian_version",
+	"/etc/fstab",
+	"/etc/hosts",
+	"/etc/resolv.conf",
+	"/etc/apt/sources.list",
+	"/proc/cpuinfo",
+	"/proc/meminfo",
+	"/proc/loadavg",
+	"/proc/mounts",
+	"/proc/uptime",
+	"/proc/sys/kernel/hostname",
+	"/proc/sys/kernel/osrelease",
+}
+
+// essentialCommands are always collected for the same reason
+var essentialCommands = [][]string{
+	{"uname", "-a"},
+	{"mount"},
+	{"df", "-h"},
+	{"df", "-i"},
+	{"dpkg", "--audit"},
+	{"systemctl", "list-units", "--failed", "--no-legend", "--plain", "--no-pager"},
+	{"journalctl", "-b", "-p", "warning", "-n", "500", "--no-pager"},
+	{"dmesg"},
+	{"ip", "addr"},
+	{"ip", "route"},
+}
+
+// Collector records everything read through its machine. Repeated reads of
+// the same file or runs of the same command keep the latest result
+type Collector struct {
+	machine *machine.Machine
+
+	// A check abandoned after its deadline may still be reading
+	mu      sync.Mutex
+	entries []Entry
+	index   map[string]int
+}
+
+// NewCollector wraps inner so everything read through Machine is collected
+func NewCollector(inner *machine.Machine) *Collector {
+	c := &Collector{index: make(map[string]int)}
+	c.machine = machine.Capture(inner, c.add)
+	return c
+}
+
+// Machine returns the machine checks and diagnoses should use
+func (c *Collector) Machine() *machine.Machine {
+	return c.machine
+}
+
+func (c *Collector) add(rec machine.Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := rec.Kind + "\x00" + rec.Name + "\x00" + strings.Join(rec.Args, "\x00")
+	if i, ok := c.index[key]; ok {
+		c.entries[i] = Entry{Record: rec}
+		return
+	}
+	c.index[key] = len(c.entries)
+	c.entries = append(c.entries, Entry{Record: rec})
+}
+
+// CollectEssentials reads the files and runs the commands every bundle
+// should contain. Missing files and programs are recorded as such
+func (c *Collector) CollectEssentials() {
+	for _, name := range essentialFiles {
+		c.machine.ReadFile(name)
+	}
+	for _, command := range essentialCommands {
+		c.machine.Run(command[0], command[1:]...)
+	}
+	c.machine.Interfaces()
+	c.machine.CPUPercent()
+}
+
+// Manifest describes everything collected so far
+func (c *Collector) Manifest(isRoot bool) *Manifest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hostname, _ := os.Hostname()
+	return &Manifest{
+		Format:      Format,
+		Hostname:    hostname,
+		CollectedAt: time.Now().UTC(),
+		Root:        isRoot,
+		Entries:     append([]Entry(nil), c.entries...),
+	}
+}
+
+// DefaultName is the bundle file name used when none is given, e.g.
+// debian-doctor-web1-20240102-150405.tar.gz
+func DefaultName(manifest *Manifest) string {
+	return RootName(manifest) + ".tar.gz"
+}
+
+// RootName is the top-level directory inside the bundle
+func RootName(manifest *Manifest) string {
+	host := sanitize(manifest.Hostname)
+	if host == "" {
+		host = "unknown"
+	}
+	return "debian-doctor-" + host + "-" + manifest.CollectedAt.Format("20060102-150405")
+}
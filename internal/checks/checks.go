@@ -1,25 +1,81 @@
 package checks
 
-import "os"
+import (
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
 
-// GetAllChecks returns all available system checks
-func GetAllChecks() []Check {
-	isRoot := os.Geteuid() == 0
-	
-	checks := []Check{
-		SystemInfoCheck{},
-		DiskSpaceCheck{},
-		MemoryCheck{},
-		NetworkCheck{},
-		LogsCheck{},
-		PackagesCheck{},
-		FilesystemCheck{},
+// thresholds are the limits checks compare against
+var thresholds = config.DefaultThresholds()
+
+// SetThresholds replaces the limits used by every check. It is meant to be
+// called while setting up, before any check runs, since they read the limits
+// without locking
+func SetThresholds(t config.Thresholds) {
+	thresholds = t
+}
+
+// sys is the machine checks run commands and read files on
+var sys = machine.Local(config.DefaultRunnerSettings().CheckTimeout)
+
+// facts are the probes shared with diagnoses running on the same machine
+var facts = probes.For(sys)
+
+// SetMachine replaces the machine used by every check, for recording or
+// replaying a fixture
+func SetMachine(m *machine.Machine) {
+	sys = m
+	facts = probes.For(m)
+}
+
+// defaultRegistry holds the built-in checks
+var defaultRegistry = mustRegistry(
+	SystemInfoCheck{},
+	DiskSpaceCheck{},
+	MemoryCheck{},
+	NetworkCheck{},
+	LogsCheck{},
+	PackagesCheck{},
+	FilesystemCheck{},
+	ServicesCheck{},
+)
+
+func mustRegistry(checks ...Check) *Registry {
+	registry, err := NewRegistry(checks...)
+	if err != nil {
+		panic(err)
 	}
-	
-	// Add root-only checks if running as root
-	if isRoot {
-		checks = append(checks, ServicesCheck{})
+	return registry
+}
+
+// DefaultRegistry returns the registry of built-in checks
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// GetAllChecks returns all available system checks. Checks that require
+// root are included; RunCheck reports them as skipped when not root
+func GetAllChecks() []Check {
+	return defaultRegistry.Checks()
+}
+
+// LoadRegistry returns a registry of the built-in checks followed by the
+// external plugins found in the configured plugin directories
+func LoadRegistry(cfg *config.Config) (*Registry, error) {
+	return NewRegistry(append(defaultRegistry.Checks(), DiscoverPlugins(cfg.Checks.PluginDirs)...)...)
+}
+
+// GetChecks returns the checks selected by the configuration, external
+// plugins included
+func GetChecks(cfg *config.Config) ([]Check, error) {
+	registry, err := LoadRegistry(cfg)
+	if err != nil {
+		return nil, err
 	}
-	
-	return checks
-}
\ No newline at end of file
+	return registry.Select(Selector{
+		Only: cfg.Checks.Enabled,
+		Skip: cfg.Checks.Disabled,
+		Tags: cfg.Checks.Tags,
+	})
+}
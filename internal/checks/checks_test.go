@@ -1,10 +1,31 @@
 package checks
 
 import (
-	"os"
+	"strings"
 	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
 )
 
+// useMachine points the checks at m for the rest of the test
+func useMachine(t *testing.T, m *machine.Machine) {
+	t.Helper()
+	previous := sys
+	SetMachine(m)
+	t.Cleanup(func() { SetMachine(previous) })
+}
+
+// useFixture replays a recorded fixture from testdata
+func useFixture(t *testing.T, name string) {
+	t.Helper()
+	fixture, err := machine.LoadFixture("testdata/" + name)
+	if err != nil {
+		t.Fatalf("LoadFixture() error = %v", err)
+	}
+	useMachine(t, fixture.Machine())
+}
+
 func TestGetAllChecks(t *testing.T) {
 	// Test with current user privileges
 	checks := GetAllChecks()
@@ -32,16 +53,9 @@ func TestGetAllChecks(t *testing.T) {
 		}
 	}
 	
-	// Test that services check is included only if running as root
-	isRoot := os.Geteuid() == 0
-	hasServicesCheck := checkNames["System Services"]
-	
-	if isRoot && !hasServicesCheck {
-		t.Error("Expected Services check when running as root")
-	}
-	
-	if !isRoot && hasServicesCheck {
-		t.Error("Did not expect Services check when not running as root")
+	// Root-only checks are always listed; RunCheck skips them when not root
+	if !checkNames["System Services"] {
+		t.Error("Expected Services check to be included")
 	}
 }
 
@@ -146,6 +160,10 @@ func (m *mockCheckImpl) Name() string {
 	return m.name
 }
 
+func (m *mockCheckImpl) Info() Info {
+	return Info{ID: "mock", Category: "test", Cost: CostCheap}
+}
+
 func (m *mockCheckImpl) RequiresRoot() bool {
 	return m.requiresRoot
 }
@@ -157,4 +175,62 @@ func (m *mockCheckImpl) Run() CheckResult {
 		Message:  "Mock check completed successfully",
 		Details:  []string{"Mock detail 1", "Mock detail 2"},
 	}
-}
\ No newline at end of file
+}
+
+func TestGetChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection config.CheckSelection
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "all checks",
+			want:   []string{"System Information", "Disk Space", "Memory Usage", "Network Configuration", "System Logs", "Package System", "Filesystem Health", "System Services"},
+		},
+		{
+			name:      "enabled list",
+			selection: config.CheckSelection{Enabled: []string{"memory", "disk"}},
+			want:      []string{"Disk Space", "Memory Usage"},
+		},
+		{
+			name:      "disabled wins over enabled",
+			selection: config.CheckSelection{Enabled: []string{"disk", "memory"}, Disabled: []string{"Memory"}},
+			want:      []string{"Disk Space"},
+		},
+		{
+			name:      "root-only check kept",
+			selection: config.CheckSelection{Enabled: []string{"services"}},
+			want:      []string{"System Services"},
+		},
+		{
+			name:      "tags",
+			selection: config.CheckSelection{Tags: []string{"storage"}, Disabled: []string{"filesystem"}},
+			want:      []string{"Disk Space"},
+		},
+		{
+			name:      "unknown check",
+			selection: config.CheckSelection{Disabled: []string{"bogus"}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.New()
+			cfg.Checks = tt.selection
+
+			selected, err := GetChecks(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetChecks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, check := range selected {
+				names = append(names, check.Name())
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("GetChecks() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,9 @@ package checks
 
 import (
 	"fmt"
-	"syscall"
 	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 )
 
 // DiskSpaceCheck checks disk space usage
@@ -13,6 +14,15 @@ func (d DiskSpaceCheck) Name() string {
 	return "Disk Space"
 }
 
+func (d DiskSpaceCheck) Info() Info {
+	return Info{
+		ID:       "disk",
+		Category: "storage",
+		Tags:     []string{"storage", "capacity"},
+		Cost:     CostCheap,
+	}
+}
+
 func (d DiskSpaceCheck) RequiresRoot() bool {
 	return false
 }
@@ -26,8 +36,7 @@ func (d DiskSpaceCheck) Run() CheckResult {
 	}
 
 	// Check main filesystem
-	var stat syscall.Statfs_t
-	err := syscall.Statfs("/", &stat)
+	stat, err := sys.Statfs("/")
 	if err != nil {
 		result.Severity = SeverityError
 		result.Message = "Failed to check disk space"
@@ -46,12 +55,14 @@ func (d DiskSpaceCheck) Run() CheckResult {
 
 	// Set severity based on usage
 	switch {
-	case usagePercent > 95:
+	case usagePercent > thresholds.DiskCriticalPercent:
 		result.Severity = SeverityCritical
 		result.Message = fmt.Sprintf("Disk usage critical: %d%%", usagePercent)
-	case usagePercent > 85:
+		result.AddFindings(diskFullFinding(SeverityCritical, usagePercent))
+	case usagePercent > thresholds.DiskWarningPercent:
 		result.Severity = SeverityWarning
 		result.Message = fmt.Sprintf("Disk usage high: %d%%", usagePercent)
+		result.AddFindings(diskFullFinding(SeverityWarning, usagePercent))
 	default:
 		result.Severity = SeverityInfo
 		result.Message = fmt.Sprintf("Disk usage OK: %d%%", usagePercent)
@@ -65,10 +76,20 @@ func (d DiskSpaceCheck) Run() CheckResult {
 	
 	result.Details = append(result.Details, fmt.Sprintf("Inode usage: %d%%", inodeUsagePercent))
 	
-	if inodeUsagePercent > 90 {
+	if inodeUsagePercent > thresholds.InodeWarningPercent {
 		result.Severity = SeverityWarning
 		result.Message += fmt.Sprintf(" (High inode usage: %d%%)", inodeUsagePercent)
+		result.AddFindings(finding.Newf("disk.inodes_high", SeverityWarning, "Root filesystem inode usage is %d%%", inodeUsagePercent).
+			With("inode_percent", inodeUsagePercent).
+			Affects(finding.ResourcePath, "/"))
 	}
 
 	return result
+}
+
+func diskFullFinding(severity Severity, usagePercent int) finding.Finding {
+	return finding.Newf("disk.full", severity, "Root filesystem is %d%% full", usagePercent).
+		With("used_percent", usagePercent).
+		Affects(finding.ResourcePath, "/").
+		FixedBy("clean_package_cache", "remove_orphaned_packages")
 }
\ No newline at end of file
@@ -2,6 +2,8 @@ package checks
 
 import (
 	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
 )
 
 func TestDiskSpaceCheck(t *testing.T) {
@@ -31,4 +33,34 @@ func TestDiskSpaceCheck(t *testing.T) {
 	if len(result.Details) == 0 {
 		t.Error("Expected disk usage details")
 	}
-}
\ No newline at end of file
+}
+
+func TestDiskSpaceCheckThresholds(t *testing.T) {
+	tests := []struct {
+		name     string
+		bavail   uint64
+		ffree    uint64
+		severity Severity
+		message  string
+	}{
+		{"plenty of space", 600, 900, SeverityInfo, "Disk usage OK: 40%"},
+		{"above warning", 100, 900, SeverityWarning, "Disk usage high: 90%"},
+		{"above critical", 20, 900, SeverityCritical, "Disk usage critical: 98%"},
+		{"inodes exhausted", 600, 50, SeverityWarning, "Disk usage OK: 40% (High inode usage: 95%)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := machine.NewFixture()
+			fixture.Add(machine.Record{Kind: machine.KindStatfs, Name: "/", Statfs: &machine.Statfs{
+				Blocks: 1000, Bavail: tt.bavail, Bsize: 4096, Files: 1000, Ffree: tt.ffree,
+			}})
+			useMachine(t, fixture.Machine())
+
+			result := DiskSpaceCheck{}.Run()
+			if result.Severity != tt.severity || result.Message != tt.message {
+				t.Errorf("Run() = %v %q, want %v %q", result.Severity, result.Message, tt.severity, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// PluginPrefix starts the ID of every external check, so plugins cannot
+// shadow a built-in check
+const PluginPrefix = "plugin."
+
+// pluginName matches the file names run-parts accepts. Editor backups and
+// files such as foo.dpkg-old are left alone
+var pluginName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ExternalCheck runs an executable from a checks.d directory and turns the
+// JSON it prints into a check result
+type ExternalCheck struct {
+	Path    string
+	problem string // Why the plugin must not run, if anything
+}
+
+// PluginOutput is what a plugin prints on standard output
+type PluginOutput struct {
+	Severity string             `json:"severity"` // ok, info, warning, error or critical
+	Message  string             `json:"message"`
+	Details  []string           `json:"details,omitempty"`
+	Fixes    []PluginFix        `json:"fixes,omitempty"`
+	Metrics  map[string]float64 `json:"metrics,omitempty"`
+}
+
+// PluginFix is a fix a plugin suggests. ID names a built-in fix, which then
+// shows up in the recommendations; commands are only displayed, never run
+type PluginFix struct {
+	ID          string   `json:"id,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Commands    []string `json:"commands,omitempty"`
+}
+
+func (c ExternalCheck) Name() string {
+	return filepath.Base(c.Path)
+}
+
+func (c ExternalCheck) Info() Info {
+	return Info{
+		ID:       PluginPrefix + strings.ToLower(c.Name()),
+		Category: "plugin",
+		Tags:     []string{"plugin"},
+		Binaries: []string{c.Path},
+		Cost:     CostModerate,
+	}
+}
+
+func (c ExternalCheck) RequiresRoot() bool {
+	return false // Plugins that need root report it themselves
+}
+
+func (c ExternalCheck) Run() CheckResult {
+	return c.RunContext(context.Background())
+}
+
+// RunContext runs the plugin, killing it once ctx is done
+func (c ExternalCheck) RunContext(ctx context.Context) CheckResult {
+	result := CheckResult{
+		Name:      c.Name(),
+		Severity:  SeverityError,
+		Details:   []string{"Plugin: " + c.Path},
+		Timestamp: time.Now(),
+		Plugin:    true,
+	}
+	if c.problem != "" {
+		result.Message = fmt.Sprintf("Refusing to run plugin %s", c.Name())
+		result.Details = append(result.Details, c.problem)
+		return result
+	}
+
+	// The machine kills plugins that outlive the check timeout or ctx
+	stdout, err := sys.WithContext(ctx).Output(c.Path)
+	output, parseErr := ParsePluginOutput(stdout)
+	if parseErr != nil {
+		result.Message = fmt.Sprintf("Plugin %s failed", c.Name())
+		var exitErr *machine.ExitError
+		switch {
+		case errors.As(err, &exitErr):
+			result.Details = append(result.Details, fmt.Sprintf("Exit status %d", exitErr.ExitCode))
+			if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+				result.Details = append(result.Details, "stderr: "+stderr)
+			}
+		case err != nil:
+			result.Details = append(result.Details, err.Error())
+		default:
+			result.Details = append(result.Details, parseErr.Error())
+		}
+		return result
+	}
+
+	// A plugin may exit non-zero, as monitoring plugins do, as long as it
+	// reports what it found
+	return output.Result(c.Name())
+}
+
+// ParsePluginOutput decodes and validates a plugin's output
+func ParsePluginOutput(data []byte) (PluginOutput, error) {
+	var output PluginOutput
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return output, fmt.Errorf("plugin printed nothing")
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return output, fmt.Errorf("invalid plugin output: %w", err)
+	}
+	if _, err := ParseSeverity(output.Severity); err != nil {
+		return output, fmt.Errorf("invalid plugin output: %w", err)
+	}
+	if strings.TrimSpace(output.Message) == "" {
+		return output, fmt.Errorf("invalid plugin output: message is missing")
+	}
+	for _, fix := range output.Fixes {
+		if fix.ID == "" && fix.Title == "" {
+			return output, fmt.Errorf("invalid plugin output: a fix needs an id or a title")
+		}
+	}
+	return output, nil
+}
+
+// Result converts parsed output into the result of the plugin called name.
+// The name always comes from the plugin file, so a plugin cannot pass its
+// result off as another check's
+func (o PluginOutput) Result(name string) CheckResult {
+	severity, _ := ParseSeverity(o.Severity)
+	result := CheckResult{
+		Name:      name,
+		Severity:  severity,
+		Message:   o.Message,
+		Details:   append([]string{}, o.Details...),
+		Timestamp: time.Now(),
+		Plugin:    true,
+	}
+	for metric, value := range o.Metrics {
+		result.SetMetric(metric, value)
+	}
+
+	// Built-in fixes are linked through a finding so reports recommend them
+	var fixIDs []string
+	for _, fix := range o.Fixes {
+		if fix.ID != "" {
+			fixIDs = append(fixIDs, fix.ID)
+		}
+		if fix.Title != "" {
+			line := "Suggested fix: " + fix.Title
+			if fix.Description != "" {
+				line += " - " + fix.Description
+			}
+			result.Details = append(result.Details, line)
+			for _, command := range fix.Commands {
+				result.Details = append(result.Details, "  $ "+command)
+			}
+		}
+	}
+	if len(fixIDs) > 0 {
+		result.AddFindings(finding.New(PluginPrefix+pluginFindingID(name), severity, o.Message).FixedBy(fixIDs...))
+	}
+	return result
+}
+
+// pluginFindingID turns a plugin name into the last part of a finding ID
+func pluginFindingID(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(name))
+}
+
+// DiscoverPlugins finds the executables in dirs, following the naming rules
+// of run-parts. A plugin in a later directory replaces one of the same name
+// in an earlier directory. Plugins are returned sorted by name; ones that
+// other users could change, through the file or its directory, are
+// returned too but refuse to run
+func DiscoverPlugins(dirs []string) []Check {
+	byName := make(map[string]ExternalCheck)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // Missing directories are normal
+		}
+		for _, entry := range entries {
+			if !pluginName.MatchString(entry.Name()) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+				continue
+			}
+			check := ExternalCheck{Path: path}
+			if err := config.CheckTrusted(path); err != nil {
+				check.problem = err.Error()
+			}
+			byName[strings.ToLower(entry.Name())] = check
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	plugins := make([]Check, len(names))
+	for i, name := range names {
+		plugins[i] = byName[name]
+	}
+	return plugins
+}
@@ -0,0 +1,196 @@
+package checks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// writePlugin writes a shell script plugin into dir
+func writePlugin(t *testing.T, dir, name, script string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	system, user := t.TempDir(), t.TempDir()
+	writePlugin(t, system, "zfs-pool", "true", 0755)
+	writePlugin(t, system, "hsm", "echo system", 0755)
+	writePlugin(t, user, "hsm", "echo user", 0700)
+	writePlugin(t, system, "not-executable", "true", 0644)
+	writePlugin(t, system, "backup.dpkg-old", "true", 0755)
+	writePlugin(t, system, "editor~", "true", 0755)
+	writePlugin(t, system, "shared", "true", 0777)
+	if err := os.Mkdir(filepath.Join(system, "subdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins := DiscoverPlugins([]string{system, user, filepath.Join(system, "missing")})
+	var ids []string
+	for _, plugin := range plugins {
+		ids = append(ids, plugin.Info().ID)
+	}
+	if got, want := strings.Join(ids, ","), "plugin.hsm,plugin.shared,plugin.zfs-pool"; got != want {
+		t.Fatalf("plugins = %s, want %s", got, want)
+	}
+	if path := plugins[0].(ExternalCheck).Path; path != filepath.Join(user, "hsm") {
+		t.Errorf("hsm plugin = %s, want the one in the user directory", path)
+	}
+
+	result := plugins[1].Run()
+	if result.Severity != SeverityError || !strings.Contains(strings.Join(result.Details, "\n"), "writable by other users") {
+		t.Errorf("world-writable plugin ran: %+v", result)
+	}
+}
+
+func TestDiscoverPluginsUntrusted(t *testing.T) {
+	shared := filepath.Join(t.TempDir(), "shared")
+	if err := os.Mkdir(shared, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writePlugin(t, shared, "hsm", "true", 0755)
+	if err := os.Chmod(shared, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins := DiscoverPlugins([]string{shared})
+	if len(plugins) != 1 || !strings.Contains(plugins[0].(ExternalCheck).problem, "writable by other users") {
+		t.Fatalf("plugin in a world-writable directory = %+v", plugins)
+	}
+
+	if os.Geteuid() != 0 {
+		t.Skip("changing file ownership needs root")
+	}
+	owned := t.TempDir()
+	path := writePlugin(t, owned, "hsm", "true", 0755)
+	if err := os.Chown(path, 65534, 65534); err != nil {
+		t.Fatal(err)
+	}
+	plugins = DiscoverPlugins([]string{owned})
+	if len(plugins) != 1 || !strings.Contains(plugins[0].(ExternalCheck).problem, "owned by uid 65534") {
+		t.Errorf("plugin owned by another user = %+v", plugins)
+	}
+}
+
+func TestExternalCheckRun(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "hsm", `cat <<'EOF'
+{
+  "name": "HSM",
+  "severity": "warning",
+  "message": "HSM battery low",
+  "details": ["Battery at 12%"],
+  "fixes": [
+    {"title": "Replace the battery", "commands": ["hsmctl battery status"]},
+    {"id": "restart_failed_services"}
+  ],
+  "metrics": {"hsm_battery_percent": 12}
+}
+EOF
+exit 1`, 0755)
+
+	// The result keeps the file name whatever the plugin calls itself
+	result := RunCheck(ExternalCheck{Path: path}, false)
+	if result.Name != "hsm" || !result.Plugin || result.Severity != SeverityWarning || result.Message != "HSM battery low" {
+		t.Fatalf("result = %+v", result)
+	}
+	want := []string{"Battery at 12%", "Suggested fix: Replace the battery", "  $ hsmctl battery status"}
+	if strings.Join(result.Details, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Details = %q, want %q", result.Details, want)
+	}
+	if result.Metrics["hsm_battery_percent"] != 12 {
+		t.Errorf("Metrics = %v", result.Metrics)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].ID != "plugin.hsm" ||
+		result.Findings[0].Category != "plugin" || result.Findings[0].FixIDs[0] != "restart_failed_services" {
+		t.Errorf("Findings = %+v", result.Findings)
+	}
+}
+
+func TestExternalCheckFailures(t *testing.T) {
+	useMachine(t, machine.Local(500*time.Millisecond))
+	dir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		script string
+		detail string
+	}{
+		{"silent", "true", "plugin printed nothing"},
+		{"garbage", "echo not json", "invalid plugin output"},
+		{"bad-severity", `echo '{"severity": "dire", "message": "x"}'`, "unknown severity"},
+		{"no-message", `echo '{"severity": "ok"}'`, "message is missing"},
+		{"crash", "echo oops >&2; exit 2", "stderr: oops"},
+		{"hang", "sleep 5", "killed after"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writePlugin(t, dir, tt.name, tt.script, 0755)
+			result := ExternalCheck{Path: path}.Run()
+			if result.Severity != SeverityError || result.Message != "Plugin "+tt.name+" failed" {
+				t.Errorf("result = %+v", result)
+			}
+			if details := strings.Join(result.Details, "\n"); !strings.Contains(details, tt.detail) {
+				t.Errorf("Details = %q, want %q", details, tt.detail)
+			}
+		})
+	}
+}
+
+func TestExternalCheckRunContext(t *testing.T) {
+	useMachine(t, machine.Local(time.Minute))
+	path := writePlugin(t, t.TempDir(), "hang", "sleep 5", 0755)
+
+	// The runner's deadline kills the plugin instead of abandoning it, so
+	// the next run can start it again long before the sleep would end
+	runner := &Runner{Workers: 1, Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	runner.Run(context.Background(), []Check{ExternalCheck{Path: path}})
+	for time.Since(start) < 3*time.Second {
+		results := runner.Run(context.Background(), []Check{ExternalCheck{Path: path}})
+		if !strings.Contains(results.GetAllChecks()[0].Message, "still running") {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("Plugin outlived its deadline")
+}
+
+func TestGetChecksIncludesPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "hsm", `echo '{"severity": "ok", "message": "HSM ready"}'`, 0755)
+
+	cfg := config.New()
+	cfg.Checks.PluginDirs = []string{dir}
+	cfg.Checks.Tags = []string{"plugin"}
+	selected, err := GetChecks(cfg)
+	if err != nil {
+		t.Fatalf("GetChecks() error = %v", err)
+	}
+	if len(selected) != 1 || selected[0].Info().ID != "plugin.hsm" {
+		t.Fatalf("selected = %v", selected)
+	}
+
+	cfg.Checks.Tags = nil
+	cfg.Checks.Disabled = []string{"plugin.hsm"}
+	selected, err = GetChecks(cfg)
+	if err != nil {
+		t.Fatalf("GetChecks() error = %v", err)
+	}
+	if len(selected) != len(GetAllChecks()) {
+		t.Errorf("selected %d checks, want the %d built-in ones", len(selected), len(GetAllChecks()))
+	}
+}
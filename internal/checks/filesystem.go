@@ -3,12 +3,13 @@ package checks
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
 )
 
 // FilesystemCheck checks filesystem health and integrity
@@ -18,6 +19,16 @@ func (c FilesystemCheck) Name() string {
 	return "Filesystem Health"
 }
 
+func (c FilesystemCheck) Info() Info {
+	return Info{
+		ID:       "filesystem",
+		Category: "storage",
+		Tags:     []string{"storage", "integrity"},
+		Binaries: []string{"df", "mount", "dmesg", "dumpe2fs", "e2freefrag", "systemctl"},
+		Cost:     CostExpensive,
+	}
+}
+
 func (c FilesystemCheck) RequiresRoot() bool {
 	return false // Basic filesystem checks don't require root
 }
@@ -36,10 +47,7 @@ func (c FilesystemCheck) Run() CheckResult {
 	if len(mountIssues) > 0 {
 		result.Severity = SeverityError
 		result.Message = "Filesystem mount issues detected"
-		result.Details = append(result.Details, "Mount issues:")
-		for _, issue := range mountIssues {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", issue))
-		}
+		result.AddFindings(mountIssues...)
 	}
 
 	// Check for read-only filesystems
@@ -49,9 +57,10 @@ func (c FilesystemCheck) Run() CheckResult {
 			result.Severity = SeverityWarning
 			result.Message = "Read-only filesystems detected"
 		}
-		result.Details = append(result.Details, "Read-only filesystems:")
-		for _, fs := range readOnlyFS {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", fs))
+		for _, mount := range readOnlyFS {
+			result.AddFindings(finding.Newf("filesystem.read_only", SeverityWarning, "%s is mounted read-only", mount.Path).
+				Affects(finding.ResourceDevice, mount.Device).
+				Affects(finding.ResourcePath, mount.Path))
 		}
 	}
 
@@ -60,14 +69,7 @@ func (c FilesystemCheck) Run() CheckResult {
 	if len(fsErrors) > 0 {
 		result.Severity = SeverityCritical
 		result.Message = "Filesystem errors detected"
-		result.Details = append(result.Details, "Filesystem errors in kernel log:")
-		for i, err := range fsErrors {
-			if i >= 5 { // Limit to first 5
-				result.Details = append(result.Details, fmt.Sprintf("... and %d more", len(fsErrors)-5))
-				break
-			}
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", err))
-		}
+		result.AddFindings(fsErrors...)
 	}
 
 	// Check for full inodes
@@ -77,10 +79,7 @@ func (c FilesystemCheck) Run() CheckResult {
 			result.Severity = SeverityWarning
 			result.Message = "High inode usage detected"
 		}
-		result.Details = append(result.Details, "Inode usage issues:")
-		for _, issue := range inodeIssues {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", issue))
-		}
+		result.AddFindings(inodeIssues...)
 	}
 
 	// Check for filesystem corruption indicators
@@ -88,10 +87,7 @@ func (c FilesystemCheck) Run() CheckResult {
 	if len(corruptionSigns) > 0 {
 		result.Severity = SeverityCritical
 		result.Message = "Filesystem corruption signs detected"
-		result.Details = append(result.Details, "Corruption indicators:")
-		for _, sign := range corruptionSigns {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", sign))
-		}
+		result.AddFindings(corruptionSigns...)
 	}
 
 	// Check disk usage patterns
@@ -101,21 +97,21 @@ func (c FilesystemCheck) Run() CheckResult {
 			result.Severity = SeverityWarning
 			result.Message = "Disk usage issues detected"
 		}
-		result.Details = append(result.Details, "Disk usage concerns:")
-		for _, issue := range diskUsageIssues {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", issue))
-		}
+		result.AddFindings(diskUsageIssues...)
 	}
 
 	// Check for orphaned files and directories
 	orphanedCount := c.checkOrphanedFiles()
 	if orphanedCount > 0 {
 		result.Details = append(result.Details, fmt.Sprintf("Potential orphaned files in /tmp: %d", orphanedCount))
-		if orphanedCount > 1000 {
+		if orphanedCount > thresholds.TmpFilesWarning {
 			if result.Severity < SeverityWarning {
 				result.Severity = SeverityWarning
 				result.Message = "Many orphaned files detected"
 			}
+			result.AddFindings(finding.Newf("filesystem.tmp_orphans", SeverityWarning, "%d files in /tmp are older than 7 days", orphanedCount).
+				With("count", orphanedCount).
+				Affects(finding.ResourcePath, "/tmp"))
 		}
 	}
 
@@ -126,10 +122,7 @@ func (c FilesystemCheck) Run() CheckResult {
 			result.Severity = SeverityWarning
 			result.Message = "Symbolic link issues detected"
 		}
-		result.Details = append(result.Details, "Symbolic link issues:")
-		for _, issue := range symlinkIssues {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", issue))
-		}
+		result.AddFindings(symlinkIssues...)
 	}
 
 	// Check filesystem fragmentation (for ext filesystems)
@@ -149,87 +142,40 @@ func (c FilesystemCheck) Run() CheckResult {
 }
 
 // checkMountStatus checks for mount-related issues
-func (c FilesystemCheck) checkMountStatus() []string {
-	issues := []string{}
+func (c FilesystemCheck) checkMountStatus() []finding.Finding {
+	issues := []finding.Finding{}
 
-	// Check /proc/mounts for any mount errors
-	cmd := exec.Command("mount")
-	output, err := cmd.Output()
-	if err != nil {
-		issues = append(issues, "Failed to read mount information")
+	if _, err := facts.Mounts(); err != nil {
+		issues = append(issues, finding.New("filesystem.mounts_unreadable", SeverityError, "Failed to read mount information").
+			With("error", err))
 		return issues
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "ro,") && !strings.Contains(line, "tmpfs") {
-			// Extract filesystem name
-			fields := strings.Fields(line)
-			if len(fields) >= 3 {
-				issues = append(issues, fmt.Sprintf("%s mounted read-only", fields[2]))
-			}
-		}
-	}
-
 	// Check for failed mounts in systemd
-	cmd = exec.Command("systemctl", "list-units", "--failed", "--type=mount")
-	output, err = cmd.Output()
-	if err == nil {
-		content := string(output)
-		if strings.Contains(content, "failed") && !strings.Contains(content, "0 loaded units") {
-			issues = append(issues, "Failed mount units detected in systemd")
-		}
+	failed, _ := facts.FailedUnitsOfType("mount")
+	for _, unit := range failed {
+		issues = append(issues, finding.Newf("filesystem.mount_unit_failed", SeverityError, "Mount unit %s failed", unit.Name).
+			With("state", unit.Sub).
+			Affects(finding.ResourceUnit, unit.Name))
 	}
 
 	return issues
 }
 
-// checkReadOnlyFilesystems finds filesystems mounted read-only
-func (c FilesystemCheck) checkReadOnlyFilesystems() []string {
-	readOnly := []string{}
-
-	file, err := os.Open("/proc/mounts")
-	if err != nil {
-		return readOnly
-	}
-	defer file.Close()
-
-	cmd := exec.Command("cat", "/proc/mounts")
-	output, err := cmd.Output()
-	if err != nil {
-		return readOnly
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		fields := strings.Fields(line)
-		if len(fields) >= 4 {
-			mountPoint := fields[1]
-			options := fields[3]
-			
-			// Skip virtual filesystems
-			if strings.HasPrefix(mountPoint, "/proc") ||
-				strings.HasPrefix(mountPoint, "/sys") ||
-				strings.HasPrefix(mountPoint, "/dev") ||
-				strings.Contains(fields[2], "tmpfs") {
-				continue
-			}
-
-			if strings.Contains(options, "ro") {
-				readOnly = append(readOnly, mountPoint)
-			}
-		}
+// checkReadOnlyFilesystems finds real filesystems mounted read-only
+func (c FilesystemCheck) checkReadOnlyFilesystems() []probes.Mount {
+	readOnly, _ := facts.ReadOnlyMounts()
+	if readOnly == nil {
+		return []probes.Mount{}
 	}
-
 	return readOnly
 }
 
 // checkFilesystemErrors looks for filesystem errors in kernel logs
-func (c FilesystemCheck) checkFilesystemErrors() []string {
-	errors := []string{}
+func (c FilesystemCheck) checkFilesystemErrors() []finding.Finding {
+	errors := []finding.Finding{}
 
-	cmd := exec.Command("dmesg")
-	output, err := cmd.Output()
+	output, err := sys.Output("dmesg")
 	if err != nil {
 		return errors
 	}
@@ -247,60 +193,41 @@ func (c FilesystemCheck) checkFilesystemErrors() []string {
 		"remounting filesystem read-only",
 	}
 
+	seen := make(map[string]bool)
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
 		lineLower := strings.ToLower(line)
 		for _, pattern := range errorPatterns {
 			if strings.Contains(lineLower, pattern) {
-				if len(line) > 100 {
-					line = line[:100] + "..."
+				summary := strings.TrimSpace(line)
+				if len(summary) > 100 {
+					summary = summary[:100] + "..."
+				}
+				if !seen[summary] {
+					seen[summary] = true
+					errors = append(errors, finding.New("filesystem.kernel_error", SeverityCritical, summary).
+						With("pattern", pattern).
+						WithExcerpt(line))
 				}
-				errors = append(errors, strings.TrimSpace(line))
 				break
 			}
 		}
 	}
 
-	return removeDuplicateStrings(errors)
+	return errors
 }
 
 // checkInodeUsage checks for high inode usage
-func (c FilesystemCheck) checkInodeUsage() []string {
-	issues := []string{}
-
-	cmd := exec.Command("df", "-i")
-	output, err := cmd.Output()
-	if err != nil {
-		return issues
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for i, line := range lines {
-		if i == 0 { // Skip header
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 5 {
-			filesystem := fields[0]
-			usageStr := fields[4]
-			
-			// Skip virtual filesystems
-			if strings.HasPrefix(filesystem, "tmpfs") ||
-				strings.HasPrefix(filesystem, "devtmpfs") ||
-				strings.HasPrefix(filesystem, "udev") {
-				continue
-			}
-
-			if strings.HasSuffix(usageStr, "%") {
-				usageStr = strings.TrimSuffix(usageStr, "%")
-				if usage, err := strconv.Atoi(usageStr); err == nil {
-					if usage > 90 {
-						mountPoint := fields[5]
-						issues = append(issues, fmt.Sprintf("%s: %d%% inode usage", mountPoint, usage))
-					}
-				}
-			}
+func (c FilesystemCheck) checkInodeUsage() []finding.Finding {
+	issues := []finding.Finding{}
+
+	usage, _ := facts.InodeUsage()
+	for _, fs := range usage {
+		if fs.Percent > thresholds.InodeWarningPercent {
+			issues = append(issues, finding.Newf("filesystem.inodes_high", SeverityWarning, "%s: %d%% inode usage", fs.Mount, fs.Percent).
+				With("inode_percent", fs.Percent).
+				Affects(finding.ResourceDevice, fs.Filesystem).
+				Affects(finding.ResourcePath, fs.Mount))
 		}
 	}
 
@@ -308,23 +235,24 @@ func (c FilesystemCheck) checkInodeUsage() []string {
 }
 
 // checkCorruptionSigns looks for signs of filesystem corruption
-func (c FilesystemCheck) checkCorruptionSigns() []string {
-	signs := []string{}
+func (c FilesystemCheck) checkCorruptionSigns() []finding.Finding {
+	signs := []finding.Finding{}
 
 	// Check for lost+found directories with content
 	lostFoundDirs := []string{"/lost+found", "/home/lost+found", "/var/lost+found"}
 	for _, dir := range lostFoundDirs {
-		if _, err := os.Stat(dir); err == nil {
-			entries, err := os.ReadDir(dir)
+		if _, err := sys.Stat(dir); err == nil {
+			entries, err := sys.ReadDir(dir)
 			if err == nil && len(entries) > 0 {
-				signs = append(signs, fmt.Sprintf("Files found in %s (%d items)", dir, len(entries)))
+				signs = append(signs, finding.Newf("filesystem.lost_found", SeverityCritical, "Files found in %s (%d items)", dir, len(entries)).
+					With("items", len(entries)).
+					Affects(finding.ResourcePath, dir))
 			}
 		}
 	}
 
 	// Check for bad blocks in ext filesystems
-	cmd := exec.Command("dumpe2fs", "-h", "/dev/sda1")
-	output, err := cmd.Output()
+	output, err := sys.Output("dumpe2fs", "-h", "/dev/sda1")
 	if err == nil {
 		content := string(output)
 		if strings.Contains(content, "Bad block count:") {
@@ -332,7 +260,9 @@ func (c FilesystemCheck) checkCorruptionSigns() []string {
 			matches := re.FindStringSubmatch(content)
 			if len(matches) >= 2 {
 				if count, err := strconv.Atoi(matches[1]); err == nil && count > 0 {
-					signs = append(signs, fmt.Sprintf("Bad blocks detected: %d", count))
+					signs = append(signs, finding.Newf("filesystem.bad_blocks", SeverityCritical, "Bad blocks detected: %d", count).
+						With("bad_blocks", count).
+						Affects(finding.ResourceDevice, "/dev/sda1"))
 				}
 			}
 		}
@@ -342,12 +272,11 @@ func (c FilesystemCheck) checkCorruptionSigns() []string {
 }
 
 // checkDiskUsagePatterns analyzes disk usage for concerning patterns
-func (c FilesystemCheck) checkDiskUsagePatterns() []string {
-	issues := []string{}
+func (c FilesystemCheck) checkDiskUsagePatterns() []finding.Finding {
+	issues := []finding.Finding{}
 
 	// Check for rapid disk usage changes (simplified check)
-	cmd := exec.Command("df", "-h")
-	output, err := cmd.Output()
+	output, err := sys.Output("df", "-h")
 	if err != nil {
 		return issues
 	}
@@ -365,10 +294,16 @@ func (c FilesystemCheck) checkDiskUsagePatterns() []string {
 				usageStr = strings.TrimSuffix(usageStr, "%")
 				if usage, err := strconv.Atoi(usageStr); err == nil {
 					mountPoint := fields[5]
-					if usage > 95 {
-						issues = append(issues, fmt.Sprintf("%s is %d%% full (critical)", mountPoint, usage))
-					} else if usage > 85 {
-						issues = append(issues, fmt.Sprintf("%s is %d%% full (warning)", mountPoint, usage))
+					if usage > thresholds.DiskCriticalPercent {
+						issues = append(issues, finding.Newf("filesystem.disk_full", SeverityCritical, "%s is %d%% full (critical)", mountPoint, usage).
+							With("used_percent", usage).
+							Affects(finding.ResourceDevice, fields[0]).
+							Affects(finding.ResourcePath, mountPoint))
+					} else if usage > thresholds.DiskWarningPercent {
+						issues = append(issues, finding.Newf("filesystem.disk_full", SeverityWarning, "%s is %d%% full (warning)", mountPoint, usage).
+							With("used_percent", usage).
+							Affects(finding.ResourceDevice, fields[0]).
+							Affects(finding.ResourcePath, mountPoint))
 					}
 				}
 			}
@@ -384,7 +319,7 @@ func (c FilesystemCheck) checkOrphanedFiles() int {
 	count := 0
 
 	// Count files older than 7 days in /tmp
-	err := filepath.Walk(tmpDir, func(path string, info os.FileInfo, err error) error {
+	err := sys.Walk(tmpDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip files we can't access
 		}
@@ -409,39 +344,17 @@ func (c FilesystemCheck) checkOrphanedFiles() int {
 }
 
 // checkSymbolicLinks checks for broken symbolic links
-func (c FilesystemCheck) checkSymbolicLinks() []string {
-	issues := []string{}
-
-	// Check common directories for broken symlinks
-	checkDirs := []string{"/usr/bin", "/usr/local/bin", "/bin", "/sbin"}
-	
-	for _, dir := range checkDirs {
-		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Skip files we can't access
-			}
-
-			if info.Mode()&os.ModeSymlink != 0 {
-				// Check if symlink target exists
-				if _, err := os.Stat(path); os.IsNotExist(err) {
-					relPath := strings.TrimPrefix(path, dir)
-					issues = append(issues, fmt.Sprintf("Broken symlink: %s%s", dir, relPath))
-				}
-			}
-
-			return nil
-		})
-
-		if err != nil {
-			continue // Skip directories we can't access
-		}
+func (c FilesystemCheck) checkSymbolicLinks() []finding.Finding {
+	issues := []finding.Finding{}
 
+	broken, _ := facts.BrokenSymlinks()
+	for i, path := range broken {
 		// Limit the number of issues reported
-		if len(issues) > 10 {
-			issues = issues[:10]
-			issues = append(issues, "... (truncated, more broken symlinks exist)")
+		if i == 10 {
 			break
 		}
+		issues = append(issues, finding.Newf("filesystem.broken_symlink", SeverityWarning, "Broken symlink: %s", path).
+			Affects(finding.ResourcePath, path))
 	}
 
 	return issues
@@ -452,11 +365,9 @@ func (c FilesystemCheck) checkFragmentation() []string {
 	fragmentation := []string{}
 
 	// Check if e2freefrag is available and run it on ext filesystems
-	cmd := exec.Command("which", "e2freefrag")
-	if cmd.Run() == nil {
+	if sys.Run("which", "e2freefrag") == nil {
 		// Try to run e2freefrag on the root filesystem
-		cmd = exec.Command("e2freefrag", "/dev/sda1")
-		output, err := cmd.Output()
+		output, err := sys.Output("e2freefrag", "/dev/sda1")
 		if err == nil {
 			content := string(output)
 			lines := strings.Split(content, "\n")
@@ -475,18 +386,3 @@ func (c FilesystemCheck) checkFragmentation() []string {
 
 	return fragmentation
 }
-
-// removeDuplicateStrings removes duplicate strings from a slice
-func removeDuplicateStrings(slice []string) []string {
-	keys := make(map[string]bool)
-	result := []string{}
-
-	for _, item := range slice {
-		if !keys[item] {
-			keys[item] = true
-			result = append(result, item)
-		}
-	}
-
-	return result
-}
\ No newline at end of file
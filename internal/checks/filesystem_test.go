@@ -74,7 +74,7 @@ func TestFilesystemCheck_checkMountStatus(t *testing.T) {
 	
 	// If issues exist, they should be non-empty strings
 	for i, issue := range issues {
-		if strings.TrimSpace(issue) == "" {
+		if strings.TrimSpace(issue.Summary) == "" {
 			t.Errorf("Mount issue %d is empty or whitespace only", i)
 		}
 	}
@@ -95,13 +95,13 @@ func TestFilesystemCheck_checkReadOnlyFilesystems(t *testing.T) {
 	
 	// If filesystems exist, they should be valid paths
 	for i, fs := range readOnly {
-		if strings.TrimSpace(fs) == "" {
+		if strings.TrimSpace(fs.Path) == "" {
 			t.Errorf("Read-only filesystem %d is empty or whitespace only", i)
 		}
 		
 		// Should be absolute paths
-		if !strings.HasPrefix(fs, "/") {
-			t.Errorf("Read-only filesystem %d is not an absolute path: %s", i, fs)
+		if !strings.HasPrefix(fs.Path, "/") {
+			t.Errorf("Read-only filesystem %d is not an absolute path: %s", i, fs.Path)
 		}
 	}
 	
@@ -122,7 +122,7 @@ func TestFilesystemCheck_checkFilesystemErrors(t *testing.T) {
 	
 	// If errors exist, they should be non-empty strings
 	for i, err := range errors {
-		if strings.TrimSpace(err) == "" {
+		if strings.TrimSpace(err.Summary) == "" {
 			t.Errorf("Filesystem error %d is empty or whitespace only", i)
 		}
 	}
@@ -144,13 +144,13 @@ func TestFilesystemCheck_checkInodeUsage(t *testing.T) {
 	
 	// If issues exist, they should contain percentage information
 	for i, issue := range issues {
-		if strings.TrimSpace(issue) == "" {
+		if strings.TrimSpace(issue.Summary) == "" {
 			t.Errorf("Inode issue %d is empty or whitespace only", i)
 		}
 		
 		// Should contain percentage and path information
-		if !strings.Contains(issue, "%") || !strings.Contains(issue, ":") {
-			t.Errorf("Inode issue %d doesn't contain expected format: %s", i, issue)
+		if !strings.Contains(issue.Summary, "%") || len(issue.Resources) == 0 {
+			t.Errorf("Inode issue %d doesn't contain expected format: %+v", i, issue)
 		}
 	}
 	
@@ -171,7 +171,7 @@ func TestFilesystemCheck_checkCorruptionSigns(t *testing.T) {
 	
 	// If signs exist, they should be non-empty strings
 	for i, sign := range signs {
-		if strings.TrimSpace(sign) == "" {
+		if strings.TrimSpace(sign.Summary) == "" {
 			t.Errorf("Corruption sign %d is empty or whitespace only", i)
 		}
 	}
@@ -193,13 +193,13 @@ func TestFilesystemCheck_checkDiskUsagePatterns(t *testing.T) {
 	
 	// If issues exist, they should contain usage information
 	for i, issue := range issues {
-		if strings.TrimSpace(issue) == "" {
+		if strings.TrimSpace(issue.Summary) == "" {
 			t.Errorf("Disk usage issue %d is empty or whitespace only", i)
 		}
 		
 		// Should contain percentage information
-		if !strings.Contains(issue, "%") {
-			t.Errorf("Disk usage issue %d doesn't contain percentage: %s", i, issue)
+		if issue.Evidence["used_percent"] == "" {
+			t.Errorf("Disk usage issue %d doesn't contain percentage: %+v", i, issue)
 		}
 	}
 	
@@ -234,13 +234,13 @@ func TestFilesystemCheck_checkSymbolicLinks(t *testing.T) {
 	
 	// If issues exist, they should be valid paths
 	for i, issue := range issues {
-		if strings.TrimSpace(issue) == "" {
+		if strings.TrimSpace(issue.Summary) == "" {
 			t.Errorf("Symlink issue %d is empty or whitespace only", i)
 		}
 		
 		// Should mention symlink
-		if !strings.Contains(strings.ToLower(issue), "symlink") {
-			t.Errorf("Symlink issue %d doesn't mention symlink: %s", i, issue)
+		if issue.ID != "filesystem.broken_symlink" || len(issue.Resources) != 1 {
+			t.Errorf("Symlink issue %d is not a broken symlink finding: %+v", i, issue)
 		}
 	}
 	
@@ -273,48 +273,6 @@ func TestFilesystemCheck_checkFragmentation(t *testing.T) {
 	t.Logf("Fragmentation info entries: %d", len(fragmentation))
 }
 
-func TestRemoveDuplicateStrings_Filesystem(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    []string
-		expected []string
-	}{
-		{
-			name:     "filesystem errors with duplicates",
-			input:    []string{"ext4-fs error", "corruption detected", "ext4-fs error", "bad block"},
-			expected: []string{"ext4-fs error", "corruption detected", "bad block"},
-		},
-		{
-			name:     "mount points with duplicates",
-			input:    []string{"/home", "/var", "/home", "/tmp"},
-			expected: []string{"/home", "/var", "/tmp"},
-		},
-		{
-			name:     "empty input",
-			input:    []string{},
-			expected: []string{},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := removeDuplicateStrings(tt.input)
-			
-			// Check length
-			if len(result) != len(tt.expected) {
-				t.Errorf("removeDuplicateStrings() length = %d, want %d", len(result), len(tt.expected))
-			}
-			
-			// Check contents
-			for i, expected := range tt.expected {
-				if i >= len(result) || result[i] != expected {
-					t.Errorf("removeDuplicateStrings() = %v, want %v", result, tt.expected)
-					break
-				}
-			}
-		})
-	}
-}
 
 func TestFilesystemCheck_Integration(t *testing.T) {
 	// Integration test that validates the overall filesystem check functionality
@@ -335,7 +293,7 @@ func TestFilesystemCheck_Integration(t *testing.T) {
 	}
 	
 	// Check that details contain filesystem-related information
-	detailsText := strings.Join(result.Details, " ")
+	detailsText := strings.Join(result.Lines(), " ")
 	expectedKeywords := []string{"filesystem", "mount", "disk", "inode"}
 	foundKeywords := 0
 	
@@ -355,7 +313,7 @@ func TestFilesystemCheck_SeverityEscalation(t *testing.T) {
 	check := FilesystemCheck{}
 	result := check.Run()
 	
-	detailsText := strings.Join(result.Details, " ")
+	detailsText := strings.Join(result.Lines(), " ")
 	
 	// Critical issues should result in critical severity
 	criticalKeywords := []string{"corruption", "filesystem error", "critical"}
@@ -2,11 +2,12 @@ package checks
 
 import (
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 )
 
 // LogsCheck checks system logs for errors and issues
@@ -16,6 +17,16 @@ func (c LogsCheck) Name() string {
 	return "System Logs"
 }
 
+func (c LogsCheck) Info() Info {
+	return Info{
+		ID:       "logs",
+		Category: "logs",
+		Tags:     []string{"logs", "security", "services"},
+		Binaries: []string{"journalctl", "systemctl", "stat"},
+		Cost:     CostExpensive,
+	}
+}
+
 func (c LogsCheck) RequiresRoot() bool {
 	return false // Most log viewing doesn't require root
 }
@@ -35,15 +46,7 @@ func (c LogsCheck) Run() CheckResult {
 		result.Severity = SeverityWarning
 		result.Message = "Errors found in system journal"
 		result.Details = append(result.Details, fmt.Sprintf("Recent journal errors: %d", len(journalErrors)))
-		
-		// Show first few errors as examples
-		for i, err := range journalErrors {
-			if i >= 3 { // Limit to first 3 errors
-				result.Details = append(result.Details, fmt.Sprintf("... and %d more errors", len(journalErrors)-3))
-				break
-			}
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", err))
-		}
+		result.AddFindings(journalErrors...)
 	}
 
 	// Check for authentication failures
@@ -54,6 +57,9 @@ func (c LogsCheck) Run() CheckResult {
 			result.Message = "Authentication failures detected"
 		}
 		result.Details = append(result.Details, fmt.Sprintf("Recent auth failures: %d", authFailures))
+		result.AddFindings(finding.Newf("logs.auth_failures", SeverityWarning, "%d authentication failures in the last 24 hours", authFailures).
+			With("count", authFailures).
+			Affects(finding.ResourceUnit, "ssh.service"))
 	}
 
 	// Check for disk errors
@@ -61,10 +67,8 @@ func (c LogsCheck) Run() CheckResult {
 	if len(diskErrors) > 0 {
 		result.Severity = SeverityCritical
 		result.Message = "Disk errors detected in logs"
-		result.Details = append(result.Details, "Disk errors found:")
-		for _, err := range diskErrors {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", err))
-		}
+		result.Details = append(result.Details, fmt.Sprintf("Disk errors found: %d", len(diskErrors)))
+		result.AddFindings(diskErrors...)
 	}
 
 	// Check for memory issues
@@ -74,10 +78,8 @@ func (c LogsCheck) Run() CheckResult {
 			result.Severity = SeverityWarning
 			result.Message = "Memory issues detected"
 		}
-		result.Details = append(result.Details, "Memory issues:")
-		for _, issue := range memoryIssues {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", issue))
-		}
+		result.Details = append(result.Details, fmt.Sprintf("Memory issues: %d", len(memoryIssues)))
+		result.AddFindings(memoryIssues...)
 	}
 
 	// Check for service failures
@@ -87,23 +89,35 @@ func (c LogsCheck) Run() CheckResult {
 			result.Severity = SeverityWarning
 			result.Message = "Service failures detected"
 		}
-		result.Details = append(result.Details, "Failed services:")
+		result.Details = append(result.Details, fmt.Sprintf("Failed services: %d", len(serviceFailures)))
 		for _, service := range serviceFailures {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", service))
+			result.AddFindings(finding.Newf("logs.unit_failed", SeverityWarning, "%s has failed", service).
+				Affects(finding.ResourceUnit, service))
+		}
+	}
+
+	result.SetMetric("failed_units", float64(len(serviceFailures)))
+
+	// Crash dumps are reported but not graded; one old dump is no emergency
+	if coreDumps, err := facts.CoreDumpCount(); err == nil {
+		result.SetMetric("core_dumps", float64(coreDumps))
+		if coreDumps > 0 {
+			result.Details = append(result.Details, fmt.Sprintf("Core dumps kept: %d", coreDumps))
 		}
 	}
 
 	// Check log file sizes
 	logSizes := c.checkLogSizes()
+	if sizeMB, err := facts.JournalSizeMB(); err == nil {
+		result.SetMetric("journal_size_mb", sizeMB)
+	}
 	if len(logSizes) > 0 {
 		if result.Severity < SeverityWarning {
 			result.Severity = SeverityWarning
 			result.Message = "Large log files detected"
 		}
-		result.Details = append(result.Details, "Large log files:")
-		for _, logInfo := range logSizes {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", logInfo))
-		}
+		result.Details = append(result.Details, fmt.Sprintf("Large log files: %d", len(logSizes)))
+		result.AddFindings(logSizes...)
 	}
 
 	if result.Severity == SeverityInfo {
@@ -114,12 +128,11 @@ func (c LogsCheck) Run() CheckResult {
 }
 
 // checkJournalErrors checks systemd journal for recent errors
-func (c LogsCheck) checkJournalErrors() []string {
-	errors := []string{}
+func (c LogsCheck) checkJournalErrors() []finding.Finding {
+	errors := []finding.Finding{}
 
 	// Get errors from the last 24 hours
-	cmd := exec.Command("journalctl", "--since", "24 hours ago", "-p", "err", "--no-pager", "-n", "20")
-	output, err := cmd.Output()
+	output, err := sys.Output("journalctl", "--since", "24 hours ago", "-p", "err", "--no-pager", "-n", "20")
 	if err != nil {
 		return errors
 	}
@@ -141,7 +154,9 @@ func (c LogsCheck) checkJournalErrors() []string {
 			
 			// Filter out common non-critical errors
 			if c.isSignificantError(message) {
-				errors = append(errors, fmt.Sprintf("%s: %s", timestamp, message))
+				errors = append(errors, finding.Newf("logs.journal_error", SeverityWarning, "%s: %s", timestamp, message).
+					With("time", timestamp).
+					WithExcerpt(line))
 			}
 		}
 	}
@@ -151,8 +166,7 @@ func (c LogsCheck) checkJournalErrors() []string {
 
 // checkAuthFailures counts recent authentication failures
 func (c LogsCheck) checkAuthFailures() int {
-	cmd := exec.Command("journalctl", "--since", "24 hours ago", "-u", "ssh", "-u", "systemd-logind", "--no-pager")
-	output, err := cmd.Output()
+	output, err := sys.Output("journalctl", "--since", "24 hours ago", "-u", "ssh", "-u", "systemd-logind", "--no-pager")
 	if err != nil {
 		return 0
 	}
@@ -175,11 +189,10 @@ func (c LogsCheck) checkAuthFailures() int {
 }
 
 // checkDiskErrors looks for disk-related errors in logs
-func (c LogsCheck) checkDiskErrors() []string {
-	errors := []string{}
+func (c LogsCheck) checkDiskErrors() []finding.Finding {
+	errors := []finding.Finding{}
 
-	cmd := exec.Command("journalctl", "--since", "7 days ago", "-p", "err", "--no-pager")
-	output, err := cmd.Output()
+	output, err := sys.Output("journalctl", "--since", "7 days ago", "-p", "err", "--no-pager")
 	if err != nil {
 		return errors
 	}
@@ -203,10 +216,13 @@ func (c LogsCheck) checkDiskErrors() []string {
 		for _, pattern := range diskErrorPatterns {
 			if strings.Contains(lineLower, pattern) {
 				// Extract relevant part of the error message
-				if len(line) > 200 {
-					line = line[:200] + "..."
+				summary := strings.TrimSpace(line)
+				if len(summary) > 200 {
+					summary = summary[:200] + "..."
 				}
-				errors = append(errors, strings.TrimSpace(line))
+				errors = append(errors, finding.New("logs.disk_error", SeverityCritical, summary).
+					With("pattern", pattern).
+					WithExcerpt(line))
 				break
 			}
 		}
@@ -216,11 +232,10 @@ func (c LogsCheck) checkDiskErrors() []string {
 }
 
 // checkMemoryIssues looks for memory-related problems
-func (c LogsCheck) checkMemoryIssues() []string {
-	issues := []string{}
+func (c LogsCheck) checkMemoryIssues() []finding.Finding {
+	issues := []finding.Finding{}
 
-	cmd := exec.Command("journalctl", "--since", "24 hours ago", "--no-pager")
-	output, err := cmd.Output()
+	output, err := sys.Output("journalctl", "--since", "24 hours ago", "--no-pager")
 	if err != nil {
 		return issues
 	}
@@ -239,10 +254,13 @@ func (c LogsCheck) checkMemoryIssues() []string {
 		lineLower := strings.ToLower(line)
 		for _, pattern := range memoryPatterns {
 			if strings.Contains(lineLower, pattern) {
-				if len(line) > 150 {
-					line = line[:150] + "..."
+				summary := strings.TrimSpace(line)
+				if len(summary) > 150 {
+					summary = summary[:150] + "..."
 				}
-				issues = append(issues, strings.TrimSpace(line))
+				issues = append(issues, finding.New("logs.memory_pressure", SeverityWarning, summary).
+					With("pattern", pattern).
+					WithExcerpt(line))
 				break
 			}
 		}
@@ -255,64 +273,23 @@ func (c LogsCheck) checkMemoryIssues() []string {
 func (c LogsCheck) checkServiceFailures() []string {
 	failures := []string{}
 
-	cmd := exec.Command("systemctl", "--failed", "--no-pager", "--no-legend")
-	output, err := cmd.Output()
-	if err != nil {
-		return failures
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 1 {
-			serviceName := fields[0]
-			if serviceName != "" {
-				failures = append(failures, serviceName)
-			}
-		}
+	units, _ := facts.FailedUnits()
+	for _, unit := range units {
+		failures = append(failures, unit.Name)
 	}
 
 	return failures
 }
 
 // checkLogSizes checks for excessively large log files
-func (c LogsCheck) checkLogSizes() []string {
-	largeLogs := []string{}
+func (c LogsCheck) checkLogSizes() []finding.Finding {
+	largeLogs := []finding.Finding{}
 
 	// Check journal size
-	cmd := exec.Command("journalctl", "--disk-usage")
-	output, err := cmd.Output()
-	if err == nil {
-		content := string(output)
-		if strings.Contains(content, "Archived and active journals take up") {
-			// Extract size information
-			re := regexp.MustCompile(`take up ([0-9.]+)([KMGT]?)B`)
-			matches := re.FindStringSubmatch(content)
-			if len(matches) >= 3 {
-				size, _ := strconv.ParseFloat(matches[1], 64)
-				unit := matches[2]
-				
-				// Convert to MB for comparison
-				sizeMB := size
-				switch unit {
-				case "G":
-					sizeMB *= 1024
-				case "T":
-					sizeMB *= 1024 * 1024
-				case "K":
-					sizeMB /= 1024
-				}
-
-				if sizeMB > 1000 { // More than 1GB
-					largeLogs = append(largeLogs, fmt.Sprintf("systemd journal: %.1f%sB", size, unit))
-				}
-			}
-		}
+	if sizeMB, err := facts.JournalSizeMB(); err == nil && sizeMB > thresholds.JournalMaxMB {
+		largeLogs = append(largeLogs, finding.Newf("logs.journal_large", SeverityWarning, "systemd journal: %.1f MB", sizeMB).
+			With("size_mb", fmt.Sprintf("%.1f", sizeMB)).
+			Affects(finding.ResourcePath, "/var/log/journal"))
 	}
 
 	// Check common log files
@@ -328,8 +305,7 @@ func (c LogsCheck) checkLogSizes() []string {
 	}
 
 	for _, logFile := range logFiles {
-		cmd := exec.Command("stat", "-c", "%s", logFile)
-		output, err := cmd.Output()
+		output, err := sys.Output("stat", "-c", "%s", logFile)
 		if err != nil {
 			continue // File doesn't exist or can't be accessed
 		}
@@ -340,10 +316,12 @@ func (c LogsCheck) checkLogSizes() []string {
 			continue
 		}
 
-		// Flag files larger than 100MB
-		if size > 100*1024*1024 {
-			sizeMB := float64(size) / (1024 * 1024)
-			largeLogs = append(largeLogs, fmt.Sprintf("%s: %.1f MB", logFile, sizeMB))
+		// Flag files above the configured size
+		sizeMB := float64(size) / (1024 * 1024)
+		if sizeMB > thresholds.LogFileMaxMB {
+			largeLogs = append(largeLogs, finding.Newf("logs.file_large", SeverityWarning, "%s: %.1f MB", logFile, sizeMB).
+				With("size_mb", fmt.Sprintf("%.1f", sizeMB)).
+				Affects(finding.ResourcePath, logFile))
 		}
 	}
 
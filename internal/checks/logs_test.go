@@ -97,7 +97,7 @@ func TestLogsCheck_checkJournalErrors(t *testing.T) {
 	
 	// If errors exist, they should be non-empty strings
 	for i, err := range errors {
-		if strings.TrimSpace(err) == "" {
+		if strings.TrimSpace(err.Summary) == "" {
 			t.Errorf("Error %d is empty or whitespace only", i)
 		}
 	}
@@ -128,7 +128,7 @@ func TestLogsCheck_checkDiskErrors(t *testing.T) {
 	
 	// If errors exist, they should be non-empty strings
 	for i, err := range errors {
-		if strings.TrimSpace(err) == "" {
+		if strings.TrimSpace(err.Summary) == "" {
 			t.Errorf("Disk error %d is empty or whitespace only", i)
 		}
 	}
@@ -147,7 +147,7 @@ func TestLogsCheck_checkMemoryIssues(t *testing.T) {
 	
 	// If issues exist, they should be non-empty strings
 	for i, issue := range issues {
-		if strings.TrimSpace(issue) == "" {
+		if strings.TrimSpace(issue.Summary) == "" {
 			t.Errorf("Memory issue %d is empty or whitespace only", i)
 		}
 	}
@@ -185,13 +185,13 @@ func TestLogsCheck_checkLogSizes(t *testing.T) {
 	
 	// If large logs exist, they should be non-empty strings and contain size info
 	for i, log := range largeLogs {
-		if strings.TrimSpace(log) == "" {
+		if strings.TrimSpace(log.Summary) == "" {
 			t.Errorf("Large log %d is empty or whitespace only", i)
 		}
 		
 		// Should contain size information (MB or GB)
-		if !strings.Contains(log, "MB") && !strings.Contains(log, "GB") && !strings.Contains(log, "B") {
-			t.Errorf("Large log %d doesn't contain size information: %s", i, log)
+		if log.Evidence["size_mb"] == "" || len(log.Resources) != 1 {
+			t.Errorf("Large log %d doesn't contain size information: %+v", i, log)
 		}
 	}
 }
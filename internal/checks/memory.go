@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 )
 
 // MemoryCheck checks memory usage
@@ -14,6 +14,15 @@ func (m MemoryCheck) Name() string {
 	return "Memory Usage"
 }
 
+func (m MemoryCheck) Info() Info {
+	return Info{
+		ID:       "memory",
+		Category: "resources",
+		Tags:     []string{"performance", "capacity"},
+		Cost:     CostCheap,
+	}
+}
+
 func (m MemoryCheck) RequiresRoot() bool {
 	return false
 }
@@ -27,7 +36,7 @@ func (m MemoryCheck) Run() CheckResult {
 	}
 
 	// Get virtual memory stats
-	vmStat, err := mem.VirtualMemory()
+	memInfo, err := sys.Meminfo()
 	if err != nil {
 		result.Severity = SeverityError
 		result.Message = "Failed to check memory usage"
@@ -35,35 +44,41 @@ func (m MemoryCheck) Run() CheckResult {
 	}
 
 	// Format memory information
-	result.Details = append(result.Details, fmt.Sprintf("Total: %d MB", vmStat.Total/(1024*1024)))
-	result.Details = append(result.Details, fmt.Sprintf("Available: %d MB", vmStat.Available/(1024*1024)))
-	result.Details = append(result.Details, fmt.Sprintf("Used: %d MB (%.1f%%)", vmStat.Used/(1024*1024), vmStat.UsedPercent))
+	usedPercent := memInfo.UsedPercent()
+	result.Details = append(result.Details, fmt.Sprintf("Total: %d MB", memInfo.Total/(1024*1024)))
+	result.Details = append(result.Details, fmt.Sprintf("Available: %d MB", memInfo.Available/(1024*1024)))
+	result.Details = append(result.Details, fmt.Sprintf("Used: %d MB (%.1f%%)", memInfo.Used()/(1024*1024), usedPercent))
 
 	// Check memory usage severity
 	switch {
-	case vmStat.UsedPercent > 90:
+	case usedPercent > thresholds.MemoryCriticalPercent:
 		result.Severity = SeverityError
-		result.Message = fmt.Sprintf("Memory usage critical: %.1f%%", vmStat.UsedPercent)
-	case vmStat.UsedPercent > 80:
+		result.Message = fmt.Sprintf("Memory usage critical: %.1f%%", usedPercent)
+		result.AddFindings(finding.Newf("memory.usage_high", SeverityError, "Memory usage critical: %.1f%%", usedPercent).
+			With("used_percent", fmt.Sprintf("%.1f", usedPercent)))
+	case usedPercent > thresholds.MemoryWarningPercent:
 		result.Severity = SeverityWarning
-		result.Message = fmt.Sprintf("Memory usage high: %.1f%%", vmStat.UsedPercent)
+		result.Message = fmt.Sprintf("Memory usage high: %.1f%%", usedPercent)
+		result.AddFindings(finding.Newf("memory.usage_high", SeverityWarning, "Memory usage high: %.1f%%", usedPercent).
+			With("used_percent", fmt.Sprintf("%.1f", usedPercent)))
 	default:
 		result.Severity = SeverityInfo
-		result.Message = fmt.Sprintf("Memory usage OK: %.1f%%", vmStat.UsedPercent)
+		result.Message = fmt.Sprintf("Memory usage OK: %.1f%%", usedPercent)
 	}
 
 	// Check swap usage
-	swapStat, err := mem.SwapMemory()
-	if err == nil {
-		result.Details = append(result.Details, fmt.Sprintf("Swap Total: %d MB", swapStat.Total/(1024*1024)))
-		result.Details = append(result.Details, fmt.Sprintf("Swap Used: %d MB (%.1f%%)", swapStat.Used/(1024*1024), swapStat.UsedPercent))
-		
-		if swapStat.Total == 0 {
-			result.Details = append(result.Details, "Warning: No swap space configured")
-		} else if swapStat.UsedPercent > 50 {
-			result.Severity = SeverityWarning
-			result.Message += " (High swap usage indicates memory pressure)"
-		}
+	result.Details = append(result.Details, fmt.Sprintf("Swap Total: %d MB", memInfo.SwapTotal/(1024*1024)))
+	result.Details = append(result.Details, fmt.Sprintf("Swap Used: %d MB (%.1f%%)", memInfo.SwapUsed()/(1024*1024), memInfo.SwapUsedPercent()))
+	
+	if memInfo.SwapTotal == 0 {
+		result.Details = append(result.Details, "Warning: No swap space configured")
+		result.AddFindings(finding.New("memory.no_swap", SeverityInfo, "No swap space configured").
+			FixedBy("create_swap_file"))
+	} else if memInfo.SwapUsedPercent() > thresholds.SwapWarningPercent {
+		result.Severity = SeverityWarning
+		result.Message += " (High swap usage indicates memory pressure)"
+		result.AddFindings(finding.Newf("memory.swap_high", SeverityWarning, "Swap usage is %.1f%%", memInfo.SwapUsedPercent()).
+			With("swap_percent", fmt.Sprintf("%.1f", memInfo.SwapUsedPercent())))
 	}
 
 	return result
@@ -2,10 +2,10 @@ package checks
 
 import (
 	"fmt"
-	"net"
-	"os"
 	"strings"
 	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 )
 
 // NetworkCheck checks network configuration
@@ -15,6 +15,15 @@ func (n NetworkCheck) Name() string {
 	return "Network Configuration"
 }
 
+func (n NetworkCheck) Info() Info {
+	return Info{
+		ID:       "network",
+		Category: "network",
+		Tags:     []string{"network", "connectivity"},
+		Cost:     CostCheap,
+	}
+}
+
 func (n NetworkCheck) RequiresRoot() bool {
 	return false
 }
@@ -28,7 +37,7 @@ func (n NetworkCheck) Run() CheckResult {
 	}
 
 	// Check network interfaces
-	interfaces, err := net.Interfaces()
+	interfaces, err := sys.Interfaces()
 	if err != nil {
 		result.Severity = SeverityError
 		result.Message = "Failed to check network interfaces"
@@ -38,31 +47,32 @@ func (n NetworkCheck) Run() CheckResult {
 	hasActiveInterface := false
 	for _, iface := range interfaces {
 		// Skip loopback
-		if iface.Flags&net.FlagLoopback != 0 {
+		if iface.Loopback {
 			continue
 		}
 
 		// Check if interface is up
-		if iface.Flags&net.FlagUp != 0 {
+		if iface.Up {
 			hasActiveInterface = true
 			result.Details = append(result.Details, fmt.Sprintf("Interface %s is UP", iface.Name))
 			
 			// Get addresses for this interface
-			addrs, err := iface.Addrs()
-			if err == nil && len(addrs) > 0 {
-				for _, addr := range addrs {
-					result.Details = append(result.Details, fmt.Sprintf("  IP: %s", addr.String()))
+			if len(iface.Addrs) > 0 {
+				for _, addr := range iface.Addrs {
+					result.Details = append(result.Details, fmt.Sprintf("  IP: %s", addr))
 				}
 			} else {
 				result.Details = append(result.Details, fmt.Sprintf("  No IP address assigned to %s", iface.Name))
 			}
 		} else {
 			result.Details = append(result.Details, fmt.Sprintf("Interface %s is DOWN", iface.Name))
+			result.AddFindings(finding.Newf("network.interface_down", SeverityInfo, "Interface %s is down", iface.Name).
+				Affects(finding.ResourceInterface, iface.Name))
 		}
 	}
 
 	// Check DNS configuration
-	if resolvConf, err := os.ReadFile("/etc/resolv.conf"); err == nil {
+	if resolvConf, err := sys.ReadFile("/etc/resolv.conf"); err == nil {
 		lines := strings.Split(string(resolvConf), "\n")
 		dnsServers := []string{}
 		for _, line := range lines {
@@ -78,14 +88,16 @@ func (n NetworkCheck) Run() CheckResult {
 			result.Details = append(result.Details, fmt.Sprintf("DNS servers: %s", strings.Join(dnsServers, ", ")))
 		} else {
 			result.Details = append(result.Details, "No DNS servers configured")
-			result.Severity = SeverityWarning
+			result.AddFindings(finding.New("network.no_dns", SeverityWarning, "No DNS servers configured").
+				Affects(finding.ResourcePath, "/etc/resolv.conf"))
 		}
 	}
 
 	// Set overall result
 	if !hasActiveInterface {
-		result.Severity = SeverityError
 		result.Message = "No active network interfaces found"
+		result.AddFindings(finding.New("network.no_active_interface", SeverityError, "No active network interfaces found").
+			FixedBy("restart_networking"))
 	} else {
 		result.Message = "Network configuration OK"
 	}
@@ -2,11 +2,10 @@ package checks
 
 import (
 	"fmt"
-	"os/exec"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 )
 
 // PackagesCheck checks the APT package system for issues
@@ -16,6 +15,16 @@ func (c PackagesCheck) Name() string {
 	return "Package System"
 }
 
+func (c PackagesCheck) Info() Info {
+	return Info{
+		ID:       "packages",
+		Category: "packages",
+		Tags:     []string{"packages", "security"},
+		Binaries: []string{"dpkg", "apt", "apt-mark", "du", "systemctl"},
+		Cost:     CostModerate,
+	}
+}
+
 func (c PackagesCheck) RequiresRoot() bool {
 	return false // Basic package checks don't require root
 }
@@ -31,38 +40,36 @@ func (c PackagesCheck) Run() CheckResult {
 
 	// Check for broken packages
 	brokenPackages := c.checkBrokenPackages()
+	result.SetMetric("broken_packages", float64(len(brokenPackages)))
 	if len(brokenPackages) > 0 {
 		result.Severity = SeverityError
 		result.Message = "Broken packages detected"
 		result.Details = append(result.Details, fmt.Sprintf("Broken packages found: %d", len(brokenPackages)))
-		for i, pkg := range brokenPackages {
-			if i >= 5 { // Limit to first 5
-				result.Details = append(result.Details, fmt.Sprintf("... and %d more", len(brokenPackages)-5))
-				break
-			}
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", pkg))
+		for _, pkg := range brokenPackages {
+			result.AddFindings(finding.Newf("packages.broken", SeverityError, "Broken package: %s", pkg).
+				Affects(finding.ResourcePackage, pkg).
+				FixedBy("fix_broken_packages"))
 		}
 	}
 
 	// Check for held packages
 	heldPackages := c.checkHeldPackages()
+	result.SetMetric("held_packages", float64(len(heldPackages)))
 	if len(heldPackages) > 0 {
 		if result.Severity < SeverityWarning {
 			result.Severity = SeverityWarning
 			result.Message = "Held packages detected"
 		}
 		result.Details = append(result.Details, fmt.Sprintf("Held packages: %d", len(heldPackages)))
-		for i, pkg := range heldPackages {
-			if i >= 3 {
-				result.Details = append(result.Details, fmt.Sprintf("... and %d more", len(heldPackages)-3))
-				break
-			}
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", pkg))
+		for _, pkg := range heldPackages {
+			result.AddFindings(finding.Newf("packages.held", SeverityWarning, "Held package: %s", pkg).
+				Affects(finding.ResourcePackage, pkg))
 		}
 	}
 
 	// Check for upgradeable packages
 	upgradeableCount := c.checkUpgradeablePackages()
+	result.SetMetric("upgradable_packages", float64(upgradeableCount))
 	if upgradeableCount > 0 {
 		result.Details = append(result.Details, fmt.Sprintf("Packages available for upgrade: %d", upgradeableCount))
 		if upgradeableCount > 50 {
@@ -70,11 +77,14 @@ func (c PackagesCheck) Run() CheckResult {
 				result.Severity = SeverityWarning
 				result.Message = "Many packages need upgrading"
 			}
+			result.AddFindings(finding.Newf("packages.upgrades_pending", SeverityWarning, "%d packages can be upgraded", upgradeableCount).
+				With("count", upgradeableCount))
 		}
 	}
 
 	// Check for autoremovable packages
 	autoremovableCount := c.checkAutoremovablePackages()
+	result.SetMetric("autoremovable_packages", float64(autoremovableCount))
 	if autoremovableCount > 0 {
 		result.Details = append(result.Details, fmt.Sprintf("Autoremovable packages: %d", autoremovableCount))
 		if autoremovableCount > 20 {
@@ -82,6 +92,9 @@ func (c PackagesCheck) Run() CheckResult {
 				result.Severity = SeverityWarning
 				result.Message = "Many orphaned packages detected"
 			}
+			result.AddFindings(finding.Newf("packages.autoremovable", SeverityWarning, "%d packages are no longer needed", autoremovableCount).
+				With("count", autoremovableCount).
+				FixedBy("remove_orphaned_packages"))
 		}
 	}
 
@@ -90,9 +103,11 @@ func (c PackagesCheck) Run() CheckResult {
 	if len(invalidSources) > 0 {
 		result.Severity = SeverityError
 		result.Message = "Invalid APT sources detected"
-		result.Details = append(result.Details, "Invalid sources:")
+		result.Details = append(result.Details, fmt.Sprintf("Invalid sources: %d", len(invalidSources)))
 		for _, source := range invalidSources {
-			result.Details = append(result.Details, fmt.Sprintf("  - %s", source))
+			result.AddFindings(finding.New("packages.source_invalid", SeverityError, source).
+				WithExcerpt(source).
+				FixedBy("update_package_cache"))
 		}
 	}
 
@@ -100,17 +115,23 @@ func (c PackagesCheck) Run() CheckResult {
 	if c.checkDpkgInterrupted() {
 		result.Severity = SeverityError
 		result.Message = "Package installation was interrupted"
-		result.Details = append(result.Details, "dpkg was interrupted - packages may be in inconsistent state")
+		result.AddFindings(finding.New("packages.dpkg_interrupted", SeverityError, "dpkg was interrupted - packages may be in inconsistent state").
+			FixedBy("fix_broken_packages"))
 	}
 
 	// Check package cache size
 	cacheSize := c.checkPackageCacheSize()
-	if cacheSize > 1000 { // More than 1GB
+	result.SetMetric("package_cache_mb", cacheSize)
+	if cacheSize > thresholds.PackageCacheMaxMB {
 		if result.Severity < SeverityWarning {
 			result.Severity = SeverityWarning
 			result.Message = "Large package cache detected"
 		}
 		result.Details = append(result.Details, fmt.Sprintf("Package cache size: %.1f MB", cacheSize))
+		result.AddFindings(finding.Newf("packages.cache_large", SeverityWarning, "Package cache uses %.1f MB", cacheSize).
+			With("size_mb", fmt.Sprintf("%.1f", cacheSize)).
+			Affects(finding.ResourcePath, "/var/cache/apt/archives").
+			FixedBy("clean_package_cache"))
 	}
 
 	// Check for unattended upgrades status
@@ -126,112 +147,39 @@ func (c PackagesCheck) Run() CheckResult {
 
 // checkBrokenPackages finds packages in broken state
 func (c PackagesCheck) checkBrokenPackages() []string {
-	broken := []string{}
-
-	// Check with dpkg
-	cmd := exec.Command("dpkg", "-l")
-	output, err := cmd.Output()
-	if err != nil {
-		return broken
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "iU") || strings.HasPrefix(line, "iF") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				broken = append(broken, fields[1])
-			}
-		}
-	}
-
-	// Also check with apt
-	cmd = exec.Command("apt", "list", "--broken")
-	output, err = cmd.Output()
-	if err == nil {
-		lines = strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if strings.Contains(line, "/") && !strings.HasPrefix(line, "WARNING") {
-				parts := strings.Split(line, "/")
-				if len(parts) > 0 {
-					pkgName := strings.TrimSpace(parts[0])
-					if pkgName != "" && pkgName != "Listing..." {
-						broken = append(broken, pkgName)
-					}
-				}
-			}
-		}
+	broken, _ := facts.BrokenPackages()
+	if broken == nil {
+		return []string{}
 	}
-
-	return removeDuplicates(broken)
+	return broken
 }
 
 // checkHeldPackages finds packages on hold
 func (c PackagesCheck) checkHeldPackages() []string {
-	held := []string{}
-
-	cmd := exec.Command("apt-mark", "showhold")
-	output, err := cmd.Output()
-	if err != nil {
-		return held
+	held, _ := facts.HeldPackages()
+	if held == nil {
+		return []string{}
 	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			held = append(held, line)
-		}
-	}
-
 	return held
 }
 
 // checkUpgradeablePackages counts packages that can be upgraded
 func (c PackagesCheck) checkUpgradeablePackages() int {
-	cmd := exec.Command("apt", "list", "--upgradable")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0
-	}
-
-	lines := strings.Split(string(output), "\n")
-	count := 0
-	for _, line := range lines {
-		if strings.Contains(line, "[upgradable from:") {
-			count++
-		}
-	}
-
+	count, _ := facts.UpgradableCount()
 	return count
 }
 
 // checkAutoremovablePackages counts packages that can be autoremoved
 func (c PackagesCheck) checkAutoremovablePackages() int {
-	cmd := exec.Command("apt", "autoremove", "--dry-run")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0
-	}
-
-	content := string(output)
-	// Look for the line that says "The following packages will be REMOVED:"
-	re := regexp.MustCompile(`The following packages will be REMOVED:\s*\n(.*?)(\n\n|\nNeed|\nAfter|$)`)
-	matches := re.FindStringSubmatch(content)
-	if len(matches) >= 2 {
-		packages := strings.Fields(matches[1])
-		return len(packages)
-	}
-
-	return 0
+	count, _ := facts.AutoremovableCount()
+	return count
 }
 
 // checkAPTSources validates APT source lists
 func (c PackagesCheck) checkAPTSources() []string {
 	invalid := []string{}
 
-	cmd := exec.Command("apt", "update", "--dry-run")
-	output, err := cmd.CombinedOutput()
+	output, err := sys.CombinedOutput("apt", "update", "--dry-run")
 	if err != nil {
 		content := string(output)
 		lines := strings.Split(content, "\n")
@@ -249,39 +197,20 @@ func (c PackagesCheck) checkAPTSources() []string {
 
 // checkDpkgInterrupted checks if dpkg was interrupted
 func (c PackagesCheck) checkDpkgInterrupted() bool {
-	cmd := exec.Command("dpkg", "--audit")
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-
-	return strings.TrimSpace(string(output)) != ""
+	audit, _ := facts.DpkgAudit()
+	return len(audit) > 0
 }
 
 // checkPackageCacheSize returns cache size in MB
 func (c PackagesCheck) checkPackageCacheSize() float64 {
-	cmd := exec.Command("du", "-sm", "/var/cache/apt/archives")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0
-	}
-
-	fields := strings.Fields(string(output))
-	if len(fields) >= 1 {
-		size, err := strconv.ParseFloat(fields[0], 64)
-		if err == nil {
-			return size
-		}
-	}
-
-	return 0
+	size, _ := facts.PackageCacheMB()
+	return size
 }
 
 // checkUnattendedUpgrades checks unattended-upgrades status
 func (c PackagesCheck) checkUnattendedUpgrades() string {
 	// Check if unattended-upgrades is installed
-	cmd := exec.Command("dpkg", "-l", "unattended-upgrades")
-	output, err := cmd.Output()
+	output, err := sys.Output("dpkg", "-l", "unattended-upgrades")
 	if err != nil {
 		return "not installed"
 	}
@@ -291,8 +220,7 @@ func (c PackagesCheck) checkUnattendedUpgrades() string {
 	}
 
 	// Check if it's enabled
-	cmd = exec.Command("systemctl", "is-enabled", "unattended-upgrades")
-	output, err = cmd.Output()
+	output, err = sys.Output("systemctl", "is-enabled", "unattended-upgrades")
 	if err != nil {
 		return "installed but status unknown"
 	}
@@ -305,19 +233,4 @@ func (c PackagesCheck) checkUnattendedUpgrades() string {
 	}
 
 	return fmt.Sprintf("installed (%s)", status)
-}
-
-// removeDuplicates removes duplicate strings from a slice
-func removeDuplicates(slice []string) []string {
-	keys := make(map[string]bool)
-	result := []string{}
-
-	for _, item := range slice {
-		if !keys[item] {
-			keys[item] = true
-			result = append(result, item)
-		}
-	}
-
-	return result
 }
\ No newline at end of file
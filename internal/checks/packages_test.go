@@ -3,6 +3,9 @@ package checks
 import (
 	"strings"
 	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/machine"
 )
 
 func TestPackagesCheck_Name(t *testing.T) {
@@ -217,53 +220,6 @@ func TestPackagesCheck_checkUnattendedUpgrades(t *testing.T) {
 	t.Logf("Unattended upgrades status: %s", status)
 }
 
-func TestRemoveDuplicates(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    []string
-		expected []string
-	}{
-		{
-			name:     "empty slice",
-			input:    []string{},
-			expected: []string{},
-		},
-		{
-			name:     "no duplicates",
-			input:    []string{"a", "b", "c"},
-			expected: []string{"a", "b", "c"},
-		},
-		{
-			name:     "with duplicates",
-			input:    []string{"a", "b", "a", "c", "b"},
-			expected: []string{"a", "b", "c"},
-		},
-		{
-			name:     "all duplicates",
-			input:    []string{"a", "a", "a"},
-			expected: []string{"a"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := removeDuplicates(tt.input)
-			
-			// Check length
-			if len(result) != len(tt.expected) {
-				t.Errorf("removeDuplicates() length = %d, want %d", len(result), len(tt.expected))
-			}
-			
-			// Check contents (order matters in this implementation)
-			for i, expected := range tt.expected {
-				if i >= len(result) || result[i] != expected {
-					t.Errorf("removeDuplicates() = %v, want %v", result, tt.expected)
-					break
-				}
-			}
-		})
-	}
-}
 
 func TestPackagesCheck_Integration(t *testing.T) {
 	// This is an integration test that checks the overall functionality
@@ -285,7 +241,7 @@ func TestPackagesCheck_Integration(t *testing.T) {
 	}
 
 	// Check that the result provides useful information
-	detailsText := strings.Join(result.Details, " ")
+	detailsText := strings.Join(result.Lines(), " ")
 	
 	// Should mention key package system aspects
 	expectedKeywords := []string{"packages", "upgrade", "cache"}
@@ -307,7 +263,7 @@ func TestPackagesCheck_SeverityLogic(t *testing.T) {
 	result := check.Run()
 
 	// If there are any error conditions, severity should reflect that
-	detailsText := strings.Join(result.Details, " ")
+	detailsText := strings.Join(result.Lines(), " ")
 	
 	if strings.Contains(detailsText, "Broken packages") && result.Severity < SeverityError {
 		t.Error("Broken packages detected but severity is not Error or Critical")
@@ -316,4 +272,66 @@ func TestPackagesCheck_SeverityLogic(t *testing.T) {
 	if strings.Contains(detailsText, "Many packages need upgrading") && result.Severity < SeverityWarning {
 		t.Error("Many upgradeable packages detected but severity is not Warning or higher")
 	}
-}
\ No newline at end of file
+}
+
+func TestPackagesCheck_RunFromFixture(t *testing.T) {
+	useFixture(t, "broken-packages.jsonl")
+
+	result := PackagesCheck{}.Run()
+
+	if result.Severity != SeverityError || result.Message != "Broken packages detected" {
+		t.Errorf("Run() = %v %q, want error for broken packages", result.Severity, result.Message)
+	}
+	details := strings.Join(result.Lines(), "\n")
+	for _, want := range []string{
+		"Broken packages found: 2",
+		"- Broken package: libfoo",
+		"- Broken package: libbar",
+		"Held packages: 1",
+		"- Held package: linux-image-amd64",
+		"Package cache size: 2048.0 MB",
+		"Unattended upgrades: enabled",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Details missing %q:\n%s", want, details)
+		}
+	}
+	if strings.Contains(details, "bash") || strings.Contains(details, "Listing") {
+		t.Errorf("Healthy packages reported as broken:\n%s", details)
+	}
+
+	broken := 0
+	for _, f := range result.Findings {
+		if f.ID != "packages.broken" {
+			continue
+		}
+		broken++
+		if len(f.Resources) != 1 || f.Resources[0].Kind != finding.ResourcePackage || len(f.FixIDs) == 0 {
+			t.Errorf("Broken package finding = %+v", f)
+		}
+	}
+	if broken != 2 {
+		t.Errorf("Got %d broken package findings, want 2", broken)
+	}
+
+	for name, want := range map[string]float64{"broken_packages": 2, "held_packages": 1, "package_cache_mb": 2048} {
+		if got, ok := result.Metrics[name]; !ok || got != want {
+			t.Errorf("Metric %s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestPackagesCheck_RunWithoutTools(t *testing.T) {
+	// Nothing recorded: every command looks uninstalled
+	useMachine(t, machine.NewFixture().Machine())
+
+	result := PackagesCheck{}.Run()
+
+	if result.Severity != SeverityInfo {
+		t.Errorf("Run() severity = %v, want info when package tools are missing", result.Severity)
+	}
+	details := strings.Join(result.Details, "\n")
+	if !strings.Contains(details, "Unattended upgrades: not installed") {
+		t.Errorf("Details = %s", details)
+	}
+}
@@ -0,0 +1,228 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cost is the rough price of running a check
+type Cost int
+
+const (
+	CostCheap     Cost = iota // Reads kernel counters or a few files
+	CostModerate              // Runs a handful of external commands
+	CostExpensive             // Scans the filesystem or large logs
+)
+
+func (c Cost) String() string {
+	switch c {
+	case CostCheap:
+		return "cheap"
+	case CostModerate:
+		return "moderate"
+	case CostExpensive:
+		return "expensive"
+	}
+	return "unknown"
+}
+
+// MarshalText renders the cost as its name
+func (c Cost) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// Info describes a check for selection and listing
+type Info struct {
+	ID       string   `json:"id" yaml:"id"`
+	Category string   `json:"category" yaml:"category"`
+	Tags     []string `json:"tags" yaml:"tags"`
+	Binaries []string `json:"binaries,omitempty" yaml:"binaries,omitempty"` // External programs the check runs
+	Cost     Cost     `json:"cost" yaml:"cost"`
+}
+
+// HasTag reports whether the check carries a tag
+func (i Info) HasTag(tag string) bool {
+	for _, t := range i.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds checks by ID, in the order they were registered
+type Registry struct {
+	checks []Check
+	byID   map[string]Check
+}
+
+// NewRegistry creates a registry holding the given checks
+func NewRegistry(checks ...Check) (*Registry, error) {
+	r := &Registry{byID: make(map[string]Check)}
+	for _, check := range checks {
+		if err := r.Register(check); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a check. IDs must be unique and lowercase
+func (r *Registry) Register(check Check) error {
+	id := check.Info().ID
+	if id == "" || id != strings.ToLower(id) {
+		return fmt.Errorf("check %q has invalid ID %q", check.Name(), id)
+	}
+	if _, exists := r.byID[id]; exists {
+		return fmt.Errorf("check ID %q registered twice", id)
+	}
+	r.byID[id] = check
+	r.checks = append(r.checks, check)
+	return nil
+}
+
+// Checks returns every registered check
+func (r *Registry) Checks() []Check {
+	return append([]Check(nil), r.checks...)
+}
+
+// Lookup finds a check by ID
+func (r *Registry) Lookup(id string) (Check, bool) {
+	check, ok := r.byID[strings.ToLower(strings.TrimSpace(id))]
+	return check, ok
+}
+
+// IDs lists the registered check IDs in registration order
+func (r *Registry) IDs() []string {
+	ids := make([]string, len(r.checks))
+	for i, check := range r.checks {
+		ids[i] = check.Info().ID
+	}
+	return ids
+}
+
+// Tags lists every tag used by a registered check, sorted
+func (r *Registry) Tags() []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, check := range r.checks {
+		for _, tag := range check.Info().Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Selector narrows the registered checks. Only and Skip hold check IDs;
+// Tags keeps checks carrying at least one of the tags. Empty fields select
+// everything
+type Selector struct {
+	Only []string
+	Skip []string
+	Tags []string
+}
+
+// Select returns the checks matching the selector in registration order.
+// Unknown IDs and tags are an error so typos do not silently run nothing
+func (r *Registry) Select(sel Selector) ([]Check, error) {
+	only, err := r.idSet(sel.Only)
+	if err != nil {
+		return nil, err
+	}
+	skip, err := r.idSet(sel.Skip)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	for _, tag := range r.Tags() {
+		known[tag] = true
+	}
+	var tags []string
+	for _, tag := range sel.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !known[tag] {
+			return nil, fmt.Errorf("unknown tag %q (available: %s)", tag, strings.Join(r.Tags(), ", "))
+		}
+		tags = append(tags, tag)
+	}
+
+	var selected []Check
+	for _, check := range r.checks {
+		info := check.Info()
+		if len(only) > 0 && !only[info.ID] {
+			continue
+		}
+		if skip[info.ID] {
+			continue
+		}
+		if len(tags) > 0 && !hasAnyTag(info, tags) {
+			continue
+		}
+		selected = append(selected, check)
+	}
+	return selected, nil
+}
+
+func (r *Registry) idSet(ids []string) (map[string]bool, error) {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		check, ok := r.Lookup(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown check %q (available: %s)", id, strings.Join(r.IDs(), ", "))
+		}
+		set[check.Info().ID] = true
+	}
+	return set, nil
+}
+
+func hasAnyTag(info Info, tags []string) bool {
+	for _, tag := range tags {
+		if info.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunCheck runs a check, or reports it as skipped when it needs root and the
+// process does not have it
+func RunCheck(check Check, isRoot bool) CheckResult {
+	return RunCheckContext(context.Background(), check, isRoot)
+}
+
+// RunCheckContext is RunCheck for a check that should stop once ctx is
+// done. Only checks implementing ContextCheck are told
+func RunCheckContext(ctx context.Context, check Check, isRoot bool) CheckResult {
+	if check.RequiresRoot() && !isRoot {
+		return CheckResult{
+			Name:      check.Name(),
+			Severity:  SeverityInfo,
+			Status:    StatusSkipped,
+			Message:   "Skipped: requires root",
+			Details:   []string{"Run debian-doctor with sudo to include this check"},
+			Timestamp: time.Now(),
+		}
+	}
+	var result CheckResult
+	if contextCheck, ok := check.(ContextCheck); ok {
+		result = contextCheck.RunContext(ctx)
+	} else {
+		result = check.Run()
+	}
+	// Findings belong to the check's category unless they say otherwise
+	category := check.Info().Category
+	for i := range result.Findings {
+		if result.Findings[i].Category == "" {
+			result.Findings[i].Category = category
+		}
+	}
+	return result
+}
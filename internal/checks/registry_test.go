@@ -0,0 +1,94 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	registry, err := NewRegistry(DiskSpaceCheck{}, MemoryCheck{})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	if err := registry.Register(DiskSpaceCheck{}); err == nil {
+		t.Error("Expected duplicate ID to be rejected")
+	}
+	if err := registry.Register(&mockCheckImpl{name: "Mock"}); err != nil {
+		t.Errorf("Register() error = %v", err)
+	}
+
+	if got := strings.Join(registry.IDs(), ","); got != "disk,memory,mock" {
+		t.Errorf("IDs() = %s", got)
+	}
+	if check, ok := registry.Lookup(" Memory "); !ok || check.Name() != "Memory Usage" {
+		t.Errorf("Lookup() = %v, %v", check, ok)
+	}
+}
+
+func TestRegistrySelect(t *testing.T) {
+	registry := DefaultRegistry()
+
+	tests := []struct {
+		name    string
+		sel     Selector
+		want    string
+		wantErr bool
+	}{
+		{"everything", Selector{}, strings.Join(registry.IDs(), ","), false},
+		{"only", Selector{Only: []string{"packages", "disk"}}, "disk,packages", false},
+		{"skip", Selector{Skip: []string{"system", "network", "logs", "packages", "filesystem", "services"}}, "disk,memory", false},
+		{"tags", Selector{Tags: []string{"security"}}, "logs,packages,services", false},
+		{"tags and skip", Selector{Tags: []string{"security"}, Skip: []string{"logs"}}, "packages,services", false},
+		{"unknown check", Selector{Only: []string{"dsik"}}, "", true},
+		{"unknown tag", Selector{Tags: []string{"nope"}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := registry.Select(tt.sel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Select() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ids []string
+			for _, check := range selected {
+				ids = append(ids, check.Info().ID)
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("Select() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRegistryMetadata(t *testing.T) {
+	for _, check := range GetAllChecks() {
+		info := check.Info()
+		if info.Category == "" || len(info.Tags) == 0 {
+			t.Errorf("Check %s is missing a category or tags: %+v", info.ID, info)
+		}
+	}
+}
+
+func TestRunCheckSkipsRootOnly(t *testing.T) {
+	result := RunCheck(ServicesCheck{}, false)
+	if result.Status != StatusSkipped {
+		t.Fatalf("Status = %q, want %q", result.Status, StatusSkipped)
+	}
+	if result.Name != "System Services" || result.Message != "Skipped: requires root" {
+		t.Errorf("Unexpected skipped result: %+v", result)
+	}
+
+	results := NewResults()
+	results.AddResult(result)
+	if len(results.GetInfo()) != 0 || len(results.GetWarnings()) != 0 {
+		t.Error("Skipped check should not count as info or warning")
+	}
+	if skipped := results.GetSkipped(); len(skipped) != 1 || skipped[0] != "System Services: requires root" {
+		t.Errorf("GetSkipped() = %v", skipped)
+	}
+
+	if ran := RunCheck(&mockCheckImpl{name: "Mock", requiresRoot: true}, true); ran.Status != "" {
+		t.Errorf("Expected root check to run as root, got status %q", ran.Status)
+	}
+}
@@ -0,0 +1,213 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// Runner executes checks in a bounded worker pool, giving each check its own
+// deadline. A check that misses its deadline is reported as timed out;
+// checks implementing ContextCheck are told to stop, others are left to
+// finish in the background. A check still running from an earlier run of
+// the same Runner is not started again until it has finished
+type Runner struct {
+	Workers int
+	Timeout time.Duration
+	IsRoot  bool
+
+	// Progress, when set, is called after each check finishes. Calls are
+	// never concurrent
+	Progress func(result CheckResult, done, total int)
+
+	mu      sync.Mutex
+	running map[string]bool // IDs of checks whose goroutine has not returned
+}
+
+// NewRunner creates a runner using the configured worker count and timeout
+func NewRunner(cfg *config.Config) *Runner {
+	return &Runner{
+		Workers: cfg.Runner.Workers,
+		Timeout: cfg.Runner.CheckTimeout,
+		IsRoot:  cfg.IsRoot,
+	}
+}
+
+// Run executes the checks and returns their results in the order given.
+// Checks still waiting when ctx is cancelled are reported as cancelled.
+// Every run starts from freshly gathered facts
+func (r *Runner) Run(ctx context.Context, checks []Check) Results {
+	facts.Reset()
+
+	type completed struct {
+		index  int
+		result CheckResult
+	}
+
+	workers := r.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(checks) {
+		workers = len(checks)
+	}
+
+	jobs := make(chan int)
+	done := make(chan completed)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				done <- completed{i, r.runOne(ctx, checks[i])}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range checks {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	ordered := make([]*CheckResult, len(checks))
+	finished := 0
+	for c := range done {
+		result := c.result
+		ordered[c.index] = &result
+		finished++
+		if r.Progress != nil {
+			r.Progress(result, finished, len(checks))
+		}
+	}
+
+	results := NewResults()
+	for i, check := range checks {
+		if ordered[i] == nil {
+			results.AddResult(cancelledResult(check))
+			continue
+		}
+		results.AddResult(*ordered[i])
+	}
+	return results
+}
+
+// runOne runs a single check under its own deadline
+func (r *Runner) runOne(ctx context.Context, check Check) CheckResult {
+	if ctx.Err() != nil {
+		return cancelledResult(check)
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultRunnerSettings().CheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	id := check.Info().ID
+	if !r.start(id) {
+		return stillRunningResult(check)
+	}
+
+	// Buffered so an abandoned check can still deliver its result and exit
+	out := make(chan CheckResult, 1)
+	started := time.Now()
+	go func() {
+		defer r.finish(id)
+		defer func() {
+			if p := recover(); p != nil {
+				out <- CheckResult{
+					Name:      check.Name(),
+					Severity:  SeverityError,
+					Message:   fmt.Sprintf("%s failed: %v", check.Name(), p),
+					Details:   []string{},
+					Timestamp: time.Now(),
+				}
+			}
+		}()
+		out <- RunCheckContext(ctx, check, r.IsRoot)
+	}()
+
+	select {
+	case result := <-out:
+		result.Duration = time.Since(started)
+		return result
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result := timedOutResult(check, timeout)
+			result.Duration = timeout
+			return result
+		}
+		return cancelledResult(check)
+	}
+}
+
+// start marks a check as running, or returns false when it still is
+func (r *Runner) start(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running[id] {
+		return false
+	}
+	if r.running == nil {
+		r.running = make(map[string]bool)
+	}
+	r.running[id] = true
+	return true
+}
+
+func (r *Runner) finish(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, id)
+}
+
+func stillRunningResult(check Check) CheckResult {
+	return CheckResult{
+		Name:      check.Name(),
+		Severity:  SeverityWarning,
+		Status:    StatusTimedOut,
+		Message:   fmt.Sprintf("%s is still running from an earlier run", check.Name()),
+		Details:   []string{"The check was not started again; a command it runs may be hanging"},
+		Timestamp: time.Now(),
+	}
+}
+
+func timedOutResult(check Check, timeout time.Duration) CheckResult {
+	return CheckResult{
+		Name:      check.Name(),
+		Severity:  SeverityWarning,
+		Status:    StatusTimedOut,
+		Message:   fmt.Sprintf("%s timed out after %s", check.Name(), timeout),
+		Details:   []string{"The check did not finish before its deadline; a command it runs may be hanging"},
+		Timestamp: time.Now(),
+	}
+}
+
+func cancelledResult(check Check) CheckResult {
+	return CheckResult{
+		Name:      check.Name(),
+		Severity:  SeverityInfo,
+		Status:    StatusCancelled,
+		Message:   "cancelled before it finished",
+		Details:   []string{},
+		Timestamp: time.Now(),
+	}
+}
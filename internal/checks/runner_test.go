@@ -0,0 +1,191 @@
+package checks
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubCheck is a check whose behaviour is supplied by the test
+type stubCheck struct {
+	name         string
+	requiresRoot bool
+	run          func() CheckResult
+}
+
+func (s stubCheck) Name() string       { return s.name }
+func (s stubCheck) Info() Info         { return Info{ID: s.name, Category: "test"} }
+func (s stubCheck) RequiresRoot() bool { return s.requiresRoot }
+func (s stubCheck) Run() CheckResult   { return s.run() }
+
+func quickCheck(name string, severity Severity) stubCheck {
+	return stubCheck{name: name, run: func() CheckResult {
+		return CheckResult{Name: name, Severity: severity, Message: name + " done", Timestamp: time.Now()}
+	}}
+}
+
+func TestRunnerKeepsOrder(t *testing.T) {
+	var checks []Check
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		name := name
+		checks = append(checks, stubCheck{name: name, run: func() CheckResult {
+			// Finish in reverse order
+			time.Sleep(time.Duration('f'-name[0]) * 5 * time.Millisecond)
+			return CheckResult{Name: name, Severity: SeverityInfo, Timestamp: time.Now()}
+		}})
+	}
+
+	progress := 0
+	runner := &Runner{Workers: 5, Timeout: time.Second, IsRoot: true}
+	runner.Progress = func(result CheckResult, done, total int) {
+		progress++
+		if done != progress || total != 5 {
+			t.Errorf("Progress(%s, %d, %d) out of sequence", result.Name, done, total)
+		}
+	}
+
+	results := runner.Run(context.Background(), checks)
+	all := results.GetAllChecks()
+	if len(all) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(all))
+	}
+	for i, want := range []string{"a", "b", "c", "d", "e"} {
+		if all[i].Name != want {
+			t.Errorf("results[%d] = %s, want %s", i, all[i].Name, want)
+		}
+	}
+	if all[0].Duration < 25*time.Millisecond {
+		t.Errorf("Duration of a = %s, want at least 25ms", all[0].Duration)
+	}
+}
+
+func TestRunnerBoundsConcurrency(t *testing.T) {
+	var running, peak int32
+	check := func(name string) Check {
+		return stubCheck{name: name, run: func() CheckResult {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return CheckResult{Name: name}
+		}}
+	}
+
+	runner := &Runner{Workers: 2, Timeout: time.Second}
+	runner.Run(context.Background(), []Check{check("a"), check("b"), check("c"), check("d"), check("e")})
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 checks at once, saw %d", peak)
+	}
+}
+
+func TestRunnerTimeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	hung := stubCheck{name: "hung", run: func() CheckResult {
+		<-release
+		return CheckResult{Name: "hung"}
+	}}
+
+	runner := &Runner{Workers: 2, Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	results := runner.Run(context.Background(), []Check{hung, quickCheck("quick", SeverityInfo)})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Run() blocked for %s on a hung check", elapsed)
+	}
+
+	all := results.GetAllChecks()
+	if all[0].Status != StatusTimedOut || all[0].Severity != SeverityWarning || all[0].Duration != 50*time.Millisecond {
+		t.Errorf("Expected timed out warning, got %+v", all[0])
+	}
+	if all[1].Status != "" || all[1].Message != "quick done" {
+		t.Errorf("Quick check affected by hung one: %+v", all[1])
+	}
+	if warnings := results.GetWarnings(); len(warnings) != 1 || warnings[0] != "hung timed out after 50ms" {
+		t.Errorf("GetWarnings() = %v", warnings)
+	}
+}
+
+func TestRunnerSkipsStillRunning(t *testing.T) {
+	release := make(chan struct{})
+	var runs atomic.Int32
+	hung := stubCheck{name: "hung", run: func() CheckResult {
+		runs.Add(1)
+		<-release
+		return CheckResult{Name: "hung"}
+	}}
+
+	runner := &Runner{Workers: 1, Timeout: 50 * time.Millisecond}
+	runner.Run(context.Background(), []Check{hung})
+	results := runner.Run(context.Background(), []Check{hung})
+	if result := results.GetAllChecks()[0]; result.Status != StatusTimedOut || result.Message != "hung is still running from an earlier run" {
+		t.Errorf("Second run = %+v, want the check left alone", result)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("Check started %d times while it was hanging", runs.Load())
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		results = runner.Run(context.Background(), []Check{hung})
+		if results.GetAllChecks()[0].Status == "" || time.Now().After(deadline) {
+			break
+		}
+	}
+	if result := results.GetAllChecks()[0]; result.Status != "" {
+		t.Errorf("Check was not started again once it finished: %+v", result)
+	}
+}
+
+func TestRunnerCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	blocking := stubCheck{name: "blocking", run: func() CheckResult {
+		cancel()
+		<-release
+		return CheckResult{Name: "blocking"}
+	}}
+
+	runner := &Runner{Workers: 1, Timeout: time.Minute}
+	results := runner.Run(ctx, []Check{blocking, quickCheck("later", SeverityError)})
+
+	for _, result := range results.GetAllChecks() {
+		if result.Status != StatusCancelled {
+			t.Errorf("Expected %s to be cancelled, got %+v", result.Name, result)
+		}
+	}
+	if len(results.GetSkipped()) != 2 || len(results.GetErrors()) != 0 {
+		t.Errorf("Cancelled checks should be listed as skipped: %v", results.GetSkipped())
+	}
+}
+
+func TestRunnerRootAndPanics(t *testing.T) {
+	rootOnly := stubCheck{name: "root", requiresRoot: true, run: func() CheckResult {
+		t.Error("Root-only check ran without root")
+		return CheckResult{}
+	}}
+	panicking := stubCheck{name: "panics", run: func() CheckResult {
+		panic("boom")
+	}}
+
+	runner := &Runner{Workers: 2, Timeout: time.Second, IsRoot: false}
+	results := runner.Run(context.Background(), []Check{rootOnly, panicking})
+	all := results.GetAllChecks()
+
+	if all[0].Status != StatusSkipped {
+		t.Errorf("Expected root-only check to be skipped, got %+v", all[0])
+	}
+	if all[1].Severity != SeverityError || all[1].Message != "panics failed: boom" {
+		t.Errorf("Expected panic to become an error result, got %+v", all[1])
+	}
+}
@@ -1,11 +1,12 @@
 package checks
 
 import (
-	"bytes"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
 )
 
 // ServicesCheck checks critical system services
@@ -15,6 +16,16 @@ func (s ServicesCheck) Name() string {
 	return "System Services"
 }
 
+func (s ServicesCheck) Info() Info {
+	return Info{
+		ID:       "services",
+		Category: "services",
+		Tags:     []string{"services", "security"},
+		Binaries: []string{"systemctl"},
+		Cost:     CostModerate,
+	}
+}
+
 func (s ServicesCheck) RequiresRoot() bool {
 	return true
 }
@@ -28,7 +39,7 @@ func (s ServicesCheck) Run() CheckResult {
 	}
 
 	// Check if systemctl is available
-	if _, err := exec.LookPath("systemctl"); err != nil {
+	if _, err := sys.LookPath("systemctl"); err != nil {
 		result.Severity = SeverityWarning
 		result.Message = "systemctl not found - cannot check services"
 		return result
@@ -54,25 +65,21 @@ func (s ServicesCheck) Run() CheckResult {
 	}
 
 	// Check for any failed services
-	cmd := exec.Command("systemctl", "--failed", "--no-legend", "--no-pager")
-	output, err := cmd.Output()
-	if err == nil && len(output) > 0 {
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if line != "" {
-				parts := strings.Fields(line)
-				if len(parts) > 0 {
-					failedServices = append(failedServices, parts[0])
-				}
-			}
-		}
+	failedUnits, _ := facts.FailedUnitsOfType("service")
+	for _, unit := range failedUnits {
+		failedServices = append(failedServices, unit.Name)
 	}
+	failedServices = probes.Unique(failedServices)
 
 	// Set result based on findings
 	if len(failedServices) > 0 {
 		result.Severity = SeverityError
 		result.Message = fmt.Sprintf("%d failed services detected", len(failedServices))
 		result.Details = append(result.Details, fmt.Sprintf("Failed: %s", strings.Join(failedServices, ", ")))
+		for _, service := range failedServices {
+			result.AddFindings(finding.Newf("services.failed", SeverityError, "%s is not running", service).
+				Affects(finding.ResourceUnit, service))
+		}
 	} else {
 		result.Message = "All critical services are running"
 	}
@@ -81,17 +88,12 @@ func (s ServicesCheck) Run() CheckResult {
 }
 
 func checkServiceStatus(service string) string {
-	cmd := exec.Command("systemctl", "is-active", service)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	
-	err := cmd.Run()
-	status := strings.TrimSpace(out.String())
+	out, err := sys.Output("systemctl", "is-active", service)
+	status := strings.TrimSpace(string(out))
 	
 	if err != nil || status != "active" {
 		// Try to get more info
-		cmd = exec.Command("systemctl", "status", service, "--no-pager", "-n", "0")
-		if output, err := cmd.Output(); err == nil {
+		if output, err := sys.Output("systemctl", "status", service, "--no-pager", "-n", "0"); err == nil {
 			lines := strings.Split(string(output), "\n")
 			if len(lines) > 0 {
 				for _, line := range lines {
@@ -2,15 +2,11 @@ package checks
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
-	"os"
-	"os/exec"
 	"runtime"
 	"strings"
 	"time"
-
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/host"
 )
 
 type SystemInfo struct {
@@ -28,21 +24,33 @@ type SystemInfo struct {
 func GetSystemInfo() (*SystemInfo, error) {
 	info := &SystemInfo{}
 
-	info.Hostname, _ = os.Hostname()
+	info.Hostname = readKernelString("/proc/sys/kernel/hostname")
+	info.Kernel = readKernelString("/proc/sys/kernel/osrelease")
+
+	// Ask dpkg so replayed machines report their own architecture
 	info.Architecture = runtime.GOARCH
+	if output, err := sys.Output("dpkg", "--print-architecture"); err == nil && len(bytes.TrimSpace(output)) > 0 {
+		info.Architecture = string(bytes.TrimSpace(output))
+	}
 
-	hostInfo, err := host.Info()
-	if err == nil {
-		info.OS = hostInfo.Platform
-		info.OSVersion = hostInfo.PlatformVersion
-		info.Kernel = hostInfo.KernelVersion
-		info.Uptime = formatUptime(hostInfo.Uptime)
+	if osInfo, err := getOSRelease(); err == nil {
+		info.OS = osInfo["ID"]
+		info.OSVersion = osInfo["VERSION_ID"]
+		// Debian's point release is only in debian_version
+		if info.OS == "debian" {
+			if version := readKernelString("/etc/debian_version"); version != "" {
+				info.OSVersion = version
+			}
+		}
 	}
 
-	cpuInfo, err := cpu.Info()
-	if err == nil && len(cpuInfo) > 0 {
-		info.CPUModel = cpuInfo[0].ModelName
-		info.CPUCores = int(cpuInfo[0].Cores)
+	if uptime, err := sys.Uptime(); err == nil {
+		info.Uptime = formatUptime(uint64(uptime.Seconds()))
+	}
+
+	if cpuInfo, err := sys.CPUInfo(); err == nil {
+		info.CPUModel = cpuInfo.Model
+		info.CPUCores = cpuInfo.Cores
 	}
 
 	if loadAvg, err := getLoadAverage(); err == nil {
@@ -52,6 +60,16 @@ func GetSystemInfo() (*SystemInfo, error) {
 	return info, nil
 }
 
+// readKernelString returns a one-line file's trimmed content, or "" if it
+// cannot be read
+func readKernelString(path string) string {
+	data, err := sys.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
 func formatUptime(seconds uint64) string {
 	days := seconds / 86400
 	hours := (seconds % 86400) / 3600
@@ -67,13 +85,12 @@ func formatUptime(seconds uint64) string {
 }
 
 func getLoadAverage() ([]float64, error) {
-	file, err := os.Open("/proc/loadavg")
+	data, err := sys.ReadFile("/proc/loadavg")
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	if scanner.Scan() {
 		fields := strings.Fields(scanner.Text())
 		if len(fields) >= 3 {
@@ -88,14 +105,13 @@ func getLoadAverage() ([]float64, error) {
 }
 
 func GetDistributionInfo() (string, string, error) {
-	file, err := os.Open("/etc/os-release")
+	data, err := sys.ReadFile("/etc/os-release")
 	if err != nil {
 		return "", "", err
 	}
-	defer file.Close()
 
 	var name, version string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
 		line := scanner.Text()
 		if strings.HasPrefix(line, "NAME=") {
@@ -108,7 +124,7 @@ func GetDistributionInfo() (string, string, error) {
 }
 
 func IsSystemdSystem() bool {
-	if _, err := exec.LookPath("systemctl"); err == nil {
+	if _, err := sys.LookPath("systemctl"); err == nil {
 		return true
 	}
 	return false
@@ -121,6 +137,15 @@ func (s SystemInfoCheck) Name() string {
 	return "System Information"
 }
 
+func (s SystemInfoCheck) Info() Info {
+	return Info{
+		ID:       "system",
+		Category: "system",
+		Tags:     []string{"inventory"},
+		Cost:     CostCheap,
+	}
+}
+
 func (s SystemInfoCheck) RequiresRoot() bool {
 	return false
 }
@@ -171,14 +196,13 @@ func (s SystemInfoCheck) Run() CheckResult {
 }
 
 func getOSRelease() (map[string]string, error) {
-	file, err := os.Open("/etc/os-release")
+	data, err := sys.ReadFile("/etc/os-release")
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
 	osInfo := make(map[string]string)
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -1,39 +1,103 @@
 package checks
 
-import "time"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
-// Severity levels for check results
-type Severity int
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+)
+
+// Severity levels for check results, shared with findings
+type Severity = finding.Severity
+
+const (
+	SeverityInfo     = finding.SeverityInfo
+	SeverityWarning  = finding.SeverityWarning
+	SeverityError    = finding.SeverityError
+	SeverityCritical = finding.SeverityCritical
+)
+
+// ParseSeverity converts a severity name into a Severity
+func ParseSeverity(name string) (Severity, error) {
+	return finding.ParseSeverity(name)
+}
+
+// Status tells whether a check ran. Results of checks that ran to
+// completion leave it empty
+type Status string
 
 const (
-	SeverityInfo Severity = iota
-	SeverityWarning
-	SeverityError
-	SeverityCritical
+	StatusSkipped   Status = "skipped"
+	StatusTimedOut  Status = "timed out"
+	StatusCancelled Status = "cancelled"
 )
 
 // CheckResult represents the result of a single check
 type CheckResult struct {
-	Name      string
-	Severity  Severity
-	Message   string
-	Details   []string
-	Timestamp time.Time
+	Name      string    `json:"name" yaml:"name"`
+	Severity  Severity  `json:"severity" yaml:"severity"`
+	Status    Status    `json:"status,omitempty" yaml:"status,omitempty"`
+	Message   string    `json:"message" yaml:"message"`
+	Details   []string  `json:"details" yaml:"details"`
+	Findings  []finding.Finding `json:"findings,omitempty" yaml:"findings,omitempty"`
+	Metrics   map[string]float64 `json:"metrics,omitempty" yaml:"metrics,omitempty"` // Measurements worth tracking over time
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	Duration  time.Duration `json:"duration,omitempty" yaml:"duration,omitempty"` // How long the check ran, set by the runner
+	Plugin    bool `json:"plugin,omitempty" yaml:"plugin,omitempty"` // Whether an external check plugin produced the result
+}
+
+// SetMetric records a measurement, such as a package count, that reports
+// and the run history can compare between runs
+func (r *CheckResult) SetMetric(name string, value float64) {
+	if r.Metrics == nil {
+		r.Metrics = make(map[string]float64)
+	}
+	r.Metrics[name] = value
+}
+
+// AddFindings records problems found by the check and raises the result's
+// severity to the most severe of them
+func (r *CheckResult) AddFindings(findings ...finding.Finding) {
+	if highest := finding.Highest(findings); highest > r.Severity {
+		r.Severity = highest
+	}
+	r.Findings = append(r.Findings, findings...)
+}
+
+// Lines renders the result's details followed by its findings as text
+func (r CheckResult) Lines() []string {
+	lines := append([]string(nil), r.Details...)
+	for _, f := range r.Findings {
+		lines = append(lines, "- "+f.String())
+	}
+	return lines
 }
 
 // Check interface that all checks must implement
 type Check interface {
 	Name() string
+	Info() Info
 	Run() CheckResult
 	RequiresRoot() bool
 }
 
+// ContextCheck is a Check that stops early once its context is done, for
+// instance when the runner's deadline for it has passed
+type ContextCheck interface {
+	Check
+	RunContext(ctx context.Context) CheckResult
+}
+
 // Results aggregates all check results
 type Results struct {
 	checks   []CheckResult
 	errors   []string
 	warnings []string
 	info     []string
+	skipped  []string
 }
 
 // NewResults creates a new Results instance
@@ -43,6 +107,7 @@ func NewResults() Results {
 		errors:   []string{},
 		warnings: []string{},
 		info:     []string{},
+		skipped:  []string{},
 	}
 }
 
@@ -50,6 +115,13 @@ func NewResults() Results {
 func (r *Results) AddResult(result CheckResult) {
 	r.checks = append(r.checks, result)
 	
+	// Checks that never ran are listed apart; timed-out checks keep their
+	// severity so they show up as warnings
+	if result.Status == StatusSkipped || result.Status == StatusCancelled {
+		r.skipped = append(r.skipped, fmt.Sprintf("%s: %s", result.Name, strings.TrimPrefix(result.Message, "Skipped: ")))
+		return
+	}
+	
 	switch result.Severity {
 	case SeverityError, SeverityCritical:
 		r.errors = append(r.errors, result.Message)
@@ -75,7 +147,53 @@ func (r *Results) GetInfo() []string {
 	return r.info
 }
 
+// GetSkipped lists the checks that did not run and why
+func (r *Results) GetSkipped() []string {
+	return r.skipped
+}
+
 // GetAllChecks returns all check results
 func (r *Results) GetAllChecks() []CheckResult {
 	return r.checks
-}
\ No newline at end of file
+}
+
+// HighestSeverity returns the most severe result seen, or SeverityInfo when
+// no checks have been recorded
+func (r *Results) HighestSeverity() Severity {
+	highest := SeverityInfo
+	for _, check := range r.checks {
+		if check.Severity > highest {
+			highest = check.Severity
+		}
+	}
+	return highest
+}
+
+// resultsDocument is the serialised form of Results
+type resultsDocument struct {
+	Checks []CheckResult `json:"checks" yaml:"checks"`
+}
+
+// MarshalJSON exposes the recorded check results, which are otherwise
+// unexported
+func (r Results) MarshalJSON() ([]byte, error) {
+	return json.Marshal(resultsDocument{Checks: r.checks})
+}
+
+// MarshalYAML mirrors MarshalJSON for YAML output
+func (r Results) MarshalYAML() (interface{}, error) {
+	return resultsDocument{Checks: r.checks}, nil
+}
+
+// UnmarshalJSON rebuilds Results from the form written by MarshalJSON
+func (r *Results) UnmarshalJSON(data []byte) error {
+	var doc resultsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	*r = NewResults()
+	for _, check := range doc.Checks {
+		r.AddResult(check)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package checks
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -192,4 +193,87 @@ func TestResultsGetMethods(t *testing.T) {
 	if len(results.GetAllChecks()) != 4 {
 		t.Errorf("Expected 4 total checks, got %d", len(results.GetAllChecks()))
 	}
-}
\ No newline at end of file
+}
+
+func TestSeverityString(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		expected string
+	}{
+		{SeverityInfo, "info"},
+		{SeverityWarning, "warning"},
+		{SeverityError, "error"},
+		{SeverityCritical, "critical"},
+		{Severity(42), "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.severity.String(); got != tt.expected {
+			t.Errorf("Severity(%d).String() = %s, want %s", tt.severity, got, tt.expected)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityError, SeverityCritical} {
+		parsed, err := ParseSeverity(severity.String())
+		if err != nil {
+			t.Errorf("ParseSeverity(%s) returned error: %v", severity, err)
+		}
+		if parsed != severity {
+			t.Errorf("ParseSeverity(%s) = %v, want %v", severity, parsed, severity)
+		}
+	}
+
+	if _, err := ParseSeverity("bogus"); err == nil {
+		t.Error("Expected error for unknown severity")
+	}
+}
+
+func TestHighestSeverity(t *testing.T) {
+	results := NewResults()
+	if results.HighestSeverity() != SeverityInfo {
+		t.Error("Expected SeverityInfo for empty results")
+	}
+
+	results.AddResult(CheckResult{Severity: SeverityWarning, Message: "Warning 1"})
+	results.AddResult(CheckResult{Severity: SeverityCritical, Message: "Critical 1"})
+	results.AddResult(CheckResult{Severity: SeverityInfo, Message: "Info 1"})
+
+	if results.HighestSeverity() != SeverityCritical {
+		t.Errorf("Expected SeverityCritical, got %v", results.HighestSeverity())
+	}
+}
+
+func TestResultsJSONRoundTrip(t *testing.T) {
+	results := NewResults()
+	results.AddResult(CheckResult{Name: "Disk Space", Severity: SeverityWarning, Message: "Disk usage high: 90%"})
+	results.AddResult(CheckResult{Name: "Memory Usage", Severity: SeverityInfo, Message: "Memory usage OK"})
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatalf("Failed to marshal results: %v", err)
+	}
+
+	var decoded struct {
+		Checks []map[string]interface{} `json:"checks"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode results JSON: %v", err)
+	}
+	if len(decoded.Checks) != 2 {
+		t.Fatalf("Expected 2 checks in JSON, got %d", len(decoded.Checks))
+	}
+	if decoded.Checks[0]["severity"] != "warning" {
+		t.Errorf("Expected severity 'warning', got %v", decoded.Checks[0]["severity"])
+	}
+
+	var restored Results
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Failed to unmarshal results: %v", err)
+	}
+	if len(restored.GetWarnings()) != 1 || len(restored.GetInfo()) != 1 {
+		t.Errorf("Expected restored results to be re-categorised, got %d warnings and %d info",
+			len(restored.GetWarnings()), len(restored.GetInfo()))
+	}
+}
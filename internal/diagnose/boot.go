@@ -2,9 +2,9 @@ package diagnose
 
 
 import (
-	"os/exec"
 	"strings"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
@@ -12,15 +12,17 @@ import (
 func DiagnoseBootIssues() Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "Boot Issues",
-		Findings: []string{},
+		Category: "boot",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
 	// Check systemd state
-	if output, err := exec.Command("systemctl", "is-system-running").Output(); err == nil {
+	if output, err := sys.Output("systemctl", "is-system-running"); err == nil {
 		state := strings.TrimSpace(string(output))
 		if state == "degraded" {
-			diagnosis.Findings = append(diagnosis.Findings, "System is in degraded state")
+			diagnosis.Add(finding.New("boot.degraded", finding.SeverityWarning, "System is in degraded state").
+				FixedBy("show_failed_services"))
 			diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 				ID:          "show_failed_services",
 				Title:       "Show Failed Services",
@@ -29,17 +31,17 @@ func DiagnoseBootIssues() Diagnosis {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 		} else if state == "running" {
-			diagnosis.Findings = append(diagnosis.Findings, "System is running normally")
+			diagnosis.Add(finding.New("boot.running", finding.SeverityInfo, "System is running normally"))
 		} else {
-			diagnosis.Findings = append(diagnosis.Findings, "System state: "+state)
+			diagnosis.Add(finding.New("boot.state", finding.SeverityWarning, "System state: "+state).With("state", state))
 		}
 	}
 
 	// Check for boot errors in journal
-	cmd := exec.Command("journalctl", "-b", "--no-pager", "-p", "err", "-n", "10")
-	if output, err := cmd.Output(); err == nil {
+	if output, err := sys.Output("journalctl", "-b", "--no-pager", "-p", "err", "-n", "10"); err == nil {
 		lines := strings.Split(string(output), "\n")
 		errorCount := 0
 		for _, line := range lines {
@@ -48,7 +50,10 @@ func DiagnoseBootIssues() Diagnosis {
 			}
 		}
 		if errorCount > 0 {
-			diagnosis.Findings = append(diagnosis.Findings, "Boot errors detected in system journal")
+			diagnosis.Add(finding.New("boot.journal_errors", finding.SeverityWarning, "Boot errors detected in system journal").
+				With("errors", errorCount).
+				WithExcerpt(string(output)).
+				FixedBy("view_boot_errors"))
 			diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 				ID:          "view_boot_errors",
 				Title:       "View Boot Errors",
@@ -57,29 +62,35 @@ func DiagnoseBootIssues() Diagnosis {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 		}
 	}
 
 	// Check filesystem mount status
-	if output, err := exec.Command("mount").Output(); err == nil {
-		if strings.Contains(string(output), "ro,") {
-			diagnosis.Findings = append(diagnosis.Findings, "Read-only filesystem detected")
-			diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
-				ID:          "remount_rw",
-				Title:       "Remount Filesystem Read-Write",
-				Description: "Remount the root filesystem as read-write to allow modifications",
-				Commands:    []string{"mount -o remount,rw /"},
-				RequiresRoot: true,
-				Reversible:  true,
-				ReverseCommands: []string{"mount -o remount,ro /"},
-				RiskLevel:   fixes.RiskMedium,
-			})
+	if readOnly, _ := facts.ReadOnlyMounts(); len(readOnly) > 0 {
+		issue := finding.New("boot.read_only_filesystem", finding.SeverityError, "Read-only filesystem detected").
+			FixedBy("remount_rw")
+		for _, mount := range readOnly {
+			issue = issue.Affects(finding.ResourcePath, mount.Path)
 		}
+		diagnosis.Add(issue)
+		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
+			ID:          "remount_rw",
+			Title:       "Remount Filesystem Read-Write",
+			Description: "Remount the root filesystem as read-write to allow modifications",
+			Commands:    []string{"mount -o remount,rw /"},
+			RequiresRoot: true,
+			Reversible:  true,
+			ReverseCommands: []string{"mount -o remount,ro /"},
+			RiskLevel:   fixes.RiskMedium,
+		})
 	}
 
+	diagnosis.applyRules()
+
 	if len(diagnosis.Findings) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No boot issues detected")
+		diagnosis.Add(finding.New("boot.ok", finding.SeverityInfo, "No boot issues detected"))
 	}
 
 	return diagnosis
@@ -20,7 +20,7 @@ func TestDiagnoseBootIssues(t *testing.T) {
 	
 	// Test that findings contain meaningful information
 	hasSystemStatusCheck := false
-	for _, finding := range diagnosis.Findings {
+	for _, finding := range diagnosis.Summaries() {
 		if strings.Contains(strings.ToLower(finding), "system") {
 			hasSystemStatusCheck = true
 			break
@@ -1,9 +1,9 @@
 package diagnose
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
@@ -11,7 +11,8 @@ import (
 func DiagnoseCustomIssue(userDescription string) Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "Custom Issue Diagnosis",
-		Findings: []string{},
+		Category: "custom",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
@@ -19,16 +20,18 @@ func DiagnoseCustomIssue(userDescription string) Diagnosis {
 	description := strings.TrimSpace(strings.ToLower(userDescription))
 	
 	if description == "" {
-		diagnosis.Findings = append(diagnosis.Findings, "No issue description provided")
+		diagnosis.Add(finding.New("custom.no_description", finding.SeverityInfo, "No issue description provided"))
 		diagnosis.Fixes = append(diagnosis.Fixes, getGeneralTroubleshootingFixes()...)
 		return diagnosis
 	}
 
-	diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("Analyzing issue: %s", userDescription))
+	diagnosis.Add(finding.Newf("custom.description", finding.SeverityInfo, "Analyzing issue: %s", userDescription).
+		With("description", userDescription))
 
 	// Analyze keywords in the description and provide relevant fixes
 	keywords := extractKeywords(description)
-	diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("Detected keywords: %s", strings.Join(keywords, ", ")))
+	diagnosis.Add(finding.Newf("custom.keywords", finding.SeverityInfo, "Detected keywords: %s", strings.Join(keywords, ", ")).
+		With("keywords", strings.Join(keywords, ",")))
 
 	// Add specific fixes based on detected keywords
 	specificFixes := getKeywordBasedFixes(keywords)
@@ -43,9 +46,9 @@ func DiagnoseCustomIssue(userDescription string) Diagnosis {
 	diagnosis.Fixes = append(diagnosis.Fixes, infoFixes...)
 
 	if len(keywords) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No specific keywords detected - providing general troubleshooting steps")
+		diagnosis.Add(finding.New("custom.general", finding.SeverityInfo, "No specific keywords detected - providing general troubleshooting steps"))
 	} else {
-		diagnosis.Findings = append(diagnosis.Findings, "Providing targeted troubleshooting based on detected keywords")
+		diagnosis.Add(finding.New("custom.targeted", finding.SeverityInfo, "Providing targeted troubleshooting based on detected keywords"))
 	}
 
 	return diagnosis
@@ -110,6 +113,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "network":
@@ -127,6 +131,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "performance":
@@ -144,6 +149,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "disk":
@@ -161,6 +167,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "services":
@@ -177,6 +184,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "graphics":
@@ -194,6 +202,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "audio":
@@ -211,6 +220,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "packages":
@@ -227,6 +237,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "permissions":
@@ -244,6 +255,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 
 		case "hardware":
@@ -261,6 +273,7 @@ func getKeywordBasedFixes(keywords []string) []*fixes.Fix {
 				RequiresRoot: false,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 		}
 	}
@@ -285,6 +298,7 @@ func getGeneralTroubleshootingFixes() []*fixes.Fix {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		},
 		{
 			ID:          "check_recent_changes",
@@ -299,6 +313,7 @@ func getGeneralTroubleshootingFixes() []*fixes.Fix {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		},
 		{
 			ID:          "basic_connectivity_test",
@@ -313,6 +328,7 @@ func getGeneralTroubleshootingFixes() []*fixes.Fix {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		},
 		{
 			ID:          "restart_common_services",
@@ -347,6 +363,7 @@ func getInformationGatheringFixes() []*fixes.Fix {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		},
 		{
 			ID:          "check_system_logs",
@@ -361,6 +378,7 @@ func getInformationGatheringFixes() []*fixes.Fix {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		},
 		{
 			ID:          "create_diagnostic_report",
@@ -380,6 +398,7 @@ func getInformationGatheringFixes() []*fixes.Fix {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		},
 	}
 }
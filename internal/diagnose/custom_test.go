@@ -79,7 +79,7 @@ func TestDiagnoseCustomIssue(t *testing.T) {
 			}
 
 			// Check that detected keywords are mentioned in findings
-			findingsText := strings.Join(diagnosis.Findings, " ")
+			findingsText := strings.Join(diagnosis.Summaries(), " ")
 			for _, keyword := range tt.wantKeywords {
 				if !strings.Contains(strings.ToLower(findingsText), keyword) {
 					t.Errorf("Expected keyword '%s' to be mentioned in findings", keyword)
@@ -429,7 +429,7 @@ func TestCustomDiagnosisIntegration(t *testing.T) {
 			}
 
 			// Check keyword detection expectation
-			findingsText := strings.Join(diagnosis.Findings, " ")
+			findingsText := strings.Join(diagnosis.Summaries(), " ")
 			hasKeywords := strings.Contains(strings.ToLower(findingsText), "detected keywords")
 
 			if scenario.expectKeywords && !hasKeywords {
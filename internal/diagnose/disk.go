@@ -2,11 +2,9 @@ package diagnose
 
 
 import (
-	"fmt"
-	"os/exec"
 	"strings"
-	"syscall"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
@@ -14,12 +12,12 @@ import (
 func DiagnoseDiskIssues() Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "Disk Issues",
-		Findings: []string{},
+		Category: "disk",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
 	// Check disk usage
-	var stat syscall.Statfs_t
 	filesystems := map[string]string{
 		"/":     "Root",
 		"/home": "Home",
@@ -27,21 +25,25 @@ func DiagnoseDiskIssues() Diagnosis {
 		"/tmp":  "Tmp",
 	}
 
-	fullFilesystems := []string{}
 	for path, name := range filesystems {
-		if err := syscall.Statfs(path, &stat); err == nil {
+		if stat, err := sys.Statfs(path); err == nil {
 			total := stat.Blocks * uint64(stat.Bsize)
 			free := stat.Bavail * uint64(stat.Bsize)
 			used := total - free
 			usagePercent := int((used * 100) / total)
 			
-			if usagePercent > 95 {
-				fullFilesystems = append(fullFilesystems, fmt.Sprintf("%s (%d%%)", name, usagePercent))
-				diagnosis.Findings = append(diagnosis.Findings, 
-					fmt.Sprintf("%s filesystem critical: %d%% full", name, usagePercent))
-			} else if usagePercent > 85 {
-				diagnosis.Findings = append(diagnosis.Findings, 
-					fmt.Sprintf("%s filesystem warning: %d%% full", name, usagePercent))
+			if usagePercent > thresholds.DiskCriticalPercent {
+				diagnosis.Add(finding.Newf("disk.filesystem_full", finding.SeverityCritical,
+					"%s filesystem critical: %d%% full", name, usagePercent).
+					With("used_percent", usagePercent).
+					Affects(finding.ResourcePath, path).
+					FixedBy("clean_package_cache", "remove_orphaned_packages", "find_large_files", "clear_old_logs"))
+			} else if usagePercent > thresholds.DiskWarningPercent {
+				diagnosis.Add(finding.Newf("disk.filesystem_full", finding.SeverityWarning,
+					"%s filesystem warning: %d%% full", name, usagePercent).
+					With("used_percent", usagePercent).
+					Affects(finding.ResourcePath, path).
+					FixedBy("clean_package_cache", "remove_orphaned_packages", "find_large_files", "clear_old_logs"))
 			}
 		}
 	}
@@ -66,6 +68,7 @@ func DiagnoseDiskIssues() Diagnosis {
 		RequiresRoot: false,
 		Reversible:  false,
 		RiskLevel:   fixes.RiskLow,
+		Informational: true,
 	})
 	
 	diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -79,11 +82,12 @@ func DiagnoseDiskIssues() Diagnosis {
 	})
 
 	// Check for I/O errors
-	if output, err := exec.Command("dmesg").Output(); err == nil {
+	if output, err := sys.Output("dmesg"); err == nil {
 		outputStr := string(output)
 		if strings.Contains(strings.ToLower(outputStr), "i/o error") || 
 		   strings.Contains(strings.ToLower(outputStr), "disk error") {
-			diagnosis.Findings = append(diagnosis.Findings, "Disk I/O errors detected in kernel log")
+			diagnosis.Add(finding.New("disk.io_errors", finding.SeverityError, "Disk I/O errors detected in kernel log").
+				FixedBy("check_disk_health", "filesystem_check"))
 			
 			diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 				ID:          "check_disk_health",
@@ -93,6 +97,7 @@ func DiagnoseDiskIssues() Diagnosis {
 				RequiresRoot: true,
 				Reversible:  false,
 				RiskLevel:   fixes.RiskLow,
+				Informational: true,
 			})
 			
 			diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -121,10 +126,13 @@ func DiagnoseDiskIssues() Diagnosis {
 		Reversible:  true,
 		ReverseCommands: []string{"rm -f /tmp/test"},
 		RiskLevel:   fixes.RiskLow,
+		Informational: true,
 	})
 
+	diagnosis.applyRules()
+
 	if len(diagnosis.Findings) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No disk issues detected")
+		diagnosis.Add(finding.New("disk.ok", finding.SeverityInfo, "No disk issues detected"))
 	}
 
 	return diagnosis
@@ -21,7 +21,7 @@ func TestDiagnoseDiskIssues(t *testing.T) {
 	// Test that disk components are checked
 	hasDiskUsageCheck := false
 	
-	for _, finding := range diagnosis.Findings {
+	for _, finding := range diagnosis.Summaries() {
 		lower := strings.ToLower(finding)
 		if strings.Contains(lower, "disk") || 
 		   strings.Contains(lower, "filesystem") ||
@@ -2,30 +2,29 @@ package diagnose
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"strconv"
 	"strings"
-	"syscall"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
 )
 
 // DiagnoseFilesystemIssues diagnoses filesystem-related problems and provides fixes
 func DiagnoseFilesystemIssues() Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "Filesystem Issues",
-		Findings: []string{},
+		Category: "filesystem",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
 	// Check for read-only filesystems
 	readOnlyFS := checkReadOnlyFilesystems()
 	if len(readOnlyFS) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Read-only filesystems detected:")
 		for _, fs := range readOnlyFS {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", fs))
+			diagnosis.Add(finding.Newf("filesystem.read_only", finding.SeverityError, "Read-only filesystem: %s", fs).
+				Affects(finding.ResourcePath, fs).
+				FixedBy("remount_rw", "check_filesystem_errors"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -47,15 +46,20 @@ func DiagnoseFilesystemIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check disk space issues
 	spaceIssues := checkDiskSpaceIssues()
 	if len(spaceIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Disk space issues:")
 		for _, issue := range spaceIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			severity := finding.SeverityWarning
+			if strings.Contains(issue, "critical") {
+				severity = finding.SeverityCritical
+			}
+			diagnosis.Add(finding.New("filesystem.disk_space", severity, issue).
+				FixedBy("clean_temp_files", "clean_log_files", "find_large_files"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -95,15 +99,18 @@ func DiagnoseFilesystemIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check inode issues
 	inodeIssues := checkInodeIssues()
 	if len(inodeIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Inode usage issues:")
 		for _, issue := range inodeIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			mountPoint, _, _ := strings.Cut(issue, ":")
+			diagnosis.Add(finding.New("filesystem.inodes_high", finding.SeverityWarning, issue).
+				Affects(finding.ResourcePath, mountPoint).
+				FixedBy("clean_small_files", "find_inode_consumers"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -129,15 +136,16 @@ func DiagnoseFilesystemIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check for filesystem corruption
 	corruptionSigns := checkFilesystemCorruption()
 	if len(corruptionSigns) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Filesystem corruption detected:")
 		for _, sign := range corruptionSigns {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", sign))
+			diagnosis.Add(finding.New("filesystem.corruption", finding.SeverityError, "Filesystem corruption sign: "+sign).
+				FixedBy("check_filesystem", "backup_lost_found"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -169,9 +177,9 @@ func DiagnoseFilesystemIssues() Diagnosis {
 	// Check for mount issues
 	mountIssues := checkMountIssues()
 	if len(mountIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Mount issues detected:")
 		for _, issue := range mountIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			diagnosis.Add(finding.New("filesystem.mount_issue", finding.SeverityError, issue).
+				FixedBy("reload_systemd_mounts", "check_fstab"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -198,21 +206,17 @@ func DiagnoseFilesystemIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check for broken symbolic links
 	brokenSymlinks := checkBrokenSymlinks()
 	if len(brokenSymlinks) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("Broken symbolic links found: %d", len(brokenSymlinks)))
-		
-		for i, link := range brokenSymlinks {
-			if i < 5 { // Show first 5
-				diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", link))
-			}
-		}
-		if len(brokenSymlinks) > 5 {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  ... and %d more", len(brokenSymlinks)-5))
+		for _, link := range brokenSymlinks {
+			diagnosis.Add(finding.New("filesystem.broken_symlink", finding.SeverityWarning, "Broken symbolic link: "+link).
+				Affects(finding.ResourcePath, link).
+				FixedBy("remove_broken_symlinks", "list_broken_symlinks"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -237,15 +241,16 @@ func DiagnoseFilesystemIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check filesystem performance
 	performanceIssues := checkFilesystemPerformance()
 	if len(performanceIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Filesystem performance issues:")
 		for _, issue := range performanceIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			diagnosis.Add(finding.New("filesystem.performance", finding.SeverityWarning, issue).
+				FixedBy("optimize_filesystem", "check_io_stats"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -272,6 +277,7 @@ func DiagnoseFilesystemIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
@@ -289,14 +295,17 @@ func DiagnoseFilesystemIssues() Diagnosis {
 		RequiresRoot: false,
 		Reversible:  false,
 		RiskLevel:   fixes.RiskLow,
+		Informational: true,
 	})
 
+	diagnosis.applyRules()
+
 	if len(diagnosis.Findings) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No significant filesystem issues detected")
+		diagnosis.Add(finding.New("filesystem.ok", finding.SeverityInfo, "No significant filesystem issues detected"))
 	}
 	
 	// Always add basic filesystem status to ensure comprehensive coverage
-	diagnosis.Findings = append(diagnosis.Findings, "Filesystem status: disk space, mount points, and inode usage checked")
+	diagnosis.Add(finding.New("filesystem.checked", finding.SeverityInfo, "Filesystem status: disk space, mount points, and inode usage checked"))
 
 	return diagnosis
 }
@@ -305,20 +314,9 @@ func DiagnoseFilesystemIssues() Diagnosis {
 func checkReadOnlyFilesystems() []string {
 	readOnly := []string{}
 
-	cmd := exec.Command("mount")
-	output, err := cmd.Output()
-	if err != nil {
-		return readOnly
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, " ro,") && !strings.Contains(line, "tmpfs") {
-			fields := strings.Fields(line)
-			if len(fields) >= 3 {
-				readOnly = append(readOnly, fields[2])
-			}
-		}
+	mounts, _ := facts.ReadOnlyMounts()
+	for _, mount := range mounts {
+		readOnly = append(readOnly, mount.Path)
 	}
 
 	return readOnly
@@ -328,7 +326,6 @@ func checkReadOnlyFilesystems() []string {
 func checkDiskSpaceIssues() []string {
 	issues := []string{}
 
-	var stat syscall.Statfs_t
 	filesystems := map[string]string{
 		"/":     "Root",
 		"/home": "Home",
@@ -337,15 +334,15 @@ func checkDiskSpaceIssues() []string {
 	}
 
 	for path, name := range filesystems {
-		if err := syscall.Statfs(path, &stat); err == nil {
+		if stat, err := sys.Statfs(path); err == nil {
 			total := stat.Blocks * uint64(stat.Bsize)
 			free := stat.Bavail * uint64(stat.Bsize)
 			used := total - free
 			usagePercent := int((used * 100) / total)
 			
-			if usagePercent > 95 {
+			if usagePercent > thresholds.DiskCriticalPercent {
 				issues = append(issues, fmt.Sprintf("%s filesystem critical: %d%% full", name, usagePercent))
-			} else if usagePercent > 85 {
+			} else if usagePercent > thresholds.DiskWarningPercent {
 				issues = append(issues, fmt.Sprintf("%s filesystem warning: %d%% full", name, usagePercent))
 			}
 		}
@@ -358,38 +355,10 @@ func checkDiskSpaceIssues() []string {
 func checkInodeIssues() []string {
 	issues := []string{}
 
-	cmd := exec.Command("df", "-i")
-	output, err := cmd.Output()
-	if err != nil {
-		return issues
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for i, line := range lines {
-		if i == 0 { // Skip header
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 5 {
-			filesystem := fields[0]
-			usageStr := fields[4]
-			
-			// Skip virtual filesystems
-			if strings.HasPrefix(filesystem, "tmpfs") ||
-				strings.HasPrefix(filesystem, "devtmpfs") {
-				continue
-			}
-
-			if strings.HasSuffix(usageStr, "%") {
-				usageStr = strings.TrimSuffix(usageStr, "%")
-				if usage, err := strconv.Atoi(usageStr); err == nil {
-					if usage > 90 {
-						mountPoint := fields[5]
-						issues = append(issues, fmt.Sprintf("%s: %d%% inode usage", mountPoint, usage))
-					}
-				}
-			}
+	usage, _ := facts.InodeUsage()
+	for _, fs := range usage {
+		if fs.Percent > thresholds.InodeWarningPercent {
+			issues = append(issues, fmt.Sprintf("%s: %d%% inode usage", fs.Mount, fs.Percent))
 		}
 	}
 
@@ -403,8 +372,8 @@ func checkFilesystemCorruption() []string {
 	// Check for lost+found directories with content
 	lostFoundDirs := []string{"/lost+found", "/home/lost+found", "/var/lost+found"}
 	for _, dir := range lostFoundDirs {
-		if _, err := os.Stat(dir); err == nil {
-			entries, err := os.ReadDir(dir)
+		if _, err := sys.Stat(dir); err == nil {
+			entries, err := sys.ReadDir(dir)
 			if err == nil && len(entries) > 0 {
 				signs = append(signs, fmt.Sprintf("Files found in %s (%d items)", dir, len(entries)))
 			}
@@ -412,8 +381,7 @@ func checkFilesystemCorruption() []string {
 	}
 
 	// Check for filesystem errors in dmesg
-	cmd := exec.Command("dmesg")
-	output, err := cmd.Output()
+	output, err := sys.Output("dmesg")
 	if err == nil {
 		content := strings.ToLower(string(output))
 		errorPatterns := []string{
@@ -430,7 +398,7 @@ func checkFilesystemCorruption() []string {
 		}
 	}
 
-	return removeDuplicateStrings(signs)
+	return probes.Unique(signs)
 }
 
 // checkMountIssues checks for mount-related problems
@@ -438,18 +406,12 @@ func checkMountIssues() []string {
 	issues := []string{}
 
 	// Check for failed mount units
-	cmd := exec.Command("systemctl", "list-units", "--failed", "--type=mount")
-	output, err := cmd.Output()
-	if err == nil {
-		content := string(output)
-		if strings.Contains(content, "failed") && !strings.Contains(content, "0 loaded units") {
-			issues = append(issues, "Failed mount units in systemd")
-		}
+	if failed, _ := facts.FailedUnitsOfType("mount"); len(failed) > 0 {
+		issues = append(issues, "Failed mount units in systemd")
 	}
 
 	// Check fstab validity
-	cmd = exec.Command("findmnt", "--verify")
-	output, err = cmd.CombinedOutput()
+	output, err := sys.CombinedOutput("findmnt", "--verify")
 	if err != nil {
 		content := string(output)
 		if content != "" {
@@ -468,35 +430,10 @@ func checkMountIssues() []string {
 
 // checkBrokenSymlinks finds broken symbolic links
 func checkBrokenSymlinks() []string {
-	broken := []string{}
-
-	checkDirs := []string{"/usr/bin", "/usr/local/bin", "/bin", "/sbin"}
-	
-	for _, dir := range checkDirs {
-		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-
-			if info.Mode()&os.ModeSymlink != 0 {
-				if _, err := os.Stat(path); os.IsNotExist(err) {
-					broken = append(broken, path)
-				}
-			}
-
-			return nil
-		})
-
-		if err != nil {
-			continue
-		}
-
-		// Limit the number of broken symlinks reported
-		if len(broken) > 20 {
-			break
-		}
+	broken, _ := facts.BrokenSymlinks()
+	if broken == nil {
+		return []string{}
 	}
-
 	return broken
 }
 
@@ -505,7 +442,7 @@ func checkFilesystemPerformance() []string {
 	issues := []string{}
 
 	// Check for high load average
-	loadavg, err := os.ReadFile("/proc/loadavg")
+	loadavg, err := sys.ReadFile("/proc/loadavg")
 	if err == nil {
 		fields := strings.Fields(string(loadavg))
 		if len(fields) >= 1 {
@@ -519,7 +456,7 @@ func checkFilesystemPerformance() []string {
 	}
 
 	// Check for high I/O wait
-	stat, err := os.ReadFile("/proc/stat")
+	stat, err := sys.ReadFile("/proc/stat")
 	if err == nil {
 		lines := strings.Split(string(stat), "\n")
 		for _, line := range lines {
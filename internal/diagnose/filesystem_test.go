@@ -255,53 +255,6 @@ func TestCheckFilesystemPerformance(t *testing.T) {
 	t.Logf("Performance issues found: %d", len(issues))
 }
 
-func TestRemoveDuplicateStrings_Filesystem(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    []string
-		expected []string
-	}{
-		{
-			name:     "corruption signs with duplicates",
-			input:    []string{"lost+found files", "ext4-fs error", "lost+found files", "corruption"},
-			expected: []string{"lost+found files", "ext4-fs error", "corruption"},
-		},
-		{
-			name:     "mount points with duplicates",
-			input:    []string{"/home", "/var", "/home", "/tmp"},
-			expected: []string{"/home", "/var", "/tmp"},
-		},
-		{
-			name:     "empty input",
-			input:    []string{},
-			expected: []string{},
-		},
-		{
-			name:     "single item",
-			input:    []string{"filesystem error"},
-			expected: []string{"filesystem error"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := removeDuplicateStrings(tt.input)
-			
-			// Check length
-			if len(result) != len(tt.expected) {
-				t.Errorf("removeDuplicateStrings() length = %d, want %d", len(result), len(tt.expected))
-			}
-			
-			// Check contents
-			for i, expected := range tt.expected {
-				if i >= len(result) || result[i] != expected {
-					t.Errorf("removeDuplicateStrings() = %v, want %v", result, tt.expected)
-					break
-				}
-			}
-		})
-	}
-}
 
 func TestFilesystemDiagnosisFixValidation(t *testing.T) {
 	diagnosis := DiagnoseFilesystemIssues()
@@ -345,7 +298,7 @@ func TestFilesystemDiagnosisIntegration(t *testing.T) {
 	}
 	
 	// Check that findings contain filesystem-related information
-	findingsText := strings.Join(diagnosis.Findings, " ")
+	findingsText := strings.Join(diagnosis.Summaries(), " ")
 	expectedKeywords := []string{"filesystem", "disk", "mount", "inode"}
 	foundKeywords := 0
 	
@@ -386,7 +339,7 @@ func TestFilesystemDiagnosisRiskLevels(t *testing.T) {
 	// Check that dangerous operations have appropriate risk levels
 	for _, fix := range diagnosis.Fixes {
 		// Filesystem check should be high risk
-		if strings.Contains(fix.ID, "check_filesystem") && fix.RiskLevel.String() != "High" {
+		if fix.ID == "check_filesystem" && fix.RiskLevel.String() != "High" {
 			t.Errorf("Filesystem check should be high risk, got %s", fix.RiskLevel.String())
 		}
 		
@@ -418,7 +371,7 @@ func TestFilesystemDiagnosisComprehensiveness(t *testing.T) {
 	diagnosis := DiagnoseFilesystemIssues()
 	
 	// Check that we cover major filesystem issue categories
-	findingsText := strings.Join(diagnosis.Findings, " ")
+	findingsText := strings.Join(diagnosis.Summaries(), " ")
 	
 	// Should mention various filesystem aspects
 	aspectsCovered := make(map[string]bool)
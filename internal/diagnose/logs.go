@@ -2,11 +2,11 @@ package diagnose
 
 import (
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
@@ -14,51 +14,18 @@ import (
 func DiagnoseLogIssues() Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "System Log Issues",
-		Findings: []string{},
+		Category: "logs",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
-	// Check journal disk usage
-	journalSize := checkJournalSize()
-	if journalSize > 1000 { // More than 1GB
-		diagnosis.Findings = append(diagnosis.Findings, 
-			fmt.Sprintf("systemd journal is using %.1f MB of disk space", journalSize))
-		
-		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
-			ID:          "vacuum_journal_time",
-			Title:       "Clean Old Journal Entries (30 days)",
-			Description: "Remove journal entries older than 30 days to free disk space",
-			Commands:    []string{"journalctl --vacuum-time=30d"},
-			RequiresRoot: true,
-			Reversible:  false,
-			RiskLevel:   fixes.RiskLow,
-		})
-
-		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
-			ID:          "vacuum_journal_size",
-			Title:       "Limit Journal Size (500MB)",
-			Description: "Limit systemd journal to 500MB total size",
-			Commands:    []string{"journalctl --vacuum-size=500M"},
-			RequiresRoot: true,
-			Reversible:  false,
-			RiskLevel:   fixes.RiskLow,
-		})
-	}
-
 	// Check for persistent errors
 	persistentErrors := checkPersistentErrors()
 	if len(persistentErrors) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, 
-			fmt.Sprintf("Found %d persistent error patterns in logs", len(persistentErrors)))
-		
-		for i, errPattern := range persistentErrors {
-			if i < 3 { // Show first 3 as examples
-				diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", errPattern))
-			}
-		}
-		if len(persistentErrors) > 3 {
-			diagnosis.Findings = append(diagnosis.Findings, 
-				fmt.Sprintf("  ... and %d more error patterns", len(persistentErrors)-3))
+		for _, errPattern := range persistentErrors {
+			diagnosis.Add(finding.New("logs.persistent_error", finding.SeverityWarning, "Persistent log error: "+errPattern).
+				WithExcerpt(errPattern).
+				FixedBy("analyze_errors"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -69,15 +36,16 @@ func DiagnoseLogIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check for log rotation issues
 	logRotationIssues := checkLogRotation()
 	if len(logRotationIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Log rotation issues detected:")
 		for _, issue := range logRotationIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			diagnosis.Add(finding.New("logs.rotation", finding.SeverityWarning, "Log rotation issue: "+issue).
+				FixedBy("force_logrotate", "check_logrotate_config"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -98,15 +66,17 @@ func DiagnoseLogIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check for failed services based on logs
 	failedServices := checkFailedServices()
 	if len(failedServices) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Services with errors detected:")
 		for _, service := range failedServices {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", service))
+			diagnosis.Add(finding.New("logs.service_failed", finding.SeverityError, "Service with errors: "+service).
+				Affects(finding.ResourceUnit, service).
+				FixedBy("restart_failed_services", "show_service_status"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -127,14 +97,16 @@ func DiagnoseLogIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check for core dumps
 	coreDumps := checkCoreDumps()
 	if coreDumps > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, 
-			fmt.Sprintf("Found %d core dumps on system", coreDumps))
+		diagnosis.Add(finding.Newf("logs.core_dumps", finding.SeverityWarning, "Found %d core dumps on system", coreDumps).
+			With("count", coreDumps).
+			FixedBy("list_core_dumps", "clean_core_dumps"))
 		
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 			ID:          "list_core_dumps",
@@ -144,6 +116,7 @@ func DiagnoseLogIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -160,9 +133,9 @@ func DiagnoseLogIssues() Diagnosis {
 	// Check for kernel messages
 	kernelIssues := checkKernelIssues()
 	if len(kernelIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Kernel issues detected:")
 		for _, issue := range kernelIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			diagnosis.Add(finding.New("logs.kernel_issue", finding.SeverityError, "Kernel issue "+strings.ToLower(issue)).
+				FixedBy("show_kernel_messages"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -173,6 +146,7 @@ func DiagnoseLogIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
@@ -188,52 +162,23 @@ func DiagnoseLogIssues() Diagnosis {
 		RequiresRoot: false,
 		Reversible:  false,
 		RiskLevel:   fixes.RiskLow,
+		Informational: true,
 	})
 
+	diagnosis.applyRules()
+
 	if len(diagnosis.Findings) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No significant log issues detected")
+		diagnosis.Add(finding.New("logs.ok", finding.SeverityInfo, "No significant log issues detected"))
 	}
 
 	return diagnosis
 }
 
-// checkJournalSize returns journal size in MB
-func checkJournalSize() float64 {
-	cmd := exec.Command("journalctl", "--disk-usage")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0
-	}
-
-	content := string(output)
-	re := regexp.MustCompile(`take up ([0-9.]+)([KMGT]?)B`)
-	matches := re.FindStringSubmatch(content)
-	if len(matches) >= 3 {
-		size, _ := strconv.ParseFloat(matches[1], 64)
-		unit := matches[2]
-
-		// Convert to MB
-		switch unit {
-		case "G":
-			return size * 1024
-		case "T":
-			return size * 1024 * 1024
-		case "K":
-			return size / 1024
-		default: // B or MB
-			return size
-		}
-	}
-
-	return 0
-}
-
 // checkPersistentErrors looks for repeated error patterns
 func checkPersistentErrors() []string {
 	errors := []string{}
 
-	cmd := exec.Command("journalctl", "-p", "err", "--since", "24 hours ago", "--no-pager")
-	output, err := cmd.Output()
+	output, err := sys.Output("journalctl", "-p", "err", "--since", "24 hours ago", "--no-pager")
 	if err != nil {
 		return errors
 	}
@@ -273,8 +218,7 @@ func checkLogRotation() []string {
 	issues := []string{}
 
 	// Check logrotate status
-	cmd := exec.Command("logrotate", "-d", "/etc/logrotate.conf")
-	output, err := cmd.Output()
+	output, err := sys.Output("logrotate", "-d", "/etc/logrotate.conf")
 	if err != nil {
 		issues = append(issues, "Logrotate configuration test failed")
 	} else {
@@ -293,8 +237,7 @@ func checkLogRotation() []string {
 	}
 
 	for _, logFile := range logFiles {
-		cmd := exec.Command("stat", "-c", "%s", logFile)
-		output, err := cmd.Output()
+		output, err := sys.Output("stat", "-c", "%s", logFile)
 		if err != nil {
 			continue
 		}
@@ -319,23 +262,9 @@ func checkLogRotation() []string {
 func checkFailedServices() []string {
 	services := []string{}
 
-	cmd := exec.Command("systemctl", "--failed", "--no-legend", "--no-pager")
-	output, err := cmd.Output()
-	if err != nil {
-		return services
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 1 {
-			services = append(services, fields[0])
-		}
+	units, _ := facts.FailedUnits()
+	for _, unit := range units {
+		services = append(services, unit.Name)
 	}
 
 	return services
@@ -343,8 +272,7 @@ func checkFailedServices() []string {
 
 // checkCoreDumps counts core dumps
 func checkCoreDumps() int {
-	cmd := exec.Command("coredumpctl", "list", "--no-pager", "--no-legend")
-	output, err := cmd.Output()
+	output, err := sys.Output("coredumpctl", "list", "--no-pager", "--no-legend")
 	if err != nil {
 		return 0
 	}
@@ -364,8 +292,7 @@ func checkCoreDumps() int {
 func checkKernelIssues() []string {
 	issues := []string{}
 
-	cmd := exec.Command("dmesg")
-	output, err := cmd.Output()
+	output, err := sys.Output("dmesg")
 	if err != nil {
 		return issues
 	}
@@ -40,20 +40,6 @@ func TestDiagnoseLogIssues(t *testing.T) {
 	}
 }
 
-func TestCheckJournalSize(t *testing.T) {
-	size := checkJournalSize()
-	
-	// Should return a non-negative number
-	if size < 0 {
-		t.Errorf("checkJournalSize returned negative value: %f", size)
-	}
-	
-	// Size should be reasonable (less than 100GB for most systems)
-	if size > 100*1024 {
-		t.Logf("Warning: Very large journal size detected: %.1f MB", size)
-	}
-}
-
 func TestCheckPersistentErrors(t *testing.T) {
 	errors := checkPersistentErrors()
 	
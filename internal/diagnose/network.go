@@ -3,10 +3,9 @@ package diagnose
 
 import (
 	"fmt"
-	"net"
-	"os/exec"
 	"strings"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
@@ -14,13 +13,16 @@ import (
 func DiagnoseNetworkIssues() Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "Network Issues",
-		Findings: []string{},
+		Category: "network",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
 	// Check networking service
-	if output, err := exec.Command("systemctl", "is-active", "networking").Output(); err != nil {
-		diagnosis.Findings = append(diagnosis.Findings, "Networking service is not running")
+	if output, err := sys.Output("systemctl", "is-active", "networking"); err != nil {
+		diagnosis.Add(finding.New("network.service_inactive", finding.SeverityError, "Networking service is not running").
+			Affects(finding.ResourceUnit, "networking.service").
+			FixedBy("restart_networking"))
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 			ID:           "restart_networking",
 			Title:        "Restart Networking Service",
@@ -31,27 +33,28 @@ func DiagnoseNetworkIssues() Diagnosis {
 			RiskLevel:    fixes.RiskMedium,
 		})
 	} else if strings.TrimSpace(string(output)) == "active" {
-		diagnosis.Findings = append(diagnosis.Findings, "Networking service is active")
+		diagnosis.Add(finding.New("network.service_active", finding.SeverityInfo, "Networking service is active"))
 	}
 
 	// Check interfaces
-	interfaces, err := net.Interfaces()
+	interfaces, err := sys.Interfaces()
 	if err == nil {
 		downInterfaces := []string{}
 		for _, iface := range interfaces {
-			if iface.Flags&net.FlagLoopback != 0 {
+			if iface.Loopback {
 				continue
 			}
 			
-			if iface.Flags&net.FlagUp == 0 {
+			if !iface.Up {
 				downInterfaces = append(downInterfaces, iface.Name)
 			}
 		}
 		
 		if len(downInterfaces) > 0 {
-			diagnosis.Findings = append(diagnosis.Findings, 
-				fmt.Sprintf("Interfaces down: %s", strings.Join(downInterfaces, ", ")))
 			for _, iface := range downInterfaces {
+				diagnosis.Add(finding.Newf("network.interface_down", finding.SeverityWarning, "Interface down: %s", iface).
+					Affects(finding.ResourceInterface, iface).
+					FixedBy(fmt.Sprintf("bring_up_%s", iface)))
 				diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 					ID:           fmt.Sprintf("bring_up_%s", iface),
 					Title:        fmt.Sprintf("Bring Up Interface %s", iface),
@@ -64,30 +67,34 @@ func DiagnoseNetworkIssues() Diagnosis {
 				})
 			}
 		} else {
-			diagnosis.Findings = append(diagnosis.Findings, "All network interfaces are up")
+			diagnosis.Add(finding.New("network.interfaces_up", finding.SeverityInfo, "All network interfaces are up"))
 		}
 	}
 
 	// Check DNS resolution
-	if _, err := net.LookupHost("debian.org"); err != nil {
-		diagnosis.Findings = append(diagnosis.Findings, "DNS resolution failed")
+	if _, err := sys.LookupHost("debian.org"); err != nil {
+		diagnosis.Add(finding.New("network.dns_failed", finding.SeverityError, "DNS resolution failed").
+			With("error", err).
+			FixedBy("reset_dns"))
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 			ID:           "reset_dns",
 			Title:        "Reset DNS Configuration",
 			Description:  "Reset DNS configuration",
 			Commands:     []string{"echo 'nameserver 8.8.8.8' > /etc/resolv.conf"},
 			RequiresRoot: true,
-			Reversible:   false,
+			Reversible:   true, // Restored from the snapshot of /etc/resolv.conf
 			RiskLevel:    fixes.RiskHigh,
+			Modifies:     []string{"/etc/resolv.conf"},
 		})
 	} else {
-		diagnosis.Findings = append(diagnosis.Findings, "DNS resolution working")
+		diagnosis.Add(finding.New("network.dns_ok", finding.SeverityInfo, "DNS resolution working"))
 	}
 
 	// Check default route
-	if output, err := exec.Command("ip", "route", "show", "default").Output(); err == nil {
+	if output, err := sys.Output("ip", "route", "show", "default"); err == nil {
 		if len(output) == 0 {
-			diagnosis.Findings = append(diagnosis.Findings, "No default route configured")
+			diagnosis.Add(finding.New("network.no_default_route", finding.SeverityError, "No default route configured").
+				FixedBy("add_default_route"))
 			diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 				ID:           "add_default_route",
 				Title:        "Add Default Route",
@@ -99,12 +106,14 @@ func DiagnoseNetworkIssues() Diagnosis {
 				RiskLevel:    fixes.RiskHigh,
 			})
 		} else {
-			diagnosis.Findings = append(diagnosis.Findings, "Default route configured")
+			diagnosis.Add(finding.New("network.default_route", finding.SeverityInfo, "Default route configured"))
 		}
 	}
 
+	diagnosis.applyRules()
+
 	if len(diagnosis.Findings) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No network issues detected")
+		diagnosis.Add(finding.New("network.ok", finding.SeverityInfo, "No network issues detected"))
 	}
 
 	return diagnosis
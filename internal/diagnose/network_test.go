@@ -3,6 +3,8 @@ package diagnose
 import (
 	"strings"
 	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
 )
 
 func TestDiagnoseNetworkIssues(t *testing.T) {
@@ -23,7 +25,7 @@ func TestDiagnoseNetworkIssues(t *testing.T) {
 	hasInterfaceCheck := false
 	hasDNSCheck := false
 	
-	for _, finding := range diagnosis.Findings {
+	for _, finding := range diagnosis.Summaries() {
 		lower := strings.ToLower(finding)
 		if strings.Contains(lower, "service") || strings.Contains(lower, "networking") {
 			hasServiceCheck = true
@@ -66,4 +68,26 @@ func TestDiagnoseNetworkIssues(t *testing.T) {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestDiagnoseNetworkDNS(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		rec  machine.Record
+		want string
+	}{
+		{"resolves", machine.Record{Kind: machine.KindLookupHost, Name: "debian.org", Addrs: []string{"192.0.2.1"}}, "network.dns_ok"},
+		{"fails", machine.Record{Kind: machine.KindLookupHost, Name: "debian.org", Error: "no such host"}, "network.dns_failed"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := machine.NewFixture()
+			fixture.Add(tt.rec)
+			useCommands(t, fixture)
+
+			diagnosis := DiagnoseNetworkIssues()
+			if len(findingsWithID(diagnosis, tt.want)) != 1 {
+				t.Errorf("Expected finding %s, got %v", tt.want, diagnosis.Summaries())
+			}
+		})
+	}
+}
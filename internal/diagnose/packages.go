@@ -2,10 +2,9 @@ package diagnose
 
 import (
 	"fmt"
-	"os/exec"
-	"regexp"
 	"strings"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
@@ -13,24 +12,18 @@ import (
 func DiagnosePackageIssues() Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "Package System Issues",
-		Findings: []string{},
+		Category: "packages",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
 	// Check for broken packages
 	brokenPackages := checkBrokenPackages()
 	if len(brokenPackages) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, 
-			fmt.Sprintf("Broken packages detected: %d", len(brokenPackages)))
-		
-		for i, pkg := range brokenPackages {
-			if i < 5 { // Show first 5
-				diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", pkg))
-			}
-		}
-		if len(brokenPackages) > 5 {
-			diagnosis.Findings = append(diagnosis.Findings, 
-				fmt.Sprintf("  ... and %d more", len(brokenPackages)-5))
+		for _, pkg := range brokenPackages {
+			diagnosis.Add(finding.New("packages.broken", finding.SeverityError, "Broken package: "+pkg).
+				Affects(finding.ResourcePackage, pkg).
+				FixedBy("fix_broken_packages", "dpkg_configure_all"))
 		}
 
 		// Get common fixes for broken packages
@@ -53,9 +46,9 @@ func DiagnosePackageIssues() Diagnosis {
 	// Check for dependency issues
 	dependencyIssues := checkDependencyIssues()
 	if len(dependencyIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Dependency issues found:")
 		for _, issue := range dependencyIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			diagnosis.Add(finding.New("packages.dependency", finding.SeverityError, "Dependency issue: "+issue).
+				FixedBy("fix_dependencies"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -71,7 +64,8 @@ func DiagnosePackageIssues() Diagnosis {
 
 	// Check for lock file issues
 	if checkAPTLocked() {
-		diagnosis.Findings = append(diagnosis.Findings, "APT is currently locked (another package operation in progress)")
+		diagnosis.Add(finding.New("packages.apt_locked", finding.SeverityWarning, "APT is currently locked (another package operation in progress)").
+			FixedBy("show_apt_processes", "remove_apt_lock"))
 		
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 			ID:          "show_apt_processes",
@@ -81,6 +75,7 @@ func DiagnosePackageIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -101,9 +96,10 @@ func DiagnosePackageIssues() Diagnosis {
 	// Check for repository issues
 	repoIssues := checkRepositoryIssues()
 	if len(repoIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Repository issues detected:")
 		for _, issue := range repoIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			diagnosis.Add(finding.New("packages.repository", finding.SeverityWarning, "Repository issue: "+issue).
+				WithExcerpt(issue).
+				FixedBy("update_package_cache", "fix_repository_keys"))
 		}
 
 		commonFixes := fixes.GetCommonFixes()
@@ -127,9 +123,11 @@ func DiagnosePackageIssues() Diagnosis {
 
 	// Check for package cache issues
 	cacheSize := checkPackageCacheSize()
-	if cacheSize > 1000 { // More than 1GB
-		diagnosis.Findings = append(diagnosis.Findings, 
-			fmt.Sprintf("Large package cache detected: %.1f MB", cacheSize))
+	if cacheSize > thresholds.PackageCacheMaxMB {
+		diagnosis.Add(finding.Newf("packages.cache_large", finding.SeverityWarning, "Large package cache detected: %.1f MB", cacheSize).
+			With("size_mb", fmt.Sprintf("%.1f", cacheSize)).
+			Affects(finding.ResourcePath, "/var/cache/apt/archives").
+			FixedBy("clean_package_cache"))
 		
 		commonFixes := fixes.GetCommonFixes()
 		if cleanFix, exists := commonFixes["clean_package_cache"]; exists {
@@ -140,8 +138,9 @@ func DiagnosePackageIssues() Diagnosis {
 	// Check for many upgradeable packages
 	upgradeableCount := checkUpgradeableCount()
 	if upgradeableCount > 20 {
-		diagnosis.Findings = append(diagnosis.Findings, 
-			fmt.Sprintf("Many packages available for upgrade: %d", upgradeableCount))
+		diagnosis.Add(finding.Newf("packages.upgrades_pending", finding.SeverityInfo, "Many packages available for upgrade: %d", upgradeableCount).
+			With("count", upgradeableCount).
+			FixedBy("upgrade_packages", "list_upgradeable"))
 		
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 			ID:          "upgrade_packages",
@@ -161,14 +160,16 @@ func DiagnosePackageIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check for orphaned packages
 	orphanedCount := checkOrphanedPackages()
 	if orphanedCount > 10 {
-		diagnosis.Findings = append(diagnosis.Findings, 
-			fmt.Sprintf("Many orphaned packages detected: %d", orphanedCount))
+		diagnosis.Add(finding.Newf("packages.orphaned", finding.SeverityInfo, "Many orphaned packages detected: %d", orphanedCount).
+			With("count", orphanedCount).
+			FixedBy("remove_orphaned_packages", "list_orphaned"))
 		
 		commonFixes := fixes.GetCommonFixes()
 		if removeFix, exists := commonFixes["remove_orphaned_packages"]; exists {
@@ -183,34 +184,18 @@ func DiagnosePackageIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
-		})
-	}
-
-	// Check for package configuration issues
-	configIssues := checkPackageConfiguration()
-	if len(configIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Package configuration issues:")
-		for _, issue := range configIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
-		}
-
-		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
-			ID:          "reconfigure_packages",
-			Title:       "Reconfigure Packages",
-			Description: "Reconfigure packages that failed configuration",
-			Commands:    []string{"dpkg-reconfigure -a"},
-			RequiresRoot: true,
-			Reversible:  false,
-			RiskLevel:   fixes.RiskMedium,
+			Informational: true,
 		})
 	}
 
 	// Check for duplicate packages
 	duplicates := checkDuplicatePackages()
 	if len(duplicates) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Duplicate packages detected:")
 		for _, dup := range duplicates {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", dup))
+			pkg, _, _ := strings.Cut(dup, " ")
+			diagnosis.Add(finding.New("packages.duplicate", finding.SeverityWarning, "Duplicate package: "+dup).
+				Affects(finding.ResourcePackage, pkg).
+				FixedBy("remove_duplicates"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -237,10 +222,13 @@ func DiagnosePackageIssues() Diagnosis {
 		RequiresRoot: false,
 		Reversible:  false,
 		RiskLevel:   fixes.RiskLow,
+		Informational: true,
 	})
 
+	diagnosis.applyRules()
+
 	if len(diagnosis.Findings) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No significant package system issues detected")
+		diagnosis.Add(finding.New("packages.ok", finding.SeverityInfo, "No significant package system issues detected"))
 	}
 
 	return diagnosis
@@ -248,33 +236,18 @@ func DiagnosePackageIssues() Diagnosis {
 
 // checkBrokenPackages finds packages in broken state
 func checkBrokenPackages() []string {
-	broken := []string{}
-
-	cmd := exec.Command("dpkg", "-l")
-	output, err := cmd.Output()
-	if err != nil {
-		return broken
+	broken, _ := facts.BrokenPackages()
+	if broken == nil {
+		return []string{}
 	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "iU") || strings.HasPrefix(line, "iF") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				broken = append(broken, fields[1])
-			}
-		}
-	}
-
-	return removeDuplicateStrings(broken)
+	return broken
 }
 
 // checkDependencyIssues checks for unmet dependencies
 func checkDependencyIssues() []string {
 	issues := []string{}
 
-	cmd := exec.Command("apt-get", "check")
-	output, err := cmd.CombinedOutput()
+	output, err := sys.CombinedOutput("apt-get", "check")
 	if err != nil {
 		content := string(output)
 		lines := strings.Split(content, "\n")
@@ -297,8 +270,7 @@ func checkAPTLocked() bool {
 		"/var/cache/apt/archives/lock",
 	}
 
-	cmd := exec.Command("lsof")
-	output, err := cmd.Output()
+	output, err := sys.Output("lsof")
 	if err != nil {
 		return false
 	}
@@ -317,8 +289,7 @@ func checkAPTLocked() bool {
 func checkRepositoryIssues() []string {
 	issues := []string{}
 
-	cmd := exec.Command("apt-get", "update")
-	output, err := cmd.CombinedOutput()
+	output, err := sys.CombinedOutput("apt-get", "update")
 	if err != nil {
 		content := string(output)
 		lines := strings.Split(content, "\n")
@@ -337,108 +308,35 @@ func checkRepositoryIssues() []string {
 
 // checkPackageCacheSize returns cache size in MB
 func checkPackageCacheSize() float64 {
-	cmd := exec.Command("du", "-sm", "/var/cache/apt/archives")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0
-	}
-
-	fields := strings.Fields(string(output))
-	if len(fields) >= 1 {
-		var size float64
-		fmt.Sscanf(fields[0], "%f", &size)
-		return size
-	}
-
-	return 0
+	size, _ := facts.PackageCacheMB()
+	return size
 }
 
 // checkUpgradeableCount counts packages that can be upgraded
 func checkUpgradeableCount() int {
-	cmd := exec.Command("apt", "list", "--upgradable")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0
-	}
-
-	lines := strings.Split(string(output), "\n")
-	count := 0
-	for _, line := range lines {
-		if strings.Contains(line, "[upgradable from:") {
-			count++
-		}
-	}
-
+	count, _ := facts.UpgradableCount()
 	return count
 }
 
 // checkOrphanedPackages counts orphaned packages
 func checkOrphanedPackages() int {
-	cmd := exec.Command("apt", "autoremove", "--dry-run")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0
-	}
-
-	content := string(output)
-	re := regexp.MustCompile(`The following packages will be REMOVED:\s*\n(.*?)(\n\n|\nNeed|\nAfter|$)`)
-	matches := re.FindStringSubmatch(content)
-	if len(matches) >= 2 {
-		packages := strings.Fields(matches[1])
-		return len(packages)
-	}
-
-	return 0
-}
-
-// checkPackageConfiguration checks for configuration issues
-func checkPackageConfiguration() []string {
-	issues := []string{}
-
-	cmd := exec.Command("dpkg", "--audit")
-	output, err := cmd.Output()
-	if err != nil {
-		return issues
-	}
-
-	content := strings.TrimSpace(string(output))
-	if content != "" {
-		lines := strings.Split(content, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				issues = append(issues, line)
-			}
-		}
-	}
-
-	return issues
+	count, _ := facts.AutoremovableCount()
+	return count
 }
 
 // checkDuplicatePackages finds packages with multiple versions
 func checkDuplicatePackages() []string {
 	duplicates := []string{}
 
-	cmd := exec.Command("dpkg", "-l")
-	output, err := cmd.Output()
+	packages, err := facts.Packages()
 	if err != nil {
 		return duplicates
 	}
 
 	packageCounts := make(map[string]int)
-	lines := strings.Split(string(output), "\n")
-	
-	for _, line := range lines {
-		if strings.HasPrefix(line, "ii") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				// Extract base package name (remove architecture suffix)
-				pkgName := fields[1]
-				if colonIndex := strings.Index(pkgName, ":"); colonIndex != -1 {
-					pkgName = pkgName[:colonIndex]
-				}
-				packageCounts[pkgName]++
-			}
+	for _, pkg := range packages {
+		if pkg.Installed() {
+			packageCounts[pkg.BaseName()]++
 		}
 	}
 
@@ -449,19 +347,4 @@ func checkDuplicatePackages() []string {
 	}
 
 	return duplicates
-}
-
-// removeDuplicateStrings removes duplicate strings from a slice
-func removeDuplicateStrings(slice []string) []string {
-	keys := make(map[string]bool)
-	result := []string{}
-
-	for _, item := range slice {
-		if !keys[item] {
-			keys[item] = true
-			result = append(result, item)
-		}
-	}
-
-	return result
 }
\ No newline at end of file
@@ -143,24 +143,6 @@ func TestCheckOrphanedPackages(t *testing.T) {
 	t.Logf("Orphaned packages: %d", count)
 }
 
-func TestCheckPackageConfiguration(t *testing.T) {
-	issues := checkPackageConfiguration()
-	
-	// Should return a slice (might be empty)
-	if issues == nil {
-		t.Error("checkPackageConfiguration returned nil, expected slice")
-	}
-	
-	// If issues exist, they should be non-empty strings
-	for i, issue := range issues {
-		if strings.TrimSpace(issue) == "" {
-			t.Errorf("Configuration issue %d is empty or whitespace only", i)
-		}
-	}
-	
-	t.Logf("Configuration issues found: %d", len(issues))
-}
-
 func TestCheckDuplicatePackages(t *testing.T) {
 	duplicates := checkDuplicatePackages()
 	
@@ -184,53 +166,6 @@ func TestCheckDuplicatePackages(t *testing.T) {
 	t.Logf("Duplicate packages found: %d", len(duplicates))
 }
 
-func TestRemoveDuplicateStrings(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    []string
-		expected []string
-	}{
-		{
-			name:     "empty slice",
-			input:    []string{},
-			expected: []string{},
-		},
-		{
-			name:     "no duplicates",
-			input:    []string{"package1", "package2", "package3"},
-			expected: []string{"package1", "package2", "package3"},
-		},
-		{
-			name:     "with duplicates",
-			input:    []string{"package1", "package2", "package1", "package3", "package2"},
-			expected: []string{"package1", "package2", "package3"},
-		},
-		{
-			name:     "all duplicates",
-			input:    []string{"package1", "package1", "package1"},
-			expected: []string{"package1"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := removeDuplicateStrings(tt.input)
-			
-			// Check length
-			if len(result) != len(tt.expected) {
-				t.Errorf("removeDuplicateStrings() length = %d, want %d", len(result), len(tt.expected))
-			}
-			
-			// Check contents (order matters in this implementation)
-			for i, expected := range tt.expected {
-				if i >= len(result) || result[i] != expected {
-					t.Errorf("removeDuplicateStrings() = %v, want %v", result, tt.expected)
-					break
-				}
-			}
-		})
-	}
-}
 
 func TestPackageDiagnosisFixValidation(t *testing.T) {
 	diagnosis := DiagnosePackageIssues()
@@ -274,7 +209,7 @@ func TestPackageDiagnosisIntegration(t *testing.T) {
 	}
 	
 	// Check that findings contain package-related information
-	findingsText := strings.Join(diagnosis.Findings, " ")
+	findingsText := strings.Join(diagnosis.Summaries(), " ")
 	expectedKeywords := []string{"package", "apt", "dpkg"}
 	foundKeywords := 0
 	
@@ -3,65 +3,68 @@ package diagnose
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/load"
-	"github.com/shirou/gopsutil/v3/mem"
 )
 
 // DiagnosePerformanceIssues diagnoses performance-related problems
 func DiagnosePerformanceIssues() Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "Performance Issues",
-		Findings: []string{},
+		Category: "performance",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
 	// Check CPU usage
-	if percent, err := cpu.Percent(0, false); err == nil && len(percent) > 0 {
-		cpuUsage := percent[0]
-		if cpuUsage > 80 {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("High CPU usage: %.1f%%", cpuUsage))
+	if cpuUsage, err := sys.CPUPercent(); err == nil {
+		if cpuUsage > thresholds.CPUWarningPercent {
+			diagnosis.Add(finding.Newf("performance.cpu_high", finding.SeverityWarning, "High CPU usage: %.1f%%", cpuUsage).
+				With("cpu_percent", fmt.Sprintf("%.1f", cpuUsage)))
 			
 			// Get top CPU processes
-			cmd := exec.Command("ps", "aux", "--sort=-pcpu")
-			if output, err := cmd.Output(); err == nil {
+			if output, err := sys.Output("ps", "aux", "--sort=-pcpu"); err == nil {
 				lines := strings.Split(string(output), "\n")
 				if len(lines) > 1 {
-					diagnosis.Findings = append(diagnosis.Findings, "Top CPU consumers:")
 					for i := 1; i < 4 && i < len(lines); i++ {
 						fields := strings.Fields(lines[i])
 						if len(fields) > 10 {
-							diagnosis.Findings = append(diagnosis.Findings, 
-								fmt.Sprintf("  - %s: %s%% CPU", fields[10], fields[2]))
+							diagnosis.Add(finding.Newf("performance.top_cpu_process", finding.SeverityInfo,
+								"Top CPU consumer %s: %s%% CPU", fields[10], fields[2]).
+								With("pid", fields[1]).
+								With("cpu_percent", fields[2]).
+								Affects(finding.ResourceProcess, fields[10]))
 						}
 					}
 				}
 			}
 		} else {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("CPU usage normal: %.1f%%", cpuUsage))
+			diagnosis.Add(finding.Newf("performance.cpu_normal", finding.SeverityInfo, "CPU usage normal: %.1f%%", cpuUsage))
 		}
 	}
 
 	// Check memory usage
-	if vmStat, err := mem.VirtualMemory(); err == nil {
-		if vmStat.UsedPercent > 85 {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("High memory usage: %.1f%%", vmStat.UsedPercent))
+	memInfo, memErr := sys.Meminfo()
+	if memErr == nil {
+		if memInfo.UsedPercent() > thresholds.MemoryWarningPercent {
+			diagnosis.Add(finding.Newf("performance.memory_high", finding.SeverityWarning, "High memory usage: %.1f%%", memInfo.UsedPercent()).
+				With("used_percent", fmt.Sprintf("%.1f", memInfo.UsedPercent())).
+				FixedBy("clear_caches"))
 			
 			// Get top memory processes
-			cmd := exec.Command("ps", "aux", "--sort=-pmem")
-			if output, err := cmd.Output(); err == nil {
+			if output, err := sys.Output("ps", "aux", "--sort=-pmem"); err == nil {
 				lines := strings.Split(string(output), "\n")
 				if len(lines) > 1 {
-					diagnosis.Findings = append(diagnosis.Findings, "Top memory consumers:")
 					for i := 1; i < 4 && i < len(lines); i++ {
 						fields := strings.Fields(lines[i])
 						if len(fields) > 10 {
-							diagnosis.Findings = append(diagnosis.Findings, 
-								fmt.Sprintf("  - %s: %s%% MEM", fields[10], fields[3]))
+							diagnosis.Add(finding.Newf("performance.top_memory_process", finding.SeverityInfo,
+								"Top memory consumer %s: %s%% MEM", fields[10], fields[3]).
+								With("pid", fields[1]).
+								With("memory_percent", fields[3]).
+								Affects(finding.ResourceProcess, fields[10]))
 						}
 					}
 				}
@@ -77,16 +80,22 @@ func DiagnosePerformanceIssues() Diagnosis {
 				RiskLevel:    fixes.RiskLow,
 			})
 		} else {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("Memory usage normal: %.1f%%", vmStat.UsedPercent))
+			diagnosis.Add(finding.Newf("performance.memory_normal", finding.SeverityInfo, "Memory usage normal: %.1f%%", memInfo.UsedPercent()))
 		}
 	}
 
 	// Check load average
-	if avg, err := load.Avg(); err == nil {
-		cpuCount, _ := cpu.Counts(true)
-		if avg.Load1 > float64(cpuCount*2) {
-			diagnosis.Findings = append(diagnosis.Findings, 
-				fmt.Sprintf("High system load: %.2f (cores: %d)", avg.Load1, cpuCount))
+	if avg, err := sys.LoadAverage(); err == nil {
+		cpuCount := 0
+		if cpuInfo, err := sys.CPUInfo(); err == nil {
+			cpuCount = cpuInfo.Threads
+		}
+		if avg[0] > float64(cpuCount)*thresholds.LoadPerCPU {
+			diagnosis.Add(finding.Newf("performance.load_high", finding.SeverityWarning,
+				"High system load: %.2f (cores: %d)", avg[0], cpuCount).
+				With("load1", fmt.Sprintf("%.2f", avg[0])).
+				With("cores", cpuCount).
+				FixedBy("view_processes"))
 			diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 				ID:           "view_processes",
 				Title:        "View Running Processes",
@@ -95,18 +104,20 @@ func DiagnosePerformanceIssues() Diagnosis {
 				RequiresRoot: false,
 				Reversible:   false,
 				RiskLevel:    fixes.RiskLow,
+				Informational: true,
 			})
 		} else {
-			diagnosis.Findings = append(diagnosis.Findings, 
-				fmt.Sprintf("System load normal: %.2f", avg.Load1))
+			diagnosis.Add(finding.Newf("performance.load_normal", finding.SeverityInfo, "System load normal: %.2f", avg[0]))
 		}
 	}
 
 	// Check for swap usage
-	if swapStat, err := mem.SwapMemory(); err == nil {
-		if swapStat.UsedPercent > 50 {
-			diagnosis.Findings = append(diagnosis.Findings, 
-				fmt.Sprintf("High swap usage: %.1f%% - possible memory pressure", swapStat.UsedPercent))
+	if memErr == nil {
+		if memInfo.SwapUsedPercent() > thresholds.SwapWarningPercent {
+			diagnosis.Add(finding.Newf("performance.swap_high", finding.SeverityWarning,
+				"High swap usage: %.1f%% - possible memory pressure", memInfo.SwapUsedPercent()).
+				With("swap_percent", fmt.Sprintf("%.1f", memInfo.SwapUsedPercent())).
+				FixedBy("clear_swap"))
 			diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
 				ID:           "clear_swap",
 				Title:        "Clear Swap Memory",
@@ -119,8 +130,10 @@ func DiagnosePerformanceIssues() Diagnosis {
 		}
 	}
 
+	diagnosis.applyRules()
+
 	if len(diagnosis.Findings) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No performance issues detected")
+		diagnosis.Add(finding.New("performance.ok", finding.SeverityInfo, "No performance issues detected"))
 	}
 
 	return diagnosis
@@ -23,7 +23,7 @@ func TestDiagnosePerformanceIssues(t *testing.T) {
 	hasMemoryCheck := false
 	hasLoadCheck := false
 	
-	for _, finding := range diagnosis.Findings {
+	for _, finding := range diagnosis.Summaries() {
 		lower := strings.ToLower(finding)
 		if strings.Contains(lower, "cpu") {
 			hasCPUCheck = true
@@ -5,16 +5,50 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
+// Critical system directories and their expected permissions
+var systemDirModes = map[string]os.FileMode{
+	"/etc":     0755,
+	"/bin":     0755,
+	"/sbin":    0755,
+	"/usr/bin": 0755,
+	"/var/log": 0755,
+}
+
+// Sensitive configuration files and their expected permissions
+var sensitiveFileModes = map[string]os.FileMode{
+	"/etc/passwd":          0644,
+	"/etc/shadow":          0640,
+	"/etc/gshadow":         0640,
+	"/etc/sudoers":         0440,
+	"/etc/ssh/sshd_config": 0644,
+}
+
+// KeyPaths returns the system directories and sensitive files whose
+// permissions are checked, sorted
+func KeyPaths() []string {
+	paths := make([]string, 0, len(systemDirModes)+len(sensitiveFileModes))
+	for path := range systemDirModes {
+		paths = append(paths, path)
+	}
+	for path := range sensitiveFileModes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // DiagnosePermissionIssues performs comprehensive permission analysis
 func DiagnosePermissionIssues() Diagnosis {
-	findings := []string{}
+	findings := []finding.Finding{}
 	allFixes := []*fixes.Fix{}
 	
 	// Check common permission issues
@@ -29,29 +63,35 @@ func DiagnosePermissionIssues() Diagnosis {
 	// Generate fixes
 	allFixes = append(allFixes, generatePermissionFixes(findings)...)
 	
-	if len(findings) == 0 {
-		findings = append(findings, "No permission issues detected")
-	}
-	
-	return Diagnosis{
+	diagnosis := Diagnosis{
 		Issue:    "Permission Issues",
-		Findings: findings,
+		Category: "permissions",
 		Fixes:    allFixes,
 	}
+	diagnosis.Add(findings...)
+	diagnosis.applyRules()
+
+	if len(diagnosis.Findings) == 0 {
+		diagnosis.Add(finding.New("permissions.ok", finding.SeverityInfo, "No permission issues detected"))
+	}
+	return diagnosis
 }
 
 // DiagnoseFilePermissions analyzes permissions for a specific file or directory
 func DiagnoseFilePermissions(path string) Diagnosis {
-	findings := []string{}
+	findings := []finding.Finding{}
 	allFixes := []*fixes.Fix{}
 	
 	// Get file info
-	info, err := os.Stat(path)
+	info, err := sys.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			findings = append(findings, fmt.Sprintf("Path does not exist: %s", path))
+			findings = append(findings, finding.Newf("permissions.missing", finding.SeverityError, "Path does not exist: %s", path).
+				Affects(finding.ResourcePath, path))
 		} else if os.IsPermission(err) {
-			findings = append(findings, fmt.Sprintf("Permission denied accessing: %s", path))
+			findings = append(findings, finding.Newf("permissions.access_denied", finding.SeverityError, "Permission denied accessing: %s", path).
+				Affects(finding.ResourcePath, path).
+				FixedBy("fix_access_permission"))
 			allFixes = append(allFixes, &fixes.Fix{
 				ID:           "fix_access_permission",
 				Title:        "Fix Access Permission",
@@ -61,20 +101,25 @@ func DiagnoseFilePermissions(path string) Diagnosis {
 				RiskLevel:    fixes.RiskMedium,
 			})
 		} else {
-			findings = append(findings, fmt.Sprintf("Error accessing path: %v", err))
+			findings = append(findings, finding.Newf("permissions.stat_failed", finding.SeverityError, "Error accessing path: %v", err).
+				Affects(finding.ResourcePath, path))
 		}
-		return Diagnosis{
+		diagnosis := Diagnosis{
 			Issue:    fmt.Sprintf("File Permission Analysis: %s", path),
-			Findings: findings,
+			Category: "permissions",
 			Fixes:    allFixes,
 		}
+		diagnosis.Add(findings...)
+		return diagnosis
 	}
 	
 	// Analyze permissions
 	mode := info.Mode()
-	findings = append(findings, fmt.Sprintf("Path: %s", path))
-	findings = append(findings, fmt.Sprintf("Type: %s", getFileType(mode)))
-	findings = append(findings, fmt.Sprintf("Permissions: %s (%04o)", mode.String(), mode.Perm()))
+	findings = append(findings, finding.Newf("permissions.path", finding.SeverityInfo, "Path: %s", path).
+		Affects(finding.ResourcePath, path))
+	findings = append(findings, finding.Newf("permissions.type", finding.SeverityInfo, "Type: %s", getFileType(mode)))
+	findings = append(findings, finding.Newf("permissions.mode", finding.SeverityInfo, "Permissions: %s (%04o)", mode.String(), mode.Perm()).
+		With("mode", fmt.Sprintf("%04o", mode.Perm())))
 	
 	// Get ownership information (Unix-specific)
 	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
@@ -85,15 +130,17 @@ func DiagnoseFilePermissions(path string) Diagnosis {
 		userName := getUsername(uid)
 		groupName := getGroupname(gid)
 		
-		findings = append(findings, fmt.Sprintf("Owner: %s (UID: %d)", userName, uid))
-		findings = append(findings, fmt.Sprintf("Group: %s (GID: %d)", groupName, gid))
+		findings = append(findings, finding.Newf("permissions.owner", finding.SeverityInfo, "Owner: %s (UID: %d)", userName, uid).
+			With("uid", uid))
+		findings = append(findings, finding.Newf("permissions.group", finding.SeverityInfo, "Group: %s (GID: %d)", groupName, gid).
+			With("gid", gid))
 		
 		// Check if current user owns the file
 		currentUser, err := user.Current()
 		if err == nil {
 			currentUID, _ := strconv.ParseUint(currentUser.Uid, 10, 32)
 			if uint32(currentUID) != uid {
-				findings = append(findings, "You do not own this file")
+				findings = append(findings, finding.New("permissions.not_owner", finding.SeverityInfo, "You do not own this file"))
 			}
 		}
 	}
@@ -110,28 +157,38 @@ func DiagnoseFilePermissions(path string) Diagnosis {
 	// Check for security issues
 	securityIssues := checkSecurityIssues(path, mode)
 	if len(securityIssues) > 0 {
-		findings = append(findings, "SECURITY ISSUES:")
-		findings = append(findings, securityIssues...)
-		allFixes = append(allFixes, generateSecurityFixes(path, mode)...)
+		securityFixes := generateSecurityFixes(path, mode)
+		fixIDs := make([]string, len(securityFixes))
+		for i, fix := range securityFixes {
+			fixIDs[i] = fix.ID
+		}
+		for _, issue := range securityIssues {
+			findings = append(findings, finding.New("permissions.security", finding.SeverityWarning, "SECURITY: "+issue).
+				Affects(finding.ResourcePath, path).
+				FixedBy(fixIDs...))
+		}
+		allFixes = append(allFixes, securityFixes...)
 	}
 	
-	return Diagnosis{
+	diagnosis := Diagnosis{
 		Issue:    fmt.Sprintf("File Permission Analysis: %s", path),
-		Findings: findings,
+		Category: "permissions",
 		Fixes:    allFixes,
 	}
+	diagnosis.Add(findings...)
+	return diagnosis
 }
 
-func checkUserPermissions() []string {
-	findings := []string{}
+func checkUserPermissions() []finding.Finding {
+	findings := []finding.Finding{}
 	
 	currentUser, err := user.Current()
 	if err != nil {
-		findings = append(findings, fmt.Sprintf("Cannot determine current user: %v", err))
+		findings = append(findings, finding.Newf("permissions.user_unknown", finding.SeverityWarning, "Cannot determine current user: %v", err))
 		return findings
 	}
 	
-	findings = append(findings, fmt.Sprintf("Current user: %s (UID: %s)", currentUser.Username, currentUser.Uid))
+	findings = append(findings, finding.Newf("permissions.current_user", finding.SeverityInfo, "Current user: %s (UID: %s)", currentUser.Username, currentUser.Uid))
 	
 	// Check if user is in important groups
 	groups, err := currentUser.GroupIds()
@@ -139,7 +196,8 @@ func checkUserPermissions() []string {
 		importantGroups := []string{"sudo", "admin", "wheel", "docker", "vboxusers"}
 		for _, group := range importantGroups {
 			if hasGroup(groups, group) {
-				findings = append(findings, fmt.Sprintf("User is in '%s' group", group))
+				findings = append(findings, finding.Newf("permissions.group_member", finding.SeverityInfo, "User is in '%s' group", group).
+					With("group", group))
 			}
 		}
 	}
@@ -147,15 +205,15 @@ func checkUserPermissions() []string {
 	return findings
 }
 
-func checkHomeDirectoryPermissions() []string {
-	findings := []string{}
+func checkHomeDirectoryPermissions() []finding.Finding {
+	findings := []finding.Finding{}
 	
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return findings
 	}
 	
-	info, err := os.Stat(homeDir)
+	info, err := sys.Stat(homeDir)
 	if err != nil {
 		return findings
 	}
@@ -165,17 +223,20 @@ func checkHomeDirectoryPermissions() []string {
 	
 	// Home directory should typically be 755 or 750
 	if perm&0077 != 0 {
-		findings = append(findings, fmt.Sprintf("Home directory has overly permissive permissions: %04o", perm))
+		findings = append(findings, finding.Newf("permissions.home_permissive", finding.SeverityWarning, "Home directory has overly permissive permissions: %04o", perm).
+			Affects(finding.ResourcePath, homeDir).
+			FixedBy("fix_home_permissions"))
 	}
 	
 	// Check important subdirectories
 	importantDirs := []string{".ssh", ".gnupg", ".config"}
 	for _, dir := range importantDirs {
 		dirPath := filepath.Join(homeDir, dir)
-		if info, err := os.Stat(dirPath); err == nil {
+		if info, err := sys.Stat(dirPath); err == nil {
 			mode := info.Mode()
 			if dir == ".ssh" && mode.Perm()&0077 != 0 {
-				findings = append(findings, fmt.Sprintf("%s directory has insecure permissions: %04o", dir, mode.Perm()))
+				findings = append(findings, finding.Newf("permissions.insecure_dir", finding.SeverityWarning, "%s directory has insecure permissions: %04o", dir, mode.Perm()).
+					Affects(finding.ResourcePath, dirPath))
 			}
 		}
 	}
@@ -183,24 +244,17 @@ func checkHomeDirectoryPermissions() []string {
 	return findings
 }
 
-func checkSystemDirectoryPermissions() []string {
-	findings := []string{}
+func checkSystemDirectoryPermissions() []finding.Finding {
+	findings := []finding.Finding{}
 	
 	// Check critical system directories
-	criticalDirs := map[string]os.FileMode{
-		"/etc":     0755,
-		"/bin":     0755,
-		"/sbin":    0755,
-		"/usr/bin": 0755,
-		"/var/log": 0755,
-	}
-	
-	for dir, expectedPerm := range criticalDirs {
-		if info, err := os.Stat(dir); err == nil {
+	for dir, expectedPerm := range systemDirModes {
+		if info, err := sys.Stat(dir); err == nil {
 			perm := info.Mode().Perm()
 			if perm != expectedPerm {
-				findings = append(findings, fmt.Sprintf("%s has unexpected permissions: %04o (expected %04o)", 
-					dir, perm, expectedPerm))
+				findings = append(findings, finding.Newf("permissions.system_dir", finding.SeverityWarning,
+					"%s has unexpected permissions: %04o (expected %04o)", dir, perm, expectedPerm).
+					Affects(finding.ResourcePath, dir))
 			}
 		}
 	}
@@ -208,8 +262,8 @@ func checkSystemDirectoryPermissions() []string {
 	return findings
 }
 
-func checkExecutablePermissions() []string {
-	findings := []string{}
+func checkExecutablePermissions() []finding.Finding {
+	findings := []finding.Finding{}
 	
 	// Check if common executables are accessible
 	executables := []string{
@@ -221,15 +275,17 @@ func checkExecutablePermissions() []string {
 	}
 	
 	for _, exe := range executables {
-		if _, err := os.Stat(exe); err != nil {
+		if _, err := sys.Stat(exe); err != nil {
 			if os.IsPermission(err) {
-				findings = append(findings, fmt.Sprintf("Cannot access executable: %s", exe))
+				findings = append(findings, finding.Newf("permissions.executable_denied", finding.SeverityError, "Cannot access executable: %s", exe).
+					Affects(finding.ResourcePath, exe))
 			}
 		} else {
 			// Check if executable
-			if info, err := os.Stat(exe); err == nil {
+			if info, err := sys.Stat(exe); err == nil {
 				if info.Mode()&0111 == 0 {
-					findings = append(findings, fmt.Sprintf("File is not executable: %s", exe))
+					findings = append(findings, finding.Newf("permissions.not_executable", finding.SeverityError, "File is not executable: %s", exe).
+						Affects(finding.ResourcePath, exe))
 				}
 			}
 		}
@@ -238,24 +294,18 @@ func checkExecutablePermissions() []string {
 	return findings
 }
 
-func checkConfigFilePermissions() []string {
-	findings := []string{}
+func checkConfigFilePermissions() []finding.Finding {
+	findings := []finding.Finding{}
 	
 	// Check sensitive configuration files
-	sensitiveFiles := map[string]os.FileMode{
-		"/etc/passwd":     0644,
-		"/etc/shadow":     0640,
-		"/etc/gshadow":    0640,
-		"/etc/sudoers":    0440,
-		"/etc/ssh/sshd_config": 0644,
-	}
-	
-	for file, expectedPerm := range sensitiveFiles {
-		if info, err := os.Stat(file); err == nil {
+	for file, expectedPerm := range sensitiveFileModes {
+		if info, err := sys.Stat(file); err == nil {
 			perm := info.Mode().Perm()
 			// Check if too permissive
 			if perm&0007 != 0 {
-				findings = append(findings, fmt.Sprintf("%s is world-readable/writable: %04o (expected %04o)", file, perm, expectedPerm))
+				findings = append(findings, finding.Newf("permissions.config_world_accessible", finding.SeverityError,
+					"%s is world-readable/writable: %04o (expected %04o)", file, perm, expectedPerm).
+					Affects(finding.ResourcePath, file))
 			}
 		}
 	}
@@ -263,8 +313,8 @@ func checkConfigFilePermissions() []string {
 	return findings
 }
 
-func checkSSHPermissions() []string {
-	findings := []string{}
+func checkSSHPermissions() []finding.Finding {
+	findings := []finding.Finding{}
 	
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -272,10 +322,12 @@ func checkSSHPermissions() []string {
 	}
 	
 	sshDir := filepath.Join(homeDir, ".ssh")
-	if info, err := os.Stat(sshDir); err == nil {
+	if info, err := sys.Stat(sshDir); err == nil {
 		perm := info.Mode().Perm()
 		if perm != 0700 {
-			findings = append(findings, fmt.Sprintf(".ssh directory has incorrect permissions: %04o (should be 0700)", perm))
+			findings = append(findings, finding.Newf("permissions.ssh_dir", finding.SeverityWarning, ".ssh directory has incorrect permissions: %04o (should be 0700)", perm).
+				Affects(finding.ResourcePath, sshDir).
+				FixedBy("fix_ssh_dir_permissions"))
 		}
 		
 		// Check SSH key files
@@ -289,11 +341,12 @@ func checkSSHPermissions() []string {
 		
 		for file, expectedPerm := range sshFiles {
 			filePath := filepath.Join(sshDir, file)
-			if info, err := os.Stat(filePath); err == nil {
+			if info, err := sys.Stat(filePath); err == nil {
 				perm := info.Mode().Perm()
 				if strings.Contains(file, "id_") && perm != expectedPerm {
-					findings = append(findings, fmt.Sprintf("SSH private key %s has insecure permissions: %04o (should be %04o)", 
-						file, perm, expectedPerm))
+					findings = append(findings, finding.Newf("permissions.ssh_key", finding.SeverityError,
+						"SSH private key %s has insecure permissions: %04o (should be %04o)", file, perm, expectedPerm).
+						Affects(finding.ResourcePath, filePath))
 				}
 			}
 		}
@@ -302,17 +355,17 @@ func checkSSHPermissions() []string {
 	return findings
 }
 
-func checkSudoPermissions() []string {
-	findings := []string{}
+func checkSudoPermissions() []finding.Finding {
+	findings := []finding.Finding{}
 	
 	// Check if user can use sudo
 	currentUser, err := user.Current()
 	if err == nil {
 		// Check sudoers file (limited check without root)
 		sudoersPath := "/etc/sudoers"
-		if _, err := os.Stat(sudoersPath); err != nil {
+		if _, err := sys.Stat(sudoersPath); err != nil {
 			if os.IsPermission(err) {
-				findings = append(findings, "Cannot check sudoers file (permission denied)")
+				findings = append(findings, finding.New("permissions.sudoers_unreadable", finding.SeverityInfo, "Cannot check sudoers file (permission denied)"))
 			}
 		}
 		
@@ -320,7 +373,7 @@ func checkSudoPermissions() []string {
 		groups, err := currentUser.GroupIds()
 		if err == nil {
 			if !hasGroup(groups, "sudo") && !hasGroup(groups, "admin") && !hasGroup(groups, "wheel") {
-				findings = append(findings, "User is not in sudo/admin group")
+				findings = append(findings, finding.New("permissions.no_sudo", finding.SeverityInfo, "User is not in sudo/admin group"))
 			}
 		}
 	}
@@ -328,56 +381,63 @@ func checkSudoPermissions() []string {
 	return findings
 }
 
-func checkDirectoryPermissions(path string, mode os.FileMode) []string {
-	findings := []string{}
+func checkDirectoryPermissions(path string, mode os.FileMode) []finding.Finding {
+	findings := []finding.Finding{}
 	perm := mode.Perm()
 	
 	// Check execute permission (needed to access directory)
 	if perm&0111 == 0 {
-		findings = append(findings, "Directory is not accessible (no execute permission)")
+		findings = append(findings, finding.New("permissions.dir_not_accessible", finding.SeverityError, "Directory is not accessible (no execute permission)").
+			Affects(finding.ResourcePath, path).
+			FixedBy("fix_dir_access"))
 	}
 	
 	// Check write permission
 	if perm&0222 == 0 {
-		findings = append(findings, "Directory is read-only")
+		findings = append(findings, finding.New("permissions.dir_read_only", finding.SeverityWarning, "Directory is read-only").
+			Affects(finding.ResourcePath, path).
+			FixedBy("fix_dir_readonly"))
 	}
 	
 	// Check for sticky bit
 	if mode&os.ModeSticky != 0 {
-		findings = append(findings, "Directory has sticky bit set")
+		findings = append(findings, finding.New("permissions.sticky", finding.SeverityInfo, "Directory has sticky bit set"))
 	}
 	
 	// Check for setuid/setgid
 	if mode&os.ModeSetuid != 0 {
-		findings = append(findings, "Directory has setuid bit set")
+		findings = append(findings, finding.New("permissions.setuid", finding.SeverityInfo, "Directory has setuid bit set"))
 	}
 	if mode&os.ModeSetgid != 0 {
-		findings = append(findings, "Directory has setgid bit set")
+		findings = append(findings, finding.New("permissions.setgid", finding.SeverityInfo, "Directory has setgid bit set"))
 	}
 	
 	return findings
 }
 
-func checkFilePermissions(path string, mode os.FileMode) []string {
-	findings := []string{}
+func checkFilePermissions(path string, mode os.FileMode) []finding.Finding {
+	findings := []finding.Finding{}
 	perm := mode.Perm()
 	
 	// Check if executable
 	if perm&0111 != 0 {
-		findings = append(findings, "File is executable")
+		findings = append(findings, finding.New("permissions.executable", finding.SeverityInfo, "File is executable"))
 		
 		// Check for setuid/setgid on executables
 		if mode&os.ModeSetuid != 0 {
-			findings = append(findings, "SECURITY: Executable has setuid bit set")
+			findings = append(findings, finding.New("permissions.setuid", finding.SeverityWarning, "SECURITY: Executable has setuid bit set").
+				Affects(finding.ResourcePath, path))
 		}
 		if mode&os.ModeSetgid != 0 {
-			findings = append(findings, "SECURITY: Executable has setgid bit set")
+			findings = append(findings, finding.New("permissions.setgid", finding.SeverityWarning, "SECURITY: Executable has setgid bit set").
+				Affects(finding.ResourcePath, path))
 		}
 	}
 	
 	// Check world-writable
 	if perm&0002 != 0 {
-		findings = append(findings, "SECURITY: File is world-writable")
+		findings = append(findings, finding.New("permissions.world_writable", finding.SeverityWarning, "SECURITY: File is world-writable").
+			Affects(finding.ResourcePath, path))
 	}
 	
 	return findings
@@ -417,12 +477,12 @@ func checkSecurityIssues(path string, mode os.FileMode) []string {
 	return issues
 }
 
-func generatePermissionFixes(findings []string) []*fixes.Fix {
+func generatePermissionFixes(findings []finding.Finding) []*fixes.Fix {
 	allFixes := []*fixes.Fix{}
 	
 	// Fix for home directory permissions
-	for _, finding := range findings {
-		if strings.Contains(finding, "Home directory has overly permissive") {
+	for _, f := range findings {
+		if f.ID == "permissions.home_permissive" {
 			homeDir, _ := os.UserHomeDir()
 			allFixes = append(allFixes, &fixes.Fix{
 				ID:           "fix_home_permissions",
@@ -434,7 +494,7 @@ func generatePermissionFixes(findings []string) []*fixes.Fix {
 			})
 		}
 		
-		if strings.Contains(finding, ".ssh directory has incorrect permissions") {
+		if f.ID == "permissions.ssh_dir" {
 			homeDir, _ := os.UserHomeDir()
 			sshDir := filepath.Join(homeDir, ".ssh")
 			allFixes = append(allFixes, &fixes.Fix{
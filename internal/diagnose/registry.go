@@ -0,0 +1,82 @@
+package diagnose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// thresholds are the limits diagnoses compare against
+var thresholds = config.DefaultThresholds()
+
+// SetThresholds replaces the limits used by every diagnosis. It is meant to be
+// called while setting up, before any diagnosis runs, since they read the limits
+// without locking
+func SetThresholds(t config.Thresholds) {
+	thresholds = t
+}
+
+// sys is the machine diagnoses run commands and read files on
+var sys = machine.Local(config.DefaultRunnerSettings().CheckTimeout)
+
+// facts are the probes shared with checks running on the same machine
+var facts = probes.For(sys)
+
+// SetMachine replaces the machine used by every diagnosis, for recording or
+// replaying a fixture
+func SetMachine(m *machine.Machine) {
+	sys = m
+	facts = probes.For(m)
+}
+
+// Diagnoser is a named diagnosis that can be run without user input
+type Diagnoser struct {
+	ID          string
+	Name        string
+	Description string
+	Run         func() Diagnosis
+}
+
+// Diagnosers returns every diagnosis that needs no user input, in the order
+// they are offered in interactive mode
+func Diagnosers() []Diagnoser {
+	return []Diagnoser{
+		{ID: "boot", Name: "Boot Issues", Description: "System startup problems and service failures", Run: DiagnoseBootIssues},
+		{ID: "performance", Name: "Performance Issues", Description: "CPU, memory, and load analysis", Run: DiagnosePerformanceIssues},
+		{ID: "network", Name: "Network Issues", Description: "Connectivity, DNS, and routing", Run: DiagnoseNetworkIssues},
+		{ID: "disk", Name: "Disk Issues", Description: "Storage space and disk errors", Run: DiagnoseDiskIssues},
+		{ID: "filesystem", Name: "Filesystem Issues", Description: "Mount problems and filesystem integrity", Run: DiagnoseFilesystemIssues},
+		{ID: "logs", Name: "Log Issues", Description: "System log and journal errors", Run: DiagnoseLogIssues},
+		{ID: "packages", Name: "Package Issues", Description: "APT package system problems", Run: DiagnosePackageIssues},
+		{ID: "services", Name: "Service Issues", Description: "Failed and flapping services", Run: DiagnoseServiceIssues},
+		{ID: "permissions", Name: "Permission Issues", Description: "File access and security permissions", Run: DiagnosePermissionIssues},
+	}
+}
+
+// LookupDiagnosers resolves diagnosis IDs; no IDs selects all of them
+func LookupDiagnosers(ids []string) ([]Diagnoser, error) {
+	all := Diagnosers()
+	if len(ids) == 0 {
+		return all, nil
+	}
+
+	byID := make(map[string]Diagnoser, len(all))
+	known := make([]string, 0, len(all))
+	for _, d := range all {
+		byID[d.ID] = d
+		known = append(known, d.ID)
+	}
+
+	var selected []Diagnoser
+	for _, id := range ids {
+		d, ok := byID[strings.ToLower(strings.TrimSpace(id))]
+		if !ok {
+			return nil, fmt.Errorf("unknown diagnosis %q (available: %s)", id, strings.Join(known, ", "))
+		}
+		selected = append(selected, d)
+	}
+	return selected, nil
+}
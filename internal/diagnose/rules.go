@@ -0,0 +1,73 @@
+package diagnose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/rules"
+)
+
+// ruleSet holds the declarative diagnosis rules; the built-in ones until
+// SetRules is called
+var ruleSet = mustBuiltinRules()
+
+func mustBuiltinRules() *rules.Set {
+	set, err := rules.Builtin()
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// SetRules replaces the rules every diagnosis applies. Each rule must
+// belong to one of the diagnoses
+func SetRules(set *rules.Set) error {
+	known := make(map[string]bool)
+	var ids []string
+	for _, d := range Diagnosers() {
+		known[d.ID] = true
+		ids = append(ids, d.ID)
+	}
+	for _, rule := range set.Rules() {
+		if !known[rule.Diagnosis] {
+			return fmt.Errorf("rule %s (%s): unknown diagnosis %q (available: %s)",
+				rule.ID, rule.Source, rule.Diagnosis, strings.Join(ids, ", "))
+		}
+	}
+	ruleSet = set
+	return nil
+}
+
+// Rules returns the rules the diagnoses apply
+func Rules() *rules.Set {
+	return ruleSet
+}
+
+// applyRules adds the findings and fixes of the rules belonging to the
+// diagnosis. A fix that is already offered is not repeated
+func (d *Diagnosis) applyRules() {
+	for _, rule := range ruleSet.ForDiagnosis(d.Category) {
+		found, offered, err := rule.Evaluate(facts, thresholds)
+		if err != nil {
+			d.Add(finding.New("rules.failed", finding.SeverityWarning, err.Error()).With("source", rule.Source))
+			continue
+		}
+		d.Add(found...)
+		for _, fix := range offered {
+			if !d.offers(fix.ID) {
+				d.Fixes = append(d.Fixes, fix)
+			}
+		}
+	}
+}
+
+// offers reports whether the diagnosis already offers a fix
+func (d *Diagnosis) offers(fixID string) bool {
+	for _, fix := range d.Fixes {
+		if fix.ID == fixID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package diagnose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/internal/rules"
+)
+
+// useRules loads the built-in rules plus a pack for the rest of the test
+func useRules(t *testing.T, pack string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "site.yaml"), []byte(pack), 0644); err != nil {
+		t.Fatal(err)
+	}
+	set, err := rules.Load([]string{dir})
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	previous := ruleSet
+	if err := SetRules(set); err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+	t.Cleanup(func() { ruleSet = previous })
+}
+
+// useCommands replays a machine that only knows the given commands
+func useCommands(t *testing.T, fixture *machine.Fixture) {
+	t.Helper()
+	previous := sys
+	SetMachine(fixture.Machine())
+	t.Cleanup(func() { SetMachine(previous) })
+}
+
+func findingsWithID(d Diagnosis, id string) []finding.Finding {
+	var found []finding.Finding
+	for _, f := range d.Findings {
+		if f.ID == id {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+func fixWithID(d Diagnosis, id string) *fixes.Fix {
+	for _, fix := range d.Fixes {
+		if fix.ID == id {
+			return fix
+		}
+	}
+	return nil
+}
+
+func TestJournalRule(t *testing.T) {
+	fixture := machine.NewFixture()
+	fixture.AddCommand("Archived and active journals take up 1.5G in the file system.\n", 0, "journalctl", "--disk-usage")
+	useCommands(t, fixture)
+
+	diagnosis := DiagnoseLogIssues()
+	found := findingsWithID(diagnosis, "logs.journal_large")
+	if len(found) != 1 {
+		t.Fatalf("logs.journal_large findings = %v", found)
+	}
+	if found[0].Summary != "systemd journal is using 1536.0 MB of disk space" || found[0].Evidence["size_mb"] != "1536.0" {
+		t.Errorf("finding = %+v", found[0])
+	}
+	if found[0].Category != "logs" {
+		t.Errorf("Category = %q, want logs", found[0].Category)
+	}
+
+	fix := fixWithID(diagnosis, "vacuum_journal_time")
+	if fix == nil || fix.Title != "Clean Old Journal Entries (30 days)" || fix.Commands[0] != "journalctl --vacuum-time=30d" || !fix.RequiresRoot {
+		t.Errorf("vacuum_journal_time = %+v", fix)
+	}
+	if fix := fixWithID(diagnosis, "vacuum_journal_size"); fix == nil || fix.Commands[0] != "journalctl --vacuum-size=500M" {
+		t.Errorf("vacuum_journal_size = %+v", fix)
+	}
+}
+
+func TestPackageConfigurationRule(t *testing.T) {
+	fixture := machine.NewFixture()
+	fixture.AddCommand("The following packages are only half configured:\n foo  Foo tools\n", 0, "dpkg", "--audit")
+	useCommands(t, fixture)
+
+	diagnosis := DiagnosePackageIssues()
+	found := findingsWithID(diagnosis, "packages.configuration")
+	if len(found) != 2 || found[1].Summary != "Package configuration issue: foo  Foo tools" {
+		t.Fatalf("packages.configuration findings = %v", found)
+	}
+	fix := fixWithID(diagnosis, "reconfigure_packages")
+	if fix == nil || fix.RiskLevel != fixes.RiskMedium {
+		t.Errorf("reconfigure_packages = %+v", fix)
+	}
+}
+
+func TestSiteRule(t *testing.T) {
+	useRules(t, `
+rules:
+  - id: services.runbook_nginx
+    diagnosis: services
+    severity: critical
+    when:
+      fact: failed_services
+      matches: '^nginx'
+    each: true
+    resource: unit
+    summary: '{{.Item}} is down; follow runbook WEB-7'
+    fixes:
+      - id: restart_web
+        title: Restart the web server
+        commands: ['systemctl restart {{quote .Items}}']
+        requires_root: true
+        risk: medium
+params:
+  logs.journal_large:
+    max_age_days: "7"
+`)
+	fixture := machine.NewFixture()
+	fixture.AddCommand("nginx.service loaded failed failed nginx\nnginx-debug.service loaded failed failed nginx\n", 0,
+		"systemctl", "list-units", "--failed", "--no-legend", "--plain", "--no-pager")
+	fixture.AddCommand("Archived and active journals take up 1.5G in the file system.\n", 0, "journalctl", "--disk-usage")
+	useCommands(t, fixture)
+
+	diagnosis := DiagnoseServiceIssues()
+	found := findingsWithID(diagnosis, "services.runbook_nginx")
+	if len(found) != 2 || found[0].Severity != finding.SeverityCritical || found[0].Resources[0].Name != "nginx.service" {
+		t.Fatalf("runbook findings = %+v", found)
+	}
+	fix := fixWithID(diagnosis, "restart_web")
+	if fix == nil || fix.Commands[0] != "systemctl restart nginx.service nginx-debug.service" {
+		t.Errorf("restart_web = %+v", fix)
+	}
+
+	logs := DiagnoseLogIssues()
+	if fix := fixWithID(logs, "vacuum_journal_time"); fix == nil || fix.Commands[0] != "journalctl --vacuum-time=7d" {
+		t.Errorf("vacuum_journal_time = %+v", fix)
+	}
+}
+
+func TestSetRulesUnknownDiagnosis(t *testing.T) {
+	dir := t.TempDir()
+	pack := "rules:\n  - id: kernel.taint\n    diagnosis: kernel\n    when: {fact: core_dumps, above: 0}\n    summary: Tainted\n"
+	if err := os.WriteFile(filepath.Join(dir, "kernel.yaml"), []byte(pack), 0644); err != nil {
+		t.Fatal(err)
+	}
+	set, err := rules.Load([]string{dir})
+	if err != nil {
+		t.Fatalf("rules.Load() error = %v", err)
+	}
+	if err := SetRules(set); err == nil || !strings.Contains(err.Error(), `unknown diagnosis "kernel"`) {
+		t.Errorf("SetRules() error = %v", err)
+	}
+	if Rules() != ruleSet || len(Rules().ForDiagnosis("kernel")) != 0 {
+		t.Error("SetRules() replaced the rules despite the error")
+	}
+}
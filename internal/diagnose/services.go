@@ -2,10 +2,10 @@ package diagnose
 
 import (
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
 
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
@@ -13,16 +13,18 @@ import (
 func DiagnoseServiceIssues() Diagnosis {
 	diagnosis := Diagnosis{
 		Issue:    "Service Issues",
-		Findings: []string{},
+		Category: "services",
+		Findings: []finding.Finding{},
 		Fixes:    []*fixes.Fix{},
 	}
 
 	// Check for failed services
 	failedServices := checkFailedSystemdServices()
 	if len(failedServices) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Failed services detected:")
 		for _, service := range failedServices {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", service))
+			diagnosis.Add(finding.New("services.failed", finding.SeverityError, "Failed service: "+service).
+				Affects(finding.ResourceUnit, service).
+				FixedBy("restart_failed_services", "check_service_logs"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -44,15 +46,17 @@ func DiagnoseServiceIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 	}
 
 	// Check for services in error state
 	errorServices := checkServicesInErrorState()
 	if len(errorServices) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Services in error state:")
 		for _, service := range errorServices {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", service))
+			diagnosis.Add(finding.New("services.error_state", finding.SeverityError, "Service in error state: "+service).
+				Affects(finding.ResourceUnit, service).
+				FixedBy("reset_error_services"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -72,9 +76,10 @@ func DiagnoseServiceIssues() Diagnosis {
 	// Check for disabled critical services
 	criticalServices := checkCriticalServices()
 	if len(criticalServices) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Critical services that are disabled:")
 		for _, service := range criticalServices {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", service))
+			diagnosis.Add(finding.New("services.critical_disabled", finding.SeverityWarning, "Critical service disabled: "+service).
+				Affects(finding.ResourceUnit, service).
+				FixedBy("enable_critical_services"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -92,9 +97,10 @@ func DiagnoseServiceIssues() Diagnosis {
 	// Check for services with high restart rates
 	flappingServices := checkFlappingServices()
 	if len(flappingServices) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Services with high restart rates (potentially flapping):")
 		for _, service := range flappingServices {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", service))
+			diagnosis.Add(finding.New("services.flapping", finding.SeverityWarning, "Service restarting frequently: "+service).
+				Affects(finding.ResourceUnit, service).
+				FixedBy("analyze_flapping_services", "stop_flapping_services"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -105,6 +111,7 @@ func DiagnoseServiceIssues() Diagnosis {
 			RequiresRoot: false,
 			Reversible:  false,
 			RiskLevel:   fixes.RiskLow,
+			Informational: true,
 		})
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -122,9 +129,10 @@ func DiagnoseServiceIssues() Diagnosis {
 	// Check for masked services
 	maskedServices := checkMaskedServices()
 	if len(maskedServices) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Masked services that may need attention:")
 		for _, service := range maskedServices {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", service))
+			diagnosis.Add(finding.New("services.masked", finding.SeverityInfo, "Masked service: "+service).
+				Affects(finding.ResourceUnit, service).
+				FixedBy("unmask_services"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -142,9 +150,10 @@ func DiagnoseServiceIssues() Diagnosis {
 	// Check for dependency issues
 	dependencyIssues := checkServiceDependencies()
 	if len(dependencyIssues) > 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "Service dependency issues:")
 		for _, issue := range dependencyIssues {
-			diagnosis.Findings = append(diagnosis.Findings, fmt.Sprintf("  - %s", issue))
+			diagnosis.Add(finding.New("services.dependency", finding.SeverityWarning, "Service dependency issue: "+issue).
+				WithExcerpt(issue).
+				FixedBy("reload_systemd_daemon"))
 		}
 
 		diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -175,6 +184,7 @@ func DiagnoseServiceIssues() Diagnosis {
 		RequiresRoot: false,
 		Reversible:  false,
 		RiskLevel:   fixes.RiskLow,
+		Informational: true,
 	})
 
 	diagnosis.Fixes = append(diagnosis.Fixes, &fixes.Fix{
@@ -189,10 +199,13 @@ func DiagnoseServiceIssues() Diagnosis {
 		RequiresRoot: false,
 		Reversible:  false,
 		RiskLevel:   fixes.RiskLow,
+		Informational: true,
 	})
 
+	diagnosis.applyRules()
+
 	if len(diagnosis.Findings) == 0 {
-		diagnosis.Findings = append(diagnosis.Findings, "No significant service issues detected")
+		diagnosis.Add(finding.New("services.ok", finding.SeverityInfo, "No significant service issues detected"))
 	}
 
 	return diagnosis
@@ -202,24 +215,9 @@ func DiagnoseServiceIssues() Diagnosis {
 func checkFailedSystemdServices() []string {
 	failed := []string{}
 
-	cmd := exec.Command("systemctl", "list-units", "--failed", "--type=service", "--no-legend")
-	output, err := cmd.Output()
-	if err != nil {
-		return failed
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 1 && strings.HasSuffix(fields[0], ".service") {
-			serviceName := strings.TrimSuffix(fields[0], ".service")
-			failed = append(failed, serviceName)
-		}
+	units, _ := facts.FailedUnitsOfType("service")
+	for _, unit := range units {
+		failed = append(failed, unit.BaseName())
 	}
 
 	return failed
@@ -229,8 +227,7 @@ func checkFailedSystemdServices() []string {
 func checkServicesInErrorState() []string {
 	errorServices := []string{}
 
-	cmd := exec.Command("systemctl", "list-units", "--type=service", "--state=activating,deactivating", "--no-legend")
-	output, err := cmd.Output()
+	output, err := sys.Output("systemctl", "list-units", "--type=service", "--state=activating,deactivating", "--no-legend")
 	if err != nil {
 		return errorServices
 	}
@@ -263,8 +260,7 @@ func checkCriticalServices() []string {
 	}
 
 	for _, service := range criticalServicesList {
-		cmd := exec.Command("systemctl", "is-enabled", service)
-		output, err := cmd.Output()
+		output, err := sys.Output("systemctl", "is-enabled", service)
 		if err != nil {
 			continue
 		}
@@ -272,8 +268,7 @@ func checkCriticalServices() []string {
 		status := strings.TrimSpace(string(output))
 		if status == "disabled" || status == "masked" {
 			// Double-check if service exists
-			checkCmd := exec.Command("systemctl", "status", service)
-			if checkCmd.Run() == nil {
+			if sys.Run("systemctl", "status", service) == nil {
 				disabled = append(disabled, service)
 			}
 		}
@@ -286,8 +281,7 @@ func checkCriticalServices() []string {
 func checkFlappingServices() []string {
 	flapping := []string{}
 
-	cmd := exec.Command("journalctl", "--since", "1 hour ago", "--grep", "Started\\|Stopped", "--no-pager")
-	output, err := cmd.Output()
+	output, err := sys.Output("journalctl", "--since", "1 hour ago", "--grep", "Started\\|Stopped", "--no-pager")
 	if err != nil {
 		return flapping
 	}
@@ -305,9 +299,10 @@ func checkFlappingServices() []string {
 		}
 	}
 
-	// Services with more than 6 events in the last hour are considered flapping
+	// Services with more start/stop events in the last hour than the
+	// configured limit are considered flapping
 	for service, count := range serviceEvents {
-		if count > 6 {
+		if count > thresholds.ServiceRestartsPerHour {
 			flapping = append(flapping, service)
 		}
 	}
@@ -319,24 +314,9 @@ func checkFlappingServices() []string {
 func checkMaskedServices() []string {
 	masked := []string{}
 
-	cmd := exec.Command("systemctl", "list-unit-files", "--type=service", "--state=masked", "--no-legend")
-	output, err := cmd.Output()
-	if err != nil {
-		return masked
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 1 && strings.HasSuffix(fields[0], ".service") {
-			serviceName := strings.TrimSuffix(fields[0], ".service")
-			masked = append(masked, serviceName)
-		}
+	units, _ := facts.UnitFilesInState("service", "masked")
+	for _, unit := range units {
+		masked = append(masked, strings.TrimSuffix(unit, ".service"))
 	}
 
 	return masked
@@ -347,8 +327,7 @@ func checkServiceDependencies() []string {
 	issues := []string{}
 
 	// Check for circular dependencies
-	cmd := exec.Command("systemd-analyze", "verify")
-	output, err := cmd.CombinedOutput()
+	output, err := sys.CombinedOutput("systemd-analyze", "verify")
 	if err != nil {
 		content := string(output)
 		if strings.Contains(content, "circular") || strings.Contains(content, "dependency") {
@@ -400,18 +379,4 @@ func generateFlappingAnalysisCommands(services []string) []string {
 		commands = append(commands, fmt.Sprintf("journalctl -u %s --since '2 hours ago' | grep -E '(Started|Stopped|Failed)' | tail -10", service))
 	}
 	return commands
-}
-
-func removeDuplicateServiceStrings(slice []string) []string {
-	keys := make(map[string]bool)
-	result := []string{}
-
-	for _, item := range slice {
-		if !keys[item] {
-			keys[item] = true
-			result = append(result, item)
-		}
-	}
-
-	return result
 }
\ No newline at end of file
@@ -3,8 +3,22 @@ package diagnose
 import (
 	"strings"
 	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
 )
 
+// useFixture replays a recorded fixture from testdata for the rest of the test
+func useFixture(t *testing.T, name string) {
+	t.Helper()
+	fixture, err := machine.LoadFixture("testdata/" + name)
+	if err != nil {
+		t.Fatalf("LoadFixture() error = %v", err)
+	}
+	previous := sys
+	SetMachine(fixture.Machine())
+	t.Cleanup(func() { SetMachine(previous) })
+}
+
 func TestDiagnoseServiceIssues(t *testing.T) {
 	diagnosis := DiagnoseServiceIssues()
 
@@ -340,67 +354,6 @@ func TestGenerateFlappingAnalysisCommands(t *testing.T) {
 	}
 }
 
-func TestRemoveDuplicateServiceStrings(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    []string
-		expected []string
-	}{
-		{
-			name:     "no duplicates",
-			input:    []string{"a", "b", "c"},
-			expected: []string{"a", "b", "c"},
-		},
-		{
-			name:     "with duplicates",
-			input:    []string{"a", "b", "a", "c", "b"},
-			expected: []string{"a", "b", "c"},
-		},
-		{
-			name:     "empty slice",
-			input:    []string{},
-			expected: []string{},
-		},
-		{
-			name:     "all duplicates",
-			input:    []string{"a", "a", "a"},
-			expected: []string{"a"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := removeDuplicateServiceStrings(tt.input)
-
-			if len(result) != len(tt.expected) {
-				t.Errorf("Expected length %d, got %d", len(tt.expected), len(result))
-			}
-
-			// Convert to maps for easier comparison
-			expectedMap := make(map[string]bool)
-			for _, item := range tt.expected {
-				expectedMap[item] = true
-			}
-
-			resultMap := make(map[string]bool)
-			for _, item := range result {
-				resultMap[item] = true
-			}
-
-			for item := range expectedMap {
-				if !resultMap[item] {
-					t.Errorf("Expected item '%s' not found in result", item)
-				}
-			}
-
-			for item := range resultMap {
-				if !expectedMap[item] {
-					t.Errorf("Unexpected item '%s' found in result", item)
-				}
-			}
-		})
-	}
-}
 
 func TestServiceDiagnosisFixTypes(t *testing.T) {
 	diagnosis := DiagnoseServiceIssues()
@@ -581,4 +534,48 @@ func TestServiceDiagnosisCommandSafety(t *testing.T) {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+func TestDiagnoseServiceIssuesFromFixture(t *testing.T) {
+	useFixture(t, "failing-services.jsonl")
+
+	if got := strings.Join(checkFailedSystemdServices(), ","); got != "nginx,postgresql@15-main" {
+		t.Errorf("checkFailedSystemdServices() = %s", got)
+	}
+	if got := strings.Join(checkFlappingServices(), ","); got != "worker" {
+		t.Errorf("checkFlappingServices() = %s, want only worker (8 events > 6)", got)
+	}
+	if got := strings.Join(checkMaskedServices(), ","); got != "bluetooth" {
+		t.Errorf("checkMaskedServices() = %s", got)
+	}
+
+	diagnosis := DiagnoseServiceIssues()
+	fixIDs := map[string]bool{}
+	for _, fix := range diagnosis.Fixes {
+		fixIDs[fix.ID] = true
+		if fix.ID == "restart_failed_services" && fix.Commands[0] != "systemctl restart nginx postgresql@15-main" {
+			t.Errorf("restart_failed_services commands = %v", fix.Commands)
+		}
+	}
+	for _, want := range []string{"restart_failed_services", "check_service_logs"} {
+		if !fixIDs[want] {
+			t.Errorf("Expected fix %s, got %v", want, fixIDs)
+		}
+	}
+	if fixIDs["reset_error_services"] {
+		t.Error("No services were activating, reset_error_services should not be offered")
+	}
+}
+
+func TestFlappingThresholdFromConfig(t *testing.T) {
+	useFixture(t, "failing-services.jsonl")
+	previous := thresholds
+	t.Cleanup(func() { SetThresholds(previous) })
+
+	limits := previous
+	limits.ServiceRestartsPerHour = 10
+	SetThresholds(limits)
+
+	if flapping := checkFlappingServices(); len(flapping) != 0 {
+		t.Errorf("checkFlappingServices() = %v with a limit of 10 restarts", flapping)
+	}
+}
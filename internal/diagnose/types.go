@@ -1,17 +1,42 @@
 package diagnose
 
-import "github.com/debian-doctor/debian-doctor/internal/fixes"
+import (
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+)
 
 // Diagnosis represents the result of diagnosing an issue
 type Diagnosis struct {
 	Issue    string
-	Findings []string
+	Category string // Filled into findings added without one
+	Findings []finding.Finding
 	Fixes    []*fixes.Fix
 }
 
+// Add records findings, filling in the diagnosis category
+func (d *Diagnosis) Add(findings ...finding.Finding) {
+	for _, f := range findings {
+		if f.Category == "" {
+			f.Category = d.Category
+		}
+		d.Findings = append(d.Findings, f)
+	}
+}
+
+// Summaries renders the findings as text, one line each
+func (d Diagnosis) Summaries() []string {
+	return finding.Summaries(d.Findings)
+}
+
+// Severity is the most severe finding's severity
+func (d Diagnosis) Severity() finding.Severity {
+	return finding.Highest(d.Findings)
+}
+
 // DiagnosisResult contains both the diagnosis and execution status
 type DiagnosisResult struct {
 	Diagnosis *Diagnosis
 	FixExecuted bool
 	ExecutionError error
-}
\ No newline at end of file
+	Run *fixes.FixRun // Journal record of the fix run, if one was started
+}
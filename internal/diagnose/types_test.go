@@ -3,6 +3,7 @@ package diagnose
 import (
 	"testing"
 	
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
 )
 
@@ -32,9 +33,13 @@ func TestDiagnosisTypes(t *testing.T) {
 	// Test Diagnosis struct
 	diagnosis := Diagnosis{
 		Issue:    "Test Issue",
-		Findings: []string{"Finding 1", "Finding 2"},
+		Category: "test",
 		Fixes:    []*fixes.Fix{fix},
 	}
+	diagnosis.Add(
+		finding.New("test.first", finding.SeverityInfo, "Finding 1"),
+		finding.New("test.second", finding.SeverityWarning, "Finding 2").FixedBy("test_fix"),
+	)
 	
 	if diagnosis.Issue != "Test Issue" {
 		t.Errorf("Expected issue 'Test Issue', got '%s'", diagnosis.Issue)
@@ -48,7 +53,15 @@ func TestDiagnosisTypes(t *testing.T) {
 		t.Errorf("Expected 1 fix, got %d", len(diagnosis.Fixes))
 	}
 	
-	if diagnosis.Findings[0] != "Finding 1" {
-		t.Errorf("Expected first finding 'Finding 1', got '%s'", diagnosis.Findings[0])
+	if diagnosis.Findings[0].Summary != "Finding 1" {
+		t.Errorf("Expected first finding 'Finding 1', got '%s'", diagnosis.Findings[0].Summary)
+	}
+	
+	if diagnosis.Findings[1].Category != "test" {
+		t.Errorf("Expected category 'test', got '%s'", diagnosis.Findings[1].Category)
+	}
+	
+	if diagnosis.Severity() != finding.SeverityWarning {
+		t.Errorf("Expected severity warning, got %s", diagnosis.Severity())
 	}
 }
\ No newline at end of file
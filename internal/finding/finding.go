@@ -0,0 +1,216 @@
+// Package finding is the structured result shared by checks and diagnoses:
+// one problem or observation, what it affects, the evidence for it and the
+// fixes that address it
+package finding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity levels for findings and check results
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// MarshalText renders the severity as its lowercase name so that JSON and
+// YAML reports stay readable
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a severity name produced by MarshalText
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// ParseSeverity converts a severity name into a Severity
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "info", "ok":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q", name)
+}
+
+// Resource kinds
+const (
+	ResourcePackage   = "package"
+	ResourceUnit      = "unit"
+	ResourcePath      = "path"
+	ResourceDevice    = "device"
+	ResourceInterface = "interface"
+	ResourceProcess   = "process"
+	ResourceHost      = "host"
+)
+
+// Resource is something a finding is about, such as a package or a unit
+type Resource struct {
+	Kind string `json:"kind" yaml:"kind"`
+	Name string `json:"name" yaml:"name"`
+}
+
+func (r Resource) String() string {
+	return r.Kind + ":" + r.Name
+}
+
+// Finding is one problem or observation. ID names the kind of finding, e.g.
+// packages.broken, and stays the same for every package it is reported
+// against; Key tells individual findings apart
+type Finding struct {
+	ID        string            `json:"id" yaml:"id"`
+	Severity  Severity          `json:"severity" yaml:"severity"`
+	Category  string            `json:"category" yaml:"category"`
+	Summary   string            `json:"summary" yaml:"summary"`
+	Evidence  map[string]string `json:"evidence,omitempty" yaml:"evidence,omitempty"`
+	Excerpt   string            `json:"excerpt,omitempty" yaml:"excerpt,omitempty"` // Raw log or command output
+	Resources []Resource        `json:"resources,omitempty" yaml:"resources,omitempty"`
+	FixIDs    []string          `json:"fix_ids,omitempty" yaml:"fix_ids,omitempty"`
+}
+
+// New creates a finding. The category is normally filled in by whoever
+// collects it
+func New(id string, severity Severity, summary string) Finding {
+	return Finding{ID: id, Severity: severity, Summary: summary}
+}
+
+// Newf creates a finding with a formatted summary
+func Newf(id string, severity Severity, format string, args ...interface{}) Finding {
+	return New(id, severity, fmt.Sprintf(format, args...))
+}
+
+// With adds a piece of evidence
+func (f Finding) With(key string, value interface{}) Finding {
+	evidence := make(map[string]string, len(f.Evidence)+1)
+	for k, v := range f.Evidence {
+		evidence[k] = v
+	}
+	evidence[key] = fmt.Sprint(value)
+	f.Evidence = evidence
+	return f
+}
+
+// WithExcerpt attaches the raw text the finding was derived from
+func (f Finding) WithExcerpt(excerpt string) Finding {
+	f.Excerpt = strings.TrimSpace(excerpt)
+	return f
+}
+
+// Affects adds a resource the finding is about
+func (f Finding) Affects(kind, name string) Finding {
+	f.Resources = append(append([]Resource(nil), f.Resources...), Resource{Kind: kind, Name: name})
+	return f
+}
+
+// FixedBy links the finding to the fixes that address it
+func (f Finding) FixedBy(fixIDs ...string) Finding {
+	f.FixIDs = append(append([]string(nil), f.FixIDs...), fixIDs...)
+	return f
+}
+
+// Key identifies a finding for de-duplication: its ID and resources
+func (f Finding) Key() string {
+	parts := make([]string, 0, len(f.Resources)+1)
+	for _, r := range f.Resources {
+		parts = append(parts, r.String())
+	}
+	sort.Strings(parts)
+	return f.ID + "|" + strings.Join(parts, ",")
+}
+
+// String is the finding's text rendering
+func (f Finding) String() string {
+	return f.Summary
+}
+
+// Summaries renders findings as text, one line each
+func Summaries(findings []Finding) []string {
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = f.String()
+	}
+	return lines
+}
+
+// Highest returns the most severe finding's severity, or SeverityInfo
+func Highest(findings []Finding) Severity {
+	highest := SeverityInfo
+	for _, f := range findings {
+		if f.Severity > highest {
+			highest = f.Severity
+		}
+	}
+	return highest
+}
+
+// Dedupe drops findings whose Key was already seen, keeping the first and
+// merging the fix IDs of the rest into it
+func Dedupe(findings []Finding) []Finding {
+	index := make(map[string]int, len(findings))
+	result := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		i, seen := index[f.Key()]
+		if !seen {
+			index[f.Key()] = len(result)
+			result = append(result, f)
+			continue
+		}
+		for _, id := range f.FixIDs {
+			if !contains(result[i].FixIDs, id) {
+				result[i].FixIDs = append(result[i].FixIDs, id)
+			}
+		}
+	}
+	return result
+}
+
+// Filter returns the findings at or above a severity
+func Filter(findings []Finding, min Severity) []Finding {
+	var result []Finding
+	for _, f := range findings {
+		if f.Severity >= min {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package finding
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuilderDoesNotShareState(t *testing.T) {
+	base := New("packages.broken", SeverityError, "Broken package").With("state", "iU")
+	a := base.Affects(ResourcePackage, "libfoo").FixedBy("fix_broken_packages")
+	b := base.Affects(ResourcePackage, "libbar").With("state", "iF")
+
+	if len(base.Resources) != 0 || len(base.FixIDs) != 0 {
+		t.Errorf("Base finding was modified: %+v", base)
+	}
+	if base.Evidence["state"] != "iU" {
+		t.Errorf("Base evidence was modified: %v", base.Evidence)
+	}
+	if a.Resources[0].Name != "libfoo" || b.Resources[0].Name != "libbar" {
+		t.Errorf("Resources leaked between findings: %v %v", a.Resources, b.Resources)
+	}
+	if len(b.FixIDs) != 0 {
+		t.Errorf("Fix IDs leaked between findings: %v", b.FixIDs)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	findings := []Finding{
+		New("services.failed", SeverityError, "Failed service: nginx").Affects(ResourceUnit, "nginx.service").FixedBy("restart"),
+		New("services.failed", SeverityError, "nginx failed").Affects(ResourceUnit, "nginx.service").FixedBy("restart", "logs"),
+		New("services.failed", SeverityError, "Failed service: cron").Affects(ResourceUnit, "cron.service"),
+	}
+
+	got := Dedupe(findings)
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 findings, got %d", len(got))
+	}
+	if got[0].Summary != "Failed service: nginx" {
+		t.Errorf("Expected the first finding to be kept, got %q", got[0].Summary)
+	}
+	if len(got[0].FixIDs) != 2 || got[0].FixIDs[1] != "logs" {
+		t.Errorf("Expected merged fix IDs [restart logs], got %v", got[0].FixIDs)
+	}
+}
+
+func TestHighestAndFilter(t *testing.T) {
+	findings := []Finding{
+		New("a", SeverityInfo, "a"),
+		New("b", SeverityError, "b"),
+		New("c", SeverityWarning, "c"),
+	}
+	if got := Highest(findings); got != SeverityError {
+		t.Errorf("Highest() = %s, want error", got)
+	}
+	if got := Highest(nil); got != SeverityInfo {
+		t.Errorf("Highest(nil) = %s, want info", got)
+	}
+	if got := Filter(findings, SeverityWarning); len(got) != 2 {
+		t.Errorf("Filter() returned %d findings, want 2", len(got))
+	}
+}
+
+func TestFindingJSON(t *testing.T) {
+	f := New("disk.full", SeverityCritical, "Disk / is 97% full").With("used_percent", 97).Affects(ResourcePath, "/")
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded Finding
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.Severity != SeverityCritical || decoded.Evidence["used_percent"] != "97" {
+		t.Errorf("Round trip lost data: %s", data)
+	}
+	if decoded.Key() != f.Key() {
+		t.Errorf("Key() = %q, want %q", decoded.Key(), f.Key())
+	}
+}
@@ -0,0 +1,326 @@
+package fixes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+)
+
+// Command is a fix command parsed with POSIX shell quoting rules.
+//
+// Simple commands are executed directly from Argv. Commands that rely on the
+// shell (pipelines, redirection, command lists, variable or glob expansion,
+// builtins) are flagged with Shell and executed through /bin/sh with Raw as
+// the script, so quoting is preserved exactly as written in the fix.
+type Command struct {
+	Raw   string   // Command exactly as written in the fix
+	Argv  []string // Words after quote removal
+	Shell bool     // Whether the command must be run by /bin/sh
+
+	tokens        []token
+	substitutions []string // Scripts inside $(...) and backticks
+}
+
+// CommandResult captures the outcome of running a single command
+type CommandResult struct {
+	Command  string
+	Stdout   string
+	Stderr   string
+	ExitCode int  // -1 when the command could not be started or was killed
+	TimedOut bool // Killed because it ran past its deadline
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// shellBuiltins are words that only make sense when interpreted by a shell
+var shellBuiltins = map[string]bool{
+	"for": true, "while": true, "until": true, "if": true, "case": true,
+	"cd": true, "export": true, "source": true, ".": true, "exit": true,
+	"set": true, "unset": true, "eval": true, "exec": true, "{": true,
+}
+
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// ParseCommand splits a command string into words and operators
+func ParseCommand(raw string) (Command, error) {
+	cmd := Command{Raw: strings.TrimSpace(raw)}
+	if cmd.Raw == "" {
+		return cmd, fmt.Errorf("empty command")
+	}
+
+	var (
+		word      strings.Builder
+		inWord    bool
+		expansion bool
+	)
+
+	flushWord := func() {
+		if inWord {
+			cmd.tokens = append(cmd.tokens, token{kind: tokenWord, value: word.String()})
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	runes := []rune(cmd.Raw)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flushWord()
+
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return cmd, fmt.Errorf("unterminated single quote in command: %s", cmd.Raw)
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				c := runes[i]
+				if c == '\\' && i+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[i+1]) {
+					i++
+					c = runes[i]
+				} else if c == '`' || (c == '$' && i+1 < len(runes) && runes[i+1] == '(') {
+					end := substitutionEnd(runes, i)
+					if end < 0 {
+						return cmd, fmt.Errorf("unterminated command substitution in command: %s", cmd.Raw)
+					}
+					cmd.substitutions = append(cmd.substitutions, substitutionScript(runes, i, end))
+					word.WriteString(string(runes[i : end+1]))
+					expansion = true
+					i = end
+					continue
+				} else if c == '$' {
+					expansion = true
+				}
+				word.WriteRune(c)
+			}
+			if i >= len(runes) {
+				return cmd, fmt.Errorf("unterminated double quote in command: %s", cmd.Raw)
+			}
+			inWord = true
+
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+
+		case strings.ContainsRune("|&;<>()", r):
+			op := string(r)
+			// An IO number such as the 2 in 2>/dev/null belongs to the operator
+			if (r == '<' || r == '>') && inWord && isDigits(word.String()) {
+				op = word.String() + op
+				word.Reset()
+				inWord = false
+			}
+			flushWord()
+			if i+1 < len(runes) {
+				next := runes[i+1]
+				if (next == r && strings.ContainsRune("|&;<>", r)) || (r == '>' && next == '&') || (r == '<' && next == '&') {
+					op += string(next)
+					i++
+				}
+			}
+			cmd.tokens = append(cmd.tokens, token{kind: tokenOperator, value: op})
+
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '(', r == '`':
+			end := substitutionEnd(runes, i)
+			if end < 0 {
+				return cmd, fmt.Errorf("unterminated command substitution in command: %s", cmd.Raw)
+			}
+			cmd.substitutions = append(cmd.substitutions, substitutionScript(runes, i, end))
+			word.WriteString(string(runes[i : end+1]))
+			inWord = true
+			expansion = true
+			i = end
+
+		default:
+			if strings.ContainsRune("$`*?[", r) || (r == '~' && !inWord) {
+				expansion = true
+			}
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	flushWord()
+
+	for _, tok := range cmd.tokens {
+		if tok.kind == tokenWord {
+			cmd.Argv = append(cmd.Argv, tok.value)
+		} else {
+			cmd.Shell = true
+		}
+	}
+
+	if len(cmd.Argv) == 0 {
+		return cmd, fmt.Errorf("command has no words: %s", cmd.Raw)
+	}
+
+	if expansion || shellBuiltins[cmd.Argv[0]] || assignmentPattern.MatchString(cmd.Argv[0]) {
+		cmd.Shell = true
+	}
+
+	return cmd, nil
+}
+
+// String returns the command as written in the fix
+func (c Command) String() string {
+	return c.Raw
+}
+
+// exec builds the process for this command, wiring output to the given
+// writers. The command gets a process group of its own so that cancelling
+// ctx also kills whatever a shell script started
+func (c Command) exec(ctx context.Context, stdout, stderr io.Writer) *exec.Cmd {
+	var cmd *exec.Cmd
+	if c.Shell {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", c.Raw)
+	} else {
+		cmd = exec.CommandContext(ctx, c.Argv[0], c.Argv[1:]...)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// Stop waiting for output held open by anything that escaped the group
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// Run executes the command, streaming its output to the terminal while also
+// capturing it in the returned result
+func (c Command) Run() (CommandResult, error) {
+	return c.RunTo(os.Stdout, os.Stderr)
+}
+
+// RunTo executes the command like Run, streaming its output to stdout and
+// stderr instead of the terminal
+func (c Command) RunTo(stdout, stderr io.Writer) (CommandResult, error) {
+	return c.RunContext(context.Background(), stdout, stderr)
+}
+
+// RunContext is RunTo for a command that is killed, along with everything
+// it started, when ctx is done. A command that ran past the deadline of ctx
+// is marked TimedOut
+func (c Command) RunContext(ctx context.Context, stdout, stderr io.Writer) (CommandResult, error) {
+	var outBuf, errBuf bytes.Buffer
+	result := CommandResult{Command: c.Raw, ExitCode: -1}
+
+	cmd := c.exec(ctx, io.MultiWriter(stdout, &outBuf), io.MultiWriter(stderr, &errBuf))
+	err := cmd.Run()
+
+	result.Stdout = outBuf.String()
+	result.Stderr = errBuf.String()
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil && err != nil {
+		result.TimedOut = errors.Is(ctxErr, context.DeadlineExceeded)
+		return result, fmt.Errorf("command killed: %w", ctxErr)
+	}
+
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return result, fmt.Errorf("command exited with code %d", result.ExitCode)
+		}
+		return result, err
+	}
+
+	return result, nil
+}
+
+// substitutionEnd finds the rune closing the command substitution that
+// starts at runes[start], honouring nesting and quotes
+func substitutionEnd(runes []rune, start int) int {
+	if runes[start] == '`' {
+		for i := start + 1; i < len(runes); i++ {
+			if runes[i] == '\\' {
+				i++
+			} else if runes[i] == '`' {
+				return i
+			}
+		}
+		return -1
+	}
+
+	depth := 0
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return -1
+			}
+			i = end
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// substitutionScript returns the script between the delimiters of a
+// command substitution
+func substitutionScript(runes []rune, start, end int) string {
+	if runes[start] == '`' {
+		return string(runes[start+1 : end])
+	}
+	return string(runes[start+2 : end])
+}
+
+func indexRune(runes []rune, from int, target rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
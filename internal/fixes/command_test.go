@@ -0,0 +1,243 @@
+package fixes
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		argv  []string
+		shell bool
+	}{
+		{
+			name:  "simple argv command",
+			raw:   "apt-get -f install",
+			argv:  []string{"apt-get", "-f", "install"},
+			shell: false,
+		},
+		{
+			name:  "single quotes are preserved as one word",
+			raw:   "journalctl -p err --since '24 hours ago' --no-pager",
+			argv:  []string{"journalctl", "-p", "err", "--since", "24 hours ago", "--no-pager"},
+			shell: false,
+		},
+		{
+			name:  "double quotes with escapes",
+			raw:   `echo "say \"hi\""`,
+			argv:  []string{"echo", `say "hi"`},
+			shell: false,
+		},
+		{
+			name:  "sed expression with escaped slash",
+			raw:   "sed -i '/\\/swapfile/d' /etc/fstab",
+			argv:  []string{"sed", "-i", "/\\/swapfile/d", "/etc/fstab"},
+			shell: false,
+		},
+		{
+			name:  "redirection to proc",
+			raw:   "echo 3 > /proc/sys/vm/drop_caches",
+			argv:  []string{"echo", "3", "/proc/sys/vm/drop_caches"},
+			shell: true,
+		},
+		{
+			name:  "append redirection with quoted text",
+			raw:   "echo '/swapfile none swap sw 0 0' >> /etc/fstab",
+			argv:  []string{"echo", "/swapfile none swap sw 0 0", "/etc/fstab"},
+			shell: true,
+		},
+		{
+			name:  "pipeline",
+			raw:   "ps aux --sort=-%cpu | head -20",
+			argv:  []string{"ps", "aux", "--sort=-%cpu", "head", "-20"},
+			shell: true,
+		},
+		{
+			name:  "pipeline with quoted regex",
+			raw:   "journalctl -u nginx --since '2 hours ago' | grep -E '(Started|Stopped|Failed)' | tail -10",
+			argv:  []string{"journalctl", "-u", "nginx", "--since", "2 hours ago", "grep", "-E", "(Started|Stopped|Failed)", "tail", "-10"},
+			shell: true,
+		},
+		{
+			name:  "stderr redirection",
+			raw:   "find / -type f -size +100M 2>/dev/null",
+			argv:  []string{"find", "/", "-type", "f", "-size", "+100M", "/dev/null"},
+			shell: true,
+		},
+		{
+			name:  "command substitution",
+			raw:   "tar -czf /root/backup_$(date +%Y%m%d).tar.gz /lost+found",
+			argv:  []string{"tar", "-czf", "/root/backup_$(date +%Y%m%d).tar.gz", "/lost+found"},
+			shell: true,
+		},
+		{
+			name:  "glob expansion",
+			raw:   "chmod 600 '/home/user/.ssh'/id_*",
+			argv:  []string{"chmod", "600", "/home/user/.ssh/id_*"},
+			shell: true,
+		},
+		{
+			name:  "shell keyword",
+			raw:   "for dir in /tmp /var; do echo $dir; done",
+			shell: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseCommand(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseCommand() error = %v", err)
+			}
+			if cmd.Shell != tt.shell {
+				t.Errorf("ParseCommand().Shell = %v, want %v", cmd.Shell, tt.shell)
+			}
+			if tt.argv != nil && !reflect.DeepEqual(cmd.Argv, tt.argv) {
+				t.Errorf("ParseCommand().Argv = %q, want %q", cmd.Argv, tt.argv)
+			}
+			if cmd.String() != tt.raw {
+				t.Errorf("ParseCommand().String() = %q, want %q", cmd.String(), tt.raw)
+			}
+		})
+	}
+}
+
+func TestParseCommandErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"echo 'unterminated",
+		`echo "unterminated`,
+		"| ;",
+	}
+
+	for _, raw := range tests {
+		if _, err := ParseCommand(raw); err == nil {
+			t.Errorf("ParseCommand(%q) expected error", raw)
+		}
+	}
+}
+
+func TestCommandRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		stdout   string
+		exitCode int
+		wantErr  bool
+	}{
+		{
+			name:     "argv command",
+			raw:      "echo 'hello  world'",
+			stdout:   "hello  world\n",
+			exitCode: 0,
+		},
+		{
+			name:     "pipeline",
+			raw:      "printf 'b\\na\\n' | sort | head -1",
+			stdout:   "a\n",
+			exitCode: 0,
+		},
+		{
+			name:     "non-zero exit",
+			raw:      "sh -c 'exit 3'",
+			exitCode: 3,
+			wantErr:  true,
+		},
+		{
+			name:     "missing binary",
+			raw:      "debian-doctor-no-such-binary --help",
+			exitCode: -1,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseCommand(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseCommand() error = %v", err)
+			}
+
+			result, err := cmd.Run()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.ExitCode != tt.exitCode {
+				t.Errorf("Run() exit code = %d, want %d", result.ExitCode, tt.exitCode)
+			}
+			if tt.stdout != "" && result.Stdout != tt.stdout {
+				t.Errorf("Run() stdout = %q, want %q", result.Stdout, tt.stdout)
+			}
+		})
+	}
+}
+
+func TestCommandRunRedirection(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "fstab")
+
+	cmd, err := ParseCommand("echo '/swapfile none swap sw 0 0' >> " + target)
+	if err != nil {
+		t.Fatalf("ParseCommand() error = %v", err)
+	}
+
+	if _, err := cmd.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read redirection target: %v", err)
+	}
+	if strings.TrimSpace(string(content)) != "/swapfile none swap sw 0 0" {
+		t.Errorf("Unexpected file content: %q", content)
+	}
+}
+
+func TestCommandRunCapturesStderr(t *testing.T) {
+	cmd, err := ParseCommand("ls /debian-doctor-missing-path")
+	if err != nil {
+		t.Fatalf("ParseCommand() error = %v", err)
+	}
+
+	result, err := cmd.Run()
+	if err == nil {
+		t.Fatal("Expected error for failing command")
+	}
+	if result.Stderr == "" {
+		t.Error("Expected stderr to be captured")
+	}
+	if result.ExitCode == 0 {
+		t.Error("Expected non-zero exit code")
+	}
+}
+
+func TestCommandRunContextTimeout(t *testing.T) {
+	// The shell waits on a child that must be killed along with it
+	cmd, err := ParseCommand("echo started; sleep 30 & wait")
+	if err != nil {
+		t.Fatalf("ParseCommand() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	result, err := cmd.RunContext(ctx, io.Discard, io.Discard)
+	if err == nil || !result.TimedOut {
+		t.Fatalf("RunContext() = %+v, %v; want a timeout", result, err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("RunContext() returned after %s", elapsed)
+	}
+	if result.Stdout != "started\n" {
+		t.Errorf("Stdout = %q", result.Stdout)
+	}
+}
@@ -1,13 +1,14 @@
 package fixes
 
 import (
-	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"strings"
-	"syscall"
+	"time"
 
+	"github.com/debian-doctor/debian-doctor/internal/probes"
 	"github.com/debian-doctor/debian-doctor/pkg/config"
 	"github.com/debian-doctor/debian-doctor/pkg/logger"
 )
@@ -22,6 +23,8 @@ type Fix struct {
 	Reversible  bool     // Whether the fix can be undone
 	ReverseCommands []string // Commands to reverse the fix (if reversible)
 	RiskLevel   RiskLevel // Risk assessment
+	Modifies    []string // Files the fix edits; snapshotted before it runs
+	Informational bool   // Only gathers information, so fix --auto never runs it
 }
 
 // RiskLevel indicates the safety level of a fix
@@ -48,6 +51,31 @@ func (r RiskLevel) String() string {
 	return "Unknown"
 }
 
+// ParseRiskLevel converts a risk level name such as "medium" to a RiskLevel
+func ParseRiskLevel(name string) (RiskLevel, error) {
+	for _, level := range []RiskLevel{RiskLow, RiskMedium, RiskHigh, RiskCritical} {
+		if strings.EqualFold(strings.TrimSpace(name), level.String()) {
+			return level, nil
+		}
+	}
+	return RiskLow, fmt.Errorf("unknown risk level %q (expected low, medium, high or critical)", name)
+}
+
+// MarshalText encodes the risk level by name
+func (r RiskLevel) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(r.String())), nil
+}
+
+// UnmarshalText decodes a risk level name
+func (r *RiskLevel) UnmarshalText(text []byte) error {
+	level, err := ParseRiskLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*r = level
+	return nil
+}
+
 func (r RiskLevel) Color() string {
 	switch r {
 		case RiskLow:
@@ -64,64 +92,318 @@ func (r RiskLevel) Color() string {
 
 // Executor handles the execution of system fixes
 type Executor struct {
-	config *config.Config
-	logger *logger.Logger
+	config    *config.Config
+	logger    *logger.Logger
+	policy    PolicyEngine
+	policyErr error // Set when the policy file is invalid; all fixes are refused
+
+	autoApprove bool      // Run fixes without confirmation...
+	maxRisk     RiskLevel // ...as long as they are at or below this risk
+
+	confirmer Confirmer                        // Asks whether fixes may run
+	progress  func(step StepResult, total int) // Called after each fix command
+	stdout    io.Writer                        // Where plans and command output go...
+	stderr    io.Writer                        // ...and where command errors go
+}
+
+// Confirmer decides whether a fix may run and whether a fix that failed part
+// way should be undone. Executors ask on the terminal unless given another,
+// see PromptConfirmer
+type Confirmer interface {
+	ConfirmFix(fix *Fix) bool
+	ConfirmReverse(fix *Fix, failedAt int) bool
 }
 
-// NewExecutor creates a new fix executor
+// NewExecutor creates a new fix executor using the configured fix policy
 func NewExecutor(cfg *config.Config, log *logger.Logger) *Executor {
-	return &Executor{
-		config: cfg,
-		logger: log,
+	executor := &Executor{
+		config:    cfg,
+		logger:    log,
+		confirmer: NewPromptConfirmer(os.Stdout, Terminal()),
+		stdout:    os.Stdout,
+		stderr:    os.Stderr,
 	}
+	
+	policy, err := LoadConfiguredPolicy(cfg)
+	if err != nil {
+		log.Error(fmt.Sprintf("Refusing to run fixes: %s", err))
+		executor.policyErr = err
+		policy = DefaultPolicy()
+	}
+	executor.policy = policy
+	
+	return executor
 }
 
-// ExecuteFix executes a fix with user confirmation and safety checks
-func (e *Executor) ExecuteFix(fix *Fix) error {
-	// Validate fix
-	if err := e.validateFix(fix); err != nil {
-		return fmt.Errorf("fix validation failed: %w", err)
+// SetPolicy replaces the policy fixes are evaluated against
+func (e *Executor) SetPolicy(policy PolicyEngine) {
+	e.policy = policy
+	e.policyErr = nil
+}
+
+// SetAutoApprove lets fixes at or below maxRisk run without confirmation.
+// Fixes above it, or above the policy's unattended ceiling, are refused
+// with a NeedsHumanError instead of prompting.
+func (e *Executor) SetAutoApprove(maxRisk RiskLevel) {
+	e.autoApprove = true
+	e.maxRisk = maxRisk
+}
+
+// AutoApproveCeiling returns the highest risk that runs without
+// confirmation: the lower of the SetAutoApprove level and the policy's
+// max_unattended_risk
+func (e *Executor) AutoApproveCeiling() RiskLevel {
+	ceiling := e.maxRisk
+	if policy, ok := e.policy.(*Policy); ok && policy.MaxUnattendedRisk < ceiling {
+		ceiling = policy.MaxUnattendedRisk
 	}
+	return ceiling
+}
 
-	// Check permissions
-	if fix.RequiresRoot && !e.config.IsRoot {
-		return fmt.Errorf("fix '%s' requires root privileges", fix.Title)
+// SetConfirmer replaces how fixes are confirmed, e.g. by a program
+// embedding the executor. Auto-approved fixes never ask
+func (e *Executor) SetConfirmer(confirmer Confirmer) {
+	e.confirmer = confirmer
+}
+
+// SetOutput streams dry-run plans and the output of fix commands to w
+// instead of the terminal; nil discards them. Runs record the output either way
+func (e *Executor) SetOutput(w io.Writer) {
+	if w == nil {
+		w = io.Discard
 	}
+	e.stdout = w
+	e.stderr = w
+}
+
+// SetProgress calls progress after each fix command has run, with the
+// number of commands in the fix
+func (e *Executor) SetProgress(progress func(step StepResult, total int)) {
+	e.progress = progress
+}
+
+// NeedsHumanError is returned when a fix may only run after a person has
+// approved it
+type NeedsHumanError struct {
+	Fix    string
+	Reason string
+}
+
+func (e *NeedsHumanError) Error() string {
+	return fmt.Sprintf("fix '%s' needs human approval: %s", e.Fix, e.Reason)
+}
+
+// ExecuteFix executes a fix with user confirmation and safety checks. The
+// returned run records every step that was attempted; it is nil only when
+// the fix was rejected before anything could run.
+//
+// In dry-run mode the fix is described instead of run. It is still refused
+// the way a real run would refuse it, so a dry run shows which fixes would
+// be denied or wait for a person
+func (e *Executor) ExecuteFix(fix *Fix) (*FixRun, error) {
+	if e.config.DryRun {
+		plan, err := e.Plan(fix)
+		if err != nil {
+			return nil, err
+		}
+		plan.Render(e.stdout)
+		if err := e.admit(fix); err != nil {
+			return nil, err
+		}
+		run := newFixRun(fix)
+		run.finish(RunDryRun, nil)
+		return run, nil
+	}
+
+	if err := e.admit(fix); err != nil {
+		return nil, err
+	}
+
+	run := newFixRun(fix)
 
 	// Show fix details and get confirmation
-	if !e.config.NonInteractive {
-		if !e.confirmExecution(fix) {
+	switch {
+	case e.autoApprove:
+		e.logger.Info(fmt.Sprintf("Auto-approved %s risk fix: %s", fix.RiskLevel, fix.Title))
+	default:
+		if !e.confirmer.ConfirmFix(fix) {
 			e.logger.Info("Fix execution cancelled by user")
-			return nil
+			run.finish(RunCancelled, nil)
+			return run, nil
+		}
+	}
+
+	// Snapshot the files the fix declares it will modify
+	var snapshot *Snapshot
+	if len(fix.Modifies) > 0 {
+		var err error
+		snapshot, err = e.Snapshots().Take(run.ID, fix.ID, fix.Modifies)
+		if err != nil {
+			err = fmt.Errorf("refusing to run fix without a snapshot: %w", err)
+			run.finish(RunFailed, err)
+			e.record(run)
+			return run, err
+		}
+		for _, file := range snapshot.Files {
+			run.Snapshots = append(run.Snapshots, file.Path)
 		}
+		defer e.seal(snapshot)
 	}
 
 	// Execute the fix
-	e.logger.Info(fmt.Sprintf("Executing fix: %s", fix.Title))
+	e.logger.Info(fmt.Sprintf("Executing fix: %s (run %s)", fix.Title, run.ID))
 	
 	for i, cmd := range fix.Commands {
 		e.logger.Info(fmt.Sprintf("Running command %d/%d: %s", i+1, len(fix.Commands), cmd))
 		
-		if err := e.executeCommand(cmd); err != nil {
+		started := time.Now()
+		result, err := e.executeCommand(cmd)
+		run.Steps = append(run.Steps, newStepResult(i+1, started, result, err))
+		if e.progress != nil {
+			e.progress(run.Steps[len(run.Steps)-1], len(fix.Commands))
+		}
+
+		if err != nil {
 			e.logger.Error(fmt.Sprintf("Command failed: %s", err))
 			
-			// If this is not the first command, offer to reverse
-			if i > 0 && fix.Reversible {
+			// If this is not the first command, offer to reverse. Snapshotted
+			// files may have been changed by any step, including the first.
+			if (i > 0 && fix.Reversible) || snapshot != nil {
+				run.Reversal = &ReversalResult{}
 				if e.offerReverse(fix, i) {
-					e.reverseFix(fix, i-1)
+					run.Reversal = e.reverseFix(fix, i-1, snapshot)
 				}
 			}
 			
-			return fmt.Errorf("fix execution failed at command %d: %w", i+1, err)
+			err = fmt.Errorf("fix execution failed at command %d: %w", i+1, err)
+			run.finish(RunFailed, err)
+			e.record(run)
+			return run, err
 		}
 	}
 
 	e.logger.Info(fmt.Sprintf("Fix '%s' executed successfully", fix.Title))
-	return nil
+	run.finish(RunSucceeded, nil)
+	e.record(run)
+	return run, nil
+}
+
+// Journal returns the journal that fix runs are recorded in
+func (e *Executor) Journal() *Journal {
+	return NewJournal(e.config.LogDir)
+}
+
+// Snapshots returns the store that file snapshots are kept in
+func (e *Executor) Snapshots() *SnapshotStore {
+	return NewSnapshotStore(e.config.StateDir)
+}
+
+// seal records how snapshotted files looked when the run finished
+func (e *Executor) seal(snapshot *Snapshot) {
+	if err := snapshot.Seal(); err != nil {
+		e.logger.Warning(fmt.Sprintf("Failed to seal snapshot for run %s: %s", snapshot.RunID, err))
+	}
+}
+
+// Rollback restores the files snapshotted before the given run. Files edited
+// since the run finished are only overwritten when force is set.
+func (e *Executor) Rollback(runID string, force bool) (*FixRun, error) {
+	snapshot, err := e.Snapshots().Load(runID)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapshot.Verify(); err != nil {
+		return nil, err
+	}
+	if conflicts := snapshot.Conflicts(); len(conflicts) > 0 && !force {
+		return nil, fmt.Errorf("files changed since run %s finished: %s (use --force to overwrite)", runID, strings.Join(conflicts, ", "))
+	}
+
+	run := newFixRun(&Fix{
+		ID:        "rollback",
+		Title:     fmt.Sprintf("Rollback of run %s", runID),
+		RiskLevel: RiskMedium,
+	})
+	e.logger.Info(fmt.Sprintf("Rolling back run %s (run %s)", runID, run.ID))
+
+	run.Steps = e.restoreSnapshot(snapshot)
+	for _, step := range run.Steps {
+		if step.Error != "" {
+			err := fmt.Errorf("rollback of run %s was incomplete", runID)
+			run.finish(RunFailed, err)
+			e.record(run)
+			return run, err
+		}
+	}
+
+	run.finish(RunSucceeded, nil)
+	e.record(run)
+	return run, nil
+}
+
+// restoreSnapshot restores every file in a snapshot, one step per file
+func (e *Executor) restoreSnapshot(snapshot *Snapshot) []StepResult {
+	var steps []StepResult
+	for i, file := range snapshot.Files {
+		action := "restore"
+		if !file.Existed {
+			action = "remove"
+		}
+		e.logger.Info(fmt.Sprintf("Rollback: %s %s", action, file.Path))
+
+		step := StepResult{Step: i + 1, Command: fmt.Sprintf("%s %s", action, file.Path), StartedAt: time.Now()}
+		if err := snapshot.RestoreFile(file); err != nil {
+			e.logger.Error(err.Error())
+			step.ExitCode = 1
+			step.Error = err.Error()
+		}
+		step.EndedAt = time.Now()
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// record appends a run to the journal. Failing to journal must not turn a
+// successful fix into a failed one, so errors are only logged. The run may
+// have changed the machine, so facts gathered before it are forgotten.
+func (e *Executor) record(run *FixRun) {
+	probes.Invalidate()
+	if err := e.Journal().Append(run); err != nil {
+		e.logger.Warning(fmt.Sprintf("Failed to record fix run %s: %s", run.ID, err))
+	}
 }
 
 // validateFix performs safety checks on a fix
 func (e *Executor) validateFix(fix *Fix) error {
+	if err := e.checkFix(fix); err != nil {
+		return err
+	}
+	
+	if err := e.checkPolicy(fix); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// admit decides whether a fix may run at all: it must be valid, allowed by
+// the policy and within reach of our privileges. Without a person to ask,
+// only fixes covered by auto-approval may run
+func (e *Executor) admit(fix *Fix) error {
+	if err := e.validateFix(fix); err != nil {
+		return fmt.Errorf("fix validation failed: %w", err)
+	}
+	if fix.RequiresRoot && !e.config.IsRoot {
+		return fmt.Errorf("fix '%s' requires root privileges", fix.Title)
+	}
+	if !e.autoApprove && e.config.NonInteractive {
+		return &NeedsHumanError{Fix: fix.Title, Reason: "running non-interactively without auto-approval"}
+	}
+	return nil
+}
+
+// checkFix verifies that a fix is well formed
+func (e *Executor) checkFix(fix *Fix) error {
 	if fix == nil {
 		return fmt.Errorf("fix is nil")
 	}
@@ -134,101 +416,95 @@ func (e *Executor) validateFix(fix *Fix) error {
 		return fmt.Errorf("fix has no commands")
 	}
 
-	// Check for dangerous commands
-	dangerousPatterns := []string{
-		"rm -rf /",
-		"dd if=",
-		"mkfs",
-		"fdisk",
-		"parted",
-		"> /dev/",
+	return nil
+}
+
+// checkPolicy evaluates a fix against the policy. Auto-approved runs are
+// unattended and therefore also subject to the risk ceilings.
+func (e *Executor) checkPolicy(fix *Fix) error {
+	if e.policyErr != nil {
+		return fmt.Errorf("fix policy could not be loaded: %w", e.policyErr)
 	}
 
-	for _, cmd := range fix.Commands {
-		for _, pattern := range dangerousPatterns {
-			if strings.Contains(strings.ToLower(cmd), pattern) {
-				return fmt.Errorf("dangerous command detected: %s", cmd)
+	decision := e.policy.Evaluate(fix, e.autoApprove)
+	if !decision.Allowed {
+		// A fix that is only too risky to run unattended is not forbidden;
+		// it just has to wait for a person
+		var riskOnly []string
+		for _, violation := range decision.Violations {
+			if violation.Rule == "max_unattended_risk" {
+				riskOnly = append(riskOnly, violation.Reason)
 			}
 		}
-	}
+		if len(riskOnly) == len(decision.Violations) {
+			return &NeedsHumanError{Fix: fix.Title, Reason: strings.Join(riskOnly, "; ")}
+		}
 
-	return nil
-}
+		for _, violation := range decision.Violations {
+			e.logger.Warning(fmt.Sprintf("Policy denied '%s': %s", fix.Title, violation))
+		}
+		return &PolicyError{Fix: fix.Title, Violations: decision.Violations}
+	}
 
-// confirmExecution shows fix details and asks for user confirmation
-func (e *Executor) confirmExecution(fix *Fix) bool {
-	fmt.Printf("\n🔧 Fix Details:\n")
-	fmt.Printf("Title: %s\n", fix.Title)
-	fmt.Printf("Description: %s\n", fix.Description)
-	fmt.Printf("Risk Level: %s\n", fix.RiskLevel.String())
-	fmt.Printf("Requires Root: %t\n", fix.RequiresRoot)
-	fmt.Printf("Reversible: %t\n", fix.Reversible)
-	
-	fmt.Printf("\nCommands to execute:\n")
-	for i, cmd := range fix.Commands {
-		fmt.Printf("  %d. %s\n", i+1, cmd)
+	if e.autoApprove && fix.Informational {
+		return &NeedsHumanError{Fix: fix.Title, Reason: "it only gathers information, which nobody reads in an unattended run"}
 	}
 
-	if fix.RiskLevel >= RiskHigh {
-		fmt.Printf("\n⚠️  WARNING: This is a %s risk operation!\n", fix.RiskLevel.String())
-		fmt.Printf("Please review the commands carefully before proceeding.\n")
+	if e.autoApprove && fix.RiskLevel > e.maxRisk {
+		return &NeedsHumanError{
+			Fix:    fix.Title,
+			Reason: fmt.Sprintf("%s risk is above the auto-approve ceiling of %s", fix.RiskLevel, e.maxRisk),
+		}
 	}
 
-	fmt.Printf("\nDo you want to proceed? (y/N): ")
-	reader := bufio.NewReader(os.Stdin)
-	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
-	
-	return response == "y" || response == "yes"
+	return nil
 }
 
-// executeCommand runs a single shell command
-func (e *Executor) executeCommand(cmdStr string) error {
-	// Split command into parts
-	parts := strings.Fields(cmdStr)
-	if len(parts) == 0 {
-		return fmt.Errorf("empty command")
+// executeCommand parses and runs a single fix command
+func (e *Executor) executeCommand(cmdStr string) (CommandResult, error) {
+	cmd, err := ParseCommand(cmdStr)
+	if err != nil {
+		return CommandResult{Command: cmdStr, ExitCode: -1}, err
 	}
 
-	// Create command
-	cmd := exec.Command(parts[0], parts[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	// Run command
-	err := cmd.Run()
-	if err != nil {
-		// Check if it's an exit error to get the exit code
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-				return fmt.Errorf("command exited with code %d", status.ExitStatus())
-			}
-		}
-		return err
+	if e.config.Verbose && cmd.Shell {
+		e.logger.Debug("Running through /bin/sh: %s", cmd.Raw)
 	}
 
-	return nil
+	timeout := e.config.Runner.FixCommandTimeout
+	if timeout <= 0 {
+		timeout = config.DefaultRunnerSettings().FixCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := cmd.RunContext(ctx, e.stdout, e.stderr)
+	if result.TimedOut {
+		e.logger.Error(fmt.Sprintf("Command timed out after %s: %s", timeout, cmdStr))
+		err = fmt.Errorf("command timed out after %s", timeout)
+	}
+	return result, err
 }
 
 // offerReverse asks if the user wants to reverse partially executed changes
 func (e *Executor) offerReverse(fix *Fix, failedAt int) bool {
-	if !fix.Reversible {
+	if !fix.Reversible && len(fix.Modifies) == 0 {
 		return false
 	}
 
-	fmt.Printf("\n❌ Fix failed at step %d.\n", failedAt+1)
-	fmt.Printf("This fix is reversible. Do you want to undo the changes made so far? (y/N): ")
-	
-	reader := bufio.NewReader(os.Stdin)
-	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
-	
-	return response == "y" || response == "yes"
+	// Unattended runs always undo partial changes
+	if e.autoApprove {
+		e.logger.Info(fmt.Sprintf("Fix failed at step %d; undoing changes made so far", failedAt+1))
+		return true
+	}
+
+	return e.confirmer.ConfirmReverse(fix, failedAt)
 }
 
 // reverseFix undoes changes made by a partially executed fix
-func (e *Executor) reverseFix(fix *Fix, lastExecutedStep int) {
+func (e *Executor) reverseFix(fix *Fix, lastExecutedStep int, snapshot *Snapshot) *ReversalResult {
 	e.logger.Info(fmt.Sprintf("Reversing fix '%s' up to step %d", fix.Title, lastExecutedStep+1))
+	reversal := &ReversalResult{Attempted: true, Succeeded: true}
 	
 	// Execute reverse commands in reverse order
 	for i := lastExecutedStep; i >= 0; i-- {
@@ -236,13 +512,28 @@ func (e *Executor) reverseFix(fix *Fix, lastExecutedStep int) {
 			cmd := fix.ReverseCommands[i]
 			e.logger.Info(fmt.Sprintf("Reversing step %d: %s", i+1, cmd))
 			
-			if err := e.executeCommand(cmd); err != nil {
+			started := time.Now()
+			result, err := e.executeCommand(cmd)
+			reversal.Steps = append(reversal.Steps, newStepResult(i+1, started, result, err))
+			if err != nil {
 				e.logger.Error(fmt.Sprintf("Failed to reverse step %d: %s", i+1, err))
+				reversal.Succeeded = false
+			}
+		}
+	}
+	
+	// Put snapshotted files back regardless of the reverse commands
+	if snapshot != nil {
+		for _, step := range e.restoreSnapshot(snapshot) {
+			if step.Error != "" {
+				reversal.Succeeded = false
 			}
+			reversal.Steps = append(reversal.Steps, step)
 		}
 	}
 	
 	e.logger.Info("Fix reversal completed")
+	return reversal
 }
 
 // GetCommonFixes returns a collection of commonly used fixes
@@ -323,6 +614,7 @@ func GetCommonFixes() map[string]*Fix {
 				"sed -i '/\\/swapfile/d' /etc/fstab",
 			},
 			RiskLevel: RiskMedium,
+			Modifies:  []string{"/etc/fstab"},
 		},
 	}
 }
\ No newline at end of file
@@ -1,7 +1,11 @@
 package fixes
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/debian-doctor/debian-doctor/pkg/config"
 	"github.com/debian-doctor/debian-doctor/pkg/logger"
@@ -81,6 +85,22 @@ func TestValidateFix(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "dangerous command - rm with split flags",
+			fix: &Fix{
+				Title:    "Dangerous Fix",
+				Commands: []string{"rm -r -f /"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dd to a regular file",
+			fix: &Fix{
+				Title:    "Benchmark Fix",
+				Commands: []string{"dd if=/dev/zero of=/tmp/test bs=1M count=1"},
+			},
+			wantErr: false,
+		},
 		{
 			name: "valid fix",
 			fix: &Fix{
@@ -224,7 +244,7 @@ func TestExecuteCommandValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := executor.executeCommand(tt.command)
+			_, err := executor.executeCommand(tt.command)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("executeCommand() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -235,12 +255,14 @@ func TestExecuteCommandValidation(t *testing.T) {
 func TestExecuteFixPermissions(t *testing.T) {
 	cfg := config.New()
 	cfg.SetNonInteractive(true) // Avoid prompts in tests
+	cfg.SetLogDir(t.TempDir())
 	log, err := logger.NewFromConfig(cfg)
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
 	defer log.Close()
 	executor := NewExecutor(cfg, log)
+	executor.SetAutoApprove(RiskLow) // Non-interactive runs need explicit approval
 
 	// Test fix that requires root when not root
 	fix := &Fix{
@@ -252,11 +274,180 @@ func TestExecuteFixPermissions(t *testing.T) {
 	}
 
 	// This should fail if not running as root
-	err = executor.ExecuteFix(fix)
+	_, err = executor.ExecuteFix(fix)
 	if !cfg.IsRoot && err == nil {
 		t.Error("Expected error when running root-required fix without root privileges")
 	}
 	if cfg.IsRoot && err != nil {
 		t.Errorf("Unexpected error when running as root: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestExecuteFixRiskCeiling(t *testing.T) {
+	tests := []struct {
+		name       string
+		autoRisk   *RiskLevel
+		fixRisk    RiskLevel
+		info       bool
+		needsHuman bool
+	}{
+		{name: "non-interactive without auto-approval", fixRisk: RiskLow, needsHuman: true},
+		{name: "within ceiling", autoRisk: riskPtr(RiskLow), fixRisk: RiskLow},
+		{name: "above flag ceiling", autoRisk: riskPtr(RiskLow), fixRisk: RiskMedium, needsHuman: true},
+		{name: "above policy ceiling", autoRisk: riskPtr(RiskHigh), fixRisk: RiskMedium, needsHuman: true},
+		{name: "informational", autoRisk: riskPtr(RiskLow), fixRisk: RiskLow, info: true, needsHuman: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.New()
+			cfg.SetNonInteractive(true)
+			cfg.SetLogDir(t.TempDir())
+			cfg.SetStateDir(t.TempDir())
+			log, err := logger.NewFromConfig(cfg)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer log.Close()
+			executor := NewExecutor(cfg, log)
+			executor.SetPolicy(DefaultPolicy()) // Unattended ceiling of Low
+			if tt.autoRisk != nil {
+				executor.SetAutoApprove(*tt.autoRisk)
+			}
+
+			_, err = executor.ExecuteFix(&Fix{Title: "Ceiling", Commands: []string{"true"}, RiskLevel: tt.fixRisk, Informational: tt.info})
+			var needsHuman *NeedsHumanError
+			if errors.As(err, &needsHuman) != tt.needsHuman {
+				t.Errorf("ExecuteFix() error = %v, needsHuman %v", err, tt.needsHuman)
+			}
+		})
+	}
+}
+
+func riskPtr(level RiskLevel) *RiskLevel {
+	return &level
+}
+
+// stubConfirmer answers confirmations without a terminal
+type stubConfirmer struct {
+	approve  bool
+	reversed []int
+}
+
+func (c *stubConfirmer) ConfirmFix(fix *Fix) bool {
+	return c.approve
+}
+
+func (c *stubConfirmer) ConfirmReverse(fix *Fix, failedAt int) bool {
+	c.reversed = append(c.reversed, failedAt)
+	return true
+}
+
+func TestExecuteFixConfirmer(t *testing.T) {
+	cfg := config.New()
+	cfg.SetLogDir(t.TempDir())
+	cfg.SetStateDir(t.TempDir())
+	log, err := logger.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close()
+	executor := NewExecutor(cfg, log)
+	executor.SetPolicy(DefaultPolicy())
+
+	confirmer := &stubConfirmer{}
+	executor.SetConfirmer(confirmer)
+	var progress []int
+	executor.SetProgress(func(step StepResult, total int) {
+		progress = append(progress, step.Step, total)
+	})
+
+	fix := &Fix{
+		Title:           "Two steps",
+		Commands:        []string{"true", "false"},
+		Reversible:      true,
+		ReverseCommands: []string{"true"},
+	}
+	run, err := executor.ExecuteFix(fix)
+	if err != nil || run.Status != RunCancelled || len(progress) != 0 {
+		t.Fatalf("declined ExecuteFix() = %+v, %v", run, err)
+	}
+
+	confirmer.approve = true
+	run, err = executor.ExecuteFix(fix)
+	if err == nil || run.Status != RunFailed {
+		t.Fatalf("ExecuteFix() = %+v, %v; want a failed run", run, err)
+	}
+	if len(confirmer.reversed) != 1 || confirmer.reversed[0] != 1 || run.Reversal == nil || !run.Reversal.Attempted {
+		t.Errorf("reversal = %+v, asked %v", run.Reversal, confirmer.reversed)
+	}
+	if len(progress) != 4 || progress[0] != 1 || progress[2] != 2 || progress[3] != 2 {
+		t.Errorf("progress = %v", progress)
+	}
+}
+
+func TestExecuteFixOutput(t *testing.T) {
+	cfg := config.New()
+	cfg.SetLogDir(t.TempDir())
+	cfg.SetStateDir(t.TempDir())
+	log, err := logger.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close()
+	executor := NewExecutor(cfg, log)
+	executor.SetPolicy(DefaultPolicy())
+	executor.SetConfirmer(&stubConfirmer{approve: true})
+
+	var output strings.Builder
+	executor.SetOutput(&output)
+	fix := &Fix{Title: "Echo", Commands: []string{"echo out", "echo err >&2"}}
+
+	run, err := executor.ExecuteFix(fix)
+	if err != nil {
+		t.Fatalf("ExecuteFix() error = %v", err)
+	}
+	if output.String() != "out\nerr\n" || run.Steps[0].Stdout != "out\n" || run.Steps[1].Stderr != "err\n" {
+		t.Errorf("output = %q, steps = %+v", output.String(), run.Steps)
+	}
+
+	output.Reset()
+	cfg.SetDryRun(true)
+	if _, err := executor.ExecuteFix(fix); err != nil {
+		t.Fatalf("dry-run ExecuteFix() error = %v", err)
+	}
+	if !strings.Contains(output.String(), "echo err >&2") {
+		t.Errorf("dry-run plan was not written to the output: %q", output.String())
+	}
+}
+
+func TestExecuteFixCommandTimeout(t *testing.T) {
+	cfg := config.New()
+	cfg.SetLogDir(t.TempDir())
+	cfg.SetStateDir(t.TempDir())
+	cfg.Runner.FixCommandTimeout = 200 * time.Millisecond
+	log, err := logger.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close()
+
+	executor := NewExecutor(cfg, log)
+	executor.SetPolicy(DefaultPolicy())
+	executor.SetConfirmer(&stubConfirmer{approve: true})
+	executor.SetOutput(io.Discard)
+
+	run, err := executor.ExecuteFix(&Fix{ID: "hang", Title: "Hang", Commands: []string{"sleep 30"}})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Fatalf("ExecuteFix() error = %v, want a timeout", err)
+	}
+	if run == nil || run.Status != RunFailed || len(run.Steps) != 1 || !run.Steps[0].TimedOut {
+		t.Fatalf("run = %+v", run)
+	}
+
+	// The journal keeps the timeout
+	runs, err := NewJournal(cfg.LogDir).Runs()
+	if err != nil || len(runs) != 1 || !runs[0].Steps[0].TimedOut {
+		t.Errorf("journal = %+v, %v", runs, err)
+	}
+}
@@ -0,0 +1,99 @@
+package fixes
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// JournalFileName is the name of the fix journal inside the log directory
+const JournalFileName = "fix-journal.jsonl"
+
+// Journal is an append-only record of fix runs, stored as JSON lines
+type Journal struct {
+	path string
+}
+
+// NewJournal returns the journal stored in the given directory
+func NewJournal(dir string) *Journal {
+	return &Journal{path: filepath.Join(dir, JournalFileName)}
+}
+
+// Path returns the location of the journal file
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Append adds a fix run to the journal
+func (j *Journal) Append(run *FixRun) error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode fix run: %w", err)
+	}
+
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Runs returns every run in the journal, oldest first. A missing journal is
+// not an error; it simply means nothing has been run yet.
+func (j *Journal) Runs() ([]FixRun, error) {
+	runs := []FixRun{}
+
+	file, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return runs, nil
+		}
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var run FixRun
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("corrupt journal entry at line %d: %w", line, err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return runs, nil
+}
+
+// Find returns the run with the given ID
+func (j *Journal) Find(id string) (*FixRun, error) {
+	runs, err := j.Runs()
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].ID == id {
+			return &runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no fix run with ID %s in %s", id, j.path)
+}
@@ -0,0 +1,118 @@
+package fixes
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJournalAppendAndRead(t *testing.T) {
+	journal := NewJournal(t.TempDir())
+
+	runs, err := journal.Runs()
+	if err != nil {
+		t.Fatalf("Runs() on missing journal error = %v", err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("Expected empty journal, got %d runs", len(runs))
+	}
+
+	first := newFixRun(&Fix{ID: "first", Title: "First", RiskLevel: RiskMedium})
+	first.finish(RunSucceeded, nil)
+	second := newFixRun(&Fix{ID: "second", Title: "Second", RiskLevel: RiskHigh})
+	second.finish(RunFailed, os.ErrPermission)
+
+	for _, run := range []*FixRun{first, second} {
+		if err := journal.Append(run); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	runs, err = journal.Runs()
+	if err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].FixID != "first" || runs[1].FixID != "second" {
+		t.Errorf("Runs out of order: %s, %s", runs[0].FixID, runs[1].FixID)
+	}
+	if runs[1].RiskLevel != RiskHigh {
+		t.Errorf("RiskLevel = %v, want %v", runs[1].RiskLevel, RiskHigh)
+	}
+	if runs[1].Status != RunFailed || runs[1].Error == "" {
+		t.Errorf("Expected failed run with error, got %s %q", runs[1].Status, runs[1].Error)
+	}
+
+	found, err := journal.Find(second.ID)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if found.Title != "Second" {
+		t.Errorf("Find() returned %s", found.Title)
+	}
+	if _, err := journal.Find("missing"); err == nil {
+		t.Error("Expected error for unknown run ID")
+	}
+}
+
+func TestExecuteFixRecordsRun(t *testing.T) {
+	executor := newTestExecutor(t)
+
+	fix := &Fix{
+		ID:              "journal_test",
+		Title:           "Journal Test",
+		Commands:        []string{"echo first", "sh -c 'echo oops >&2; exit 4'"},
+		Reversible:      true,
+		ReverseCommands: []string{"echo undo"},
+		RiskLevel:       RiskLow,
+	}
+
+	run, err := executor.ExecuteFix(fix)
+	if err == nil {
+		t.Fatal("Expected failing fix to return an error")
+	}
+	if run == nil {
+		t.Fatal("Expected run record for executed fix")
+	}
+	if run.Status != RunFailed {
+		t.Errorf("Status = %s, want %s", run.Status, RunFailed)
+	}
+	if len(run.Steps) != 2 {
+		t.Fatalf("Expected 2 steps, got %d", len(run.Steps))
+	}
+	if run.Steps[0].ExitCode != 0 || strings.TrimSpace(run.Steps[0].Stdout) != "first" {
+		t.Errorf("Unexpected first step: %+v", run.Steps[0])
+	}
+	if run.Steps[1].ExitCode != 4 || strings.TrimSpace(run.Steps[1].Stderr) != "oops" {
+		t.Errorf("Unexpected second step: %+v", run.Steps[1])
+	}
+	if run.Reversal == nil || !run.Reversal.Attempted || !run.Reversal.Succeeded {
+		t.Errorf("Expected unattended run to undo partial changes, got %+v", run.Reversal)
+	}
+
+	recorded, err := executor.Journal().Runs()
+	if err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if len(recorded) != 1 || recorded[0].ID != run.ID {
+		t.Errorf("Expected run %s in journal, got %+v", run.ID, recorded)
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	short := "hello\n"
+	if got := truncateOutput(short); got != short {
+		t.Errorf("truncateOutput() changed short output: %q", got)
+	}
+
+	long := strings.Repeat("a", maxOutputBytes) + "tail"
+	got := truncateOutput(long)
+	if !strings.HasPrefix(got, "[... 4 bytes truncated ...]") {
+		t.Errorf("Missing truncation marker: %q", got[:40])
+	}
+	if !strings.HasSuffix(got, "tail") {
+		t.Error("Expected tail of output to be kept")
+	}
+}
@@ -0,0 +1,470 @@
+package fixes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// BinaryCheck reports whether a program a command invokes can be found
+type BinaryCheck struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Found   bool   `json:"found"`
+	Builtin bool   `json:"builtin,omitempty"`
+}
+
+// StepPlan describes what a single fix command would do
+type StepPlan struct {
+	Step     int           `json:"step"`
+	Command  string        `json:"command"`
+	Shell    bool          `json:"shell"`
+	Binaries []BinaryCheck `json:"binaries"`
+	Touches  []string      `json:"touches,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Plan is a dry-run description of a fix: what would run, which files
+// would be touched and what is needed for it to succeed
+type Plan struct {
+	FixID        string      `json:"fix_id"`
+	Title        string      `json:"title"`
+	RiskLevel    RiskLevel   `json:"risk_level"`
+	RequiresRoot bool        `json:"requires_root"`
+	IsRoot       bool        `json:"is_root"`
+	Reversible   bool        `json:"reversible"`
+	Steps        []StepPlan  `json:"steps"`
+	Files        []string    `json:"files"`
+	Violations   []Violation `json:"violations,omitempty"`
+	Problems     []string    `json:"problems,omitempty"`
+}
+
+// Ready reports whether nothing in the plan would stop the fix from running
+func (p *Plan) Ready() bool {
+	return len(p.Problems) == 0
+}
+
+// shellKeywords introduce or close compound commands; the next word is a command
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true, "esac": true,
+	"{": true, "}": true, "!": true, "time": true,
+}
+
+// rootPathPrefixes are locations that normally only root can write to
+var rootPathPrefixes = []string{"/etc/", "/usr/", "/var/", "/boot/", "/proc/", "/sys/", "/opt/", "/lib/", "/sbin/", "/bin/", "/root/", "/swapfile"}
+
+// Plan builds a dry-run description of the fix without executing anything
+func (e *Executor) Plan(fix *Fix) (*Plan, error) {
+	if err := e.checkFix(fix); err != nil {
+		return nil, fmt.Errorf("fix validation failed: %w", err)
+	}
+
+	plan := &Plan{
+		FixID:        fix.ID,
+		Title:        fix.Title,
+		RiskLevel:    fix.RiskLevel,
+		RequiresRoot: fix.RequiresRoot,
+		IsRoot:       e.config.IsRoot,
+		Reversible:   fix.Reversible,
+	}
+
+	files := map[string]bool{}
+	for _, file := range fix.Modifies {
+		files[file] = true
+	}
+	for i, raw := range fix.Commands {
+		step := planCommand(i+1, raw)
+		for _, file := range step.Touches {
+			files[file] = true
+			if !plan.RequiresRoot && needsRoot(file) {
+				plan.RequiresRoot = true
+			}
+		}
+		if step.Error != "" {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("step %d cannot be parsed: %s", step.Step, step.Error))
+		}
+		for _, binary := range step.Binaries {
+			if !binary.Found {
+				plan.Problems = append(plan.Problems, fmt.Sprintf("step %d: %s not found on $PATH", step.Step, binary.Name))
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	plan.Files = make([]string, 0, len(files))
+	for file := range files {
+		plan.Files = append(plan.Files, file)
+	}
+	sort.Strings(plan.Files)
+
+	if plan.RequiresRoot && !plan.IsRoot {
+		plan.Problems = append(plan.Problems, "root privileges are required but debian-doctor is not running as root")
+	}
+
+	// Report policy denials instead of failing so the plan shows all of them
+	var policyErr *PolicyError
+	if err := e.checkPolicy(fix); errors.As(err, &policyErr) {
+		plan.Violations = policyErr.Violations
+		for _, violation := range policyErr.Violations {
+			plan.Problems = append(plan.Problems, "denied by policy: "+violation.String())
+		}
+	} else if err != nil {
+		plan.Problems = append(plan.Problems, err.Error())
+	}
+
+	return plan, nil
+}
+
+// planCommand inspects a single command without running it
+func planCommand(step int, raw string) StepPlan {
+	plan := StepPlan{Step: step, Command: raw, Binaries: []BinaryCheck{}}
+
+	cmd, err := ParseCommand(raw)
+	if err != nil {
+		plan.Error = err.Error()
+		return plan
+	}
+	plan.Shell = cmd.Shell
+
+	seen := map[string]bool{}
+	for _, simple := range splitSimpleCommands(cmd.tokens) {
+		if simple.skip || len(simple.words) == 0 {
+			continue
+		}
+		name := simple.words[0]
+		if strings.ContainsAny(name, "$`") {
+			continue // Only known once the shell expands it
+		}
+		if !seen[name] {
+			seen[name] = true
+			plan.Binaries = append(plan.Binaries, lookupBinary(name))
+		}
+		plan.Touches = append(plan.Touches, touchedByArgs(name, simple.words[1:])...)
+	}
+
+	plan.Touches = append(plan.Touches, redirectTargets(cmd.tokens)...)
+	plan.Touches = uniqueStrings(plan.Touches)
+
+	return plan
+}
+
+// simpleCommand is one command of a pipeline or list, minus redirections
+type simpleCommand struct {
+	words []string
+	skip  bool // Part of a for/case header rather than a command
+}
+
+// splitSimpleCommands breaks a token stream into the simple commands it runs
+func splitSimpleCommands(tokens []token) []simpleCommand {
+	var (
+		commands []simpleCommand
+		current  simpleCommand
+	)
+
+	flush := func() {
+		if len(current.words) > 0 || current.skip {
+			commands = append(commands, current)
+		}
+		current = simpleCommand{}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind == tokenOperator {
+			if isRedirect(tok.value) {
+				i++ // The next word is the redirection target
+				continue
+			}
+			flush()
+			continue
+		}
+
+		if len(current.words) == 0 && !current.skip {
+			switch {
+			case tok.value == "for" || tok.value == "case" || tok.value == "select":
+				current.skip = true
+				continue
+			case shellKeywords[tok.value]:
+				continue
+			case assignmentPattern.MatchString(tok.value):
+				continue
+			}
+		}
+		if current.skip {
+			// "case x in" is followed by patterns up to the closing paren
+			continue
+		}
+		current.words = append(current.words, tok.value)
+	}
+	flush()
+
+	return commands
+}
+
+// redirectTargets returns files written to by output redirections
+func redirectTargets(tokens []token) []string {
+	var targets []string
+	for i := 0; i+1 < len(tokens); i++ {
+		op := strings.TrimLeft(tokens[i].value, "0123456789")
+		if tokens[i].kind != tokenOperator || tokens[i+1].kind != tokenWord {
+			continue
+		}
+		switch op {
+		case ">", ">>", ">|":
+		case ">&":
+			if isDigits(tokens[i+1].value) || tokens[i+1].value == "-" {
+				continue
+			}
+		default:
+			continue
+		}
+		target := tokens[i+1].value
+		if target == "/dev/null" || strings.HasPrefix(target, "/dev/std") || strings.HasPrefix(target, "/dev/fd/") {
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+func isRedirect(op string) bool {
+	op = strings.TrimLeft(op, "0123456789")
+	switch op {
+	case ">", ">>", ">|", "<", "<<", "<>", ">&", "<&":
+		return true
+	}
+	return false
+}
+
+// touchedByArgs knows which arguments of common file-modifying tools are
+// the files they change
+func touchedByArgs(name string, args []string) []string {
+	base := name[strings.LastIndex(name, "/")+1:]
+	operands, opts := splitOperands(args, optionsWithValues[base])
+
+	switch base {
+	case "rm", "rmdir", "touch", "mkdir", "tee", "shred", "truncate", "fallocate", "mkswap", "unlink":
+		return operands
+	case "chmod", "chown", "chgrp":
+		if len(operands) > 1 {
+			return operands[1:]
+		}
+	case "cp", "install", "ln":
+		if len(operands) > 1 {
+			return operands[len(operands)-1:]
+		}
+	case "mv":
+		return operands
+	case "sed", "perl":
+		if opts["-i"] || opts["--in-place"] || opts["-pi"] {
+			if opts["-e"] || opts["-f"] {
+				return operands
+			}
+			if len(operands) > 1 {
+				return operands[1:]
+			}
+		}
+	case "dd":
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "of=") {
+				return []string{strings.TrimPrefix(arg, "of=")}
+			}
+		}
+	case "find":
+		if findDeletes(args) {
+			return findStartingPaths(args)
+		}
+	case "tar":
+		for i, arg := range args {
+			cluster := strings.TrimPrefix(arg, "-")
+			if (i == 0 || strings.HasPrefix(arg, "-")) && !strings.HasPrefix(arg, "--") &&
+				strings.ContainsAny(cluster, "cru") && strings.HasSuffix(cluster, "f") && i+1 < len(args) {
+				return []string{args[i+1]}
+			}
+		}
+	}
+	return nil
+}
+
+// findStartingPaths returns the paths find searches, "." when none are given
+func findStartingPaths(args []string) []string {
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case len(paths) == 0 && (arg == "-H" || arg == "-L" || arg == "-P" || strings.HasPrefix(arg, "-O")):
+		case len(paths) == 0 && arg == "-D":
+			i++
+		case strings.HasPrefix(arg, "-") || arg == "(" || arg == "!":
+			i = len(args)
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		return []string{"."}
+	}
+	return paths
+}
+
+// findDeletes reports whether find deletes what it finds
+func findDeletes(args []string) bool {
+	return containsString(args, "-delete")
+}
+
+// findCommands returns the commands find runs through -exec and similar
+// actions, with "{}" standing for each file found
+func findCommands(args []string) [][]string {
+	var commands [][]string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-exec", "-execdir", "-ok", "-okdir":
+		default:
+			continue
+		}
+		var command []string
+		for i++; i < len(args); i++ {
+			if args[i] == ";" || (args[i] == "+" && len(command) > 0 && command[len(command)-1] == "{}") {
+				break
+			}
+			command = append(command, args[i])
+		}
+		if len(command) > 0 {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// optionsWithValues lists short options that consume the following argument
+var optionsWithValues = map[string]map[string]bool{
+	"fallocate": {"-l": true, "-o": true},
+	"truncate":  {"-s": true, "-r": true},
+	"sed":       {"-e": true, "-f": true},
+	"install":   {"-m": true, "-o": true, "-g": true},
+	"mkswap":    {"-L": true, "-U": true, "-p": true},
+	"shred":     {"-n": true, "-s": true},
+}
+
+// splitOperands separates non-option arguments from options
+func splitOperands(args []string, withValues map[string]bool) ([]string, map[string]bool) {
+	var operands []string
+	opts := map[string]bool{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			operands = append(operands, args[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			// -i.bak and --in-place=.bak still mean in-place editing
+			key := arg
+			if strings.HasPrefix(arg, "-i") && !strings.HasPrefix(arg, "--") {
+				key = "-i"
+			} else if idx := strings.Index(arg, "="); idx > 0 {
+				key = arg[:idx]
+			}
+			opts[key] = true
+			if withValues[arg] && i+1 < len(args) {
+				i++
+			}
+			continue
+		}
+		operands = append(operands, arg)
+	}
+	return operands, opts
+}
+
+func lookupBinary(name string) BinaryCheck {
+	check := BinaryCheck{Name: name}
+	if shellBuiltins[name] || name == "echo" || name == "printf" || name == "true" || name == "false" || name == "test" || name == "[" {
+		check.Builtin = true
+		check.Found = true
+		return check
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		check.Path = path
+		check.Found = true
+	}
+	return check
+}
+
+func needsRoot(path string) bool {
+	for _, prefix := range rootPathPrefixes {
+		if strings.HasPrefix(path, prefix) || path == strings.TrimSuffix(prefix, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// Render writes a human-readable description of the plan
+func (p *Plan) Render(w io.Writer) {
+	fmt.Fprintf(w, "DRY RUN: %s\n", p.Title)
+	fmt.Fprintf(w, "  Risk Level:    %s\n", p.RiskLevel)
+	fmt.Fprintf(w, "  Requires Root: %s\n", yesNo(p.RequiresRoot))
+	fmt.Fprintf(w, "  Running Root:  %s\n", yesNo(p.IsRoot))
+	fmt.Fprintf(w, "  Reversible:    %s\n", yesNo(p.Reversible))
+
+	fmt.Fprintf(w, "\n  Commands that would run:\n")
+	for _, step := range p.Steps {
+		mode := ""
+		if step.Shell {
+			mode = " (via /bin/sh)"
+		}
+		fmt.Fprintf(w, "    %d. %s%s\n", step.Step, step.Command, mode)
+		if step.Error != "" {
+			fmt.Fprintf(w, "       ! %s\n", step.Error)
+		}
+		for _, binary := range step.Binaries {
+			switch {
+			case binary.Builtin:
+				fmt.Fprintf(w, "       - %s: shell builtin\n", binary.Name)
+			case binary.Found:
+				fmt.Fprintf(w, "       - %s: %s\n", binary.Name, binary.Path)
+			default:
+				fmt.Fprintf(w, "       - %s: NOT FOUND on $PATH\n", binary.Name)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n  Files that would be touched:\n")
+	if len(p.Files) == 0 {
+		fmt.Fprintf(w, "    (none detected)\n")
+	}
+	for _, file := range p.Files {
+		fmt.Fprintf(w, "    - %s\n", file)
+	}
+
+	if len(p.Problems) > 0 {
+		fmt.Fprintf(w, "\n  Problems:\n")
+		for _, problem := range p.Problems {
+			fmt.Fprintf(w, "    ! %s\n", problem)
+		}
+	} else {
+		fmt.Fprintf(w, "\n  No problems detected; nothing was executed.\n")
+	}
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,192 @@
+package fixes
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"github.com/debian-doctor/debian-doctor/pkg/logger"
+)
+
+func TestPlanCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		binaries []string
+		touches  []string
+	}{
+		{
+			name:     "append to fstab",
+			raw:      "echo '/swapfile none swap sw 0 0' >> /etc/fstab",
+			binaries: []string{"echo"},
+			touches:  []string{"/etc/fstab"},
+		},
+		{
+			name:     "sed in place",
+			raw:      "sed -i '/\\/swapfile/d' /etc/fstab",
+			binaries: []string{"sed"},
+			touches:  []string{"/etc/fstab"},
+		},
+		{
+			name:     "fallocate skips size argument",
+			raw:      "fallocate -l 1G /swapfile",
+			binaries: []string{"fallocate"},
+			touches:  []string{"/swapfile"},
+		},
+		{
+			name:     "chmod skips mode",
+			raw:      "chmod 600 /swapfile",
+			binaries: []string{"chmod"},
+			touches:  []string{"/swapfile"},
+		},
+		{
+			name:     "tee in pipeline",
+			raw:      "echo 'nameserver 1.1.1.1' | tee /etc/resolv.conf",
+			binaries: []string{"echo", "tee"},
+			touches:  []string{"/etc/resolv.conf"},
+		},
+		{
+			name:     "read-only pipeline ignores /dev/null",
+			raw:      "find / -type f -size +100M 2>/dev/null | head -20",
+			binaries: []string{"find", "head"},
+		},
+		{
+			name:     "stderr duplication is not a file",
+			raw:      "apt-get update 2>&1",
+			binaries: []string{"apt-get"},
+		},
+		{
+			name:     "loop body commands",
+			raw:      "for dir in /tmp /var; do rm -rf $dir/cache; done",
+			binaries: []string{"rm"},
+			touches:  []string{"$dir/cache"},
+		},
+		{
+			name:     "sed without -i edits nothing",
+			raw:      "sed -n '1p' /etc/fstab",
+			binaries: []string{"sed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := planCommand(1, tt.raw)
+			if step.Error != "" {
+				t.Fatalf("planCommand() error = %s", step.Error)
+			}
+
+			var names []string
+			for _, binary := range step.Binaries {
+				names = append(names, binary.Name)
+			}
+			if !reflect.DeepEqual(names, tt.binaries) {
+				t.Errorf("binaries = %q, want %q", names, tt.binaries)
+			}
+
+			if len(step.Touches) != 0 || len(tt.touches) != 0 {
+				if !reflect.DeepEqual(step.Touches, tt.touches) {
+					t.Errorf("touches = %q, want %q", step.Touches, tt.touches)
+				}
+			}
+		})
+	}
+}
+
+func TestExecutorPlan(t *testing.T) {
+	cfg := config.New()
+	cfg.SetNonInteractive(true)
+	cfg.SetLogDir(t.TempDir())
+	log, err := logger.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close()
+	executor := NewExecutor(cfg, log)
+
+	plan, err := executor.Plan(GetCommonFixes()["create_swap_file"])
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	want := []string{"/etc/fstab", "/swapfile"}
+	if !reflect.DeepEqual(plan.Files, want) {
+		t.Errorf("Files = %q, want %q", plan.Files, want)
+	}
+	if !plan.RequiresRoot {
+		t.Error("Expected plan to require root")
+	}
+	if len(plan.Steps) != 5 {
+		t.Errorf("Expected 5 steps, got %d", len(plan.Steps))
+	}
+
+	missing, err := executor.Plan(&Fix{
+		Title:    "Missing Binary",
+		Commands: []string{"debian-doctor-no-such-binary --fix"},
+	})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if missing.Ready() {
+		t.Error("Expected plan with missing binary to report a problem")
+	}
+
+	var out bytes.Buffer
+	missing.Render(&out)
+	if !strings.Contains(out.String(), "NOT FOUND") {
+		t.Errorf("Rendered plan does not flag missing binary:\n%s", out.String())
+	}
+}
+
+func TestExecuteFixDryRun(t *testing.T) {
+	cfg := config.New()
+	cfg.SetNonInteractive(true)
+	cfg.SetDryRun(true)
+	cfg.SetLogDir(t.TempDir())
+	log, err := logger.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer log.Close()
+	executor := NewExecutor(cfg, log)
+	executor.SetPolicy(DefaultPolicy())
+	executor.SetOutput(io.Discard)
+
+	// Without auto-approval nobody would approve the fix, and a dry run
+	// says so instead of pretending it would run
+	target := t.TempDir() + "/marker"
+	fix := &Fix{Title: "Dry Run", Commands: []string{"touch " + target}}
+	var needsHuman *NeedsHumanError
+	if _, err := executor.ExecuteFix(fix); !errors.As(err, &needsHuman) {
+		t.Errorf("ExecuteFix() without auto-approval error = %v, want NeedsHumanError", err)
+	}
+
+	executor.SetAutoApprove(RiskLow)
+	var denied *PolicyError
+	if _, err := executor.ExecuteFix(&Fix{Title: "Denied", Commands: []string{"rm -rf /etc/ssh"}}); !errors.As(err, &denied) {
+		t.Errorf("ExecuteFix() of a denied fix error = %v, want PolicyError", err)
+	}
+	if _, err := executor.ExecuteFix(&Fix{Title: "Risky", Commands: []string{"true"}, RiskLevel: RiskHigh}); !errors.As(err, &needsHuman) {
+		t.Errorf("ExecuteFix() above the risk ceiling error = %v, want NeedsHumanError", err)
+	}
+
+	run, err := executor.ExecuteFix(fix)
+	if err != nil {
+		t.Fatalf("ExecuteFix() error = %v", err)
+	}
+	if run.Status != RunDryRun {
+		t.Errorf("Status = %s, want %s", run.Status, RunDryRun)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("Dry run must not execute commands")
+	}
+
+	runs, _ := executor.Journal().Runs()
+	if len(runs) != 0 {
+		t.Errorf("Dry run must not be journaled, found %d runs", len(runs))
+	}
+}
@@ -0,0 +1,553 @@
+package fixes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPolicyPath is where the system-wide fix policy is read from
+const DefaultPolicyPath = config.DefaultPolicyFile
+
+// maxShellDepth bounds how far nested "sh -c" scripts are followed
+const maxShellDepth = 3
+
+// PolicyEngine decides whether a fix may be executed
+type PolicyEngine interface {
+	Evaluate(fix *Fix, unattended bool) Decision
+}
+
+// Violation explains why a policy rule denied a fix
+type Violation struct {
+	Rule    string `json:"rule" yaml:"rule"`
+	Step    int    `json:"step,omitempty" yaml:"step,omitempty"`
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	Reason  string `json:"reason" yaml:"reason"`
+}
+
+func (v Violation) String() string {
+	if v.Step > 0 {
+		return fmt.Sprintf("step %d (%s): %s [%s]", v.Step, v.Command, v.Reason, v.Rule)
+	}
+	return fmt.Sprintf("%s [%s]", v.Reason, v.Rule)
+}
+
+// Decision is the outcome of evaluating a fix against a policy
+type Decision struct {
+	Allowed    bool        `json:"allowed" yaml:"allowed"`
+	Violations []Violation `json:"violations,omitempty" yaml:"violations,omitempty"`
+}
+
+// PolicyError is returned when a fix is denied by policy
+type PolicyError struct {
+	Fix        string
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		reasons[i] = v.String()
+	}
+	return fmt.Sprintf("fix '%s' denied by policy: %s", e.Fix, strings.Join(reasons, "; "))
+}
+
+// Policy is the rule set fixes are evaluated against. Binary and path
+// entries are shell-style globs; a path entry ending in /** also matches
+// everything below it.
+type Policy struct {
+	AllowedBinaries   []string  `yaml:"allowed_binaries"`    // Empty means any binary that is not denied
+	DeniedBinaries    []string  `yaml:"denied_binaries"`     // Never allowed, even if listed as allowed
+	ForbiddenPaths    []string  `yaml:"forbidden_paths"`     // May not be written, removed or changed
+	ProtectedTrees    []string  `yaml:"protected_trees"`     // Nothing in them may be removed, moved or changed recursively
+	MaxUnattendedRisk RiskLevel `yaml:"max_unattended_risk"` // Highest risk run without confirmation
+	AllowNetwork      bool      `yaml:"allow_network"`       // Whether commands may contact the network
+	NetworkCommands   []string  `yaml:"network_commands"`    // Binaries, or "binary subcommand", that use the network
+
+	Source string `yaml:"-"` // Where the policy was loaded from
+}
+
+// DefaultPolicy returns the policy used when no policy file is installed
+func DefaultPolicy() *Policy {
+	return &Policy{
+		AllowedBinaries: []string{},
+		DeniedBinaries: []string{
+			"mkfs", "mkfs.*", "mke2fs", "fdisk", "sfdisk", "cfdisk", "gdisk", "sgdisk",
+			"parted", "wipefs", "shred", "blkdiscard", "eval",
+		},
+		ForbiddenPaths: []string{
+			"/", "/bin", "/boot", "/boot/**", "/dev/sd*", "/dev/hd*", "/dev/vd*", "/dev/xvd*",
+			"/dev/nvme*", "/dev/mmcblk*", "/dev/md*", "/dev/dm-*", "/dev/mapper/**", "/dev/disk/**",
+			"/etc", "/etc/passwd", "/etc/shadow", "/etc/group", "/etc/gshadow", "/etc/sudoers",
+			"/etc/sudoers.d/**", "/home", "/lib", "/lib64", "/opt", "/root", "/sbin", "/srv",
+			"/usr", "/var",
+		},
+		ProtectedTrees: []string{
+			"/bin", "/boot", "/etc", "/home", "/lib", "/lib32", "/lib64", "/opt", "/root", "/sbin", "/srv",
+			"/usr", "/var/backups", "/var/lib", "/var/mail", "/var/opt", "/var/spool", "/var/www",
+		},
+		MaxUnattendedRisk: RiskLow,
+		AllowNetwork:      true,
+		NetworkCommands: []string{
+			"curl", "wget", "ping", "ping6", "ssh", "scp", "sftp", "rsync", "nc", "ncat", "telnet",
+			"ftp", "dig", "nslookup", "host", "traceroute", "ntpdate",
+			"apt update", "apt install", "apt upgrade", "apt full-upgrade", "apt download",
+			"apt-get update", "apt-get install", "apt-get upgrade", "apt-get dist-upgrade", "apt-get download",
+			"apt-get source", "aptitude update", "aptitude install", "aptitude upgrade",
+			"git clone", "git fetch", "git pull", "snap install", "snap refresh",
+			"pip install", "pip3 install", "pip download", "pip3 download",
+		},
+		Source: "built-in defaults",
+	}
+}
+
+// LoadPolicy reads a policy file. Keys that are not set keep their default
+// values; a missing file yields the default policy.
+func LoadPolicy(path string) (*Policy, error) {
+	policy := DefaultPolicy()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return policy, nil
+		}
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	policy.Source = path
+
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// LoadConfiguredPolicy reads the policy file cfg names. When running as
+// root, a policy file that other users could change is refused, since it
+// decides what runs unattended
+func LoadConfiguredPolicy(cfg *config.Config) (*Policy, error) {
+	path := cfg.PolicyFile
+	if path == "" {
+		path = DefaultPolicyPath
+	}
+	if cfg.IsRoot {
+		if err := config.CheckTrusted(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("untrusted policy: %w", err)
+		}
+	}
+	return LoadPolicy(path)
+}
+
+// validate checks that every pattern in the policy is well formed
+func (p *Policy) validate() error {
+	for _, group := range [][]string{p.AllowedBinaries, p.DeniedBinaries, p.ForbiddenPaths, p.ProtectedTrees} {
+		for _, pattern := range group {
+			if _, err := filepath.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+				return fmt.Errorf("bad pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate checks every command of a fix against the policy. Unattended
+// runs are additionally limited by MaxUnattendedRisk.
+func (p *Policy) Evaluate(fix *Fix, unattended bool) Decision {
+	var violations []Violation
+
+	if unattended && fix.RiskLevel > p.MaxUnattendedRisk {
+		violations = append(violations, Violation{
+			Rule:   "max_unattended_risk",
+			Reason: fmt.Sprintf("%s risk exceeds the %s risk allowed without confirmation", fix.RiskLevel, p.MaxUnattendedRisk),
+		})
+	}
+
+	for i, raw := range fix.Commands {
+		for _, v := range p.evaluateCommand(raw, 0, nil) {
+			v.Step = i + 1
+			v.Command = raw
+			violations = append(violations, v)
+		}
+	}
+
+	return Decision{Allowed: len(violations) == 0, Violations: violations}
+}
+
+// evaluateCommand checks one command line, following nested shell scripts.
+// walk is set for commands that find runs on everything below its paths
+func (p *Policy) evaluateCommand(raw string, depth int, walk []string) []Violation {
+	cmd, err := ParseCommand(raw)
+	if err != nil {
+		return []Violation{{Rule: "parse", Reason: fmt.Sprintf("command cannot be parsed: %v", err)}}
+	}
+
+	var violations []Violation
+	for _, script := range cmd.substitutions {
+		if depth >= maxShellDepth {
+			violations = append(violations, Violation{Rule: "parse", Reason: "command substitutions are nested too deeply to verify"})
+			continue
+		}
+		violations = append(violations, p.evaluateCommand(script, depth+1, walk)...)
+	}
+
+	for _, target := range redirectTargets(cmd.tokens) {
+		violations = append(violations, p.checkTarget(target, walk != nil, walk)...)
+	}
+
+	for _, simple := range splitSimpleCommands(cmd.tokens) {
+		if simple.skip || len(simple.words) == 0 {
+			continue
+		}
+		violations = append(violations, p.evaluateWords(simple.words, depth, walk)...)
+	}
+
+	return violations
+}
+
+// evaluateWords checks a simple command given as its words
+func (p *Policy) evaluateWords(words []string, depth int, walk []string) []Violation {
+	words, fromInput := unwrapCommand(words)
+	if len(words) == 0 {
+		return nil
+	}
+	name, args := words[0], words[1:]
+	base := filepath.Base(name)
+
+	if strings.ContainsAny(name, "$`") {
+		return []Violation{{
+			Rule:   "allowed_binaries",
+			Reason: fmt.Sprintf("program %q is only known once the shell expands it", name),
+		}}
+	}
+
+	// Scripts passed to a shell are evaluated like any other command
+	if script, ok := shellScript(base, args); ok {
+		if depth >= maxShellDepth {
+			return []Violation{{Rule: "parse", Reason: "shell scripts are nested too deeply to verify"}}
+		}
+		return p.evaluateCommand(script, depth+1, walk)
+	}
+
+	var violations []Violation
+
+	// Paths after a cd would be checked against the wrong directory
+	if len(p.ForbiddenPaths) > 0 && changesDirectory(base) {
+		violations = append(violations, Violation{
+			Rule:   "forbidden_paths",
+			Reason: fmt.Sprintf("%s changes the directory paths are resolved against; use absolute paths instead", base),
+		})
+	}
+
+	violations = append(violations, p.checkBinary(name, base)...)
+
+	if !p.AllowNetwork && p.usesNetwork(base, args) {
+		violations = append(violations, Violation{
+			Rule:   "allow_network",
+			Reason: fmt.Sprintf("%s contacts the network and network access is not allowed", base),
+		})
+	}
+
+	trees := treeTargets(base, args)
+	if fromInput && recursiveCommand(base, args) {
+		violations = append(violations, Violation{
+			Rule:   "forbidden_paths",
+			Reason: fmt.Sprintf("recursive %s of paths read from input", base),
+		})
+	}
+	for _, target := range touchedByArgs(name, args) {
+		violations = append(violations, p.checkTarget(target, walk != nil || containsString(trees, target), walk)...)
+	}
+
+	// Commands find runs on what it finds are checked for everything below
+	// its starting paths
+	if base == "find" {
+		starts := findStartingPaths(args)
+		for _, command := range findCommands(args) {
+			violations = append(violations, p.evaluateWords(command, depth, starts)...)
+		}
+	}
+
+	return violations
+}
+
+// checkTarget checks a path a command touches. Inside a find command "{}"
+// stands for anything below the paths in walk
+func (p *Policy) checkTarget(target string, recursive bool, walk []string) []Violation {
+	targets := []string{target}
+	if walk != nil && strings.Contains(target, "{}") {
+		targets = nil
+		for _, start := range walk {
+			targets = append(targets, strings.ReplaceAll(target, "{}", start))
+		}
+	}
+	var violations []Violation
+	for _, t := range targets {
+		if v, denied := p.checkPath(t, recursive); denied {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// checkBinary applies the allowed and denied binary lists
+func (p *Policy) checkBinary(name, base string) []Violation {
+	if matchesAny(p.DeniedBinaries, base) || matchesAny(p.DeniedBinaries, name) {
+		return []Violation{{Rule: "denied_binaries", Reason: fmt.Sprintf("%s is on the denied binaries list", base)}}
+	}
+	if len(p.AllowedBinaries) == 0 || isShellBuiltin(base) {
+		return nil
+	}
+	if !matchesAny(p.AllowedBinaries, base) && !matchesAny(p.AllowedBinaries, name) {
+		return []Violation{{Rule: "allowed_binaries", Reason: fmt.Sprintf("%s is not on the allowed binaries list", base)}}
+	}
+	return nil
+}
+
+// checkPath reports whether changing target would touch a forbidden path.
+// Recursive operations and globs also cover everything below the target.
+func (p *Policy) checkPath(target string, recursive bool) (Violation, bool) {
+	if strings.ContainsAny(target, "$`") {
+		if recursive {
+			return Violation{
+				Rule:   "forbidden_paths",
+				Reason: fmt.Sprintf("recursive change of %s, a path only known once the shell expands it", target),
+			}, true
+		}
+		return Violation{}, false
+	}
+
+	// Relative paths depend on the directory the fix runs in, so any of
+	// them could reach a protected path
+	if !filepath.IsAbs(target) && len(p.ForbiddenPaths) > 0 {
+		return Violation{
+			Rule:   "forbidden_paths",
+			Reason: fmt.Sprintf("relative path %s could reach a protected path; use an absolute path", target),
+		}, true
+	}
+
+	path := filepath.Clean(target)
+	if idx := strings.IndexAny(path, "*?["); idx >= 0 {
+		// A glob may expand to anything inside its static directory
+		path = filepath.Dir(path[:idx] + "x")
+		recursive = true
+	}
+
+	for _, pattern := range p.ForbiddenPaths {
+		if matchPath(pattern, path) || (recursive && isAncestor(path, pattern)) {
+			return Violation{
+				Rule:   "forbidden_paths",
+				Reason: fmt.Sprintf("%s is protected by forbidden path %q", target, pattern),
+			}, true
+		}
+	}
+	if recursive {
+		for _, tree := range p.ProtectedTrees {
+			tree = strings.TrimSuffix(tree, "/**")
+			if matchPath(tree+"/**", path) || isAncestor(path, tree) {
+				return Violation{
+					Rule:   "protected_trees",
+					Reason: fmt.Sprintf("recursive change of %s reaches protected tree %q", target, tree),
+				}, true
+			}
+		}
+	}
+	return Violation{}, false
+}
+
+// changesDirectory reports whether a builtin moves the shell to another
+// working directory
+func changesDirectory(base string) bool {
+	switch base {
+	case "cd", "pushd", "popd":
+		return true
+	}
+	return false
+}
+
+// usesNetwork matches a command against the network command list
+func (p *Policy) usesNetwork(base string, args []string) bool {
+	subcommand := ""
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			subcommand = arg
+			break
+		}
+	}
+	for _, entry := range p.NetworkCommands {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 || fields[0] != base {
+			continue
+		}
+		if len(fields) == 1 || fields[1] == subcommand {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapperCommands run the command given in their arguments
+var wrapperCommands = map[string]bool{
+	"sudo": true, "env": true, "nohup": true, "nice": true, "ionice": true,
+	"timeout": true, "stdbuf": true, "xargs": true, "exec": true, "command": true,
+}
+
+// unwrapCommand strips wrappers such as sudo or timeout, and busybox in
+// front of an applet, so the policy sees the program that actually runs.
+// fromInput reports that xargs adds arguments read from its input
+func unwrapCommand(words []string) (unwrapped []string, fromInput bool) {
+	for len(words) > 0 {
+		wrapper := filepath.Base(words[0])
+		switch {
+		case wrapperCommands[wrapper]:
+			fromInput = fromInput || wrapper == "xargs"
+			words = skipWrapperArgs(wrapper, words[1:])
+		case wrapper == "busybox" && len(words) > 1 && !strings.HasPrefix(words[1], "-"):
+			words = words[1:]
+		default:
+			return words, fromInput
+		}
+	}
+	return words, fromInput
+}
+
+// skipWrapperArgs drops a wrapper's own options and operands
+func skipWrapperArgs(wrapper string, words []string) []string {
+	for len(words) > 0 {
+		word := words[0]
+		switch {
+		case strings.HasPrefix(word, "-"):
+			// Options of nice, ionice, sudo and timeout that take a value
+			if (word == "-n" || word == "-u" || word == "-g" || word == "-c" || word == "-s" || word == "-k") && len(words) > 1 {
+				words = words[1:]
+			}
+			words = words[1:]
+		case wrapper == "env" && strings.Contains(word, "="):
+			words = words[1:]
+		case wrapper == "timeout":
+			return words[1:] // The duration comes right before the command
+		default:
+			return words
+		}
+	}
+	return words
+}
+
+// shellScript returns the script run by "sh -c SCRIPT" and similar
+func shellScript(base string, args []string) (string, bool) {
+	switch base {
+	case "sh", "ash", "bash", "dash", "zsh", "ksh":
+	default:
+		return "", false
+	}
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, "c") && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+func hasRecursiveFlag(args []string) bool {
+	for _, arg := range args {
+		if arg == "--recursive" {
+			return true
+		}
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.ContainsAny(arg, "rR") {
+			return true
+		}
+	}
+	return false
+}
+
+// recursiveCommand reports whether a command changes whole trees of files
+func recursiveCommand(base string, args []string) bool {
+	switch base {
+	case "rm", "chmod", "chown", "chgrp":
+		return hasRecursiveFlag(args)
+	case "mv":
+		return true
+	case "find":
+		return findDeletes(args)
+	}
+	return false
+}
+
+// treeTargets returns the paths a command changes along with everything
+// below them. A moved directory takes its contents along, so the sources
+// of mv count too
+func treeTargets(base string, args []string) []string {
+	if !recursiveCommand(base, args) {
+		return nil
+	}
+	targets := touchedByArgs(base, args)
+	if base == "mv" && len(targets) > 0 {
+		return targets[:len(targets)-1]
+	}
+	return targets
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isShellBuiltin(name string) bool {
+	return shellBuiltins[name] || name == "echo" || name == "printf" || name == "true" ||
+		name == "false" || name == "test" || name == "["
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath matches a path against a forbidden path pattern
+func matchPath(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "/**") {
+		prefix := strings.TrimSuffix(pattern, "/**")
+		if ok, _ := filepath.Match(prefix, path); ok {
+			return true
+		}
+		for dir := filepath.Dir(path); dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+			if ok, _ := filepath.Match(prefix, dir); ok {
+				return true
+			}
+		}
+		return false
+	}
+	ok, _ := filepath.Match(pattern, path)
+	return ok
+}
+
+// isAncestor reports whether dir contains anything the pattern could match
+func isAncestor(dir, pattern string) bool {
+	static := strings.TrimSuffix(pattern, "/**")
+	if idx := strings.IndexAny(static, "*?["); idx >= 0 {
+		static = filepath.Dir(static[:idx] + "x")
+	}
+	if dir == "/" {
+		return true
+	}
+	return static == dir || strings.HasPrefix(static, dir+"/")
+}
@@ -0,0 +1,261 @@
+package fixes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+func TestDefaultPolicyEvaluate(t *testing.T) {
+	policy := DefaultPolicy()
+
+	tests := []struct {
+		name    string
+		command string
+		allowed bool
+		rule    string
+	}{
+		{name: "rm -rf /", command: "rm -rf /", rule: "forbidden_paths"},
+		{name: "split flags", command: "rm -r -f /", rule: "forbidden_paths"},
+		{name: "long flags", command: "rm --recursive --force //", rule: "forbidden_paths"},
+		{name: "root glob", command: "rm -rf /*", rule: "forbidden_paths"},
+		{name: "dotted path", command: "rm -rf /tmp/../", rule: "forbidden_paths"},
+		{name: "nested shell", command: "sh -c 'rm -rf /'", rule: "forbidden_paths"},
+		{name: "sudo wrapper", command: "sudo -u root rm -rf /etc", rule: "forbidden_paths"},
+		{name: "command substitution", command: "echo \"$(rm -rf /usr)\"", rule: "forbidden_paths"},
+		{name: "recursive delete of unknown path", command: "rm -rf $TARGET", rule: "forbidden_paths"},
+		{name: "dd to disk", command: "dd if=/dev/zero of=/dev/sda bs=1M", rule: "forbidden_paths"},
+		{name: "redirect to disk", command: "cat image > /dev/nvme0n1", rule: "forbidden_paths"},
+		{name: "mkfs", command: "mkfs.ext4 /dev/sdb1", rule: "denied_binaries"},
+		{name: "full path binary", command: "/sbin/fdisk -l", rule: "denied_binaries"},
+		{name: "overwrite shadow", command: "echo x >> /etc/shadow", rule: "forbidden_paths"},
+		{name: "relative path", command: "rm ../../etc/shadow", rule: "forbidden_paths"},
+		{name: "relative redirect", command: "echo x > ../etc/shadow", rule: "forbidden_paths"},
+		{name: "cd first", command: "cd /etc && rm shadow", rule: "forbidden_paths"},
+		{name: "cd in nested shell", command: "sh -c 'cd /boot; rm -rf grub'", rule: "forbidden_paths"},
+		{name: "rm -rf /usr/bin", command: "rm -rf /usr/bin", rule: "protected_trees"},
+		{name: "rm -rf /usr/lib", command: "rm -rf /usr/lib", rule: "protected_trees"},
+		{name: "rm -rf /var/lib/dpkg", command: "rm -rf /var/lib/dpkg", rule: "protected_trees"},
+		{name: "rm -rf /etc/ssh", command: "rm -rf /etc/ssh", rule: "protected_trees"},
+		{name: "rm -rf /root/.ssh", command: "rm -rf /root/.ssh", rule: "protected_trees"},
+		{name: "rm -rf /home/alice", command: "rm -rf /home/alice", rule: "protected_trees"},
+		{name: "glob below a protected tree", command: "rm -rf /etc/ssh/*", rule: "protected_trees"},
+		{name: "chown -R", command: "chown -R nobody /srv/data", rule: "protected_trees"},
+		{name: "mv /usr/bin", command: "mv /usr/bin /tmp/x", rule: "protected_trees"},
+		{name: "find / -delete", command: "find / -delete", rule: "forbidden_paths"},
+		{name: "find -delete in a protected tree", command: "find /usr/bin /usr/local/bin /bin /sbin -type l ! -exec test -e {} \\; -delete", rule: "protected_trees"},
+		{name: "find -exec rm", command: "find /usr -exec rm -rf {} +", rule: "forbidden_paths"},
+		{name: "find -exec rm without -r", command: "find /etc/ssh -name '*.conf' -exec rm {} \\;", rule: "protected_trees"},
+		{name: "find -exec shell", command: "find /etc -exec sh -c 'rm \"$1\"' _ {} \\;", rule: "forbidden_paths"},
+		{name: "find -exec denied binary", command: "find /dev -name 'sd*' -exec wipefs -a {} +", rule: "denied_binaries"},
+		{name: "busybox applet", command: "busybox rm -rf /", rule: "forbidden_paths"},
+		{name: "busybox shell", command: "busybox sh -c 'rm -rf /usr/lib'", rule: "protected_trees"},
+		{name: "env wrapper", command: "env LC_ALL=C nice -n 5 rm -rf /etc/ssh", rule: "protected_trees"},
+		{name: "xargs recursive delete", command: "find /tmp -name x | xargs rm -rf", rule: "forbidden_paths"},
+		{name: "dd to tmp", command: "dd if=/dev/zero of=/tmp/test bs=1M count=1024 conv=fdatasync", allowed: true},
+		{name: "remove tmp file", command: "rm -f /tmp/test", allowed: true},
+		{name: "stderr to dev null", command: "find / -size +100M 2>/dev/null", allowed: true},
+		{name: "edit fstab", command: "sed -i '/\\/swapfile/d' /etc/fstab", allowed: true},
+		{name: "mention of mkfs in text", command: "echo 'run mkfs by hand'", allowed: true},
+		{name: "remove dpkg lock", command: "rm -f /var/lib/dpkg/lock", allowed: true},
+		{name: "edit in a protected tree", command: "sed -i 's/yes/no/' /etc/ssh/sshd_config", allowed: true},
+		{name: "find -delete in tmp", command: "find /tmp -type f -atime +7 -delete", allowed: true},
+		{name: "find -delete in logs", command: "find /var/log -name '*.log' -type f -mtime +30 -delete", allowed: true},
+		{name: "find without delete", command: "find /usr -type f -size +100M", allowed: true},
+		{name: "mv into a protected tree", command: "mv /tmp/hosts.new /etc/hosts", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := policy.Evaluate(&Fix{Title: tt.name, Commands: []string{tt.command}}, false)
+			if decision.Allowed != tt.allowed {
+				t.Fatalf("Evaluate(%q).Allowed = %v, want %v (violations: %v)", tt.command, decision.Allowed, tt.allowed, decision.Violations)
+			}
+			if tt.allowed {
+				return
+			}
+			if decision.Violations[0].Rule != tt.rule {
+				t.Errorf("Violation rule = %s, want %s", decision.Violations[0].Rule, tt.rule)
+			}
+			if decision.Violations[0].Reason == "" {
+				t.Error("Violation must carry an explanation")
+			}
+		})
+	}
+}
+
+func TestPolicyCommonFixesAllowed(t *testing.T) {
+	policy := DefaultPolicy()
+	for id, fix := range GetCommonFixes() {
+		if decision := policy.Evaluate(fix, false); !decision.Allowed {
+			t.Errorf("Common fix %s denied: %v", id, decision.Violations)
+		}
+	}
+}
+
+func TestPolicyUnattendedRisk(t *testing.T) {
+	policy := DefaultPolicy()
+	fix := &Fix{Title: "Medium", Commands: []string{"echo ok"}, RiskLevel: RiskMedium}
+
+	if decision := policy.Evaluate(fix, false); !decision.Allowed {
+		t.Errorf("Attended run should not be risk limited: %v", decision.Violations)
+	}
+
+	decision := policy.Evaluate(fix, true)
+	if decision.Allowed || decision.Violations[0].Rule != "max_unattended_risk" {
+		t.Errorf("Expected max_unattended_risk violation, got %+v", decision)
+	}
+}
+
+func TestAutoApproveCeiling(t *testing.T) {
+	executor := newTestExecutor(t)
+	policy := DefaultPolicy()
+	policy.MaxUnattendedRisk = RiskMedium
+	executor.SetPolicy(policy)
+
+	executor.SetAutoApprove(RiskHigh)
+	if got := executor.AutoApproveCeiling(); got != RiskMedium {
+		t.Errorf("AutoApproveCeiling() = %v, want the policy's Medium", got)
+	}
+	executor.SetAutoApprove(RiskLow)
+	if got := executor.AutoApproveCeiling(); got != RiskLow {
+		t.Errorf("AutoApproveCeiling() = %v, want the requested Low", got)
+	}
+}
+
+func TestPolicyAllowedBinariesAndNetwork(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.AllowedBinaries = []string{"apt-get", "systemctl"}
+	policy.AllowNetwork = false
+
+	tests := []struct {
+		command string
+		rule    string
+	}{
+		{command: "systemctl restart ssh"},
+		{command: "echo builtins are always allowed"},
+		{command: "apt-get clean"},
+		{command: "apt-get update", rule: "allow_network"},
+		{command: "curl -I http://example.com", rule: "allowed_binaries"},
+		{command: "apt-get -q install vim", rule: "allow_network"},
+	}
+
+	for _, tt := range tests {
+		decision := policy.Evaluate(&Fix{Title: "t", Commands: []string{tt.command}}, false)
+		if tt.rule == "" {
+			if !decision.Allowed {
+				t.Errorf("%q denied: %v", tt.command, decision.Violations)
+			}
+			continue
+		}
+		found := false
+		for _, v := range decision.Violations {
+			found = found || v.Rule == tt.rule
+		}
+		if !found {
+			t.Errorf("%q: expected %s violation, got %v", tt.command, tt.rule, decision.Violations)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+
+	policy, err := LoadPolicy(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadPolicy() on missing file error = %v", err)
+	}
+	if policy.Source != "built-in defaults" {
+		t.Errorf("Source = %q, want built-in defaults", policy.Source)
+	}
+
+	path := filepath.Join(dir, "policy.yaml")
+	os.WriteFile(path, []byte(`
+max_unattended_risk: medium
+allow_network: false
+forbidden_paths:
+  - /srv/**
+`), 0644)
+
+	policy, err = LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	if policy.MaxUnattendedRisk != RiskMedium {
+		t.Errorf("MaxUnattendedRisk = %v, want Medium", policy.MaxUnattendedRisk)
+	}
+	if policy.AllowNetwork {
+		t.Error("Expected allow_network to be false")
+	}
+	if len(policy.DeniedBinaries) == 0 {
+		t.Error("Unset keys should keep their defaults")
+	}
+	decision := policy.Evaluate(&Fix{Title: "t", Commands: []string{"rm /srv/www/index.html"}}, false)
+	if decision.Allowed {
+		t.Error("Expected /srv/** to forbid files below /srv")
+	}
+
+	for name, content := range map[string]string{
+		"unknown key": "allowed_binary: [ls]\n",
+		"bad risk":    "max_unattended_risk: extreme\n",
+		"bad pattern": "forbidden_paths: ['/etc/[']\n",
+	} {
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf("%s: expected LoadPolicy() error", name)
+		}
+	}
+}
+
+func TestLoadConfiguredPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	if err := os.WriteFile(path, []byte("max_unattended_risk: medium\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0666); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.New()
+	cfg.PolicyFile = path
+
+	cfg.IsRoot = false
+	if policy, err := LoadConfiguredPolicy(cfg); err != nil || policy.MaxUnattendedRisk != RiskMedium {
+		t.Errorf("LoadConfiguredPolicy() as a user = %+v, %v", policy, err)
+	}
+	cfg.IsRoot = true
+	if _, err := LoadConfiguredPolicy(cfg); err == nil || !strings.Contains(err.Error(), "untrusted policy") {
+		t.Errorf("LoadConfiguredPolicy() of a world-writable policy as root error = %v", err)
+	}
+
+	// A missing policy is not a trust problem
+	cfg.PolicyFile = filepath.Join(dir, "missing.yaml")
+	if _, err := LoadConfiguredPolicy(cfg); err != nil {
+		t.Errorf("LoadConfiguredPolicy() of a missing policy error = %v", err)
+	}
+}
+
+func TestExecuteFixPolicyDenied(t *testing.T) {
+	executor := newTestExecutor(t)
+	policy := DefaultPolicy()
+	policy.DeniedBinaries = append(policy.DeniedBinaries, "touch")
+	executor.SetPolicy(policy)
+
+	target := filepath.Join(t.TempDir(), "marker")
+	_, err := executor.ExecuteFix(&Fix{Title: "Denied", Commands: []string{"touch " + target}})
+
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Expected PolicyError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "denied binaries") {
+		t.Errorf("Error should explain the denial: %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("Denied fix must not run")
+	}
+}
@@ -0,0 +1,110 @@
+package fixes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Prompter asks a person a question and returns their answer. Whoever
+// drives the executor supplies one: the terminal, the interactive menu, or
+// a test replaying answers
+type Prompter interface {
+	Prompt(question string) (string, error)
+}
+
+// LinePrompter writes questions to a writer and reads one line per answer
+type LinePrompter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	scanner *bufio.Scanner
+}
+
+// NewLinePrompter creates a prompter reading answers from in. Answers read
+// ahead are kept between prompts, so one prompter should own the reader
+func NewLinePrompter(in io.Reader, out io.Writer) *LinePrompter {
+	return &LinePrompter{out: out, scanner: bufio.NewScanner(in)}
+}
+
+// Prompt writes the question and waits for a line; io.EOF means no more
+// answers will come
+func (p *LinePrompter) Prompt(question string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprint(p.out, question)
+	if p.scanner.Scan() {
+		return strings.TrimSpace(p.scanner.Text()), nil
+	}
+	if err := p.scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+var (
+	terminalOnce     sync.Once
+	terminalPrompter *LinePrompter
+)
+
+// Terminal returns the prompter for stdin and stdout, shared by every
+// executor in the process so that no buffered input is lost between them
+func Terminal() Prompter {
+	terminalOnce.Do(func() {
+		terminalPrompter = NewLinePrompter(os.Stdin, os.Stdout)
+	})
+	return terminalPrompter
+}
+
+// PromptConfirmer is a Confirmer that shows fix details on an output and
+// asks a Prompter for a yes or no answer. Anything else, including no
+// answer at all, declines
+type PromptConfirmer struct {
+	out      io.Writer
+	prompter Prompter
+}
+
+// NewPromptConfirmer creates a confirmer writing fix details to out
+func NewPromptConfirmer(out io.Writer, prompter Prompter) *PromptConfirmer {
+	return &PromptConfirmer{out: out, prompter: prompter}
+}
+
+// ConfirmFix shows fix details and asks for user confirmation
+func (c *PromptConfirmer) ConfirmFix(fix *Fix) bool {
+	fmt.Fprintf(c.out, "\n🔧 Fix Details:\n")
+	fmt.Fprintf(c.out, "Title: %s\n", fix.Title)
+	fmt.Fprintf(c.out, "Description: %s\n", fix.Description)
+	fmt.Fprintf(c.out, "Risk Level: %s\n", fix.RiskLevel.String())
+	fmt.Fprintf(c.out, "Requires Root: %t\n", fix.RequiresRoot)
+	fmt.Fprintf(c.out, "Reversible: %t\n", fix.Reversible)
+
+	fmt.Fprintf(c.out, "\nCommands to execute:\n")
+	for i, cmd := range fix.Commands {
+		fmt.Fprintf(c.out, "  %d. %s\n", i+1, cmd)
+	}
+
+	if fix.RiskLevel >= RiskHigh {
+		fmt.Fprintf(c.out, "\n⚠️  WARNING: This is a %s risk operation!\n", fix.RiskLevel.String())
+		fmt.Fprintf(c.out, "Please review the commands carefully before proceeding.\n")
+	}
+
+	return c.askYesNo("\nDo you want to proceed? (y/N): ")
+}
+
+// ConfirmReverse asks if the user wants to reverse partially executed changes
+func (c *PromptConfirmer) ConfirmReverse(fix *Fix, failedAt int) bool {
+	fmt.Fprintf(c.out, "\n❌ Fix failed at step %d.\n", failedAt+1)
+	return c.askYesNo("This fix is reversible. Do you want to undo the changes made so far? (y/N): ")
+}
+
+func (c *PromptConfirmer) askYesNo(question string) bool {
+	response, err := c.prompter.Prompt(question)
+	if err != nil {
+		return false
+	}
+	response = strings.ToLower(response)
+	return response == "y" || response == "yes"
+}
@@ -0,0 +1,52 @@
+package fixes
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLinePrompter(t *testing.T) {
+	var out strings.Builder
+	prompter := NewLinePrompter(strings.NewReader("  yes \nno\n"), &out)
+
+	for _, want := range []string{"yes", "no"} {
+		if got, err := prompter.Prompt("? "); err != nil || got != want {
+			t.Errorf("Prompt() = %q, %v; want %q", got, err, want)
+		}
+	}
+	if _, err := prompter.Prompt("? "); !errors.Is(err, io.EOF) {
+		t.Errorf("Prompt() after the last answer error = %v, want io.EOF", err)
+	}
+	if out.String() != "? ? ? " {
+		t.Errorf("questions = %q", out.String())
+	}
+}
+
+func TestPromptConfirmer(t *testing.T) {
+	var out strings.Builder
+	confirmer := NewPromptConfirmer(&out, NewLinePrompter(strings.NewReader("y\nn\n"), &out))
+	fix := &Fix{Title: "Wipe cache", Commands: []string{"rm -rf /var/cache/foo"}, RiskLevel: RiskHigh}
+
+	if !confirmer.ConfirmFix(fix) {
+		t.Error("ConfirmFix() = false after answering y")
+	}
+	for _, want := range []string{"Title: Wipe cache", "  1. rm -rf /var/cache/foo", "WARNING: This is a High risk operation", "Do you want to proceed? (y/N): "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if confirmer.ConfirmReverse(fix, 1) {
+		t.Error("ConfirmReverse() = true after answering n")
+	}
+	if !strings.Contains(out.String(), "Fix failed at step 2.") {
+		t.Errorf("output = %s", out.String())
+	}
+
+	// Running out of answers declines
+	if confirmer.ConfirmFix(fix) {
+		t.Error("ConfirmFix() = true without an answer")
+	}
+}
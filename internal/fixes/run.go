@@ -0,0 +1,138 @@
+package fixes
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxOutputBytes bounds how much of each output stream is kept per step
+const maxOutputBytes = 4096
+
+// RunStatus describes how a fix run ended
+type RunStatus string
+
+const (
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+	RunCancelled RunStatus = "cancelled"
+	RunDryRun    RunStatus = "dry-run" // Planned only; nothing was executed
+)
+
+// StepResult records the execution of a single fix command
+type StepResult struct {
+	Step      int       `json:"step"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	ExitCode  int       `json:"exit_code"`
+	Stdout    string    `json:"stdout,omitempty"`
+	Stderr    string    `json:"stderr,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	TimedOut  bool      `json:"timed_out,omitempty"` // Killed at the command deadline
+}
+
+// ReversalResult records an attempt to undo a partially applied fix
+type ReversalResult struct {
+	Attempted bool         `json:"attempted"`
+	Succeeded bool         `json:"succeeded"`
+	Steps     []StepResult `json:"steps,omitempty"`
+}
+
+// FixRun is the complete record of one attempt to apply a fix
+type FixRun struct {
+	ID        string          `json:"id"`
+	FixID     string          `json:"fix_id"`
+	Title     string          `json:"title"`
+	RiskLevel RiskLevel       `json:"risk_level"`
+	Hostname  string          `json:"hostname"`
+	User      string          `json:"user"`
+	StartedAt time.Time       `json:"started_at"`
+	EndedAt   time.Time       `json:"ended_at"`
+	Status    RunStatus       `json:"status"`
+	Error     string          `json:"error,omitempty"`
+	Steps     []StepResult    `json:"steps"`
+	Snapshots []string        `json:"snapshots,omitempty"` // Files snapshotted before the run
+	Reversal  *ReversalResult `json:"reversal,omitempty"`
+}
+
+// newFixRun starts a run record for the given fix
+func newFixRun(fix *Fix) *FixRun {
+	hostname, _ := os.Hostname()
+	return &FixRun{
+		ID:        newRunID(),
+		FixID:     fix.ID,
+		Title:     fix.Title,
+		RiskLevel: fix.RiskLevel,
+		Hostname:  hostname,
+		User:      currentUser(),
+		StartedAt: time.Now(),
+		Steps:     []StepResult{},
+	}
+}
+
+// finish closes the run with the given status and error
+func (r *FixRun) finish(status RunStatus, err error) {
+	r.EndedAt = time.Now()
+	r.Status = status
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// Duration returns how long the run took
+func (r *FixRun) Duration() time.Duration {
+	if r.EndedAt.IsZero() {
+		return 0
+	}
+	return r.EndedAt.Sub(r.StartedAt)
+}
+
+// newStepResult converts a command result into a journal step
+func newStepResult(step int, started time.Time, result CommandResult, err error) StepResult {
+	stepResult := StepResult{
+		Step:      step,
+		Command:   result.Command,
+		StartedAt: started,
+		EndedAt:   time.Now(),
+		ExitCode:  result.ExitCode,
+		Stdout:    truncateOutput(result.Stdout),
+		Stderr:    truncateOutput(result.Stderr),
+		TimedOut:  result.TimedOut,
+	}
+	if err != nil {
+		stepResult.Error = err.Error()
+	}
+	return stepResult
+}
+
+// truncateOutput keeps the tail of long command output, which is where
+// errors usually end up
+func truncateOutput(output string) string {
+	if len(output) <= maxOutputBytes {
+		return output
+	}
+	dropped := len(output) - maxOutputBytes
+	return fmt.Sprintf("[... %d bytes truncated ...]\n%s", dropped, output[dropped:])
+}
+
+// newRunID returns a sortable, unique identifier for a fix run
+func newRunID() string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return time.Now().Format("20060102-150405.000000")
+	}
+	return fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), hex.EncodeToString(suffix))
+}
+
+func currentUser() string {
+	for _, key := range []string{"SUDO_USER", "USER", "LOGNAME"} {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value
+		}
+	}
+	return fmt.Sprintf("uid:%d", os.Getuid())
+}
@@ -0,0 +1,274 @@
+package fixes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+const snapshotManifest = "manifest.json"
+
+// FileSnapshot records the state of one file before a fix modified it
+type FileSnapshot struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Mode    os.FileMode `json:"mode,omitempty"`
+	UID     int         `json:"uid"`
+	GID     int         `json:"gid"`
+	SHA256  string      `json:"sha256,omitempty"`
+	Stored  string      `json:"stored,omitempty"`       // Copy of the original inside the snapshot directory
+	After   string      `json:"after_sha256,omitempty"` // Checksum once the fix finished, to detect later edits
+}
+
+// Snapshot is the set of files saved before a fix run
+type Snapshot struct {
+	RunID     string         `json:"run_id"`
+	FixID     string         `json:"fix_id"`
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []FileSnapshot `json:"files"`
+
+	dir string
+}
+
+// SnapshotStore keeps file snapshots for fix runs under a state directory
+type SnapshotStore struct {
+	dir string
+}
+
+// NewSnapshotStore returns the snapshot store inside the given state directory
+func NewSnapshotStore(stateDir string) *SnapshotStore {
+	return &SnapshotStore{dir: filepath.Join(stateDir, "snapshots")}
+}
+
+// Dir returns the directory holding all snapshots
+func (s *SnapshotStore) Dir() string {
+	return s.dir
+}
+
+// Take copies the given files before a fix run modifies them. Files that do
+// not exist yet are recorded so that rollback can remove them again.
+func (s *SnapshotStore) Take(runID, fixID string, paths []string) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		RunID:     runID,
+		FixID:     fixID,
+		CreatedAt: time.Now(),
+		Files:     []FileSnapshot{},
+		dir:       filepath.Join(s.dir, runID),
+	}
+
+	if err := os.MkdirAll(snapshot.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	for i, path := range paths {
+		file := FileSnapshot{Path: filepath.Clean(path)}
+
+		// Follow symlinks such as /etc/resolv.conf so the real file is restored
+		// and the link itself is left alone
+		if resolved, err := filepath.EvalSymlinks(file.Path); err == nil {
+			file.Path = resolved
+		}
+
+		info, err := os.Stat(file.Path)
+		switch {
+		case os.IsNotExist(err):
+			snapshot.Files = append(snapshot.Files, file)
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to stat %s: %w", file.Path, err)
+		case !info.Mode().IsRegular():
+			return nil, fmt.Errorf("cannot snapshot %s: not a regular file", file.Path)
+		}
+
+		file.Existed = true
+		file.Mode = info.Mode().Perm()
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			file.UID = int(stat.Uid)
+			file.GID = int(stat.Gid)
+		}
+		file.Stored = fmt.Sprintf("%03d-%s", i+1, filepath.Base(file.Path))
+
+		sum, err := copyFile(file.Path, filepath.Join(snapshot.dir, file.Stored), 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %w", file.Path, err)
+		}
+		file.SHA256 = sum
+
+		snapshot.Files = append(snapshot.Files, file)
+	}
+
+	if err := snapshot.save(); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Seal records the checksums of the snapshotted files after the fix ran, so
+// a later rollback can tell whether someone changed them since
+func (s *Snapshot) Seal() error {
+	for i := range s.Files {
+		sum, err := fileChecksum(s.Files[i].Path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.Files[i].After = sum
+	}
+	return s.save()
+}
+
+// Load reads the snapshot taken for a fix run. The manifest names the files
+// that rollback overwrites, so a snapshot that anyone but root or the
+// current user could have changed is refused
+func (s *SnapshotStore) Load(runID string) (*Snapshot, error) {
+	dir := filepath.Join(s.dir, filepath.Base(runID))
+	manifest := filepath.Join(dir, snapshotManifest)
+	for _, path := range []string{dir, manifest} {
+		if err := config.CheckTrusted(path); err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("no snapshot for run %s in %s", runID, s.dir)
+			}
+			return nil, fmt.Errorf("untrusted snapshot for run %s: %w", runID, err)
+		}
+	}
+
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot manifest: %w", err)
+	}
+
+	snapshot := &Snapshot{dir: dir}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("corrupt snapshot manifest for run %s: %w", runID, err)
+	}
+	return snapshot, nil
+}
+
+// Conflicts lists files that changed after the fix finished. Restoring them
+// would discard those later edits.
+func (s *Snapshot) Conflicts() []string {
+	var conflicts []string
+	for _, file := range s.Files {
+		current, err := fileChecksum(file.Path)
+		if err != nil && !os.IsNotExist(err) {
+			conflicts = append(conflicts, file.Path)
+			continue
+		}
+		if current != file.After {
+			conflicts = append(conflicts, file.Path)
+		}
+	}
+	return conflicts
+}
+
+// Verify checks that every stored copy still matches its recorded checksum
+func (s *Snapshot) Verify() error {
+	for _, file := range s.Files {
+		if !file.Existed {
+			continue
+		}
+		sum, err := fileChecksum(filepath.Join(s.dir, file.Stored))
+		if err != nil {
+			return fmt.Errorf("snapshot of %s is unreadable: %w", file.Path, err)
+		}
+		if sum != file.SHA256 {
+			return fmt.Errorf("snapshot of %s is corrupt: checksum %s, expected %s", file.Path, sum, file.SHA256)
+		}
+	}
+	return nil
+}
+
+// RestoreFile puts one file back the way it was before the fix ran
+func (s *Snapshot) RestoreFile(file FileSnapshot) error {
+	if !file.Existed {
+		if err := os.Remove(file.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file.Path, err)
+		}
+		return nil
+	}
+
+	// Write next to the target and rename so the file is never half-restored
+	tmp := file.Path + ".debian-doctor-restore"
+	sum, err := copyFile(filepath.Join(s.dir, file.Stored), tmp, file.Mode)
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to restore %s: %w", file.Path, err)
+	}
+	if sum != file.SHA256 {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to restore %s: checksum mismatch", file.Path)
+	}
+	if err := os.Chmod(tmp, file.Mode); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to restore mode of %s: %w", file.Path, err)
+	}
+	if os.Geteuid() == 0 {
+		if err := os.Chown(tmp, file.UID, file.GID); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("failed to restore owner of %s: %w", file.Path, err)
+		}
+	}
+	if err := os.Rename(tmp, file.Path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to restore %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+func (s *Snapshot) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, snapshotManifest), data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst and returns the SHA-256 of the copied content
+func copyFile(src, dst string, mode os.FileMode) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, hash), in); err != nil {
+		out.Close()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fileChecksum returns the SHA-256 of a file, or "" with the error if it
+// cannot be read
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,171 @@
+package fixes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"github.com/debian-doctor/debian-doctor/pkg/logger"
+)
+
+func newTestExecutor(t *testing.T) *Executor {
+	t.Helper()
+	cfg := config.New()
+	cfg.SetNonInteractive(true)
+	cfg.SetLogDir(t.TempDir())
+	cfg.SetStateDir(t.TempDir())
+	log, err := logger.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { log.Close() })
+	executor := NewExecutor(cfg, log)
+	executor.SetAutoApprove(RiskLow)
+	return executor
+}
+
+func TestSnapshotTakeAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "fstab")
+	created := filepath.Join(dir, "new.conf")
+	if err := os.WriteFile(existing, []byte("original\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewSnapshotStore(t.TempDir())
+	snapshot, err := store.Take("run-1", "test_fix", []string{existing, created})
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+
+	os.WriteFile(existing, []byte("modified\n"), 0640)
+	os.WriteFile(created, []byte("created\n"), 0644)
+	if err := snapshot.Seal(); err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	loaded, err := store.Load("run-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := loaded.Verify(); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if conflicts := loaded.Conflicts(); len(conflicts) != 0 {
+		t.Errorf("Unexpected conflicts: %v", conflicts)
+	}
+
+	for _, file := range loaded.Files {
+		if err := loaded.RestoreFile(file); err != nil {
+			t.Fatalf("RestoreFile(%s) error = %v", file.Path, err)
+		}
+	}
+
+	content, _ := os.ReadFile(existing)
+	if string(content) != "original\n" {
+		t.Errorf("Restored content = %q, want %q", content, "original\n")
+	}
+	info, _ := os.Stat(existing)
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Restored mode = %v, want 0640", info.Mode().Perm())
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("Expected file created by the fix to be removed")
+	}
+}
+
+func TestSnapshotDetectsCorruptionAndConflicts(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "resolv.conf")
+	os.WriteFile(target, []byte("nameserver 10.0.0.1\n"), 0644)
+
+	store := NewSnapshotStore(t.TempDir())
+	snapshot, err := store.Take("run-2", "reset_dns", []string{target})
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	os.WriteFile(target, []byte("nameserver 8.8.8.8\n"), 0644)
+	snapshot.Seal()
+
+	os.WriteFile(target, []byte("nameserver 9.9.9.9\n"), 0644)
+	if conflicts := snapshot.Conflicts(); len(conflicts) != 1 || conflicts[0] != target {
+		t.Errorf("Conflicts() = %v, want [%s]", conflicts, target)
+	}
+
+	stored := filepath.Join(store.Dir(), "run-2", snapshot.Files[0].Stored)
+	os.WriteFile(stored, []byte("tampered\n"), 0600)
+	if err := snapshot.Verify(); err == nil {
+		t.Error("Expected Verify() to detect a corrupt snapshot")
+	}
+}
+
+func TestSnapshotLoadRefusesUntrusted(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "fstab")
+	os.WriteFile(target, []byte("UUID=1234 / ext4 defaults 0 1\n"), 0644)
+
+	store := NewSnapshotStore(t.TempDir())
+	if _, err := store.Take("run-3", "edit_fstab", []string{target}); err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	dir := filepath.Join(store.Dir(), "run-3")
+	manifest := filepath.Join(dir, snapshotManifest)
+
+	for _, path := range []string{dir, manifest} {
+		info, _ := os.Stat(path)
+		os.Chmod(path, info.Mode().Perm()|0002)
+		if _, err := store.Load("run-3"); err == nil || !strings.Contains(err.Error(), "untrusted snapshot") {
+			t.Errorf("Load() with %s writable by others: error = %v, want untrusted snapshot", path, err)
+		}
+		os.Chmod(path, info.Mode().Perm())
+	}
+
+	if _, err := store.Load("run-3"); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+	if _, err := store.Load("run-missing"); err == nil || !strings.Contains(err.Error(), "no snapshot") {
+		t.Errorf("Load() of a missing run: error = %v, want no snapshot", err)
+	}
+}
+
+func TestExecuteFixSnapshotsAndRollback(t *testing.T) {
+	executor := newTestExecutor(t)
+	target := filepath.Join(t.TempDir(), "fstab")
+	os.WriteFile(target, []byte("UUID=1234 / ext4 defaults 0 1\n"), 0644)
+
+	run, err := executor.ExecuteFix(&Fix{
+		ID:        "append_line",
+		Title:     "Append Line",
+		Commands:  []string{"echo '/swapfile none swap sw 0 0' >> " + target},
+		RiskLevel: RiskLow,
+		Modifies:  []string{target},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteFix() error = %v", err)
+	}
+	if len(run.Snapshots) != 1 {
+		t.Fatalf("Expected 1 snapshotted file, got %v", run.Snapshots)
+	}
+
+	rollback, err := executor.Rollback(run.ID, false)
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if rollback.Status != RunSucceeded || len(rollback.Steps) != 1 {
+		t.Errorf("Unexpected rollback run: %+v", rollback)
+	}
+
+	content, _ := os.ReadFile(target)
+	if string(content) != "UUID=1234 / ext4 defaults 0 1\n" {
+		t.Errorf("File not restored, got %q", content)
+	}
+
+	runs, _ := executor.Journal().Runs()
+	if len(runs) != 2 || runs[1].FixID != "rollback" {
+		t.Errorf("Expected fix run and rollback run in journal, got %d runs", len(runs))
+	}
+
+	if _, err := executor.Rollback("no-such-run", false); err == nil {
+		t.Error("Expected error rolling back unknown run")
+	}
+}
@@ -0,0 +1,112 @@
+package history
+
+import (
+	"sort"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+)
+
+// Comparison is what changed between two runs
+type Comparison struct {
+	From        Run            `json:"from"`
+	To          Run            `json:"to"`
+	ScoreChange int            `json:"score_change"`
+	Regressed   []CheckChange  `json:"regressed"` // Checks that got worse
+	Recovered   []CheckChange  `json:"recovered"` // Checks that got better
+	Added       []CheckState   `json:"added"`     // Checks only in the newer run
+	Removed     []CheckState   `json:"removed"`   // Checks only in the older run
+	Resources   []MetricChange `json:"resources"` // Resource usage that moved
+	Metrics     []MetricChange `json:"metrics"`   // Check measurements that moved
+}
+
+// CheckChange is a check whose outcome differs between two runs
+type CheckChange struct {
+	Name string     `json:"name"`
+	From CheckState `json:"from"`
+	To   CheckState `json:"to"`
+}
+
+// MetricChange is a measurement that differs between two runs
+type MetricChange struct {
+	Name string  `json:"name"`
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// Delta is how much the measurement moved
+func (m MetricChange) Delta() float64 {
+	return m.To - m.From
+}
+
+// Compare reports what changed from one run to a later one
+func Compare(from, to Run) Comparison {
+	c := Comparison{From: from, To: to, ScoreChange: to.HealthScore - from.HealthScore}
+
+	for _, after := range to.Checks {
+		before, ok := from.Check(after.Name)
+		if !ok {
+			c.Added = append(c.Added, after)
+			continue
+		}
+		change := CheckChange{Name: after.Name, From: before, To: after}
+		switch {
+		case rank(after) > rank(before):
+			c.Regressed = append(c.Regressed, change)
+		case rank(after) < rank(before):
+			c.Recovered = append(c.Recovered, change)
+		}
+	}
+	for _, before := range from.Checks {
+		if _, ok := to.Check(before.Name); !ok {
+			c.Removed = append(c.Removed, before)
+		}
+	}
+
+	c.Resources = changes(resourceMetrics(from.Resources), resourceMetrics(to.Resources))
+	c.Metrics = changes(from.Metrics, to.Metrics)
+	return c
+}
+
+// rank orders check outcomes from best to worst. A check that did not run
+// says nothing about the machine, so it ranks with a healthy one
+func rank(check CheckState) checks.Severity {
+	if check.Status == checks.StatusSkipped || check.Status == checks.StatusCancelled {
+		return checks.SeverityInfo
+	}
+	return check.Severity
+}
+
+// resourceMetrics names a run's resource measurements like check metrics
+func resourceMetrics(r Resources) map[string]float64 {
+	metrics := map[string]float64{
+		"cpu_percent":    r.CPUPercent,
+		"memory_percent": r.MemoryPercent,
+		"swap_percent":   r.SwapPercent,
+		"load1":          r.Load1,
+	}
+	for path, used := range r.Disks {
+		metrics["disk_percent "+path] = used
+	}
+	return metrics
+}
+
+// changes lists the measurements that differ, sorted by name. Measurements
+// missing from one side count as zero there
+func changes(from, to map[string]float64) []MetricChange {
+	names := make(map[string]bool)
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+
+	var moved []MetricChange
+	for name := range names {
+		if from[name] != to[name] {
+			moved = append(moved, MetricChange{Name: name, From: from[name], To: to[name]})
+		}
+	}
+	sort.Slice(moved, func(i, j int) bool { return moved[i].Name < moved[j].Name })
+	return moved
+}
@@ -0,0 +1,267 @@
+// Package history keeps a record of past runs so that the health of a
+// machine can be followed over time and two runs can be compared
+package history
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/summary"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// FileName is the name of the history file inside the state directory
+const FileName = "history.jsonl"
+
+// Run is what the history keeps of one run: enough to follow the health
+// score and compare check results and measurements, not the full report
+type Run struct {
+	ID             string             `json:"id"`
+	Timestamp      time.Time          `json:"timestamp"`
+	Hostname       string             `json:"hostname"`
+	HealthScore    int                `json:"health_score"`
+	CriticalIssues int                `json:"critical_issues"`
+	Warnings       int                `json:"warnings"`
+	Checks         []CheckState       `json:"checks"`
+	Resources      Resources          `json:"resources"`
+	Metrics        map[string]float64 `json:"metrics,omitempty"` // Measurements reported by checks
+}
+
+// CheckState is the outcome of one check in a run
+type CheckState struct {
+	Name     string          `json:"name"`
+	Severity checks.Severity `json:"severity"`
+	Status   checks.Status   `json:"status,omitempty"`
+	Message  string          `json:"message"`
+}
+
+// Resources are the resource measurements of a run
+type Resources struct {
+	CPUPercent    float64            `json:"cpu_percent"`
+	MemoryPercent float64            `json:"memory_percent"`
+	SwapPercent   float64            `json:"swap_percent"`
+	Load1         float64            `json:"load1"`
+	Disks         map[string]float64 `json:"disks,omitempty"` // Used percent by mount point
+}
+
+// NewRun extracts what the history keeps from a report
+func NewRun(s *summary.SystemSummary) Run {
+	run := Run{
+		ID:             newRunID(s.Timestamp),
+		Timestamp:      s.Timestamp,
+		Hostname:       s.SystemInfo.Hostname,
+		HealthScore:    s.HealthScore,
+		CriticalIssues: len(s.CriticalIssues),
+		Warnings:       len(s.Warnings),
+		Checks:         []CheckState{},
+		Resources: Resources{
+			CPUPercent:    s.ResourceStatus.CPUUsage,
+			MemoryPercent: s.ResourceStatus.MemoryPercent,
+			SwapPercent:   s.ResourceStatus.SwapPercent,
+			Load1:         s.ResourceStatus.LoadAverage[0],
+			Disks:         make(map[string]float64),
+		},
+		Metrics: make(map[string]float64),
+	}
+	for _, disk := range s.ResourceStatus.DiskUsage {
+		run.Resources.Disks[disk.Path] = disk.UsedPercent
+	}
+	for _, result := range s.CheckResults.GetAllChecks() {
+		run.Checks = append(run.Checks, CheckState{
+			Name:     result.Name,
+			Severity: result.Severity,
+			Status:   result.Status,
+			Message:  result.Message,
+		})
+		for name, value := range result.Metrics {
+			run.Metrics[name] = value
+		}
+	}
+	return run
+}
+
+// Check returns the state of the named check in the run
+func (r Run) Check(name string) (CheckState, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return CheckState{}, false
+}
+
+// newRunID names a run after when it started, with a random suffix so that
+// runs started in the same second stay apart
+func newRunID(started time.Time) string {
+	if started.IsZero() {
+		started = time.Now()
+	}
+	suffix := make([]byte, 2)
+	if _, err := rand.Read(suffix); err != nil {
+		return started.Format("20060102-150405.000000")
+	}
+	return fmt.Sprintf("%s-%s", started.Format("20060102-150405"), hex.EncodeToString(suffix))
+}
+
+// Store is the run history, stored as JSON lines
+type Store struct {
+	path string
+}
+
+// NewStore returns the history stored in the given directory
+func NewStore(dir string) *Store {
+	return &Store{path: filepath.Join(dir, FileName)}
+}
+
+// Path returns the location of the history file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append adds a run to the history
+func (s *Store) Append(run Run) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Runs returns every run in the history, oldest first. A missing history is
+// not an error; nothing has been recorded yet
+func (s *Store) Runs() ([]Run, error) {
+	runs := []Run{}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return runs, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("corrupt history entry at line %d: %w", line, err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Timestamp.Before(runs[j].Timestamp) })
+	return runs, nil
+}
+
+// Find returns the run with the given ID. "latest" and "previous" name the
+// last and second to last runs
+func (s *Store) Find(id string) (*Run, error) {
+	runs, err := s.Runs()
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	switch id {
+	case "latest":
+		index = len(runs) - 1
+	case "previous":
+		index = len(runs) - 2
+	default:
+		for i := range runs {
+			if runs[i].ID == id {
+				index = i
+			}
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("no run %s in %s", id, s.path)
+	}
+	return &runs[index], nil
+}
+
+// Prune drops the oldest runs so that at most max remain. The history is
+// rewritten through a temporary file so that a crash cannot truncate it
+func (s *Store) Prune(max int) error {
+	runs, err := s.Runs()
+	if err != nil || max <= 0 || len(runs) <= max {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), FileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	for _, run := range runs[len(runs)-max:] {
+		data, err := json.Marshal(run)
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to encode run: %w", err)
+		}
+		writer.Write(append(data, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Record adds a report to the history in the configured state directory and
+// drops runs beyond the configured limit. Nothing is kept when the history
+// is disabled
+func Record(cfg *config.Config, s *summary.SystemSummary) (*Run, error) {
+	if !cfg.History.Enabled {
+		return nil, nil
+	}
+	store := NewStore(cfg.StateDir)
+	run := NewRun(s)
+	if err := store.Append(run); err != nil {
+		return nil, err
+	}
+	if err := store.Prune(cfg.History.MaxRuns); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
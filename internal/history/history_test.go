@@ -0,0 +1,134 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/summary"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+func testRun(id string, at time.Time, score int, checkStates ...CheckState) Run {
+	return Run{ID: id, Timestamp: at, HealthScore: score, Checks: checkStates}
+}
+
+func TestNewRun(t *testing.T) {
+	results := checks.NewResults()
+	packages := checks.CheckResult{Name: "Package System", Severity: checks.SeverityWarning, Message: "Held packages detected"}
+	packages.SetMetric("upgradable_packages", 12)
+	results.AddResult(packages)
+
+	s := &summary.SystemSummary{
+		Timestamp:      time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC),
+		SystemInfo:     summary.SystemInfo{Hostname: "web01"},
+		ResourceStatus: summary.ResourceStatus{MemoryPercent: 42, DiskUsage: []summary.DiskInfo{{Path: "/", UsedPercent: 81}}},
+		CheckResults:   results,
+		HealthScore:    95,
+		Warnings:       []string{"Held packages detected"},
+	}
+
+	run := NewRun(s)
+	if run.HealthScore != 95 || run.Warnings != 1 || run.Hostname != "web01" {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+	if run.ID[:15] != "20261001-093000" {
+		t.Errorf("Run ID %q should start with the run's time", run.ID)
+	}
+	if check, ok := run.Check("Package System"); !ok || check.Severity != checks.SeverityWarning {
+		t.Errorf("Check state = %+v, %v", check, ok)
+	}
+	if run.Metrics["upgradable_packages"] != 12 || run.Resources.Disks["/"] != 81 {
+		t.Errorf("Measurements not kept: %+v %+v", run.Metrics, run.Resources)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if runs, err := store.Runs(); err != nil || len(runs) != 0 {
+		t.Fatalf("Empty history = %v, %v", runs, err)
+	}
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		if err := store.Append(testRun(string(rune('a'+i)), start.Add(time.Duration(i)*time.Hour), 90-i)); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	latest, err := store.Find("latest")
+	if err != nil || latest.ID != "e" {
+		t.Errorf("Find(latest) = %v, %v", latest, err)
+	}
+	previous, err := store.Find("previous")
+	if err != nil || previous.ID != "d" {
+		t.Errorf("Find(previous) = %v, %v", previous, err)
+	}
+	if _, err := store.Find("missing"); err == nil {
+		t.Error("Expected an error for an unknown run")
+	}
+
+	if err := store.Prune(3); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	runs, err := store.Runs()
+	if err != nil || len(runs) != 3 || runs[0].ID != "c" || runs[2].ID != "e" {
+		t.Errorf("After pruning to 3 runs got %v, %v", runs, err)
+	}
+}
+
+func TestRecordDisabled(t *testing.T) {
+	cfg := config.New()
+	cfg.StateDir = t.TempDir()
+	cfg.History.Enabled = false
+
+	run, err := Record(cfg, &summary.SystemSummary{})
+	if run != nil || err != nil {
+		t.Errorf("Record with history disabled = %v, %v", run, err)
+	}
+	if runs, _ := NewStore(cfg.StateDir).Runs(); len(runs) != 0 {
+		t.Errorf("Disabled history recorded %d runs", len(runs))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	from := testRun("a", time.Unix(0, 0), 90,
+		CheckState{Name: "Disk Space", Severity: checks.SeverityInfo},
+		CheckState{Name: "Memory", Severity: checks.SeverityWarning},
+		CheckState{Name: "Services", Status: checks.StatusSkipped},
+		CheckState{Name: "Network", Severity: checks.SeverityInfo},
+	)
+	from.Resources = Resources{MemoryPercent: 40, Disks: map[string]float64{"/": 80}}
+	from.Metrics = map[string]float64{"upgradable_packages": 3, "broken_packages": 0}
+
+	to := testRun("b", time.Unix(3600, 0), 60,
+		CheckState{Name: "Disk Space", Severity: checks.SeverityError, Message: "Root filesystem 96% full"},
+		CheckState{Name: "Memory", Severity: checks.SeverityInfo},
+		CheckState{Name: "Services", Severity: checks.SeverityInfo},
+		CheckState{Name: "Logs", Severity: checks.SeverityWarning},
+	)
+	to.Resources = Resources{MemoryPercent: 40, Disks: map[string]float64{"/": 96}}
+	to.Metrics = map[string]float64{"upgradable_packages": 10, "broken_packages": 0}
+
+	c := Compare(from, to)
+	if c.ScoreChange != -30 {
+		t.Errorf("ScoreChange = %d, want -30", c.ScoreChange)
+	}
+	if len(c.Regressed) != 1 || c.Regressed[0].Name != "Disk Space" {
+		t.Errorf("Regressed = %+v", c.Regressed)
+	}
+	// A skipped check that now runs cleanly neither regressed nor recovered
+	if len(c.Recovered) != 1 || c.Recovered[0].Name != "Memory" {
+		t.Errorf("Recovered = %+v", c.Recovered)
+	}
+	if len(c.Added) != 1 || c.Added[0].Name != "Logs" || len(c.Removed) != 1 || c.Removed[0].Name != "Network" {
+		t.Errorf("Added = %+v, Removed = %+v", c.Added, c.Removed)
+	}
+	if len(c.Resources) != 1 || c.Resources[0].Name != "disk_percent /" || c.Resources[0].Delta() != 16 {
+		t.Errorf("Resources = %+v", c.Resources)
+	}
+	if len(c.Metrics) != 1 || c.Metrics[0].Name != "upgradable_packages" || c.Metrics[0].Delta() != 7 {
+		t.Errorf("Metrics = %+v", c.Metrics)
+	}
+}
@@ -0,0 +1,495 @@
+package machine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Record kinds
+const (
+	KindCommand    = "command"
+	KindLookPath   = "lookpath"
+	KindFile       = "file"
+	KindStat       = "stat"
+	KindLstat      = "lstat"
+	KindDir        = "dir"
+	KindStatfs     = "statfs"
+	KindInterfaces = "interfaces"
+	KindCPUPercent = "cpu_percent"
+	KindLookupHost = "lookup_host"
+)
+
+// Error classes stored in records. Anything else is replayed as a plain error
+const (
+	errNotExist   = "not exist"
+	errPermission = "permission denied"
+	errNotFound   = "executable not found"
+)
+
+// Record is one observed interaction with the machine. A fixture is a file
+// of records, one JSON object per line
+type Record struct {
+	Kind       string      `json:"kind"`
+	Name       string      `json:"name"`
+	Args       []string    `json:"args,omitempty"`
+	Stdout     string      `json:"stdout,omitempty"`
+	Stderr     string      `json:"stderr,omitempty"`
+	ExitCode   int         `json:"exit_code,omitempty"`
+	Data       string      `json:"data,omitempty"`
+	Path       string      `json:"path,omitempty"` // LookPath result
+	Info       *FileInfo   `json:"info,omitempty"`
+	Entries    []FileInfo  `json:"entries,omitempty"`
+	Statfs     *Statfs     `json:"statfs,omitempty"`
+	Interfaces []Interface `json:"interfaces,omitempty"`
+	Percent    *float64    `json:"percent,omitempty"`
+	Addrs      []string    `json:"addrs,omitempty"` // LookupHost result
+	Error      string      `json:"error,omitempty"`
+}
+
+// FileInfo is the serialisable part of fs.FileInfo
+type FileInfo struct {
+	Name    string      `json:"name"`
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mod_time"`
+	UID     uint32      `json:"uid"`
+	GID     uint32      `json:"gid"`
+}
+
+func newFileInfo(info fs.FileInfo) FileInfo {
+	f := FileInfo{Name: info.Name(), Size: info.Size(), Mode: info.Mode(), ModTime: info.ModTime()}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		f.UID, f.GID = stat.Uid, stat.Gid
+	}
+	return f
+}
+
+// fileInfo adapts FileInfo to fs.FileInfo; the field and method names clash
+type fileInfo struct{ f FileInfo }
+
+func (i fileInfo) Name() string       { return i.f.Name }
+func (i fileInfo) Size() int64        { return i.f.Size }
+func (i fileInfo) Mode() fs.FileMode  { return i.f.Mode }
+func (i fileInfo) ModTime() time.Time { return i.f.ModTime }
+func (i fileInfo) IsDir() bool        { return i.f.Mode.IsDir() }
+func (i fileInfo) Sys() any           { return &syscall.Stat_t{Uid: i.f.UID, Gid: i.f.GID} }
+
+// dirEntry adapts FileInfo to fs.DirEntry
+type dirEntry struct{ f FileInfo }
+
+func (d dirEntry) Name() string               { return d.f.Name }
+func (d dirEntry) IsDir() bool                { return d.f.Mode.IsDir() }
+func (d dirEntry) Type() fs.FileMode          { return d.f.Mode.Type() }
+func (d dirEntry) Info() (fs.FileInfo, error) { return fileInfo{d.f}, nil }
+
+func errorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, fs.ErrNotExist):
+		return errNotExist
+	case errors.Is(err, fs.ErrPermission):
+		return errPermission
+	case errors.Is(err, exec.ErrNotFound):
+		return errNotFound
+	}
+	return err.Error()
+}
+
+func classError(op, name, class string) error {
+	switch class {
+	case "":
+		return nil
+	case errNotExist:
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	case errPermission:
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrPermission}
+	case errNotFound:
+		return &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+	return errors.New(class)
+}
+
+// NewRecorder wraps inner so every command and file access is appended to w
+// as a Record. Each record is written as soon as it is observed, so a
+// fixture survives the process exiting early
+func NewRecorder(inner *Machine, w io.Writer) *Machine {
+	enc := json.NewEncoder(w)
+	return Capture(inner, func(rec Record) {
+		// A failed write only loses the fixture, never the diagnosis
+		_ = enc.Encode(rec)
+	})
+}
+
+// Capture wraps inner so fn sees a Record of every command and file access.
+// Calls to fn are never concurrent
+func Capture(inner *Machine, fn func(Record)) *Machine {
+	r := &recorder{inner: inner, emit: fn}
+	return &Machine{Commands: r, Files: r, Host: r}
+}
+
+type recorder struct {
+	inner *Machine
+	mu    sync.Mutex
+	emit  func(Record)
+}
+
+func (r *recorder) write(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emit(rec)
+}
+
+func (r *recorder) Exec(name string, args ...string) Result {
+	return r.ExecContext(context.Background(), name, args...)
+}
+
+func (r *recorder) ExecContext(ctx context.Context, name string, args ...string) Result {
+	result := r.inner.WithContext(ctx).Commands.Exec(name, args...)
+	r.write(Record{
+		Kind:     KindCommand,
+		Name:     name,
+		Args:     args,
+		Stdout:   string(result.Stdout),
+		Stderr:   string(result.Stderr),
+		ExitCode: result.ExitCode,
+		Error:    errorClass(result.Err),
+	})
+	return result
+}
+
+func (r *recorder) LookPath(name string) (string, error) {
+	path, err := r.inner.Commands.LookPath(name)
+	r.write(Record{Kind: KindLookPath, Name: name, Path: path, Error: errorClass(err)})
+	return path, err
+}
+
+func (r *recorder) ReadFile(name string) ([]byte, error) {
+	data, err := r.inner.Files.ReadFile(name)
+	r.write(Record{Kind: KindFile, Name: name, Data: string(data), Error: errorClass(err)})
+	return data, err
+}
+
+func (r *recorder) Stat(name string) (fs.FileInfo, error) {
+	info, err := r.inner.Files.Stat(name)
+	r.write(infoRecord(KindStat, name, info, err))
+	return info, err
+}
+
+func (r *recorder) Lstat(name string) (fs.FileInfo, error) {
+	info, err := r.inner.Files.Lstat(name)
+	r.write(infoRecord(KindLstat, name, info, err))
+	return info, err
+}
+
+func infoRecord(kind, name string, info fs.FileInfo, err error) Record {
+	rec := Record{Kind: kind, Name: name, Error: errorClass(err)}
+	if err == nil {
+		fi := newFileInfo(info)
+		rec.Info = &fi
+	}
+	return rec
+}
+
+func (r *recorder) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := r.inner.Files.ReadDir(name)
+	rec := Record{Kind: KindDir, Name: name, Error: errorClass(err)}
+	for _, entry := range entries {
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			continue
+		}
+		rec.Entries = append(rec.Entries, newFileInfo(info))
+	}
+	r.write(rec)
+	return entries, err
+}
+
+func (r *recorder) Statfs(path string) (Statfs, error) {
+	stat, err := r.inner.Files.Statfs(path)
+	rec := Record{Kind: KindStatfs, Name: path, Error: errorClass(err)}
+	if err == nil {
+		rec.Statfs = &stat
+	}
+	r.write(rec)
+	return stat, err
+}
+
+func (r *recorder) Interfaces() ([]Interface, error) {
+	ifaces, err := r.inner.Host.Interfaces()
+	r.write(Record{Kind: KindInterfaces, Interfaces: ifaces, Error: errorClass(err)})
+	return ifaces, err
+}
+
+func (r *recorder) CPUPercent() (float64, error) {
+	percent, err := r.inner.Host.CPUPercent()
+	rec := Record{Kind: KindCPUPercent, Error: errorClass(err)}
+	if err == nil {
+		rec.Percent = &percent
+	}
+	r.write(rec)
+	return percent, err
+}
+
+func (r *recorder) LookupHost(host string) ([]string, error) {
+	addrs, err := r.inner.Host.LookupHost(host)
+	r.write(Record{Kind: KindLookupHost, Name: host, Addrs: addrs, Error: errorClass(err)})
+	return addrs, err
+}
+
+// Fixture is a set of records that can be replayed as a Machine. Commands
+// are matched on their name and exact arguments, files on their path
+type Fixture struct {
+	records map[string]Record
+}
+
+// NewFixture creates an empty fixture
+func NewFixture() *Fixture {
+	return &Fixture{records: make(map[string]Record)}
+}
+
+// LoadFixture reads a fixture written by a recorder. When the same
+// interaction was recorded twice the later record wins
+func LoadFixture(path string) (*Fixture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fixture: %w", err)
+	}
+	defer file.Close()
+
+	f, err := ReadFixture(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// ReadFixture parses JSON-lines records from r
+func ReadFixture(r io.Reader) (*Fixture, error) {
+	f := NewFixture()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		f.Add(rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Add stores a record, replacing any earlier one for the same interaction
+func (f *Fixture) Add(rec Record) {
+	f.records[recordKey(rec.Kind, rec.Name, rec.Args)] = rec
+}
+
+// AddCommand records the output and exit code of a program
+func (f *Fixture) AddCommand(stdout string, exitCode int, name string, args ...string) {
+	f.Add(Record{Kind: KindCommand, Name: name, Args: args, Stdout: stdout, ExitCode: exitCode})
+}
+
+// AddFile records a regular file's content. Its parent directories are
+// listed and stat-able so walks find it
+func (f *Fixture) AddFile(path, content string) {
+	f.Add(Record{Kind: KindFile, Name: path, Data: content})
+	f.addEntry(path, FileInfo{Name: filepath.Base(path), Size: int64(len(content)), Mode: 0644})
+}
+
+// AddDir records an empty directory
+func (f *Fixture) AddDir(path string) {
+	f.addEntry(path, FileInfo{Name: filepath.Base(path), Mode: fs.ModeDir | 0755})
+}
+
+// addEntry makes info visible to Stat, Lstat and its parent's ReadDir,
+// creating parent directories as needed
+func (f *Fixture) addEntry(path string, info FileInfo) {
+	path = filepath.Clean(path)
+	for _, kind := range []string{KindStat, KindLstat} {
+		f.Add(Record{Kind: kind, Name: path, Info: &info})
+	}
+	if _, ok := f.lookup(KindDir, path, nil); info.Mode.IsDir() && !ok {
+		f.Add(Record{Kind: KindDir, Name: path})
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return
+	}
+	if _, ok := f.lookup(KindDir, parent, nil); !ok {
+		f.AddDir(parent)
+	}
+	dir, _ := f.lookup(KindDir, parent, nil)
+	entries := []FileInfo{info}
+	for _, entry := range dir.Entries {
+		if entry.Name != info.Name {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	dir.Entries = entries
+	f.Add(dir)
+}
+
+// Len returns the number of distinct recorded interactions
+func (f *Fixture) Len() int {
+	return len(f.records)
+}
+
+// Machine returns a machine that answers from the fixture. Programs that
+// were not recorded are reported as not installed, files as missing
+func (f *Fixture) Machine() *Machine {
+	return &Machine{Commands: replayCommands{f}, Files: replayFS{f}, Host: replayHost{f}}
+}
+
+func (f *Fixture) lookup(kind, name string, args []string) (Record, bool) {
+	rec, ok := f.records[recordKey(kind, name, args)]
+	return rec, ok
+}
+
+func recordKey(kind, name string, args []string) string {
+	if kind != KindCommand {
+		name = filepath.Clean(name)
+	}
+	return kind + "\x00" + name + "\x00" + strings.Join(args, "\x00")
+}
+
+type replayCommands struct{ f *Fixture }
+
+func (r replayCommands) Exec(name string, args ...string) Result {
+	rec, ok := r.f.lookup(KindCommand, name, args)
+	if !ok {
+		return Result{ExitCode: -1, Err: &exec.Error{Name: name, Err: exec.ErrNotFound}}
+	}
+	return Result{
+		Stdout:   []byte(rec.Stdout),
+		Stderr:   []byte(rec.Stderr),
+		ExitCode: rec.ExitCode,
+		Err:      classError("exec", name, rec.Error),
+	}
+}
+
+func (r replayCommands) LookPath(name string) (string, error) {
+	if rec, ok := r.f.lookup(KindLookPath, name, nil); ok {
+		return rec.Path, classError("lookpath", name, rec.Error)
+	}
+	// Having output for a program implies it was installed
+	for _, rec := range r.f.records {
+		if rec.Kind == KindCommand && rec.Name == name && rec.Error == "" {
+			return "/usr/bin/" + name, nil
+		}
+	}
+	return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+}
+
+type replayFS struct{ f *Fixture }
+
+func (r replayFS) ReadFile(name string) ([]byte, error) {
+	rec, ok := r.f.lookup(KindFile, name, nil)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return []byte(rec.Data), classError("open", name, rec.Error)
+}
+
+func (r replayFS) Stat(name string) (fs.FileInfo, error) {
+	return r.info(KindStat, name)
+}
+
+func (r replayFS) Lstat(name string) (fs.FileInfo, error) {
+	return r.info(KindLstat, name)
+}
+
+func (r replayFS) info(kind, name string) (fs.FileInfo, error) {
+	rec, ok := r.f.lookup(kind, name, nil)
+	if !ok {
+		return nil, &fs.PathError{Op: kind, Path: name, Err: fs.ErrNotExist}
+	}
+	if err := classError(kind, name, rec.Error); err != nil {
+		return nil, err
+	}
+	if rec.Info == nil {
+		return nil, &fs.PathError{Op: kind, Path: name, Err: fs.ErrNotExist}
+	}
+	return fileInfo{*rec.Info}, nil
+}
+
+func (r replayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	rec, ok := r.f.lookup(KindDir, name, nil)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	entries := make([]fs.DirEntry, len(rec.Entries))
+	for i, entry := range rec.Entries {
+		entries[i] = dirEntry{entry}
+	}
+	return entries, classError("open", name, rec.Error)
+}
+
+func (r replayFS) Statfs(path string) (Statfs, error) {
+	rec, ok := r.f.lookup(KindStatfs, path, nil)
+	if !ok {
+		return Statfs{}, &fs.PathError{Op: "statfs", Path: path, Err: fs.ErrNotExist}
+	}
+	if err := classError("statfs", path, rec.Error); err != nil {
+		return Statfs{}, err
+	}
+	if rec.Statfs == nil {
+		return Statfs{}, &fs.PathError{Op: "statfs", Path: path, Err: fs.ErrNotExist}
+	}
+	return *rec.Statfs, nil
+}
+
+type replayHost struct{ f *Fixture }
+
+func (r replayHost) Interfaces() ([]Interface, error) {
+	rec, ok := r.f.lookup(KindInterfaces, "", nil)
+	if !ok {
+		return nil, fmt.Errorf("network interfaces were not recorded")
+	}
+	if err := classError("interfaces", "", rec.Error); err != nil {
+		return nil, err
+	}
+	return rec.Interfaces, nil
+}
+
+func (r replayHost) CPUPercent() (float64, error) {
+	rec, ok := r.f.lookup(KindCPUPercent, "", nil)
+	if !ok || rec.Percent == nil {
+		return 0, fmt.Errorf("CPU usage was not recorded")
+	}
+	return *rec.Percent, nil
+}
+
+func (r replayHost) LookupHost(host string) ([]string, error) {
+	rec, ok := r.f.lookup(KindLookupHost, host, nil)
+	if !ok {
+		return nil, fmt.Errorf("lookup of %s was not recorded", host)
+	}
+	if err := classError("lookup", host, rec.Error); err != nil {
+		return nil, err
+	}
+	return rec.Addrs, nil
+}
@@ -0,0 +1,361 @@
+// Package machine is how checks and diagnoses observe the system: the
+// programs they run and the files they read. Swapping the implementation
+// lets a run be recorded into a fixture and replayed later without touching
+// the real machine
+package machine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// CommandRunner runs external programs
+type CommandRunner interface {
+	Exec(name string, args ...string) Result
+	LookPath(name string) (string, error)
+}
+
+// ContextRunner is a CommandRunner that can stop a program once a context
+// is done
+type ContextRunner interface {
+	ExecContext(ctx context.Context, name string, args ...string) Result
+}
+
+// Result is the outcome of running a program
+type Result struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	Err      error // Set when the program could not be started or was killed
+}
+
+// FS reads files and filesystem statistics
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Statfs(path string) (Statfs, error)
+}
+
+// Host reports live kernel state that is not read from a file
+type Host interface {
+	Interfaces() ([]Interface, error)
+	CPUPercent() (float64, error)
+	LookupHost(host string) ([]string, error)
+}
+
+// Interface is a network interface and its addresses
+type Interface struct {
+	Name     string   `json:"name"`
+	Up       bool     `json:"up"`
+	Loopback bool     `json:"loopback"`
+	Addrs    []string `json:"addrs"`
+}
+
+// Statfs holds the statfs(2) fields debian-doctor uses
+type Statfs struct {
+	Blocks uint64 `json:"blocks"`
+	Bavail uint64 `json:"bavail"`
+	Bsize  int64  `json:"bsize"`
+	Files  uint64 `json:"files"`
+	Ffree  uint64 `json:"ffree"`
+}
+
+// ExitError reports a program that exited with a non-zero status
+type ExitError struct {
+	Name     string
+	ExitCode int
+	Stderr   []byte
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s: exit status %d", e.Name, e.ExitCode)
+}
+
+// Machine bundles the command runner, filesystem and host state checks use
+type Machine struct {
+	Commands CommandRunner
+	Files    FS
+	Host     Host
+}
+
+// Local returns the machine debian-doctor runs on. Commands still running
+// after timeout are killed; zero means no limit
+func Local(timeout time.Duration) *Machine {
+	return &Machine{
+		Commands: localCommands{timeout: timeout},
+		Files:    localFS{},
+		Host:     localHost{},
+	}
+}
+
+// WithContext returns a copy of the machine whose programs are killed once
+// ctx is done. Runners that cannot stop a program still refuse to start one
+// after that
+func (m *Machine) WithContext(ctx context.Context) *Machine {
+	bound := *m
+	bound.Commands = contextCommands{ctx: ctx, inner: m.Commands}
+	return &bound
+}
+
+// Output runs a program and returns its standard output. Like
+// exec.Cmd.Output, a non-zero exit is an error
+func (m *Machine) Output(name string, args ...string) ([]byte, error) {
+	result := m.Commands.Exec(name, args...)
+	return result.Stdout, resultError(name, result)
+}
+
+// CombinedOutput runs a program and returns standard output followed by
+// standard error
+func (m *Machine) CombinedOutput(name string, args ...string) ([]byte, error) {
+	result := m.Commands.Exec(name, args...)
+	combined := append(append([]byte{}, result.Stdout...), result.Stderr...)
+	return combined, resultError(name, result)
+}
+
+// Run runs a program for its exit status only
+func (m *Machine) Run(name string, args ...string) error {
+	return resultError(name, m.Commands.Exec(name, args...))
+}
+
+// LookPath finds a program on $PATH
+func (m *Machine) LookPath(name string) (string, error) {
+	return m.Commands.LookPath(name)
+}
+
+// ReadFile reads a whole file
+func (m *Machine) ReadFile(name string) ([]byte, error) {
+	return m.Files.ReadFile(name)
+}
+
+// Stat describes a file, following symlinks
+func (m *Machine) Stat(name string) (fs.FileInfo, error) {
+	return m.Files.Stat(name)
+}
+
+// ReadDir lists a directory sorted by name
+func (m *Machine) ReadDir(name string) ([]fs.DirEntry, error) {
+	return m.Files.ReadDir(name)
+}
+
+// Statfs returns usage statistics for the filesystem holding path
+func (m *Machine) Statfs(path string) (Statfs, error) {
+	return m.Files.Statfs(path)
+}
+
+// Interfaces lists the network interfaces
+func (m *Machine) Interfaces() ([]Interface, error) {
+	return m.Host.Interfaces()
+}
+
+// CPUPercent is overall CPU utilisation since the process started
+func (m *Machine) CPUPercent() (float64, error) {
+	return m.Host.CPUPercent()
+}
+
+// LookupHost resolves a host name to its addresses
+func (m *Machine) LookupHost(host string) ([]string, error) {
+	return m.Host.LookupHost(host)
+}
+
+// Walk behaves like filepath.Walk on the machine's filesystem
+func (m *Machine) Walk(root string, fn filepath.WalkFunc) error {
+	info, err := m.Files.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = m.walk(root, info, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (m *Machine) walk(path string, info fs.FileInfo, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+
+	entries, err := m.Files.ReadDir(path)
+	err1 := fn(path, info, err)
+	if err != nil || err1 != nil {
+		return err1
+	}
+
+	for _, entry := range entries {
+		name := filepath.Join(path, entry.Name())
+		fileInfo, err := m.Files.Lstat(name)
+		if err != nil {
+			if err := fn(name, fileInfo, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		if err := m.walk(name, fileInfo, fn); err != nil {
+			if !fileInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func resultError(name string, result Result) error {
+	if result.Err != nil {
+		return result.Err
+	}
+	if result.ExitCode != 0 {
+		return &ExitError{Name: name, ExitCode: result.ExitCode, Stderr: result.Stderr}
+	}
+	return nil
+}
+
+// localCommands runs programs on this machine
+type localCommands struct {
+	timeout time.Duration
+}
+
+func (l localCommands) Exec(name string, args ...string) Result {
+	return l.ExecContext(context.Background(), name, args...)
+}
+
+func (l localCommands) ExecContext(parent context.Context, name string, args ...string) Result {
+	ctx := parent
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// A killed script's children may keep its output open; stop waiting
+	// for them shortly after
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+
+	result := Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+	var exitErr *exec.ExitError
+	switch {
+	case parent.Err() != nil:
+		result.ExitCode = -1
+		result.Err = fmt.Errorf("%s killed: %w", name, parent.Err())
+	case ctx.Err() != nil:
+		result.ExitCode = -1
+		result.Err = fmt.Errorf("%s killed after %s", name, l.timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Err = err
+	}
+	return result
+}
+
+func (l localCommands) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+// contextCommands stops programs run by another runner when ctx is done
+type contextCommands struct {
+	ctx   context.Context
+	inner CommandRunner
+}
+
+func (c contextCommands) Exec(name string, args ...string) Result {
+	if err := c.ctx.Err(); err != nil {
+		return Result{ExitCode: -1, Err: fmt.Errorf("%s not started: %w", name, err)}
+	}
+	if runner, ok := c.inner.(ContextRunner); ok {
+		return runner.ExecContext(c.ctx, name, args...)
+	}
+	return c.inner.Exec(name, args...)
+}
+
+func (c contextCommands) LookPath(name string) (string, error) {
+	return c.inner.LookPath(name)
+}
+
+// localFS reads this machine's filesystem
+type localFS struct{}
+
+func (localFS) ReadFile(name string) ([]byte, error)   { return os.ReadFile(name) }
+func (localFS) Stat(name string) (fs.FileInfo, error)  { return os.Stat(name) }
+func (localFS) Lstat(name string) (fs.FileInfo, error) { return os.Lstat(name) }
+
+func (localFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := os.ReadDir(name)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, err
+}
+
+func (localFS) Statfs(path string) (Statfs, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return Statfs{}, &fs.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	return Statfs{
+		Blocks: stat.Blocks,
+		Bavail: stat.Bavail,
+		Bsize:  int64(stat.Bsize),
+		Files:  stat.Files,
+		Ffree:  stat.Ffree,
+	}, nil
+}
+
+// localHost reports this machine's kernel state
+type localHost struct{}
+
+func (localHost) Interfaces() ([]Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Interface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		entry := Interface{
+			Name:     iface.Name,
+			Up:       iface.Flags&net.FlagUp != 0,
+			Loopback: iface.Flags&net.FlagLoopback != 0,
+			Addrs:    []string{},
+		}
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, addr := range addrs {
+				entry.Addrs = append(entry.Addrs, addr.String())
+			}
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+func (localHost) LookupHost(host string) ([]string, error) {
+	return net.LookupHost(host)
+}
+
+func (localHost) CPUPercent() (float64, error) {
+	percent, err := cpu.Percent(0, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(percent) == 0 {
+		return 0, fmt.Errorf("no CPU statistics available")
+	}
+	return percent[0], nil
+}
@@ -0,0 +1,243 @@
+package machine
+
+import (
+	"context"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalCommands(t *testing.T) {
+	m := Local(time.Second)
+
+	output, err := m.Output("sh", "-c", "echo out; echo err >&2")
+	if err != nil || string(output) != "out\n" {
+		t.Errorf("Output() = %q, %v", output, err)
+	}
+
+	combined, err := m.CombinedOutput("sh", "-c", "echo out; echo err >&2; exit 3")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode != 3 {
+		t.Errorf("Expected exit status 3, got %v", err)
+	}
+	if string(combined) != "out\nerr\n" {
+		t.Errorf("CombinedOutput() = %q", combined)
+	}
+
+	if err := m.Run("definitely-not-a-real-program"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLocalCommandTimeout(t *testing.T) {
+	m := Local(50 * time.Millisecond)
+	start := time.Now()
+	err := m.Run("sleep", "5")
+	if err == nil || !strings.Contains(err.Error(), "killed after") {
+		t.Errorf("Expected hung command to be killed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Run() took %s", elapsed)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	m := Local(time.Minute).WithContext(ctx)
+
+	start := time.Now()
+	if err := m.Run("sleep", "5"); err == nil || !strings.Contains(err.Error(), "killed: context deadline exceeded") {
+		t.Errorf("Expected the command to be killed with the context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Run() took %s", elapsed)
+	}
+	if err := m.Run("true"); err == nil || !strings.Contains(err.Error(), "not started") {
+		t.Errorf("Expected no command to start after the context is done, got %v", err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	rec := NewRecorder(Local(time.Second), &buf)
+
+	exercise := func(m *Machine) (out []byte, outErr error, data []byte, walked []string, statfs Statfs) {
+		out, outErr = m.Output("sh", "-c", "echo recorded; exit 2")
+		data, _ = m.ReadFile(filepath.Join(dir, "a.txt"))
+		m.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+			if err == nil {
+				rel, _ := filepath.Rel(dir, path)
+				walked = append(walked, rel+":"+info.Mode().Type().String())
+			}
+			return nil
+		})
+		statfs, _ = m.Statfs(dir)
+		return
+	}
+
+	wantOut, wantErr, wantData, wantWalk, wantStatfs := exercise(rec)
+
+	fixture, err := ReadFixture(&buf)
+	if err != nil {
+		t.Fatalf("ReadFixture() error = %v", err)
+	}
+	gotOut, gotErr, gotData, gotWalk, gotStatfs := exercise(fixture.Machine())
+
+	if string(gotOut) != string(wantOut) || gotErr.Error() != wantErr.Error() {
+		t.Errorf("Replayed command = %q, %v; recorded %q, %v", gotOut, gotErr, wantOut, wantErr)
+	}
+	if string(gotData) != "hello" || string(wantData) != "hello" {
+		t.Errorf("Replayed file = %q", gotData)
+	}
+	if strings.Join(gotWalk, " ") != strings.Join(wantWalk, " ") || len(gotWalk) != 4 {
+		t.Errorf("Replayed walk = %v, recorded %v", gotWalk, wantWalk)
+	}
+	if gotStatfs != wantStatfs || gotStatfs.Blocks == 0 {
+		t.Errorf("Replayed statfs = %+v, recorded %+v", gotStatfs, wantStatfs)
+	}
+}
+
+func TestFixtureMissing(t *testing.T) {
+	f := NewFixture()
+	f.AddCommand("ok\n", 0, "systemctl", "is-active", "ssh")
+	m := f.Machine()
+
+	if _, err := m.Output("systemctl", "is-active", "cron"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Unrecorded arguments should look uninstalled, got %v", err)
+	}
+	if _, err := m.LookPath("systemctl"); err != nil {
+		t.Errorf("LookPath() of a recorded program = %v", err)
+	}
+	if _, err := m.LookPath("dpkg"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("LookPath() of an unrecorded program = %v", err)
+	}
+	if _, err := m.ReadFile("/etc/hostname"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Unrecorded file should not exist, got %v", err)
+	}
+}
+
+func TestReplayLookupHost(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(Local(time.Second), &buf)
+	wantAddrs, wantErr := rec.LookupHost("localhost")
+
+	fixture, err := ReadFixture(&buf)
+	if err != nil {
+		t.Fatalf("ReadFixture() error = %v", err)
+	}
+	m := fixture.Machine()
+	gotAddrs, gotErr := m.LookupHost("localhost")
+	if strings.Join(gotAddrs, " ") != strings.Join(wantAddrs, " ") || (gotErr == nil) != (wantErr == nil) {
+		t.Errorf("Replayed lookup = %v, %v; recorded %v, %v", gotAddrs, gotErr, wantAddrs, wantErr)
+	}
+	if _, err := m.LookupHost("debian.org"); err == nil {
+		t.Error("Expected an unrecorded lookup to fail")
+	}
+}
+
+func TestFixtureAddFile(t *testing.T) {
+	f := NewFixture()
+	f.AddFile("/tmp/a/one", "1")
+	f.AddFile("/tmp/a/two", "22")
+	f.AddDir("/tmp/b")
+	m := f.Machine()
+
+	var walked []string
+	m.Walk("/tmp", func(path string, info fs.FileInfo, err error) error {
+		walked = append(walked, path)
+		return nil
+	})
+	if got := strings.Join(walked, " "); got != "/tmp /tmp/a /tmp/a/one /tmp/a/two /tmp/b" {
+		t.Errorf("Walk() visited %s", got)
+	}
+
+	info, err := m.Stat("/tmp/a/two")
+	if err != nil || info.Size() != 2 || info.IsDir() {
+		t.Errorf("Stat() = %+v, %v", info, err)
+	}
+}
+
+func TestLoadFixtureLaterRecordWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.jsonl")
+	content := `{"kind":"file","name":"/etc/os-release","data":"old"}
+
+{"kind":"file","name":"/etc/os-release","data":"new"}
+{"kind":"file","name":"/etc/shadow","error":"permission denied"}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("LoadFixture() error = %v", err)
+	}
+	m := f.Machine()
+	if data, _ := m.ReadFile("/etc/os-release"); string(data) != "new" {
+		t.Errorf("ReadFile() = %q, want later record", data)
+	}
+	if _, err := m.ReadFile("/etc/shadow"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Expected recorded permission error, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFixture(path); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected parse error with line number, got %v", err)
+	}
+}
+
+func TestParseMeminfo(t *testing.T) {
+	data := []byte(`MemTotal:        8000000 kB
+MemFree:         1000000 kB
+MemAvailable:    5000000 kB
+Buffers:          500000 kB
+Cached:          2000000 kB
+SwapCached:            0 kB
+SReclaimable:     500000 kB
+SwapTotal:       2000000 kB
+SwapFree:         500000 kB
+`)
+	info, err := ParseMeminfo(data)
+	if err != nil {
+		t.Fatalf("ParseMeminfo() error = %v", err)
+	}
+	if info.Total != 8000000*1024 || info.Available != 5000000*1024 {
+		t.Errorf("ParseMeminfo() = %+v", info)
+	}
+	if got := info.UsedPercent(); got != 50 {
+		t.Errorf("UsedPercent() = %v, want 50", got)
+	}
+	if got := info.SwapUsedPercent(); got != 75 {
+		t.Errorf("SwapUsedPercent() = %v, want 75", got)
+	}
+
+	// Old kernels have no MemAvailable
+	info, err = ParseMeminfo([]byte("MemTotal: 100 kB\nMemFree: 10 kB\nBuffers: 5 kB\nCached: 20 kB\n"))
+	if err != nil || info.Available != 35*1024 || info.SwapUsedPercent() != 0 {
+		t.Errorf("ParseMeminfo() without MemAvailable = %+v, %v", info, err)
+	}
+
+	if _, err := ParseMeminfo([]byte("MemFree: 10 kB\n")); err == nil {
+		t.Error("Expected an error without MemTotal")
+	}
+}
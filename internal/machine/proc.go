@@ -0,0 +1,192 @@
+package machine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Meminfo holds the /proc/meminfo fields debian-doctor uses, in bytes
+type Meminfo struct {
+	Total        uint64
+	Free         uint64
+	Available    uint64
+	Buffers      uint64
+	Cached       uint64
+	SReclaimable uint64
+	SwapTotal    uint64
+	SwapFree     uint64
+}
+
+// Meminfo reads and parses /proc/meminfo
+func (m *Machine) Meminfo() (Meminfo, error) {
+	data, err := m.ReadFile("/proc/meminfo")
+	if err != nil {
+		return Meminfo{}, err
+	}
+	return ParseMeminfo(data)
+}
+
+// ParseMeminfo parses the contents of /proc/meminfo
+func ParseMeminfo(data []byte) (Meminfo, error) {
+	var info Meminfo
+	fields := map[string]*uint64{
+		"MemTotal":     &info.Total,
+		"MemFree":      &info.Free,
+		"MemAvailable": &info.Available,
+		"Buffers":      &info.Buffers,
+		"Cached":       &info.Cached,
+		"SReclaimable": &info.SReclaimable,
+		"SwapTotal":    &info.SwapTotal,
+		"SwapFree":     &info.SwapFree,
+	}
+
+	hasAvailable := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, rest, ok := strings.Cut(scanner.Text(), ":")
+		field, known := fields[key]
+		if !ok || !known {
+			continue
+		}
+		value := strings.Fields(rest)
+		if len(value) == 0 {
+			continue
+		}
+		kb, err := strconv.ParseUint(value[0], 10, 64)
+		if err != nil {
+			return Meminfo{}, fmt.Errorf("meminfo %s: %w", key, err)
+		}
+		*field = kb * 1024
+		if key == "MemAvailable" {
+			hasAvailable = true
+		}
+	}
+	if info.Total == 0 {
+		return Meminfo{}, fmt.Errorf("meminfo: MemTotal missing")
+	}
+	// Kernels before 3.14 do not report MemAvailable
+	if !hasAvailable {
+		info.Available = info.Free + info.Buffers + info.Cached
+	}
+	return info, nil
+}
+
+// Used is memory in use by processes, excluding buffers and reclaimable cache
+func (i Meminfo) Used() uint64 {
+	reclaimable := i.Free + i.Buffers + i.Cached + i.SReclaimable
+	if reclaimable > i.Total {
+		return 0
+	}
+	return i.Total - reclaimable
+}
+
+// UsedPercent is Used as a percentage of total memory
+func (i Meminfo) UsedPercent() float64 {
+	return float64(i.Used()) / float64(i.Total) * 100
+}
+
+// SwapUsed is swap space in use
+func (i Meminfo) SwapUsed() uint64 {
+	if i.SwapFree > i.SwapTotal {
+		return 0
+	}
+	return i.SwapTotal - i.SwapFree
+}
+
+// SwapUsedPercent is SwapUsed as a percentage of total swap, or zero without
+// swap
+func (i Meminfo) SwapUsedPercent() float64 {
+	if i.SwapTotal == 0 {
+		return 0
+	}
+	return float64(i.SwapUsed()) / float64(i.SwapTotal) * 100
+}
+
+// CPUInfo summarises /proc/cpuinfo
+type CPUInfo struct {
+	Model   string
+	Cores   int // Physical cores of the first package
+	Threads int // Logical CPUs
+}
+
+// CPUInfo reads and parses /proc/cpuinfo
+func (m *Machine) CPUInfo() (CPUInfo, error) {
+	data, err := m.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return CPUInfo{}, err
+	}
+	return ParseCPUInfo(data)
+}
+
+// ParseCPUInfo parses the contents of /proc/cpuinfo
+func ParseCPUInfo(data []byte) (CPUInfo, error) {
+	var info CPUInfo
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "processor":
+			info.Threads++
+		case "model name":
+			if info.Model == "" {
+				info.Model = value
+			}
+		case "cpu cores":
+			if info.Cores == 0 {
+				info.Cores, _ = strconv.Atoi(value)
+			}
+		}
+	}
+	if info.Threads == 0 {
+		return CPUInfo{}, fmt.Errorf("cpuinfo: no processors listed")
+	}
+	// Virtual machines and some architectures omit cpu cores
+	if info.Cores == 0 {
+		info.Cores = info.Threads
+	}
+	return info, nil
+}
+
+// LoadAverage reads the 1, 5 and 15 minute load averages from /proc/loadavg
+func (m *Machine) LoadAverage() ([3]float64, error) {
+	var loads [3]float64
+	data, err := m.ReadFile("/proc/loadavg")
+	if err != nil {
+		return loads, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return loads, fmt.Errorf("loadavg: unexpected format")
+	}
+	for i := range loads {
+		if loads[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return loads, fmt.Errorf("loadavg: %w", err)
+		}
+	}
+	return loads, nil
+}
+
+// Uptime reads the time since boot from /proc/uptime
+func (m *Machine) Uptime() (time.Duration, error) {
+	data, err := m.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("uptime: unexpected format")
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("uptime: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
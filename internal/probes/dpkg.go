@@ -0,0 +1,180 @@
+package probes
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+)
+
+// Package is one row of dpkg -l
+type Package struct {
+	State   string // Desired action and status, e.g. ii or iU
+	Name    string
+	Version string
+	Arch    string
+}
+
+// Broken reports whether dpkg left the package unpacked, half-configured or
+// half-installed
+func (p Package) Broken() bool {
+	switch p.State {
+	case "iU", "iF", "iH":
+		return true
+	}
+	return false
+}
+
+// Installed reports whether the package is installed and configured
+func (p Package) Installed() bool {
+	return p.State == "ii"
+}
+
+// BaseName is the package name without its architecture qualifier
+func (p Package) BaseName() string {
+	name, _, _ := strings.Cut(p.Name, ":")
+	return name
+}
+
+// ParseDpkgList parses the output of dpkg -l, skipping its header
+func ParseDpkgList(data []byte) []Package {
+	var packages []Package
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields[0]) < 2 || len(fields[0]) > 3 {
+			continue
+		}
+		state := fields[0]
+		if !strings.ContainsRune("uirhp", rune(state[0])) {
+			continue
+		}
+		pkg := Package{State: state[:2], Name: fields[1]}
+		if len(fields) > 2 {
+			pkg.Version = fields[2]
+		}
+		if len(fields) > 3 {
+			pkg.Arch = fields[3]
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// Packages returns every package dpkg knows about
+func (p *Probes) Packages() ([]Package, error) {
+	return gather(p, "dpkg-list", func(m *machine.Machine) ([]Package, error) {
+		output, err := m.Output("dpkg", "-l")
+		if err != nil {
+			return nil, err
+		}
+		return ParseDpkgList(output), nil
+	})
+}
+
+// BrokenPackages returns the packages dpkg or apt consider broken
+func (p *Probes) BrokenPackages() ([]string, error) {
+	return gather(p, "broken-packages", func(m *machine.Machine) ([]string, error) {
+		var broken []string
+		packages, dpkgErr := p.Packages()
+		for _, pkg := range packages {
+			if pkg.Broken() {
+				broken = append(broken, pkg.Name)
+			}
+		}
+
+		output, aptErr := m.Output("apt", "list", "--broken")
+		if aptErr == nil {
+			for _, line := range strings.Split(string(output), "\n") {
+				name, _, found := strings.Cut(line, "/")
+				name = strings.TrimSpace(name)
+				if found && name != "" && !strings.HasPrefix(line, "WARNING") {
+					broken = append(broken, name)
+				}
+			}
+		}
+
+		if dpkgErr != nil && aptErr != nil {
+			return nil, dpkgErr
+		}
+		return Unique(broken), nil
+	})
+}
+
+// HeldPackages returns the packages marked as held with apt-mark
+func (p *Probes) HeldPackages() ([]string, error) {
+	return gather(p, "held-packages", func(m *machine.Machine) ([]string, error) {
+		output, err := m.Output("apt-mark", "showhold")
+		if err != nil {
+			return nil, err
+		}
+		var held []string
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				held = append(held, line)
+			}
+		}
+		return held, nil
+	})
+}
+
+// PackageCacheMB returns the size of the downloaded package cache
+func (p *Probes) PackageCacheMB() (float64, error) {
+	return gather(p, "package-cache", func(m *machine.Machine) (float64, error) {
+		output, err := m.Output("du", "-sm", "/var/cache/apt/archives")
+		if err != nil {
+			return 0, err
+		}
+		fields := strings.Fields(string(output))
+		if len(fields) == 0 {
+			return 0, nil
+		}
+		return strconv.ParseFloat(fields[0], 64)
+	})
+}
+
+// UpgradableCount returns how many packages have a newer version available
+func (p *Probes) UpgradableCount() (int, error) {
+	return gather(p, "upgradable", func(m *machine.Machine) (int, error) {
+		output, err := m.Output("apt", "list", "--upgradable")
+		if err != nil {
+			return 0, err
+		}
+		return strings.Count(string(output), "[upgradable from:"), nil
+	})
+}
+
+var autoremovePattern = regexp.MustCompile(`The following packages will be REMOVED:\s*\n(.*?)(\n\n|\nNeed|\nAfter|$)`)
+
+// AutoremovableCount returns how many packages apt autoremove would remove
+func (p *Probes) AutoremovableCount() (int, error) {
+	return gather(p, "autoremovable", func(m *machine.Machine) (int, error) {
+		output, err := m.Output("apt", "autoremove", "--dry-run")
+		if err != nil {
+			return 0, err
+		}
+		matches := autoremovePattern.FindStringSubmatch(string(output))
+		if len(matches) < 2 {
+			return 0, nil
+		}
+		return len(strings.Fields(matches[1])), nil
+	})
+}
+
+// DpkgAudit returns the non-empty lines of dpkg --audit, which lists
+// packages left in an inconsistent state by an interrupted dpkg run
+func (p *Probes) DpkgAudit() ([]string, error) {
+	return gather(p, "dpkg-audit", func(m *machine.Machine) ([]string, error) {
+		output, err := m.Output("dpkg", "--audit")
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines, nil
+	})
+}
@@ -0,0 +1,141 @@
+package probes
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+)
+
+// SUIDDirs are the directories searched for setuid binaries
+var SUIDDirs = []string{"/bin", "/sbin", "/usr/bin", "/usr/sbin", "/usr/lib", "/usr/libexec", "/usr/local", "/opt"}
+
+const aptSourcesDir = "/etc/apt/sources.list.d"
+
+// APTSources returns the enabled APT source entries, one line per entry and
+// sorted. One-line .list entries are kept as written, with their spacing
+// normalised; deb822 .sources stanzas are rendered as "Types URIs Suites
+// Components"
+func (p *Probes) APTSources() ([]string, error) {
+	return gather(p, "apt-sources", func(m *machine.Machine) ([]string, error) {
+		var sources []string
+		data, listErr := m.ReadFile("/etc/apt/sources.list")
+		if listErr == nil {
+			sources = append(sources, ParseSourcesList(data)...)
+		}
+
+		entries, dirErr := m.ReadDir(aptSourcesDir)
+		for _, entry := range entries {
+			path := filepath.Join(aptSourcesDir, entry.Name())
+			data, err := m.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			switch filepath.Ext(entry.Name()) {
+			case ".list":
+				sources = append(sources, ParseSourcesList(data)...)
+			case ".sources":
+				sources = append(sources, ParseDeb822Sources(data)...)
+			}
+		}
+
+		if listErr != nil && dirErr != nil {
+			return nil, listErr
+		}
+		sources = Unique(sources)
+		sort.Strings(sources)
+		return sources, nil
+	})
+}
+
+// ParseSourcesList parses a one-line-style sources.list file
+func ParseSourcesList(data []byte) []string {
+	var sources []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && (fields[0] == "deb" || fields[0] == "deb-src") {
+			sources = append(sources, strings.Join(fields, " "))
+		}
+	}
+	return sources
+}
+
+// ParseDeb822Sources parses a deb822-style .sources file. Stanzas with
+// "Enabled: no" are skipped
+func ParseDeb822Sources(data []byte) []string {
+	var sources []string
+	for _, stanza := range strings.Split(string(data), "\n\n") {
+		fields := make(map[string]string)
+		for _, line := range strings.Split(stanza, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			key, value, found := strings.Cut(line, ":")
+			if found && !strings.HasPrefix(key, " ") {
+				fields[strings.ToLower(key)] = strings.Join(strings.Fields(value), " ")
+			}
+		}
+		if fields["types"] == "" || fields["uris"] == "" || strings.EqualFold(fields["enabled"], "no") {
+			continue
+		}
+		entry := []string{fields["types"], fields["uris"], fields["suites"]}
+		if fields["components"] != "" {
+			entry = append(entry, fields["components"])
+		}
+		sources = append(sources, strings.Join(entry, " "))
+	}
+	return sources
+}
+
+// ListeningSockets returns the TCP and UDP sockets waiting for connections
+// as "protocol address:port", sorted
+func (p *Probes) ListeningSockets() ([]string, error) {
+	return gather(p, "listening-sockets", func(m *machine.Machine) ([]string, error) {
+		output, err := m.Output("ss", "-H", "-l", "-n", "-t", "-u")
+		if err != nil {
+			return nil, err
+		}
+		sockets := Unique(ParseListeningSockets(output))
+		sort.Strings(sockets)
+		return sockets, nil
+	})
+}
+
+// ParseListeningSockets parses the output of ss -Hlntu
+func ParseListeningSockets(data []byte) []string {
+	var sockets []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		sockets = append(sockets, fields[0]+" "+fields[4])
+	}
+	return sockets
+}
+
+// SUIDBinaries returns the setuid files under SUIDDirs, sorted. find exits
+// non-zero when a directory is missing or unreadable; what it did find is
+// still returned
+func (p *Probes) SUIDBinaries() ([]string, error) {
+	return gather(p, "suid-binaries", func(m *machine.Machine) ([]string, error) {
+		args := append(append([]string{}, SUIDDirs...), "-xdev", "-type", "f", "-perm", "-4000")
+		output, err := m.Output("find", args...)
+		var exitErr *machine.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			return nil, err
+		}
+		var binaries []string
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				binaries = append(binaries, line)
+			}
+		}
+		binaries = Unique(binaries)
+		sort.Strings(binaries)
+		return binaries, nil
+	})
+}
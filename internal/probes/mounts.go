@@ -0,0 +1,110 @@
+package probes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+)
+
+// Mount is one line of /proc/mounts
+type Mount struct {
+	Device  string
+	Path    string
+	Type    string
+	Options []string
+}
+
+// HasOption reports whether the mount was made with option, e.g. ro
+func (m Mount) HasOption(option string) bool {
+	for _, o := range m.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadOnly reports whether the mount is read-only
+func (m Mount) ReadOnly() bool {
+	return m.HasOption("ro")
+}
+
+// Virtual reports whether the mount is a kernel, memory or image filesystem
+// that is expected to be read-only at times, such as sysfs or a snap
+func (m Mount) Virtual() bool {
+	for _, prefix := range []string{"/proc", "/sys", "/dev", "/run"} {
+		if m.Path == prefix || strings.HasPrefix(m.Path, prefix+"/") {
+			return true
+		}
+	}
+	switch m.Type {
+	case "squashfs", "iso9660", "udf":
+		return true
+	}
+	return strings.Contains(m.Type, "tmpfs")
+}
+
+// ParseMounts parses the contents of /proc/mounts
+func ParseMounts(data []byte) []Mount {
+	var mounts []Mount
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, Mount{
+			Device:  unescapeMountField(fields[0]),
+			Path:    unescapeMountField(fields[1]),
+			Type:    fields[2],
+			Options: strings.Split(fields[3], ","),
+		})
+	}
+	return mounts
+}
+
+// unescapeMountField decodes the octal escapes /proc/mounts uses for
+// spaces, tabs and backslashes in paths
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if code, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+// Mounts returns the mounted filesystems
+func (p *Probes) Mounts() ([]Mount, error) {
+	return gather(p, "mounts", func(m *machine.Machine) ([]Mount, error) {
+		data, err := m.ReadFile("/proc/mounts")
+		if err != nil {
+			return nil, err
+		}
+		return ParseMounts(data), nil
+	})
+}
+
+// ReadOnlyMounts returns the real filesystems that are mounted read-only
+func (p *Probes) ReadOnlyMounts() ([]Mount, error) {
+	mounts, err := p.Mounts()
+	if err != nil {
+		return nil, err
+	}
+	var readOnly []Mount
+	for _, mount := range mounts {
+		if mount.ReadOnly() && !mount.Virtual() {
+			readOnly = append(readOnly, mount)
+		}
+	}
+	return readOnly, nil
+}
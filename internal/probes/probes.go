@@ -0,0 +1,102 @@
+// Package probes gathers facts about a machine once per run: its mounts,
+// dpkg state, unit states, inode usage, journal size, core dumps, APT
+// sources, listening sockets and setuid binaries. Checks grade the
+// facts into severities and diagnoses turn them into fixes, so both see the
+// same data and nothing is collected twice
+package probes
+
+import (
+	"sync"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+)
+
+// Probes caches the facts gathered from one machine. It is safe for
+// concurrent use; a fact asked for by several checks at once is gathered
+// once. Returned slices are shared between callers and must not be modified
+type Probes struct {
+	m *machine.Machine
+
+	mu    sync.Mutex
+	facts map[string]*fact
+}
+
+// fact is one cached probe result
+type fact struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// New creates probes for m with an empty cache
+func New(m *machine.Machine) *Probes {
+	return &Probes{m: m, facts: make(map[string]*fact)}
+}
+
+var (
+	sharedMu sync.Mutex
+	shared   = make(map[*machine.Machine]*Probes)
+)
+
+// For returns the probes shared by every check and diagnosis running on m
+func For(m *machine.Machine) *Probes {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	p, ok := shared[m]
+	if !ok {
+		p = New(m)
+		shared[m] = p
+	}
+	return p
+}
+
+// Invalidate forgets every shared fact, e.g. after a fix changed the machine
+func Invalidate() {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	for _, p := range shared {
+		p.Reset()
+	}
+}
+
+// Machine returns the machine the probes gather facts from
+func (p *Probes) Machine() *machine.Machine {
+	return p.m
+}
+
+// Reset forgets the facts gathered so far
+func (p *Probes) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.facts = make(map[string]*fact)
+}
+
+// gather returns the cached fact for key, running probe the first time
+func gather[T any](p *Probes, key string, probe func(m *machine.Machine) (T, error)) (T, error) {
+	p.mu.Lock()
+	f, ok := p.facts[key]
+	if !ok {
+		f = &fact{}
+		p.facts[key] = f
+	}
+	p.mu.Unlock()
+
+	f.once.Do(func() {
+		f.value, f.err = probe(p.m)
+	})
+	value, _ := f.value.(T)
+	return value, f.err
+}
+
+// Unique returns values without duplicates, keeping the first occurrence
+func Unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
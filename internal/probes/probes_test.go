@@ -0,0 +1,250 @@
+package probes
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+)
+
+func TestGatherCachesFacts(t *testing.T) {
+	fixture := machine.NewFixture()
+	fixture.AddCommand("nginx.service loaded failed failed A high performance web server\n", 0,
+		"systemctl", "list-units", "--failed", "--no-legend", "--plain", "--no-pager")
+
+	var mu sync.Mutex
+	calls := 0
+	m := machine.Capture(fixture.Machine(), func(rec machine.Record) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+	p := New(m)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if units, err := p.FailedUnitsOfType("service"); err != nil || len(units) != 1 {
+				t.Errorf("FailedUnitsOfType() = %v, %v", units, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("Expected the fact to be gathered once, got %d", calls)
+	}
+
+	p.Reset()
+	p.FailedUnits()
+	if calls != 2 {
+		t.Errorf("Expected Reset to gather the fact again, got %d calls", calls)
+	}
+}
+
+func TestForSharesProbes(t *testing.T) {
+	m := machine.NewFixture().Machine()
+	if For(m) != For(m) {
+		t.Error("For returned different probes for the same machine")
+	}
+	if For(m) == For(machine.NewFixture().Machine()) {
+		t.Error("For shared probes between different machines")
+	}
+}
+
+func TestUnique(t *testing.T) {
+	got := Unique([]string{"b", "a", "b", "c", "a"})
+	if want := []string{"b", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unique() = %v, want %v", got, want)
+	}
+	if got := Unique(nil); got == nil || len(got) != 0 {
+		t.Errorf("Unique(nil) = %#v, want empty slice", got)
+	}
+}
+
+func TestParseMounts(t *testing.T) {
+	data := []byte(`sysfs /sys sysfs rw,nosuid,nodev,noexec,relatime 0 0
+/dev/sda1 / ext4 rw,relatime 0 0
+/dev/sdb1 /mnt/backup\040disk ext4 ro,relatime 0 0
+/dev/loop0 /snap/core/1 squashfs ro,nodev 0 0
+tmpfs /tmp tmpfs rw,nosuid 0 0
+`)
+	mounts := ParseMounts(data)
+	if len(mounts) != 5 {
+		t.Fatalf("Expected 5 mounts, got %d", len(mounts))
+	}
+	if mounts[2].Path != "/mnt/backup disk" {
+		t.Errorf("Expected escaped path to be decoded, got %q", mounts[2].Path)
+	}
+
+	var readOnly []string
+	for _, mount := range mounts {
+		if mount.ReadOnly() && !mount.Virtual() {
+			readOnly = append(readOnly, mount.Path)
+		}
+	}
+	if want := []string{"/mnt/backup disk"}; !reflect.DeepEqual(readOnly, want) {
+		t.Errorf("Read-only mounts = %v, want %v", readOnly, want)
+	}
+	if !mounts[0].Virtual() || !mounts[4].Virtual() || mounts[1].Virtual() {
+		t.Error("Virtual filesystems were not told apart from real ones")
+	}
+	// relatime must not be mistaken for ro
+	if mounts[1].ReadOnly() {
+		t.Error("Read-write mount reported as read-only")
+	}
+}
+
+func TestParseDpkgList(t *testing.T) {
+	data := []byte(`Desired=Unknown/Install/Remove/Purge/Hold
+| Status=Not/Inst/Conf-files/Unpacked/halF-conf/Half-inst/trig-aWait/Trig-pend
+|/ Err?=(none)/Reinst-required (Status,Err: uppercase=bad)
+||/ Name           Version      Architecture Description
++++-==============-============-============-=================
+ii  bash           5.2.15-2     amd64        GNU Bourne Again SHell
+iU  libfoo:amd64   1.0-1        amd64        Foo library
+iF  libbar         2.0-1        all          Bar library
+rc  oldpkg         0.1-1        amd64        Removed package
+`)
+	packages := ParseDpkgList(data)
+	if len(packages) != 4 {
+		t.Fatalf("Expected 4 packages, got %d: %v", len(packages), packages)
+	}
+
+	var broken []string
+	for _, pkg := range packages {
+		if pkg.Broken() {
+			broken = append(broken, pkg.BaseName())
+		}
+	}
+	if want := []string{"libfoo", "libbar"}; !reflect.DeepEqual(broken, want) {
+		t.Errorf("Broken packages = %v, want %v", broken, want)
+	}
+	if !packages[0].Installed() || packages[3].Installed() {
+		t.Error("Installed state misreported")
+	}
+	if packages[0].Version != "5.2.15-2" || packages[0].Arch != "amd64" {
+		t.Errorf("Unexpected package fields: %+v", packages[0])
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	data := []byte(`● nginx.service              loaded failed failed A high performance web server
+  postgresql@15-main.service loaded failed failed PostgreSQL Cluster 15-main
+● mnt-data.mount             loaded failed failed /mnt/data
+`)
+	units := ParseUnits(data)
+	if len(units) != 3 {
+		t.Fatalf("Expected 3 units, got %d", len(units))
+	}
+	if units[0].Name != "nginx.service" || units[0].BaseName() != "nginx" || units[0].Type() != "service" {
+		t.Errorf("Unexpected unit: %+v", units[0])
+	}
+	if units[1].BaseName() != "postgresql@15-main" {
+		t.Errorf("BaseName() = %q", units[1].BaseName())
+	}
+	if units[2].Type() != "mount" || units[2].Description != "/mnt/data" {
+		t.Errorf("Unexpected unit: %+v", units[2])
+	}
+}
+
+func TestParseInodeUsage(t *testing.T) {
+	data := []byte(`Filesystem      Inodes  IUsed   IFree IUse% Mounted on
+udev            501234    456  500778    1% /dev
+tmpfs           505123    890  504233    1% /run
+/dev/sda1      6553600 6225920  327680   95% /
+/dev/sdb1            0       0       0     - /boot/efi
+`)
+	usage := ParseInodeUsage(data)
+	want := []InodeUsage{{Filesystem: "/dev/sda1", Mount: "/", Percent: 95}}
+	if !reflect.DeepEqual(usage, want) {
+		t.Errorf("ParseInodeUsage() = %+v, want %+v", usage, want)
+	}
+}
+
+func TestParseJournalUsage(t *testing.T) {
+	tests := []struct {
+		output string
+		want   float64
+		ok     bool
+	}{
+		{"Archived and active journals take up 1.5G in the file system.", 1536, true},
+		{"Archived and active journals take up 512.0M in the file system.", 512, true},
+		{"Archived and active journals take up 2048.0K in the file system.", 2, true},
+		{"No journal files were found.", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseJournalUsage([]byte(tt.output))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseJournalUsage(%q) = %v, %v, want %v, %v", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUnitFilesInState(t *testing.T) {
+	fixture := machine.NewFixture()
+	fixture.AddCommand(`bluetooth.service     masked   enabled
+cron.service          enabled  enabled
+fstrim.timer          enabled  enabled
+getty@.service        static   -
+`, 0, "systemctl", "list-unit-files", "--no-legend", "--no-pager")
+	p := New(fixture.Machine())
+
+	enabled, _ := p.UnitFilesInState("", "enabled")
+	if want := []string{"cron.service", "fstrim.timer"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("Enabled units = %v, want %v", enabled, want)
+	}
+	masked, _ := p.UnitFilesInState("service", "masked")
+	if want := []string{"bluetooth.service"}; !reflect.DeepEqual(masked, want) {
+		t.Errorf("Masked services = %v, want %v", masked, want)
+	}
+}
+
+func TestAPTSources(t *testing.T) {
+	fixture := machine.NewFixture()
+	fixture.AddFile("/etc/apt/sources.list", `# Main archive
+deb http://deb.debian.org/debian bookworm main   contrib
+deb-src http://deb.debian.org/debian bookworm main # sources
+#deb http://old.example.com/debian buster main
+`)
+	fixture.AddFile("/etc/apt/sources.list.d/debian.sources", `Types: deb
+URIs: http://deb.debian.org/debian
+Suites: bookworm-updates
+Components: main
+
+Types: deb
+URIs: http://security.debian.org/debian-security
+Suites: bookworm-security
+Components: main
+Enabled: no
+`)
+	fixture.AddFile("/etc/apt/sources.list.d/docker.list", "deb [arch=amd64] https://download.docker.com/linux/debian bookworm stable\n")
+
+	sources, err := New(fixture.Machine()).APTSources()
+	if err != nil {
+		t.Fatalf("APTSources() failed: %v", err)
+	}
+	want := []string{
+		"deb [arch=amd64] https://download.docker.com/linux/debian bookworm stable",
+		"deb http://deb.debian.org/debian bookworm main contrib",
+		"deb http://deb.debian.org/debian bookworm-updates main",
+		"deb-src http://deb.debian.org/debian bookworm main",
+	}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("APTSources() = %q, want %q", sources, want)
+	}
+}
+
+func TestParseListeningSockets(t *testing.T) {
+	data := []byte(`udp   UNCONN 0      0            0.0.0.0:68         0.0.0.0:*
+tcp   LISTEN 0      128          0.0.0.0:22         0.0.0.0:*
+tcp   LISTEN 0      4096       127.0.0.1:5432       0.0.0.0:*
+`)
+	want := []string{"udp 0.0.0.0:68", "tcp 0.0.0.0:22", "tcp 127.0.0.1:5432"}
+	if got := ParseListeningSockets(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseListeningSockets() = %v, want %v", got, want)
+	}
+}
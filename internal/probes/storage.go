@@ -0,0 +1,148 @@
+package probes
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+)
+
+// InodeUsage is one filesystem's row of df -i
+type InodeUsage struct {
+	Filesystem string
+	Mount      string
+	Percent    int
+}
+
+// ParseInodeUsage parses df -i output, skipping memory filesystems and
+// filesystems without inode accounting
+func ParseInodeUsage(data []byte) []InodeUsage {
+	var usage []InodeUsage
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 6 {
+			continue
+		}
+		filesystem := fields[0]
+		if strings.HasPrefix(filesystem, "tmpfs") ||
+			strings.HasPrefix(filesystem, "devtmpfs") ||
+			strings.HasPrefix(filesystem, "udev") {
+			continue
+		}
+		percent, err := strconv.Atoi(strings.TrimSuffix(fields[4], "%"))
+		if err != nil {
+			continue
+		}
+		usage = append(usage, InodeUsage{Filesystem: filesystem, Mount: fields[5], Percent: percent})
+	}
+	return usage
+}
+
+// InodeUsage returns inode usage for every real filesystem
+func (p *Probes) InodeUsage() ([]InodeUsage, error) {
+	return gather(p, "inode-usage", func(m *machine.Machine) ([]InodeUsage, error) {
+		output, err := m.Output("df", "-i")
+		if err != nil {
+			return nil, err
+		}
+		return ParseInodeUsage(output), nil
+	})
+}
+
+// SymlinkDirs are the directories searched for broken symbolic links
+var SymlinkDirs = []string{"/usr/bin", "/usr/local/bin", "/bin", "/sbin"}
+
+// maxBrokenSymlinks stops the search once this many links were found
+const maxBrokenSymlinks = 20
+
+// BrokenSymlinks returns symbolic links in SymlinkDirs whose target is
+// missing, up to a limit
+func (p *Probes) BrokenSymlinks() ([]string, error) {
+	return gather(p, "broken-symlinks", func(m *machine.Machine) ([]string, error) {
+		broken := []string{}
+		for _, dir := range SymlinkDirs {
+			m.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return nil // Skip files we can't access
+				}
+				if info.Mode()&os.ModeSymlink != 0 {
+					if _, err := m.Stat(path); os.IsNotExist(err) {
+						broken = append(broken, path)
+					}
+				}
+				return nil
+			})
+			if len(broken) >= maxBrokenSymlinks {
+				break
+			}
+		}
+		broken = Unique(broken)
+		if len(broken) > maxBrokenSymlinks {
+			broken = broken[:maxBrokenSymlinks]
+		}
+		return broken, nil
+	})
+}
+
+var journalUsagePattern = regexp.MustCompile(`take up ([0-9.]+)([KMGT]?)B?`)
+
+// ParseJournalUsage parses journalctl --disk-usage output into megabytes
+func ParseJournalUsage(data []byte) (float64, bool) {
+	matches := journalUsagePattern.FindStringSubmatch(string(data))
+	if len(matches) < 3 {
+		return 0, false
+	}
+	size, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch matches[2] {
+	case "T":
+		return size * 1024 * 1024, true
+	case "G":
+		return size * 1024, true
+	case "M":
+		return size, true
+	case "K":
+		return size / 1024, true
+	}
+	return size / (1024 * 1024), true
+}
+
+// JournalSizeMB returns how much disk space the systemd journal uses
+func (p *Probes) JournalSizeMB() (float64, error) {
+	return gather(p, "journal-size", func(m *machine.Machine) (float64, error) {
+		output, err := m.Output("journalctl", "--disk-usage")
+		if err != nil {
+			return 0, err
+		}
+		size, _ := ParseJournalUsage(output)
+		return size, nil
+	})
+}
+
+// CoreDumpDir is where systemd-coredump keeps crash dumps
+const CoreDumpDir = "/var/lib/systemd/coredump"
+
+// CoreDumpCount returns how many crash dumps systemd-coredump has kept. A
+// missing directory means no dumps
+func (p *Probes) CoreDumpCount() (int, error) {
+	return gather(p, "core-dumps", func(m *machine.Machine) (int, error) {
+		entries, err := m.ReadDir(CoreDumpDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return 0, nil
+			}
+			return 0, err
+		}
+		count := 0
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasPrefix(entry.Name(), "core.") {
+				count++
+			}
+		}
+		return count, nil
+	})
+}
@@ -0,0 +1,122 @@
+package probes
+
+import (
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+)
+
+// Unit is one row of systemctl list-units
+type Unit struct {
+	Name        string // Full unit name, e.g. nginx.service
+	Load        string
+	Active      string
+	Sub         string
+	Description string
+}
+
+// Type is the unit type, e.g. service or mount
+func (u Unit) Type() string {
+	if i := strings.LastIndex(u.Name, "."); i >= 0 {
+		return u.Name[i+1:]
+	}
+	return ""
+}
+
+// BaseName is the unit name without its type suffix, e.g. nginx
+func (u Unit) BaseName() string {
+	return strings.TrimSuffix(u.Name, "."+u.Type())
+}
+
+// ParseUnits parses systemctl list-units output without a legend. The
+// status bullet systemctl prints in front of failed units is ignored
+func ParseUnits(data []byte) []Unit {
+	var units []Unit
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimLeft(strings.TrimSpace(line), "●*× ")
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		units = append(units, Unit{
+			Name:        fields[0],
+			Load:        fields[1],
+			Active:      fields[2],
+			Sub:         fields[3],
+			Description: strings.Join(fields[4:], " "),
+		})
+	}
+	return units
+}
+
+// FailedUnits returns every unit systemd reports as failed
+func (p *Probes) FailedUnits() ([]Unit, error) {
+	return gather(p, "failed-units", func(m *machine.Machine) ([]Unit, error) {
+		output, err := m.Output("systemctl", "list-units", "--failed", "--no-legend", "--plain", "--no-pager")
+		if err != nil {
+			return nil, err
+		}
+		return ParseUnits(output), nil
+	})
+}
+
+// FailedUnitsOfType returns the failed units of one type, e.g. service
+func (p *Probes) FailedUnitsOfType(unitType string) ([]Unit, error) {
+	units, err := p.FailedUnits()
+	if err != nil {
+		return nil, err
+	}
+	var matching []Unit
+	for _, unit := range units {
+		if unit.Type() == unitType {
+			matching = append(matching, unit)
+		}
+	}
+	return matching, nil
+}
+
+// UnitFile is one row of systemctl list-unit-files
+type UnitFile struct {
+	Name  string // Full unit name, e.g. nginx.service
+	State string // enabled, disabled, masked, static, ...
+}
+
+// ParseUnitFiles parses systemctl list-unit-files output without a legend
+func ParseUnitFiles(data []byte) []UnitFile {
+	var files []UnitFile
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		files = append(files, UnitFile{Name: fields[0], State: fields[1]})
+	}
+	return files
+}
+
+// UnitFiles returns every installed unit file and its enablement state
+func (p *Probes) UnitFiles() ([]UnitFile, error) {
+	return gather(p, "unit-files", func(m *machine.Machine) ([]UnitFile, error) {
+		output, err := m.Output("systemctl", "list-unit-files", "--no-legend", "--no-pager")
+		if err != nil {
+			return nil, err
+		}
+		return ParseUnitFiles(output), nil
+	})
+}
+
+// UnitFilesInState returns the names of the unit files of one type, e.g.
+// service, in one state, e.g. masked. An empty type matches every unit
+func (p *Probes) UnitFilesInState(unitType, state string) ([]string, error) {
+	files, err := p.UnitFiles()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		if file.State == state && (unitType == "" || strings.HasSuffix(file.Name, "."+unitType)) {
+			names = append(names, file.Name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,281 @@
+// Package redact hides identifying and secret data in reports, logs and
+// bundles so they can be shared in public bug trackers
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Rule names accepted by New
+const (
+	RuleSecrets   = "secrets"
+	RuleEmails    = "emails"
+	RuleMACs      = "macs"
+	RuleIPs       = "ips"
+	RuleHomePaths = "home_paths"
+	RuleHostnames = "hostnames"
+)
+
+// Rules lists every rule in the order it is applied. Secrets go first
+// because their values may contain anything the other rules match
+func Rules() []string {
+	return []string{RuleSecrets, RuleEmails, RuleMACs, RuleIPs, RuleHomePaths, RuleHostnames}
+}
+
+var (
+	secretPattern = regexp.MustCompile(`(?i)(\b[\w.-]*(?:pass(?:word|wd|phrase)?|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)\b)(\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s"',;&]+)`)
+	emailPattern  = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)
+	macPattern    = regexp.MustCompile(`\b[0-9A-Fa-f]{2}(?::[0-9A-Fa-f]{2}){5}\b`)
+	ipv4Pattern   = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Pattern   = regexp.MustCompile(`(^|[^\w:.])((?:[0-9A-Fa-f]{0,4}:){2,7}[0-9A-Fa-f]{0,4})`)
+	homePattern   = regexp.MustCompile(`/home/([^/\s:'"]+)`)
+)
+
+// systemd units look like emails, e.g. getty@tty1.service
+var unitSuffixes = []string{
+	".service", ".socket", ".timer", ".target", ".mount", ".automount",
+	".path", ".slice", ".scope", ".device", ".swap",
+}
+
+// Redactor replaces sensitive values with placeholders such as ip-1 or
+// user-2. The same value always gets the same placeholder, so a redacted
+// report still shows which lines refer to the same address or user
+type Redactor struct {
+	rules map[string]bool
+
+	mu       sync.Mutex
+	mappings map[string]map[string]string // kind -> original -> placeholder
+	terms    []string                     // Hostnames and other literal words
+	termRe   *regexp.Regexp
+}
+
+// New creates a redactor applying the named rules, or every rule when none
+// are named. Extra terms, such as hostnames or customer names, are hidden
+// wherever they appear when the hostnames rule is on
+func New(rules []string, terms ...string) (*Redactor, error) {
+	r := &Redactor{
+		rules:    make(map[string]bool),
+		mappings: make(map[string]map[string]string),
+	}
+	if len(rules) == 0 {
+		rules = Rules()
+	}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(strings.ToLower(rule))
+		if !isRule(rule) {
+			return nil, fmt.Errorf("unknown redaction rule %q (available: %s)", rule, strings.Join(Rules(), ", "))
+		}
+		r.rules[rule] = true
+	}
+	for _, term := range terms {
+		r.AddTerm(term)
+	}
+	return r, nil
+}
+
+func isRule(name string) bool {
+	for _, rule := range Rules() {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTerm hides a literal word, matched case-insensitively on word
+// boundaries. Names shorter than two characters and localhost are ignored
+func (r *Redactor) AddTerm(term string) {
+	term = strings.TrimSpace(term)
+	if len(term) < 2 || strings.EqualFold(term, "localhost") {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.terms {
+		if strings.EqualFold(existing, term) {
+			return
+		}
+	}
+	r.terms = append(r.terms, term)
+
+	// Longest first so web1.example.com wins over web1
+	sorted := append([]string(nil), r.terms...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	quoted := make([]string, len(sorted))
+	for i, t := range sorted {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	r.termRe = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// String returns s with every enabled rule applied
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rules[RuleSecrets] {
+		s = secretPattern.ReplaceAllStringFunc(s, func(match string) string {
+			parts := secretPattern.FindStringSubmatch(match)
+			return parts[1] + parts[2] + r.placeholder("secret", parts[3])
+		})
+	}
+	if r.rules[RuleEmails] {
+		s = emailPattern.ReplaceAllStringFunc(s, func(match string) string {
+			for _, suffix := range unitSuffixes {
+				if strings.HasSuffix(match, suffix) {
+					return match
+				}
+			}
+			return r.placeholder("email", match)
+		})
+	}
+	if r.rules[RuleMACs] {
+		s = macPattern.ReplaceAllStringFunc(s, func(match string) string {
+			lower := strings.ToLower(match)
+			if lower == "00:00:00:00:00:00" || lower == "ff:ff:ff:ff:ff:ff" {
+				return match
+			}
+			return r.placeholder("mac", lower)
+		})
+	}
+	if r.rules[RuleIPs] {
+		s = ipv6Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			parts := ipv6Pattern.FindStringSubmatch(match)
+			// At least two groups of digits, so C++ names like std::string stay
+			groups := 0
+			for _, group := range strings.Split(parts[2], ":") {
+				if group != "" {
+					groups++
+				}
+			}
+			ip := net.ParseIP(parts[2])
+			if groups < 2 || ip == nil || ip.To4() != nil || !shareable(ip) {
+				return match
+			}
+			return parts[1] + r.placeholder("ip", ip.String())
+		})
+		s = ipv4Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			ip := net.ParseIP(match)
+			if ip == nil || !shareable(ip) {
+				return match
+			}
+			return r.placeholder("ip", ip.String())
+		})
+	}
+	if r.rules[RuleHomePaths] {
+		s = homePattern.ReplaceAllStringFunc(s, func(match string) string {
+			user := strings.TrimPrefix(match, "/home/")
+			return "/home/" + r.placeholder("user", user)
+		})
+	}
+	if r.rules[RuleHostnames] && r.termRe != nil {
+		s = r.termRe.ReplaceAllStringFunc(s, func(match string) string {
+			return r.placeholder("host", strings.ToLower(match))
+		})
+	}
+	return s
+}
+
+// Bytes is String for byte slices
+func (r *Redactor) Bytes(b []byte) []byte {
+	if r == nil {
+		return b
+	}
+	return []byte(r.String(string(b)))
+}
+
+// JSON is Bytes for a JSON document. Each string is decoded, redacted and
+// encoded again, so escaped quotes can neither hide a value nor end up
+// unbalanced; the layout of the document is kept
+func (r *Redactor) JSON(data []byte) []byte {
+	if r == nil {
+		return data
+	}
+	var out bytes.Buffer
+	for i := 0; i < len(data); i++ {
+		if data[i] != '"' {
+			out.WriteByte(data[i])
+			continue
+		}
+		end := i + 1
+		for end < len(data) && data[end] != '"' {
+			if data[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(data) {
+			out.Write(data[i:])
+			break
+		}
+		var value string
+		if err := json.Unmarshal(data[i:end+1], &value); err != nil {
+			out.Write(data[i : end+1])
+		} else {
+			encoded, _ := json.Marshal(r.String(value))
+			out.Write(encoded)
+		}
+		i = end
+	}
+	return out.Bytes()
+}
+
+// Strings redacts every element of a slice in place and returns it
+func (r *Redactor) Strings(values []string) []string {
+	for i, value := range values {
+		values[i] = r.String(value)
+	}
+	return values
+}
+
+// shareable reports whether an address identifies the machine. Loopback,
+// unspecified and netmask-like addresses are kept because they help
+// diagnosis and reveal nothing
+func shareable(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 255 {
+		return false
+	}
+	return true
+}
+
+// placeholder returns the stable stand-in for value. The caller holds r.mu
+func (r *Redactor) placeholder(kind, value string) string {
+	mapping, ok := r.mappings[kind]
+	if !ok {
+		mapping = make(map[string]string)
+		r.mappings[kind] = mapping
+	}
+	if p, ok := mapping[value]; ok {
+		return p
+	}
+	p := fmt.Sprintf("%s-%d", kind, len(mapping)+1)
+	mapping[value] = p
+	return p
+}
+
+// Count returns how many distinct values have been replaced
+func (r *Redactor) Count() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total := 0
+	for _, mapping := range r.mappings {
+		total += len(mapping)
+	}
+	return total
+}
@@ -0,0 +1,90 @@
+package redact
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedactRules(t *testing.T) {
+	r, err := New(nil, "web1.example.com", "web1")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ipv4", "DNS server 10.0.0.53 via 10.0.0.1", "DNS server ip-1 via ip-2"},
+		{"ipv4 repeated", "ping 10.0.0.53", "ping ip-1"},
+		{"loopback kept", "nameserver 127.0.0.53", "nameserver 127.0.0.53"},
+		{"netmask kept", "mask 255.255.255.0", "mask 255.255.255.0"},
+		{"ipv6", "inet6 2001:db8::1/64 scope global", "inet6 ip-3/64 scope global"},
+		{"ipv6 loopback kept", "inet6 ::1/128", "inet6 ::1/128"},
+		{"cpp names kept", "std::string and 10:30:00", "std::string and 10:30:00"},
+		{"mac", "link/ether 52:54:00:AB:cd:01 brd ff:ff:ff:ff:ff:ff", "link/ether mac-1 brd ff:ff:ff:ff:ff:ff"},
+		{"email", "mail from alice@example.org", "mail from email-1"},
+		{"unit kept", "getty@tty1.service failed", "getty@tty1.service failed"},
+		{"home path", "/home/alice/.bashrc and /home/bob", "/home/user-1/.bashrc and /home/user-2"},
+		{"secret", "DB_PASSWORD=hunter2 api_key: 'abc' token=\"x y\"", "DB_PASSWORD=secret-1 api_key: secret-2 token=secret-3"},
+		{"hostname", "web1.example.com and WEB1 but not web10", "host-1 and host-2 but not web10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.String(tt.input); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if got := r.Count(); got != 12 {
+		t.Errorf("Count() = %d, want 12", got)
+	}
+}
+
+func TestRedactSelectedRules(t *testing.T) {
+	r, err := New([]string{"ips"}, "web1")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got := r.String("web1 10.1.2.3 alice@example.org")
+	if got != "web1 ip-1 alice@example.org" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if _, err := New([]string{"ips", "passports"}); err == nil || !strings.Contains(err.Error(), "passports") {
+		t.Errorf("Expected an unknown rule error, got %v", err)
+	}
+}
+
+func TestNilRedactorIsNoop(t *testing.T) {
+	var r *Redactor
+	if got := r.String("10.0.0.1"); got != "10.0.0.1" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := string(r.Bytes([]byte("10.0.0.1"))); got != "10.0.0.1" {
+		t.Errorf("Bytes() = %q", got)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	r, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	data := []byte(`{"detail": "password=\"hunter2 more\" on 10.0.0.1", "exit_code": 0}`)
+
+	got := r.JSON(data)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("JSON() output is not valid JSON: %v\n%s", err, got)
+	}
+	if decoded["detail"] != "password=secret-1 on ip-1" {
+		t.Errorf("detail = %q", decoded["detail"])
+	}
+	if !strings.HasSuffix(string(got), `, "exit_code": 0}`) {
+		t.Errorf("JSON() changed the layout: %s", got)
+	}
+}
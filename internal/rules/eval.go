@@ -0,0 +1,332 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// Data is what summaries, evidence and fixes are rendered with
+type Data struct {
+	Value  float64           // Value of a number fact
+	Limit  float64           // The above or below limit the value was compared with
+	Items  []string          // Matching items of a list fact
+	Count  int               // Number of matching items
+	Item   string            // The item a finding is about, for rules with each
+	Params map[string]string // The rule's params
+}
+
+var templateFuncs = template.FuncMap{
+	"quote": shellQuote,
+	"join":  strings.Join,
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template in %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// shellQuote quotes a string, or each string of a list joined by spaces, so
+// a shell reads it back unchanged
+func shellQuote(value interface{}) string {
+	quote := func(s string) string {
+		if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+			return s
+		}
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+	switch v := value.(type) {
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = quote(s)
+		}
+		return strings.Join(quoted, " ")
+	case string:
+		return quote(v)
+	}
+	return quote(fmt.Sprint(value))
+}
+
+// checkQuoted returns an error when a command template writes .Item or
+// .Items without passing them through quote. Items come from the machine,
+// e.g. unit names, and must not be able to inject shell syntax
+func checkQuoted(tmpl *template.Template) error {
+	var walk func(node parse.Node) error
+	walk = func(node parse.Node) error {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return nil
+			}
+			for _, child := range n.Nodes {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		case *parse.ActionNode:
+			return walkPipe(n.Pipe, false)
+		case *parse.IfNode:
+			return walkBranch(&n.BranchNode, walk)
+		case *parse.RangeNode:
+			if usesItems(n.Pipe) {
+				return fmt.Errorf("range over .Items; use {{quote .Items}} instead")
+			}
+			return walkBranch(&n.BranchNode, walk)
+		case *parse.WithNode:
+			if usesItems(n.Pipe) {
+				return fmt.Errorf("with .Item or .Items; use quote instead")
+			}
+			return walkBranch(&n.BranchNode, walk)
+		}
+		return nil
+	}
+	return walk(tmpl.Root)
+}
+
+// walkBranch checks the parts of an if, range or with node
+func walkBranch(b *parse.BranchNode, walk func(parse.Node) error) error {
+	for _, node := range []parse.Node{b.List, b.ElseList} {
+		if list, ok := node.(*parse.ListNode); ok && list != nil {
+			if err := walk(list); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkPipe returns an error when the pipeline writes an item unquoted.
+// A pipeline ending in quote, like {{.Item | quote}}, quotes everything
+// before it
+func walkPipe(pipe *parse.PipeNode, quoted bool) error {
+	if pipe == nil || len(pipe.Cmds) == 0 {
+		return nil
+	}
+	if len(pipe.Decl) > 0 && usesItems(pipe) {
+		return fmt.Errorf("assigns .Item or .Items to a variable; use quote instead")
+	}
+	if isQuote(pipe.Cmds[len(pipe.Cmds)-1]) {
+		quoted = true
+	}
+	for _, cmd := range pipe.Cmds {
+		argsQuoted := quoted || isQuote(cmd)
+		for _, arg := range cmd.Args {
+			if nested, ok := arg.(*parse.PipeNode); ok {
+				if err := walkPipe(nested, argsQuoted); err != nil {
+					return err
+				}
+				continue
+			}
+			if !argsQuoted && isItems(arg) {
+				return fmt.Errorf("%s is used without quote", arg)
+			}
+		}
+	}
+	return nil
+}
+
+// isQuote reports whether the command calls quote
+func isQuote(cmd *parse.CommandNode) bool {
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	return ok && ident.Ident == "quote"
+}
+
+// isItems reports whether the node refers to .Item or .Items
+func isItems(node parse.Node) bool {
+	var fields []string
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		fields = n.Ident
+	case *parse.VariableNode:
+		fields = n.Ident[1:] // $.Item
+	case *parse.ChainNode:
+		return isItems(n.Node)
+	}
+	return len(fields) > 0 && (fields[0] == "Item" || fields[0] == "Items")
+}
+
+// usesItems reports whether the pipeline refers to .Item or .Items anywhere
+func usesItems(pipe *parse.PipeNode) bool {
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			if nested, ok := arg.(*parse.PipeNode); ok && usesItems(nested) {
+				return true
+			}
+			if isItems(arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func execute(tmpl *template.Template, data Data) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Match tests the rule's condition against the facts in p. It returns
+// whether the rule applies and the data to render it with. A fact that
+// cannot be gathered never matches
+func (r *Rule) Match(p *probes.Probes, t config.Thresholds) (bool, Data) {
+	data := Data{Params: r.Params}
+	if data.Params == nil {
+		data.Params = map[string]string{}
+	}
+	fact, _ := lookupFact(r.When.Fact)
+	c := r.When
+
+	if !fact.IsList() {
+		value, err := fact.number(p)
+		if err != nil {
+			return false, data
+		}
+		data.Value = value
+		if c.Above != nil {
+			data.Limit = c.Above.Resolve(t)
+			if value <= data.Limit {
+				return false, data
+			}
+		}
+		if c.Below != nil {
+			data.Limit = c.Below.Resolve(t)
+			if value >= data.Limit {
+				return false, data
+			}
+		}
+		return true, data
+	}
+
+	items, err := fact.list(p)
+	if err != nil {
+		return false, data
+	}
+	for _, item := range items {
+		if (c.Contains != "" && item == c.Contains) || (r.matches != nil && r.matches.MatchString(item)) || c.NotEmpty {
+			data.Items = append(data.Items, item)
+		}
+	}
+	data.Count = len(data.Items)
+	return data.Count > 0, data
+}
+
+// Evaluate runs the rule against the facts in p and returns the findings
+// and fixes to report, or nothing when the condition does not hold
+func (r *Rule) Evaluate(p *probes.Probes, t config.Thresholds) ([]finding.Finding, []*fixes.Fix, error) {
+	ok, data := r.Match(p, t)
+	if !ok {
+		return nil, nil, nil
+	}
+	findings, offered, err := r.render(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rule %s: %w", r.ID, err)
+	}
+	return findings, offered, nil
+}
+
+// render builds the findings and fixes for a rule that applies
+func (r *Rule) render(data Data) ([]finding.Finding, []*fixes.Fix, error) {
+	fixIDs := make([]string, len(r.Fixes))
+	for i, fix := range r.Fixes {
+		fixIDs[i] = fix.ID
+	}
+
+	subjects := []Data{data}
+	if r.Each {
+		subjects = subjects[:0]
+		for _, item := range data.Items {
+			itemData := data
+			itemData.Item = item
+			subjects = append(subjects, itemData)
+		}
+	}
+
+	var findings []finding.Finding
+	for _, subject := range subjects {
+		summary, err := execute(r.summary, subject)
+		if err != nil {
+			return nil, nil, err
+		}
+		f := finding.New(r.ID, r.Severity, summary)
+		for key, tmpl := range r.evidence {
+			value, err := execute(tmpl, subject)
+			if err != nil {
+				return nil, nil, err
+			}
+			f = f.With(key, value)
+		}
+		if r.Resource != "" && subject.Item != "" {
+			f = f.Affects(r.Resource, subject.Item)
+		}
+		if len(fixIDs) > 0 {
+			f = f.FixedBy(fixIDs...)
+		}
+		findings = append(findings, f)
+	}
+
+	var offered []*fixes.Fix
+	for _, fix := range r.Fixes {
+		built, err := fix.build(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		offered = append(offered, built)
+	}
+	return findings, offered, nil
+}
+
+// build renders the fix template with data
+func (f FixTemplate) build(data Data) (*fixes.Fix, error) {
+	renderAll := func(texts []string) ([]string, error) {
+		var rendered []string
+		for _, text := range texts {
+			tmpl, err := parseTemplate("fix "+f.ID, text)
+			if err != nil {
+				return nil, err
+			}
+			out, err := execute(tmpl, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, out)
+		}
+		return rendered, nil
+	}
+
+	text, err := renderAll([]string{f.Title, f.Description})
+	if err != nil {
+		return nil, err
+	}
+	commands, err := renderAll(f.Commands)
+	if err != nil {
+		return nil, err
+	}
+	reverse, err := renderAll(f.ReverseCommands)
+	if err != nil {
+		return nil, err
+	}
+	return &fixes.Fix{
+		ID:              f.ID,
+		Title:           text[0],
+		Description:     text[1],
+		Commands:        commands,
+		RequiresRoot:    f.RequiresRoot,
+		Reversible:      f.Reversible,
+		ReverseCommands: reverse,
+		RiskLevel:       *f.Risk,
+		Modifies:        f.Modifies,
+	}, nil
+}
@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"sort"
+
+	"github.com/debian-doctor/debian-doctor/internal/probes"
+)
+
+// Fact is a value rules can test, gathered through the shared probes.
+// Number facts are compared with above and below; list facts are tested
+// with contains, matches and not_empty
+type Fact struct {
+	Name        string
+	Description string
+	number      func(*probes.Probes) (float64, error)
+	list        func(*probes.Probes) ([]string, error)
+}
+
+// IsList reports whether the fact is a list of items rather than a number
+func (f Fact) IsList() bool {
+	return f.list != nil
+}
+
+// counted adapts a probe returning a count to a number fact
+func counted(get func(*probes.Probes) (int, error)) func(*probes.Probes) (float64, error) {
+	return func(p *probes.Probes) (float64, error) {
+		n, err := get(p)
+		return float64(n), err
+	}
+}
+
+var factList = []Fact{
+	{Name: "journal_size_mb", Description: "Disk space used by the systemd journal in megabytes",
+		number: (*probes.Probes).JournalSizeMB},
+	{Name: "package_cache_mb", Description: "Size of the downloaded package cache in megabytes",
+		number: (*probes.Probes).PackageCacheMB},
+	{Name: "core_dumps", Description: "Crash dumps kept by systemd-coredump",
+		number: counted((*probes.Probes).CoreDumpCount)},
+	{Name: "upgradable_packages", Description: "Packages with a newer version available",
+		number: counted((*probes.Probes).UpgradableCount)},
+	{Name: "autoremovable_packages", Description: "Packages apt autoremove would remove",
+		number: counted((*probes.Probes).AutoremovableCount)},
+	{Name: "installed_packages", Description: "Names of the installed packages",
+		list: func(p *probes.Probes) ([]string, error) {
+			packages, err := p.Packages()
+			var names []string
+			for _, pkg := range packages {
+				if pkg.Installed() {
+					names = append(names, pkg.Name)
+				}
+			}
+			return names, err
+		}},
+	{Name: "broken_packages", Description: "Packages dpkg or apt consider broken",
+		list: (*probes.Probes).BrokenPackages},
+	{Name: "held_packages", Description: "Packages held back with apt-mark",
+		list: (*probes.Probes).HeldPackages},
+	{Name: "dpkg_audit", Description: "Lines printed by dpkg --audit",
+		list: (*probes.Probes).DpkgAudit},
+	{Name: "failed_units", Description: "systemd units in the failed state",
+		list: func(p *probes.Probes) ([]string, error) {
+			return unitNames(p.FailedUnits())
+		}},
+	{Name: "failed_services", Description: "systemd services in the failed state",
+		list: func(p *probes.Probes) ([]string, error) {
+			return unitNames(p.FailedUnitsOfType("service"))
+		}},
+	{Name: "enabled_units", Description: "Enabled systemd unit files",
+		list: func(p *probes.Probes) ([]string, error) {
+			return p.UnitFilesInState("", "enabled")
+		}},
+	{Name: "masked_units", Description: "Masked systemd unit files",
+		list: func(p *probes.Probes) ([]string, error) {
+			return p.UnitFilesInState("", "masked")
+		}},
+	{Name: "read_only_mounts", Description: "Mount points of filesystems mounted read-only",
+		list: func(p *probes.Probes) ([]string, error) {
+			mounts, err := p.ReadOnlyMounts()
+			var paths []string
+			for _, mount := range mounts {
+				paths = append(paths, mount.Path)
+			}
+			return paths, err
+		}},
+	{Name: "broken_symlinks", Description: "Symbolic links whose target is missing",
+		list: (*probes.Probes).BrokenSymlinks},
+	{Name: "apt_sources", Description: "Enabled APT sources, one line each",
+		list: (*probes.Probes).APTSources},
+	{Name: "listening_sockets", Description: "Listening TCP and UDP sockets",
+		list: (*probes.Probes).ListeningSockets},
+	{Name: "suid_binaries", Description: "Files with the setuid bit set",
+		list: (*probes.Probes).SUIDBinaries},
+}
+
+func unitNames(units []probes.Unit, err error) ([]string, error) {
+	names := make([]string, len(units))
+	for i, unit := range units {
+		names[i] = unit.Name
+	}
+	return names, err
+}
+
+// Facts lists every fact rules can test, sorted by name
+func Facts() []Fact {
+	facts := append([]Fact(nil), factList...)
+	sort.Slice(facts, func(i, j int) bool { return facts[i].Name < facts[j].Name })
+	return facts
+}
+
+// lookupFact finds a fact by name
+func lookupFact(name string) (Fact, bool) {
+	for _, fact := range factList {
+		if fact.Name == name {
+			return fact, true
+		}
+	}
+	return Fact{}, false
+}
@@ -0,0 +1,433 @@
+// Package rules turns declarative diagnosis rules into findings and fixes.
+// A rule tests one fact gathered by the probes, such as the journal size or
+// the failed units, and says which finding to report and which fixes to
+// offer when the test holds. Built-in rule packs ship inside the binary;
+// administrators add their own packs to /etc/debian-doctor/rules.d
+package rules
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed builtin/*.yaml
+var builtinPacks embed.FS
+
+// BuiltinSource is the source recorded for rules shipped with debian-doctor
+const BuiltinSource = "built-in"
+
+// Pack is a file of rules. Params changes the params of rules loaded
+// before, keyed by rule ID, without repeating the whole rule
+type Pack struct {
+	Rules  []Rule                       `yaml:"rules"`
+	Params map[string]map[string]string `yaml:"params"`
+}
+
+// Rule reports a finding and offers fixes when its condition holds. ID is
+// also the finding ID. A rule with the ID of an earlier rule replaces it;
+// Disabled turns the earlier rule off
+type Rule struct {
+	ID        string            `yaml:"id"`
+	Diagnosis string            `yaml:"diagnosis"` // Diagnosis the rule belongs to, e.g. logs
+	Severity  finding.Severity  `yaml:"severity"`
+	When      Condition         `yaml:"when"`
+	Each      bool              `yaml:"each"`     // One finding per matching item of a list fact
+	Resource  string            `yaml:"resource"` // Resource kind the items are, e.g. unit
+	Summary   string            `yaml:"summary"`
+	Evidence  map[string]string `yaml:"evidence"`
+	Params    map[string]string `yaml:"params"`
+	Fixes     []FixTemplate     `yaml:"fixes"`
+	Disabled  bool              `yaml:"disabled"`
+
+	Source string `yaml:"-"` // File the rule came from
+
+	summary  *template.Template
+	evidence map[string]*template.Template
+	matches  *regexp.Regexp
+}
+
+// Condition tests a fact. Number facts take above or below, which are
+// numbers or threshold names such as journal_max_mb. List facts match when
+// an item equals contains, when an item matches the regular expression
+// matches, or with not_empty when the list has any item
+type Condition struct {
+	Fact     string `yaml:"fact"`
+	Above    *Limit `yaml:"above"`
+	Below    *Limit `yaml:"below"`
+	Contains string `yaml:"contains"`
+	Matches  string `yaml:"matches"`
+	NotEmpty bool   `yaml:"not_empty"`
+}
+
+// Limit is a number, or the name of a configured threshold
+type Limit struct {
+	Value     float64
+	Threshold string
+}
+
+// UnmarshalYAML accepts a number or a threshold name
+func (l *Limit) UnmarshalYAML(node *yaml.Node) error {
+	if value, err := strconv.ParseFloat(node.Value, 64); err == nil {
+		l.Value = value
+		return nil
+	}
+	if _, ok := config.DefaultThresholds().Limit(node.Value); !ok {
+		return fmt.Errorf("line %d: %q is neither a number nor a threshold", node.Line, node.Value)
+	}
+	l.Threshold = node.Value
+	return nil
+}
+
+// Resolve returns the limit's value under the given thresholds
+func (l Limit) Resolve(t config.Thresholds) float64 {
+	if l.Threshold != "" {
+		value, _ := t.Limit(l.Threshold)
+		return value
+	}
+	return l.Value
+}
+
+func (l Limit) String() string {
+	if l.Threshold != "" {
+		return "thresholds." + l.Threshold
+	}
+	return strconv.FormatFloat(l.Value, 'f', -1, 64)
+}
+
+// FixTemplate is a fix whose text and commands are templates. Commands
+// see the same data as the summary, so they can name the matched items
+type FixTemplate struct {
+	ID              string           `yaml:"id"`
+	Title           string           `yaml:"title"`
+	Description     string           `yaml:"description"`
+	Commands        []string         `yaml:"commands"`
+	RequiresRoot    bool             `yaml:"requires_root"`
+	Reversible      bool             `yaml:"reversible"`
+	ReverseCommands []string         `yaml:"reverse_commands"`
+	Risk            *fixes.RiskLevel `yaml:"risk"` // Required, so a forgotten risk cannot make a fix look safe
+	Modifies        []string         `yaml:"modifies"`
+}
+
+// Set is the effective list of rules, in the order they were loaded
+type Set struct {
+	rules []*Rule
+
+	// Skipped lists the packs from rule directories that were not loaded
+	// and why, so that callers can warn about them
+	Skipped []string
+}
+
+// Rules returns the enabled rules
+func (s *Set) Rules() []*Rule {
+	var enabled []*Rule
+	for _, rule := range s.rules {
+		if !rule.Disabled {
+			enabled = append(enabled, rule)
+		}
+	}
+	return enabled
+}
+
+// ForDiagnosis returns the enabled rules belonging to a diagnosis
+func (s *Set) ForDiagnosis(id string) []*Rule {
+	var selected []*Rule
+	for _, rule := range s.Rules() {
+		if rule.Diagnosis == id {
+			selected = append(selected, rule)
+		}
+	}
+	return selected
+}
+
+// add appends a rule or replaces the rule with the same ID in place
+func (s *Set) add(rule *Rule) {
+	for i, existing := range s.rules {
+		if existing.ID == rule.ID {
+			s.rules[i] = rule
+			return
+		}
+	}
+	s.rules = append(s.rules, rule)
+}
+
+// Builtin returns the rules shipped with debian-doctor
+func Builtin() (*Set, error) {
+	return Load(nil)
+}
+
+// Load reads the built-in rules, then every *.yaml or *.yml pack in dirs in
+// name order. Missing directories are skipped. A pack that is invalid, or
+// that anyone but root or the current user could change, is left out and
+// noted in Skipped; the fixes it offers would run with our privileges
+func Load(dirs []string) (*Set, error) {
+	set := &Set{}
+	names, err := fs.Glob(builtinPacks, "builtin/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		data, err := builtinPacks.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := set.addPack(data, BuiltinSource+" "+filepath.Base(name)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read rules directory: %w", err)
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if err := config.CheckTrusted(path); err != nil {
+				set.Skipped = append(set.Skipped, fmt.Sprintf("untrusted rules %s: %v", path, err))
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				set.Skipped = append(set.Skipped, fmt.Sprintf("failed to read rules: %v", err))
+				continue
+			}
+			if err := set.addPack(data, path); err != nil {
+				set.Skipped = append(set.Skipped, err.Error())
+			}
+		}
+	}
+	return set, nil
+}
+
+// addPack parses and validates a pack, adding its rules to the set. A pack
+// that fails leaves the set as it was
+func (s *Set) addPack(data []byte, source string) error {
+	next := &Set{rules: append([]*Rule(nil), s.rules...)}
+	if err := next.mergePack(data, source); err != nil {
+		return err
+	}
+	s.rules = next.rules
+	return nil
+}
+
+// mergePack adds the rules and params of a pack as far as they are valid
+func (s *Set) mergePack(data []byte, source string) error {
+	var pack Pack
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&pack); err != nil && err != io.EOF {
+		return fmt.Errorf("invalid rules %s: %w", source, err)
+	}
+	for i := range pack.Rules {
+		rule := &pack.Rules[i]
+		rule.Source = source
+		if err := rule.compile(); err != nil {
+			if rule.ID == "" {
+				return fmt.Errorf("invalid rules %s: rule %d: %w", source, i+1, err)
+			}
+			return fmt.Errorf("invalid rules %s: rule %s: %w", source, rule.ID, err)
+		}
+		s.add(rule)
+	}
+
+	ids := make([]string, 0, len(pack.Params))
+	for id := range pack.Params {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := s.setParams(id, pack.Params[id]); err != nil {
+			return fmt.Errorf("invalid rules %s: %w", source, err)
+		}
+	}
+	return nil
+}
+
+// setParams overrides some params of a loaded rule
+func (s *Set) setParams(id string, params map[string]string) error {
+	var rule *Rule
+	for _, r := range s.rules {
+		if r.ID == id {
+			rule = r
+		}
+	}
+	if rule == nil {
+		return fmt.Errorf("params for unknown rule %s", id)
+	}
+
+	merged := make(map[string]string, len(rule.Params))
+	for name, value := range rule.Params {
+		merged[name] = value
+	}
+	for name, value := range params {
+		if _, ok := merged[name]; !ok {
+			return fmt.Errorf("rule %s has no param %q", id, name)
+		}
+		merged[name] = value
+	}
+	// Copy the rule so sets loaded earlier keep their params
+	changed := *rule
+	changed.Params = merged
+	s.add(&changed)
+	return nil
+}
+
+// compile validates the rule and parses its templates
+func (r *Rule) compile() error {
+	if r.ID == "" {
+		return fmt.Errorf("id is missing")
+	}
+	if r.Disabled {
+		return nil // Only turns off an earlier rule
+	}
+	if r.Diagnosis == "" {
+		return fmt.Errorf("diagnosis is missing")
+	}
+	if strings.TrimSpace(r.Summary) == "" {
+		return fmt.Errorf("summary is missing")
+	}
+
+	fact, ok := lookupFact(r.When.Fact)
+	if !ok {
+		names := make([]string, 0, len(factList))
+		for _, f := range Facts() {
+			names = append(names, f.Name)
+		}
+		return fmt.Errorf("unknown fact %q (available: %s)", r.When.Fact, strings.Join(names, ", "))
+	}
+	c := r.When
+	if fact.IsList() {
+		if c.Above != nil || c.Below != nil {
+			return fmt.Errorf("%s is a list; use contains, matches or not_empty", fact.Name)
+		}
+		tests := 0
+		for _, set := range []bool{c.Contains != "", c.Matches != "", c.NotEmpty} {
+			if set {
+				tests++
+			}
+		}
+		if tests != 1 {
+			return fmt.Errorf("%s needs exactly one of contains, matches or not_empty", fact.Name)
+		}
+		if c.Matches != "" {
+			matches, err := regexp.Compile(c.Matches)
+			if err != nil {
+				return fmt.Errorf("invalid matches: %w", err)
+			}
+			r.matches = matches
+		}
+	} else {
+		if c.Contains != "" || c.Matches != "" || c.NotEmpty {
+			return fmt.Errorf("%s is a number; use above or below", fact.Name)
+		}
+		if c.Above == nil && c.Below == nil {
+			return fmt.Errorf("%s needs above or below", fact.Name)
+		}
+		if r.Each {
+			return fmt.Errorf("each only applies to list facts")
+		}
+	}
+
+	var err error
+	if r.summary, err = parseTemplate("summary", r.Summary); err != nil {
+		return err
+	}
+	r.evidence = make(map[string]*template.Template, len(r.Evidence))
+	for key, text := range r.Evidence {
+		if r.evidence[key], err = parseTemplate("evidence "+key, text); err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, fix := range r.Fixes {
+		if fix.ID == "" || fix.Title == "" || len(fix.Commands) == 0 {
+			return fmt.Errorf("every fix needs an id, a title and commands")
+		}
+		if seen[fix.ID] {
+			return fmt.Errorf("fix %s listed twice", fix.ID)
+		}
+		seen[fix.ID] = true
+		if fix.Risk == nil {
+			return fmt.Errorf("fix %s needs a risk (low, medium, high or critical)", fix.ID)
+		}
+		if fix.Reversible && len(fix.ReverseCommands) == 0 {
+			return fmt.Errorf("fix %s is reversible but has no reverse_commands", fix.ID)
+		}
+		for _, command := range append(append([]string{}, fix.Commands...), fix.ReverseCommands...) {
+			tmpl, err := parseTemplate("fix "+fix.ID, command)
+			if err != nil {
+				return err
+			}
+			if err := checkQuoted(tmpl); err != nil {
+				return fmt.Errorf("fix %s: %w", fix.ID, err)
+			}
+		}
+	}
+
+	// Render everything once so a misspelt param fails when loading
+	sample := Data{Params: r.Params, Items: []string{"item"}, Count: 1, Item: "item"}
+	if _, _, err := r.render(sample); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Describe renders the condition as text, e.g. "journal_size_mb above
+// thresholds.journal_max_mb"
+func (c Condition) Describe() string {
+	var tests []string
+	if c.Above != nil {
+		tests = append(tests, "above "+c.Above.String())
+	}
+	if c.Below != nil {
+		tests = append(tests, "below "+c.Below.String())
+	}
+	if c.Contains != "" {
+		tests = append(tests, "contains "+c.Contains)
+	}
+	if c.Matches != "" {
+		tests = append(tests, "matches /"+c.Matches+"/")
+	}
+	if c.NotEmpty {
+		tests = append(tests, "not empty")
+	}
+	return c.Fact + " " + strings.Join(tests, " and ")
+}
+
+// Diagnoses lists the diagnoses the rules belong to, sorted
+func (s *Set) Diagnoses() []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, rule := range s.Rules() {
+		if !seen[rule.Diagnosis] {
+			seen[rule.Diagnosis] = true
+			ids = append(ids, rule.Diagnosis)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
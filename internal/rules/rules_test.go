@@ -0,0 +1,226 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/internal/probes"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// loadPacks loads the built-in rules followed by the given packs
+func loadPacks(t *testing.T, packs ...string) (*Set, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for i, pack := range packs {
+		name := filepath.Join(dir, string(rune('a'+i))+".yaml")
+		if err := os.WriteFile(name, []byte(pack), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return Load([]string{dir, filepath.Join(dir, "missing")})
+}
+
+func TestBuiltin(t *testing.T) {
+	set, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin() error = %v", err)
+	}
+	if len(set.ForDiagnosis("logs")) == 0 || len(set.ForDiagnosis("packages")) == 0 {
+		t.Errorf("built-in rules = %v", set.Diagnoses())
+	}
+	for _, rule := range set.Rules() {
+		if !strings.HasPrefix(rule.Source, BuiltinSource) {
+			t.Errorf("rule %s has source %q", rule.ID, rule.Source)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		pack string
+		want string
+	}{
+		{"unknown field", "rules:\n  - id: a.b\n    diagnosis: logs\n    colour: red\n", "field colour not found"},
+		{"missing id", "rules:\n  - diagnosis: logs\n", "rule 1: id is missing"},
+		{"unknown fact", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: moon_phase, above: 1}\n", `unknown fact "moon_phase"`},
+		{"list compared", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: failed_units, above: 1}\n", "failed_units is a list"},
+		{"number matched", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: core_dumps, not_empty: true}\n", "core_dumps is a number"},
+		{"two tests", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: failed_units, contains: a, not_empty: true}\n", "exactly one of"},
+		{"bad threshold", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: core_dumps, above: lots}\n", "neither a number nor a threshold"},
+		{"bad regexp", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: failed_units, matches: '('}\n", "invalid matches"},
+		{"bad severity", "rules:\n  - id: a.b\n    diagnosis: logs\n    severity: dire\n", "unknown severity"},
+		{"missing param", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: '{{.Params.size}}'\n    when: {fact: core_dumps, above: 1}\n", "size"},
+		{"fix without commands", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: core_dumps, above: 1}\n    fixes: [{id: f, title: F}]\n", "needs an id, a title and commands"},
+		{"fix without risk", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: core_dumps, above: 1}\n    fixes: [{id: f, title: F, commands: [true]}]\n", "fix f needs a risk"},
+		{"unquoted item", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: failed_units, not_empty: true}\n    fixes: [{id: f, title: F, risk: low, commands: [\"systemctl restart {{.Item}}\"]}]\n", "fix f: .Item is used without quote"},
+		{"unquoted join", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: failed_units, not_empty: true}\n    fixes: [{id: f, title: F, risk: low, commands: [\"systemctl restart {{join .Items \\\" \\\"}}\"]}]\n", ".Items is used without quote"},
+		{"range over items", "rules:\n  - id: a.b\n    diagnosis: logs\n    summary: x\n    when: {fact: failed_units, not_empty: true}\n    fixes: [{id: f, title: F, risk: low, commands: [\"{{range .Items}}{{.}}{{end}}\"]}]\n", "range over .Items"},
+		{"params for unknown rule", "params:\n  a.b: {size: 1}\n", "params for unknown rule a.b"},
+		{"unknown param", "params:\n  logs.journal_large: {max_age: 1}\n", `has no param "max_age"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := loadPacks(t, tt.pack)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(set.Skipped) != 1 || !strings.Contains(set.Skipped[0], tt.want) {
+				t.Errorf("Skipped = %v, want %q", set.Skipped, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSkipsBadPacks(t *testing.T) {
+	dir := t.TempDir()
+	packs := map[string]string{
+		// The first rule is valid, but the pack is not, so neither is used
+		"a.yaml": "rules:\n  - id: logs.journal_large\n    disabled: true\n  - id: a.b\n    diagnosis: logs\n",
+		"b.yaml": "rules:\n  - id: packages.configuration\n    disabled: true\n",
+		"c.yaml": "rules:\n  - id: site.core_dumps\n    diagnosis: logs\n    summary: x\n    when: {fact: core_dumps, above: 1}\n",
+	}
+	for name, pack := range packs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(pack), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Chmod(filepath.Join(dir, "c.yaml"), 0666)
+
+	set, err := Load([]string{dir})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(set.Skipped) != 2 || !strings.Contains(set.Skipped[0], "a.yaml") ||
+		!strings.Contains(set.Skipped[1], "untrusted rules") || !strings.Contains(set.Skipped[1], "writable by other users") {
+		t.Errorf("Skipped = %v", set.Skipped)
+	}
+	enabled := map[string]bool{}
+	for _, rule := range set.Rules() {
+		enabled[rule.ID] = true
+	}
+	if !enabled["logs.journal_large"] || enabled["packages.configuration"] || enabled["site.core_dumps"] {
+		t.Errorf("enabled rules = %v", enabled)
+	}
+}
+
+func TestLoadReplaceAndDisable(t *testing.T) {
+	set, err := loadPacks(t,
+		"rules:\n  - id: packages.configuration\n    disabled: true\n",
+		"rules:\n  - id: logs.journal_large\n    diagnosis: logs\n    severity: error\n    summary: Journal too big\n    when: {fact: journal_size_mb, above: 10}\n")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if rules := set.ForDiagnosis("packages"); len(rules) != 0 {
+		t.Errorf("disabled rule still enabled: %v", rules)
+	}
+	logs := set.ForDiagnosis("logs")
+	if len(logs) != 1 || logs[0].Summary != "Journal too big" || !strings.HasSuffix(logs[0].Source, "b.yaml") {
+		t.Errorf("logs rules = %+v", logs)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	set, err := loadPacks(t, `
+rules:
+  - id: filesystem.srv_read_only
+    diagnosis: filesystem
+    severity: critical
+    when: {fact: read_only_mounts, contains: /srv}
+    summary: '/srv is read-only ({{.Count}} of {{len .Items}})'
+    fixes:
+      - id: remount_srv
+        title: Remount /srv
+        commands: ['mount -o remount,rw {{quote .Items}}']
+        reversible: true
+        reverse_commands: ['mount -o remount,ro /srv']
+        risk: medium
+  - id: packages.cache_small_limit
+    diagnosis: packages
+    when: {fact: package_cache_mb, above: package_cache_max_mb}
+    summary: 'Cache {{.Value}} MB above {{.Limit}} MB'
+`)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	fixture := machine.NewFixture()
+	fixture.AddFile("/proc/mounts", "/dev/sda1 / ext4 rw 0 0\n/dev/sdb1 /srv ext4 ro,relatime 0 0\n")
+	p := probes.New(fixture.Machine())
+
+	rule := set.ForDiagnosis("filesystem")[0]
+	found, offered, err := rule.Evaluate(p, config.DefaultThresholds())
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(found) != 1 || found[0].Summary != "/srv is read-only (1 of 1)" || found[0].FixIDs[0] != "remount_srv" {
+		t.Errorf("findings = %+v", found)
+	}
+	if len(offered) != 1 || offered[0].Commands[0] != "mount -o remount,rw /srv" || !offered[0].Reversible {
+		t.Errorf("fixes = %+v", offered)
+	}
+
+	// A fact that cannot be gathered never matches
+	found, _, err = set.ForDiagnosis("packages")[0].Evaluate(p, config.DefaultThresholds())
+	if err != nil || len(found) != 0 {
+		t.Errorf("Evaluate() = %v, %v; want no findings", found, err)
+	}
+}
+
+func TestMatchThreshold(t *testing.T) {
+	set, err := loadPacks(t, "rules:\n  - id: logs.journal_small\n    diagnosis: logs\n    summary: x\n    when: {fact: journal_size_mb, above: journal_max_mb}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := machine.NewFixture()
+	fixture.AddCommand("Archived and active journals take up 200.0M in the file system.\n", 0, "journalctl", "--disk-usage")
+	p := probes.New(fixture.Machine())
+	rule := set.ForDiagnosis("logs")[1]
+
+	thresholds := config.DefaultThresholds()
+	if ok, data := rule.Match(p, thresholds); ok || data.Limit != thresholds.JournalMaxMB {
+		t.Errorf("Match() = %v, %+v; want no match under the default limit", ok, data)
+	}
+	thresholds.JournalMaxMB = 100
+	if ok, data := rule.Match(p, thresholds); !ok || data.Value != 200 {
+		t.Errorf("Match() = %v, %+v; want a match above 100 MB", ok, data)
+	}
+}
+
+func TestCheckQuoted(t *testing.T) {
+	for _, text := range []string{
+		"systemctl restart {{quote .Items}}",
+		"systemctl restart {{.Item | quote}}",
+		"echo {{quote (printf \"%s.service\" .Item)}} {{.Params.x}} {{.Count}}",
+	} {
+		tmpl, err := parseTemplate("test", text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkQuoted(tmpl); err != nil {
+			t.Errorf("checkQuoted(%s) error = %v", text, err)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{"nginx.service", "nginx.service"},
+		{"it's here", `'it'\''s here'`},
+		{"", "''"},
+		{[]string{"a b", "c"}, "'a b' c"},
+		{42, "42"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
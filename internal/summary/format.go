@@ -0,0 +1,80 @@
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported report formats
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatHTML       = "html"
+	FormatMarkdown   = "markdown"
+	FormatJUnit      = "junit"
+	FormatPrometheus = "prometheus"
+)
+
+// Formats lists every report format accepted by Render
+func Formats() []string {
+	return []string{FormatText, FormatJSON, FormatYAML, FormatHTML, FormatMarkdown, FormatJUnit, FormatPrometheus}
+}
+
+// FileExtension returns the file name extension for reports in format
+func FileExtension(format string) string {
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return "txt"
+	case FormatMarkdown:
+		return "md"
+	case FormatJUnit:
+		return "xml"
+	case FormatPrometheus:
+		return "prom"
+	}
+	return strings.ToLower(format)
+}
+
+// Render produces the report in the requested format
+func (s *SystemSummary) Render(format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return []byte(s.FormatReport()), nil
+	case FormatJSON:
+		return s.FormatJSON()
+	case FormatYAML, "yml":
+		return s.FormatYAML()
+	case FormatHTML, "htm":
+		return s.FormatHTML()
+	case FormatMarkdown, "md":
+		return []byte(s.FormatMarkdown()), nil
+	case FormatJUnit:
+		return s.FormatJUnit()
+	case FormatPrometheus, "prom":
+		return s.FormatPrometheus(), nil
+	}
+	return nil, fmt.Errorf("unsupported report format %q (supported: %s)",
+		format, strings.Join(Formats(), ", "))
+}
+
+// FormatJSON generates a machine-readable JSON report
+func (s *SystemSummary) FormatJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// FormatYAML generates a machine-readable YAML report
+func (s *SystemSummary) FormatYAML() ([]byte, error) {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode YAML report: %w", err)
+	}
+	return data, nil
+}
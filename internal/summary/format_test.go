@@ -0,0 +1,97 @@
+package summary
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// ciSummary has one check of every outcome a pipeline has to grade
+func ciSummary() *SystemSummary {
+	s := testSummary()
+	s.CheckResults.AddResult(checks.CheckResult{Name: "Disk", Severity: checks.SeverityCritical, Message: "Root | full", Details: []string{"/: 99%"}})
+	s.CheckResults.AddResult(checks.CheckResult{Name: "Logs", Severity: checks.SeverityWarning, Status: checks.StatusTimedOut, Message: "Timed out after 1m0s"})
+	s.CheckResults.AddResult(checks.CheckResult{Name: "Services", Status: checks.StatusSkipped, Message: "Skipped: requires root"})
+	return s
+}
+
+func TestFormatJUnit(t *testing.T) {
+	tests := []struct {
+		name         string
+		warningsFail bool
+		failures     int
+	}{
+		{"warnings pass", false, 1},
+		{"warnings fail", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ciSummary()
+			s.settings = config.ReportSettings{WarningsFail: tt.warningsFail}
+			data, err := s.Render(FormatJUnit)
+			if err != nil {
+				t.Fatalf("Render(junit) failed: %v", err)
+			}
+
+			var report junitSuites
+			if err := xml.Unmarshal(data, &report); err != nil {
+				t.Fatalf("Report is not valid XML: %v\n%s", err, data)
+			}
+			if report.Tests != 5 || report.Failures != tt.failures || report.Errors != 1 || report.Skipped != 1 {
+				t.Errorf("Counts = tests %d, failures %d, errors %d, skipped %d",
+					report.Tests, report.Failures, report.Errors, report.Skipped)
+			}
+
+			cases := map[string]junitCase{}
+			for _, testcase := range report.Suites[0].Cases {
+				cases[testcase.Name] = testcase
+			}
+			if disk := cases["Disk"]; disk.Failure == nil || disk.Failure.Type != "critical" || disk.Failure.Text != "/: 99%" {
+				t.Errorf("Critical check should fail with its details, got %+v", disk.Failure)
+			}
+			if cases["Memory"].Failure != nil {
+				t.Error("Healthy check reported as failure")
+			}
+			if (cases["Package System"].Failure != nil) != tt.warningsFail {
+				t.Errorf("Warning failure = %v, want %v", cases["Package System"].Failure != nil, tt.warningsFail)
+			}
+			if cases["Logs"].Error == nil || cases["Services"].Skipped == nil {
+				t.Error("Timed-out and skipped checks were not reported as such")
+			}
+		})
+	}
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	report := ciSummary().FormatMarkdown()
+
+	for _, want := range []string{
+		"## Debian Doctor report: web01",
+		"**Health score: 65/100 (FAIR)**",
+		"| ❌ critical | Disk | Root \\| full |",
+		"| ⏱️ timed out | Logs | Timed out after 1m0s |",
+		"| ⏭️ skipped | Services | Skipped: requires root |",
+		"<details><summary>Package System (1)</summary>",
+		"- **warning** Package cache uses 2048.0 MB &lt;script&gt;",
+		"| Disk / | 97.0% (CRITICAL) |",
+		"- **Clean Package Cache** (Low risk)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Markdown report is missing %q\n%s", want, report)
+		}
+	}
+}
+
+func TestFileExtension(t *testing.T) {
+	for format, want := range map[string]string{
+		FormatText: "txt", FormatJSON: "json", FormatHTML: "html", FormatMarkdown: "md", FormatJUnit: "xml", FormatPrometheus: "prom",
+	} {
+		if got := FileExtension(format); got != want {
+			t.Errorf("FileExtension(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
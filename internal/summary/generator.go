@@ -33,69 +33,73 @@ func NewGenerator(cfg *config.Config) *Generator {
 
 // SystemSummary holds comprehensive system information
 type SystemSummary struct {
-	Timestamp       time.Time
-	Duration        time.Duration
-	SystemInfo      SystemInfo
-	ResourceStatus  ResourceStatus
-	NetworkStatus   NetworkStatus
-	CheckResults    checks.Results
-	HealthScore     int
-	Recommendations []string
-	CriticalIssues  []string
-	Warnings        []string
+	Timestamp       time.Time      `json:"timestamp" yaml:"timestamp"`
+	Duration        time.Duration  `json:"duration" yaml:"duration"`
+	SystemInfo      SystemInfo     `json:"system_info" yaml:"system_info"`
+	ResourceStatus  ResourceStatus `json:"resource_status" yaml:"resource_status"`
+	NetworkStatus   NetworkStatus  `json:"network_status" yaml:"network_status"`
+	CheckResults    checks.Results `json:"check_results" yaml:"check_results"`
+	HealthScore     int            `json:"health_score" yaml:"health_score"`
+	Recommendations []string       `json:"recommendations" yaml:"recommendations"`
+	CriticalIssues  []string       `json:"critical_issues" yaml:"critical_issues"`
+	Warnings        []string       `json:"warnings" yaml:"warnings"`
+	Skipped         []string       `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	
+	thresholds config.Thresholds     // Limits used to label resource usage
+	settings   config.ReportSettings // How check results are graded
 }
 
 // SystemInfo contains basic system information
 type SystemInfo struct {
-	Hostname       string
-	OS             string
-	Kernel         string
-	Architecture   string
-	CPUModel       string
-	CPUCores       int
-	TotalMemory    uint64
-	Uptime         time.Duration
-	BootTime       time.Time
-	Virtualization string
+	Hostname       string        `json:"hostname" yaml:"hostname"`
+	OS             string        `json:"os" yaml:"os"`
+	Kernel         string        `json:"kernel" yaml:"kernel"`
+	Architecture   string        `json:"architecture" yaml:"architecture"`
+	CPUModel       string        `json:"cpu_model" yaml:"cpu_model"`
+	CPUCores       int           `json:"cpu_cores" yaml:"cpu_cores"`
+	TotalMemory    uint64        `json:"total_memory" yaml:"total_memory"`
+	Uptime         time.Duration `json:"uptime" yaml:"uptime"`
+	BootTime       time.Time     `json:"boot_time" yaml:"boot_time"`
+	Virtualization string        `json:"virtualization" yaml:"virtualization"`
 }
 
 // ResourceStatus contains resource usage information
 type ResourceStatus struct {
-	CPUUsage       float64
-	MemoryUsed     uint64
-	MemoryPercent  float64
-	SwapUsed       uint64
-	SwapPercent    float64
-	DiskUsage      []DiskInfo
-	LoadAverage    [3]float64
-	ProcessCount   int
+	CPUUsage      float64    `json:"cpu_usage" yaml:"cpu_usage"`
+	MemoryUsed    uint64     `json:"memory_used" yaml:"memory_used"`
+	MemoryPercent float64    `json:"memory_percent" yaml:"memory_percent"`
+	SwapUsed      uint64     `json:"swap_used" yaml:"swap_used"`
+	SwapPercent   float64    `json:"swap_percent" yaml:"swap_percent"`
+	DiskUsage     []DiskInfo `json:"disk_usage" yaml:"disk_usage"`
+	LoadAverage   [3]float64 `json:"load_average" yaml:"load_average"`
+	ProcessCount  int        `json:"process_count" yaml:"process_count"`
 }
 
 // DiskInfo contains disk usage details
 type DiskInfo struct {
-	Path        string
-	Device      string
-	Filesystem  string
-	Total       uint64
-	Used        uint64
-	Free        uint64
-	UsedPercent float64
+	Path        string  `json:"path" yaml:"path"`
+	Device      string  `json:"device" yaml:"device"`
+	Filesystem  string  `json:"filesystem" yaml:"filesystem"`
+	Total       uint64  `json:"total" yaml:"total"`
+	Used        uint64  `json:"used" yaml:"used"`
+	Free        uint64  `json:"free" yaml:"free"`
+	UsedPercent float64 `json:"used_percent" yaml:"used_percent"`
 }
 
 // NetworkStatus contains network information
 type NetworkStatus struct {
-	Interfaces []NetworkInterface
-	DNSServers []string
-	Gateway    string
-	Hostname   string
+	Interfaces []NetworkInterface `json:"interfaces" yaml:"interfaces"`
+	DNSServers []string           `json:"dns_servers" yaml:"dns_servers"`
+	Gateway    string             `json:"gateway" yaml:"gateway"`
+	Hostname   string             `json:"hostname" yaml:"hostname"`
 }
 
 // NetworkInterface contains network interface details
 type NetworkInterface struct {
-	Name      string
-	Addresses []string
-	Status    string
-	MTU       int
+	Name      string   `json:"name" yaml:"name"`
+	Addresses []string `json:"addresses" yaml:"addresses"`
+	Status    string   `json:"status" yaml:"status"`
+	MTU       int      `json:"mtu" yaml:"mtu"`
 }
 
 // Generate creates a comprehensive system summary
@@ -106,6 +110,8 @@ func (g *Generator) Generate(results checks.Results) (*SystemSummary, error) {
 		Timestamp:    g.startTime,
 		Duration:     g.endTime.Sub(g.startTime),
 		CheckResults: results,
+		thresholds:   g.config.Thresholds,
+		settings:     g.config.Report,
 	}
 	
 	// Gather system information
@@ -132,6 +138,7 @@ func (g *Generator) Generate(results checks.Results) (*SystemSummary, error) {
 	// Extract critical issues and warnings
 	summary.CriticalIssues = results.GetErrors()
 	summary.Warnings = results.GetWarnings()
+	summary.Skipped = results.GetSkipped()
 	
 	return summary, nil
 }
@@ -267,6 +274,7 @@ func (g *Generator) gatherNetworkStatus(summary *SystemSummary) error {
 }
 
 func (g *Generator) calculateHealthScore(summary *SystemSummary) {
+	limits := g.config.Thresholds
 	score := 100
 	
 	// Deduct for critical issues
@@ -278,21 +286,21 @@ func (g *Generator) calculateHealthScore(summary *SystemSummary) {
 	score -= warningCount * 5
 	
 	// Deduct for high resource usage
-	if summary.ResourceStatus.CPUUsage > 80 {
+	if summary.ResourceStatus.CPUUsage > limits.CPUWarningPercent {
 		score -= 10
 	}
-	if summary.ResourceStatus.MemoryPercent > 90 {
+	if summary.ResourceStatus.MemoryPercent > limits.MemoryCriticalPercent {
 		score -= 10
 	}
-	if summary.ResourceStatus.SwapPercent > 50 {
+	if summary.ResourceStatus.SwapPercent > limits.SwapWarningPercent {
 		score -= 5
 	}
 	
 	// Check disk usage
 	for _, disk := range summary.ResourceStatus.DiskUsage {
-		if disk.UsedPercent > 90 {
+		if disk.UsedPercent > float64(limits.DiskCriticalPercent) {
 			score -= 10
-		} else if disk.UsedPercent > 80 {
+		} else if disk.UsedPercent > float64(limits.DiskWarningPercent) {
 			score -= 5
 		}
 	}
@@ -309,36 +317,37 @@ func (g *Generator) calculateHealthScore(summary *SystemSummary) {
 }
 
 func (g *Generator) generateRecommendations(summary *SystemSummary) {
+	limits := g.config.Thresholds
 	recommendations := []string{}
 	
 	// CPU recommendations
-	if summary.ResourceStatus.CPUUsage > 80 {
+	if summary.ResourceStatus.CPUUsage > limits.CPUWarningPercent {
 		recommendations = append(recommendations, 
 			"High CPU usage detected. Consider identifying resource-intensive processes.")
 	}
 	
 	// Memory recommendations
-	if summary.ResourceStatus.MemoryPercent > 90 {
+	if summary.ResourceStatus.MemoryPercent > limits.MemoryCriticalPercent {
 		recommendations = append(recommendations,
 			"Memory usage is critical. Consider closing unused applications or adding more RAM.")
-	} else if summary.ResourceStatus.MemoryPercent > 80 {
+	} else if summary.ResourceStatus.MemoryPercent > limits.MemoryWarningPercent {
 		recommendations = append(recommendations,
 			"Memory usage is high. Monitor for memory leaks.")
 	}
 	
 	// Swap recommendations
-	if summary.ResourceStatus.SwapPercent > 50 {
+	if summary.ResourceStatus.SwapPercent > limits.SwapWarningPercent {
 		recommendations = append(recommendations,
 			"High swap usage indicates memory pressure. Consider adding more RAM.")
 	}
 	
 	// Disk recommendations
 	for _, disk := range summary.ResourceStatus.DiskUsage {
-		if disk.UsedPercent > 90 {
+		if disk.UsedPercent > float64(limits.DiskCriticalPercent) {
 			recommendations = append(recommendations,
 				fmt.Sprintf("Critical disk space on %s (%.1f%% used). Clean up immediately.", 
 					disk.Path, disk.UsedPercent))
-		} else if disk.UsedPercent > 80 {
+		} else if disk.UsedPercent > float64(limits.DiskWarningPercent) {
 			recommendations = append(recommendations,
 				fmt.Sprintf("Low disk space on %s (%.1f%% used). Consider cleanup.", 
 					disk.Path, disk.UsedPercent))
@@ -368,6 +377,7 @@ func (g *Generator) generateRecommendations(summary *SystemSummary) {
 // FormatReport generates a human-readable report
 func (s *SystemSummary) FormatReport() string {
 	var b strings.Builder
+	limits := s.limits()
 	
 	b.WriteString("\n=====================================\n")
 	b.WriteString("     COMPREHENSIVE SYSTEM REPORT    \n")
@@ -425,12 +435,7 @@ func (s *SystemSummary) FormatReport() string {
 	if len(s.ResourceStatus.DiskUsage) > 0 {
 		b.WriteString("DISK USAGE\n")
 		for _, disk := range s.ResourceStatus.DiskUsage {
-			status := "OK"
-			if disk.UsedPercent > 90 {
-				status = "CRITICAL"
-			} else if disk.UsedPercent > 80 {
-				status = "WARNING"
-			}
+			status := diskStatus(disk, limits)
 			b.WriteString(fmt.Sprintf("  %s (%s)\n", disk.Path, disk.Filesystem))
 			b.WriteString(fmt.Sprintf("    %.2f GB / %.2f GB (%.1f%%) - %s\n",
 				float64(disk.Used)/(1024*1024*1024),
@@ -482,6 +487,15 @@ func (s *SystemSummary) FormatReport() string {
 		b.WriteString("\n")
 	}
 	
+	// Checks that did not run
+	if len(s.Skipped) > 0 {
+		b.WriteString("SKIPPED CHECKS\n")
+		for _, skipped := range s.Skipped {
+			b.WriteString(fmt.Sprintf("  - %s\n", skipped))
+		}
+		b.WriteString("\n")
+	}
+	
 	// Recommendations
 	if len(s.Recommendations) > 0 {
 		b.WriteString("RECOMMENDATIONS\n")
@@ -500,6 +514,25 @@ func (s *SystemSummary) FormatReport() string {
 
 // Helper functions
 
+// limits returns the thresholds the summary was generated with. Summaries
+// read back from a report carry none, so the defaults are used instead
+func (s *SystemSummary) limits() config.Thresholds {
+	if s.thresholds == (config.Thresholds{}) {
+		return config.DefaultThresholds()
+	}
+	return s.thresholds
+}
+
+// diskStatus labels a disk's usage as OK, WARNING or CRITICAL
+func diskStatus(disk DiskInfo, limits config.Thresholds) string {
+	if disk.UsedPercent > float64(limits.DiskCriticalPercent) {
+		return "CRITICAL"
+	} else if disk.UsedPercent > float64(limits.DiskWarningPercent) {
+		return "WARNING"
+	}
+	return "OK"
+}
+
 func getHealthBar(score int) string {
 	filled := score / 10
 	bar := "["
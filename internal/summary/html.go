@@ -0,0 +1,250 @@
+package summary
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+)
+
+// gaugeCircumference is the length of the health gauge's circle (r = 54)
+var gaugeCircumference = 2 * math.Pi * 54
+
+// FormatHTML generates a self-contained HTML report. Styles are inlined and
+// sections collapse with <details>, so the file needs no scripts or assets
+func (s *SystemSummary) FormatHTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, s); err != nil {
+		return nil, fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RecommendedFixes returns the known fixes referenced by the findings of the
+// report's checks, in the order they were first referenced
+func (s *SystemSummary) RecommendedFixes() []*fixes.Fix {
+	known := fixes.GetCommonFixes()
+	seen := make(map[string]bool)
+	var recommended []*fixes.Fix
+	for _, result := range s.CheckResults.GetAllChecks() {
+		for _, f := range result.Findings {
+			for _, id := range f.FixIDs {
+				fix, ok := known[id]
+				if !ok || seen[id] {
+					continue
+				}
+				seen[id] = true
+				recommended = append(recommended, fix)
+			}
+		}
+	}
+	return recommended
+}
+
+// severityClass is the CSS class a severity's badge is drawn with
+func severityClass(severity checks.Severity) string {
+	switch {
+	case severity >= checks.SeverityError:
+		return "critical"
+	case severity == checks.SeverityWarning:
+		return "warning"
+	}
+	return "ok"
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"gb": func(bytes uint64) string {
+		return fmt.Sprintf("%.2f GB", float64(bytes)/(1024*1024*1024))
+	},
+	"healthStatus": getHealthStatus,
+	"healthClass": func(score int) string {
+		switch {
+		case score >= 75:
+			return "ok"
+		case score >= 40:
+			return "warning"
+		}
+		return "critical"
+	},
+	"gaugeDash": func(score int) string {
+		filled := gaugeCircumference * float64(score) / 100
+		return fmt.Sprintf("%.1f %.1f", filled, gaugeCircumference)
+	},
+	"uptime":    formatDuration,
+	"timestamp": func(s *SystemSummary) string { return s.Timestamp.Format("2006-01-02 15:04:05") },
+	"diskStatus": func(s *SystemSummary, disk DiskInfo) string {
+		return diskStatus(disk, s.limits())
+	},
+	"lower": strings.ToLower,
+	"checkState": func(result checks.CheckResult) string {
+		if result.Status != "" {
+			return string(result.Status)
+		}
+		return result.Severity.String()
+	},
+	"checkClass": func(result checks.CheckResult) string {
+		if result.Status == checks.StatusSkipped || result.Status == checks.StatusCancelled {
+			return "skipped"
+		}
+		return severityClass(result.Severity)
+	},
+	"severityClass": severityClass,
+	"expand": func(result checks.CheckResult) bool {
+		return result.Severity >= checks.SeverityWarning
+	},
+	"riskClass": func(risk fixes.RiskLevel) string {
+		return "risk-" + strings.ToLower(risk.String())
+	},
+}).Parse(htmlTemplate))
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Debian Doctor report: {{.SystemInfo.Hostname}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0 auto; max-width: 960px; padding: 1.5rem; color: #1f2933; background: #f5f7fa; }
+h1 { margin-bottom: 0.2rem; }
+.meta { color: #616e7c; margin-top: 0; }
+section, details { background: #fff; border: 1px solid #d9e2ec; border-radius: 6px; margin: 1rem 0; padding: 0.8rem 1rem; }
+details > summary { cursor: pointer; font-weight: 600; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.3rem 0.5rem; border-bottom: 1px solid #e4e7eb; vertical-align: top; }
+th { color: #52606d; font-weight: 600; }
+.health { display: flex; align-items: center; gap: 1.5rem; }
+.gauge circle { fill: none; stroke-width: 12; }
+.gauge .track { stroke: #e4e7eb; }
+.gauge text { font-size: 28px; font-weight: 700; fill: #1f2933; }
+.badge { display: inline-block; border-radius: 4px; padding: 0.05rem 0.45rem; font-size: 0.8rem; font-weight: 600; color: #fff; margin-right: 0.4rem; text-transform: uppercase; }
+.ok { stroke: #2f9e44; background: #2f9e44; }
+.warning { stroke: #e67700; background: #e67700; }
+.critical { stroke: #c92a2a; background: #c92a2a; }
+.skipped { background: #868e96; }
+.risk-low { background: #2f9e44; }
+.risk-medium { background: #e67700; }
+.risk-high { background: #c92a2a; }
+.risk-critical { background: #862e9c; }
+pre { background: #f0f4f8; padding: 0.5rem; overflow-x: auto; white-space: pre-wrap; }
+ul { padding-left: 1.2rem; }
+</style>
+</head>
+<body>
+<h1>Debian Doctor report</h1>
+<p class="meta">{{.SystemInfo.Hostname}} &middot; generated {{timestamp .}} &middot; scan took {{.Duration}}</p>
+
+<section class="health">
+<svg class="gauge" width="140" height="140" viewBox="0 0 140 140" role="img" aria-label="Health score {{.HealthScore}} of 100">
+<circle class="track" cx="70" cy="70" r="54"/>
+<circle class="{{healthClass .HealthScore}}" cx="70" cy="70" r="54" stroke-dasharray="{{gaugeDash .HealthScore}}" transform="rotate(-90 70 70)"/>
+<text x="70" y="80" text-anchor="middle">{{.HealthScore}}</text>
+</svg>
+<div>
+<h2>System health: {{healthStatus .HealthScore}}</h2>
+<p>{{len .CriticalIssues}} critical issues, {{len .Warnings}} warnings{{with .Skipped}}, {{len .}} checks skipped{{end}}</p>
+</div>
+</section>
+
+<details open>
+<summary>System information</summary>
+<table>
+<tr><th>Hostname</th><td>{{.SystemInfo.Hostname}}</td></tr>
+<tr><th>OS</th><td>{{.SystemInfo.OS}}</td></tr>
+<tr><th>Kernel</th><td>{{.SystemInfo.Kernel}}</td></tr>
+<tr><th>Architecture</th><td>{{.SystemInfo.Architecture}}</td></tr>
+<tr><th>CPU</th><td>{{.SystemInfo.CPUModel}} ({{.SystemInfo.CPUCores}} cores)</td></tr>
+<tr><th>Memory</th><td>{{gb .SystemInfo.TotalMemory}}</td></tr>
+<tr><th>Uptime</th><td>{{uptime .SystemInfo.Uptime}}</td></tr>
+{{- if ne .SystemInfo.Virtualization "none"}}
+<tr><th>Virtualization</th><td>{{.SystemInfo.Virtualization}}</td></tr>
+{{- end}}
+</table>
+</details>
+
+<details open>
+<summary>Resource usage</summary>
+<table>
+<tr><th>CPU</th><td>{{printf "%.1f%%" .ResourceStatus.CPUUsage}}</td></tr>
+<tr><th>Memory</th><td>{{gb .ResourceStatus.MemoryUsed}} of {{gb .SystemInfo.TotalMemory}} ({{printf "%.1f%%" .ResourceStatus.MemoryPercent}})</td></tr>
+<tr><th>Swap</th><td>{{gb .ResourceStatus.SwapUsed}} ({{printf "%.1f%%" .ResourceStatus.SwapPercent}})</td></tr>
+<tr><th>Load average</th><td>{{range $i, $load := .ResourceStatus.LoadAverage}}{{if $i}}, {{end}}{{printf "%.2f" $load}}{{end}}</td></tr>
+</table>
+{{- with .ResourceStatus.DiskUsage}}
+<h3>Disks</h3>
+<table>
+<tr><th>Mount</th><th>Device</th><th>Filesystem</th><th>Used</th><th>Size</th><th>Status</th></tr>
+{{- range .}}
+{{- $status := diskStatus $ .}}
+<tr><td>{{.Path}}</td><td>{{.Device}}</td><td>{{.Filesystem}}</td><td>{{printf "%.1f%%" .UsedPercent}}</td><td>{{gb .Total}}</td><td><span class="badge {{lower $status}}">{{$status}}</span></td></tr>
+{{- end}}
+</table>
+{{- end}}
+</details>
+
+<details>
+<summary>Network</summary>
+<table>
+<tr><th>Interface</th><th>Status</th><th>MTU</th><th>Addresses</th></tr>
+{{- range .NetworkStatus.Interfaces}}
+<tr><td>{{.Name}}</td><td>{{.Status}}</td><td>{{.MTU}}</td><td>{{range $i, $addr := .Addresses}}{{if $i}}<br>{{end}}{{$addr}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- with .NetworkStatus.DNSServers}}
+<p>DNS servers: {{range $i, $server := .}}{{if $i}}, {{end}}{{$server}}{{end}}</p>
+{{- end}}
+</details>
+
+<h2>Checks</h2>
+{{- range .CheckResults.GetAllChecks}}
+<details{{if expand .}} open{{end}}>
+<summary><span class="badge {{checkClass .}}">{{checkState .}}</span>{{.Name}}: {{.Message}}</summary>
+{{- with .Details}}
+<ul>
+{{- range .}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Findings}}
+<table>
+<tr><th>Severity</th><th>Finding</th><th>Affects</th></tr>
+{{- range .}}
+<tr><td><span class="badge {{severityClass .Severity}}">{{.Severity}}</span></td><td>{{.Summary}}{{with .Excerpt}}<pre>{{.}}</pre>{{end}}</td><td>{{range $i, $r := .Resources}}{{if $i}}<br>{{end}}{{$r}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</details>
+{{- end}}
+{{- with .RecommendedFixes}}
+
+<h2>Recommended fixes</h2>
+{{- range .}}
+<details>
+<summary><span class="badge {{riskClass .RiskLevel}}">{{.RiskLevel}} risk</span>{{.Title}}</summary>
+<p>{{.Description}}</p>
+<pre>{{range .Commands}}{{.}}
+{{end}}</pre>
+{{- if .RequiresRoot}}
+<p>Requires root privileges.</p>
+{{- end}}
+</details>
+{{- end}}
+{{- end}}
+{{- with .Recommendations}}
+
+<section>
+<h2>Recommendations</h2>
+<ol>
+{{- range .}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+</section>
+{{- end}}
+</body>
+</html>
+`
@@ -0,0 +1,69 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+)
+
+func testSummary() *SystemSummary {
+	results := checks.NewResults()
+	results.AddResult(checks.CheckResult{
+		Name:     "Memory",
+		Severity: checks.SeverityInfo,
+		Message:  "Memory usage normal",
+		Details:  []string{"Memory: 40% used"},
+	})
+
+	packages := checks.CheckResult{Name: "Package System", Message: "Large package cache detected"}
+	packages.AddFindings(finding.New("packages.cache_large", finding.SeverityWarning, "Package cache uses 2048.0 MB <script>").
+		FixedBy("clean_package_cache").
+		FixedBy("not_a_known_fix"))
+	results.AddResult(packages)
+
+	return &SystemSummary{
+		SystemInfo:     SystemInfo{Hostname: "web01", Virtualization: "none"},
+		ResourceStatus: ResourceStatus{DiskUsage: []DiskInfo{{Path: "/", UsedPercent: 97}}},
+		CheckResults:   results,
+		HealthScore:    65,
+	}
+}
+
+func TestFormatHTML(t *testing.T) {
+	data, err := testSummary().Render(FormatHTML)
+	if err != nil {
+		t.Fatalf("Render(html) failed: %v", err)
+	}
+	report := string(data)
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"System health: FAIR",
+		`<details open>
+<summary><span class="badge warning">warning</span>Package System: Large package cache detected</summary>`,
+		`<summary><span class="badge ok">info</span>Memory: Memory usage normal</summary>`,
+		`<span class="badge critical">CRITICAL</span>`,
+		`<span class="badge risk-low">Low risk</span>Clean Package Cache`,
+		"2048.0 MB &lt;script&gt;",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("HTML report is missing %q", want)
+		}
+	}
+
+	// The report must open without network access or scripts
+	for _, unwanted := range []string{"<script", "<link", "src=", "http://", "https://"} {
+		if strings.Contains(report, unwanted) {
+			t.Errorf("HTML report is not self-contained, found %q", unwanted)
+		}
+	}
+}
+
+func TestRecommendedFixes(t *testing.T) {
+	recommended := testSummary().RecommendedFixes()
+	if len(recommended) != 1 || recommended[0].ID != "clean_package_cache" {
+		t.Errorf("Expected only the known clean_package_cache fix, got %v", recommended)
+	}
+}
@@ -0,0 +1,120 @@
+package summary
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+)
+
+// junitSuites is the root element of a JUnit XML report
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Hostname   string          `xml:"hostname,attr,omitempty"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	Skipped   *junitSkipped `xml:"skipped"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// FormatJUnit generates a JUnit XML report with one testcase per check.
+// Checks reporting an error or critical problem fail, checks that timed out
+// are errors, and warnings only fail when report.warnings_fail is set
+func (s *SystemSummary) FormatJUnit() ([]byte, error) {
+	suite := junitSuite{
+		Name:      "debian-doctor",
+		Hostname:  s.SystemInfo.Hostname,
+		Timestamp: s.Timestamp.Format("2006-01-02T15:04:05"),
+		Time:      fmt.Sprintf("%.3f", s.Duration.Seconds()),
+		Properties: []junitProperty{
+			{Name: "health_score", Value: fmt.Sprint(s.HealthScore)},
+			{Name: "os", Value: s.SystemInfo.OS},
+			{Name: "kernel", Value: s.SystemInfo.Kernel},
+		},
+	}
+
+	for _, result := range s.CheckResults.GetAllChecks() {
+		testcase := junitCase{
+			Name:      result.Name,
+			ClassName: "debian-doctor.checks",
+			SystemOut: strings.Join(result.Lines(), "\n"),
+		}
+		problem := &junitProblem{
+			Message: result.Message,
+			Type:    result.Severity.String(),
+			Text:    testcase.SystemOut,
+		}
+
+		switch {
+		case result.Status == checks.StatusSkipped || result.Status == checks.StatusCancelled:
+			testcase.Skipped = &junitSkipped{Message: result.Message}
+			suite.Skipped++
+		case result.Status == checks.StatusTimedOut:
+			problem.Type = string(result.Status)
+			testcase.Error = problem
+			suite.Errors++
+		case result.Severity >= checks.SeverityError,
+			result.Severity == checks.SeverityWarning && s.settings.WarningsFail:
+			testcase.Failure = problem
+			suite.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, testcase)
+		suite.Tests++
+	}
+
+	report := junitSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	return append(append([]byte(xml.Header), data...), '\n'), nil
+}
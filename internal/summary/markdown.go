@@ -0,0 +1,124 @@
+package summary
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+)
+
+// FormatMarkdown generates a Markdown report short enough to post as a merge
+// request comment. Details of healthy checks are left out
+func (s *SystemSummary) FormatMarkdown() string {
+	var b strings.Builder
+	limits := s.limits()
+
+	b.WriteString(fmt.Sprintf("## Debian Doctor report: %s\n\n", markdownText(s.SystemInfo.Hostname)))
+	b.WriteString(fmt.Sprintf("**Health score: %d/100 (%s)** · %d critical issues · %d warnings",
+		s.HealthScore, getHealthStatus(s.HealthScore), len(s.CriticalIssues), len(s.Warnings)))
+	if len(s.Skipped) > 0 {
+		b.WriteString(fmt.Sprintf(" · %d skipped", len(s.Skipped)))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Generated %s on %s (kernel %s) in %s\n\n",
+		s.Timestamp.Format("2006-01-02 15:04:05"), markdownText(s.SystemInfo.OS),
+		markdownText(s.SystemInfo.Kernel), s.Duration.Round(time.Second)))
+
+	// One row per check
+	b.WriteString("### Checks\n\n")
+	b.WriteString("| Status | Check | Result |\n")
+	b.WriteString("|--------|-------|--------|\n")
+	for _, result := range s.CheckResults.GetAllChecks() {
+		b.WriteString(fmt.Sprintf("| %s | %s | %s |\n",
+			markdownStatus(result), markdownCell(result.Name), markdownCell(result.Message)))
+	}
+	b.WriteString("\n")
+
+	// Findings of the checks that found something
+	var problems []checks.CheckResult
+	for _, result := range s.CheckResults.GetAllChecks() {
+		if result.Severity >= checks.SeverityWarning && len(result.Findings) > 0 {
+			problems = append(problems, result)
+		}
+	}
+	if len(problems) > 0 {
+		b.WriteString("### Findings\n\n")
+		for _, result := range problems {
+			b.WriteString(fmt.Sprintf("<details><summary>%s (%d)</summary>\n\n",
+				markdownText(result.Name), len(result.Findings)))
+			for _, f := range result.Findings {
+				b.WriteString(fmt.Sprintf("- **%s** %s\n", f.Severity, markdownText(f.Summary)))
+			}
+			b.WriteString("\n</details>\n\n")
+		}
+	}
+
+	// Resources
+	b.WriteString("### Resources\n\n")
+	b.WriteString("| Resource | Usage |\n")
+	b.WriteString("|----------|-------|\n")
+	b.WriteString(fmt.Sprintf("| CPU | %.1f%% |\n", s.ResourceStatus.CPUUsage))
+	b.WriteString(fmt.Sprintf("| Memory | %.1f%% |\n", s.ResourceStatus.MemoryPercent))
+	b.WriteString(fmt.Sprintf("| Swap | %.1f%% |\n", s.ResourceStatus.SwapPercent))
+	for _, disk := range s.ResourceStatus.DiskUsage {
+		b.WriteString(fmt.Sprintf("| Disk %s | %.1f%% (%s) |\n",
+			markdownCell(disk.Path), disk.UsedPercent, diskStatus(disk, limits)))
+	}
+	b.WriteString("\n")
+
+	// Fixes
+	if recommended := s.RecommendedFixes(); len(recommended) > 0 {
+		b.WriteString("### Recommended fixes\n\n")
+		for _, fix := range recommended {
+			b.WriteString(fmt.Sprintf("- **%s** (%s risk): %s\n", fix.Title, fix.RiskLevel, fix.Description))
+			b.WriteString("  ```sh\n")
+			for _, cmd := range fix.Commands {
+				b.WriteString(fmt.Sprintf("  %s\n", cmd))
+			}
+			b.WriteString("  ```\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.Recommendations) > 0 {
+		b.WriteString("### Recommendations\n\n")
+		for i, rec := range s.Recommendations {
+			b.WriteString(fmt.Sprintf("%d. %s\n", i+1, markdownText(rec)))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// markdownStatus is the status column of a check's row
+func markdownStatus(result checks.CheckResult) string {
+	switch {
+	case result.Status == checks.StatusTimedOut:
+		return "⏱️ " + string(result.Status)
+	case result.Status != "":
+		return "⏭️ " + string(result.Status)
+	case result.Severity >= checks.SeverityError:
+		return "❌ " + result.Severity.String()
+	case result.Severity == checks.SeverityWarning:
+		return "⚠️ warning"
+	}
+	return "✅ ok"
+}
+
+// markdownReplacer escapes characters that would be read as Markdown or HTML
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "<", "&lt;", ">", "&gt;",
+)
+
+// markdownText escapes check output for use in running text
+func markdownText(text string) string {
+	return markdownReplacer.Replace(text)
+}
+
+// markdownCell escapes text for a table cell, which must stay on one line
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(markdownText(text)), " ")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,272 @@
+package summary
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+// Plugin states of the Nagios plugin API, which Icinga shares
+const (
+	NagiosOK       = 0
+	NagiosWarning  = 1
+	NagiosCritical = 2
+	NagiosUnknown  = 3
+)
+
+var nagiosStateNames = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// nagiosRank orders plugin states from best to worst. A check that could
+// not finish says less than one that found a problem
+var nagiosRank = map[int]int{NagiosOK: 0, NagiosUnknown: 1, NagiosWarning: 2, NagiosCritical: 3}
+
+// worseState returns the worse of two plugin states
+func worseState(a, b int) int {
+	if nagiosRank[b] > nagiosRank[a] {
+		return b
+	}
+	return a
+}
+
+// NagiosLimits are warning and critical levels given for one invocation,
+// keyed by performance data label. A value above its level raises the
+// plugin state
+type NagiosLimits struct {
+	Warning  map[string]float64
+	Critical map[string]float64
+}
+
+// nagiosPerf describes one performance data label. Labels the checks grade
+// themselves are backed by thresholds, so a limit given for them is also
+// applied to the check
+type nagiosPerf struct {
+	label   string
+	uom     string
+	max     float64 // Zero when there is no upper bound
+	metric  string  // Check measurement the value comes from, if any
+	warnKey string  // Config key of the backing warning threshold
+	critKey string  // Config key of the backing critical threshold
+	warn    func(config.Thresholds) float64
+	crit    func(config.Thresholds) float64
+}
+
+var nagiosPerfData = []nagiosPerf{
+	{label: "health_score", max: 100},
+	{label: "disk_used_pct", uom: "%", max: 100,
+		warnKey: "thresholds.disk_warning_percent", warn: func(t config.Thresholds) float64 { return float64(t.DiskWarningPercent) },
+		critKey: "thresholds.disk_critical_percent", crit: func(t config.Thresholds) float64 { return float64(t.DiskCriticalPercent) }},
+	{label: "mem_used_pct", uom: "%", max: 100,
+		warnKey: "thresholds.memory_warning_percent", warn: func(t config.Thresholds) float64 { return t.MemoryWarningPercent },
+		critKey: "thresholds.memory_critical_percent", crit: func(t config.Thresholds) float64 { return t.MemoryCriticalPercent }},
+	{label: "swap_used_pct", uom: "%", max: 100,
+		warnKey: "thresholds.swap_warning_percent", warn: func(t config.Thresholds) float64 { return t.SwapWarningPercent }},
+	{label: "load1"},
+	{label: "upgradeable", metric: "upgradable_packages"},
+	{label: "broken_packages", metric: "broken_packages"},
+	{label: "held_packages", metric: "held_packages"},
+	{label: "failed_units", metric: "failed_units"},
+	{label: "journal_mb", uom: "MB", metric: "journal_size_mb",
+		warnKey: "thresholds.journal_max_mb", warn: func(t config.Thresholds) float64 { return t.JournalMaxMB }},
+	{label: "package_cache_mb", uom: "MB", metric: "package_cache_mb",
+		warnKey: "thresholds.package_cache_max_mb", warn: func(t config.Thresholds) float64 { return t.PackageCacheMaxMB }},
+	{label: "core_dumps", metric: "core_dumps"},
+}
+
+// NagiosLabels lists the performance data labels limits can be given for
+func NagiosLabels() []string {
+	var labels []string
+	for _, perf := range nagiosPerfData {
+		if perf.label != "health_score" {
+			labels = append(labels, perf.label)
+		}
+	}
+	return labels
+}
+
+// NagiosThresholdKey returns the config key of the threshold behind a
+// label's warning or critical level, or "" when only the plugin output
+// grades the label. Unknown labels are an error
+func NagiosThresholdKey(label string, critical bool) (string, error) {
+	for _, perf := range nagiosPerfData {
+		if perf.label == label && label != "health_score" {
+			if critical {
+				return perf.critKey, nil
+			}
+			return perf.warnKey, nil
+		}
+	}
+	return "", fmt.Errorf("no performance data labelled %q (supported: %s)", label, strings.Join(NagiosLabels(), ", "))
+}
+
+// NagiosState maps a check result to a plugin state. Errors and critical
+// problems are both CRITICAL; a check that timed out is UNKNOWN
+func NagiosState(result checks.CheckResult) int {
+	switch {
+	case result.Status == checks.StatusTimedOut:
+		return NagiosUnknown
+	case result.Status != "":
+		return NagiosOK
+	case result.Severity >= checks.SeverityError:
+		return NagiosCritical
+	case result.Severity == checks.SeverityWarning:
+		return NagiosWarning
+	}
+	return NagiosOK
+}
+
+// FormatNagios renders the report as monitoring plugin output: a status
+// line with performance data, followed by each check's details when long
+// is set. It returns the plugin state to exit with
+func (s *SystemSummary) FormatNagios(limits NagiosLimits, long bool) ([]byte, int) {
+	all := s.CheckResults.GetAllChecks()
+
+	state := NagiosOK
+	ran := 0
+	for _, result := range all {
+		if result.Status != checks.StatusSkipped && result.Status != checks.StatusCancelled {
+			ran++
+		}
+		state = worseState(state, NagiosState(result))
+	}
+
+	perfData, breached := s.nagiosPerfData(limits)
+	for _, perfState := range breached {
+		state = worseState(state, perfState)
+	}
+	if ran == 0 {
+		state = NagiosUnknown
+	}
+
+	// The status line names the problems behind the state, worst first
+	var problems []string
+	for _, result := range all {
+		if NagiosState(result) == state && state != NagiosOK {
+			problems = append(problems, result.Message)
+		}
+	}
+	labels := make([]string, 0, len(breached))
+	for label, perfState := range breached {
+		if perfState == state {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		problems = append(problems, label+" above "+strings.ToLower(nagiosStateNames[state])+" level")
+	}
+
+	text := fmt.Sprintf("%d checks OK, health score %d", ran, s.HealthScore)
+	switch {
+	case ran == 0:
+		text = "No check ran"
+	case len(problems) > 0:
+		text = strings.Join(problems, "; ")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "DEBIAN-DOCTOR %s - %s | %s\n", nagiosStateNames[state], nagiosText(text), strings.Join(perfData, " "))
+	if long {
+		for _, result := range all {
+			fmt.Fprintf(&b, "[%s] %s: %s\n", nagiosStateNames[NagiosState(result)], result.Name, nagiosText(result.Message))
+			for _, line := range result.Lines() {
+				fmt.Fprintf(&b, "  %s\n", nagiosText(line))
+			}
+		}
+	}
+	return []byte(b.String()), state
+}
+
+// nagiosPerfData renders the performance data and returns the plugin state
+// of every label whose value is above a level given in limits
+func (s *SystemSummary) nagiosPerfData(limits NagiosLimits) ([]string, map[string]int) {
+	measurements, _ := checkMeasurements(s.CheckResults.GetAllChecks())
+	thresholds := s.limits()
+	breached := make(map[string]int)
+
+	var perfData []string
+	for _, perf := range nagiosPerfData {
+		value, ok := s.nagiosValue(perf, measurements)
+		if !ok {
+			continue
+		}
+
+		warn, hasWarn := limits.Warning[perf.label]
+		if !hasWarn && perf.warn != nil {
+			warn, hasWarn = perf.warn(thresholds), true
+		}
+		crit, hasCrit := limits.Critical[perf.label]
+		if !hasCrit && perf.crit != nil {
+			crit, hasCrit = perf.crit(thresholds), true
+		}
+
+		// The printed levels always decide, even when a check grades the same
+		// resource: the disk check only looks at /, and the memory check may
+		// not have run
+		if hasCrit && value > crit {
+			breached[perf.label] = NagiosCritical
+		} else if hasWarn && value > warn {
+			breached[perf.label] = NagiosWarning
+		}
+
+		fields := []string{formatPerfValue(value) + perf.uom, "", "", "0", ""}
+		if hasWarn {
+			fields[1] = formatPerfValue(warn)
+		}
+		if hasCrit {
+			fields[2] = formatPerfValue(crit)
+		}
+		if perf.max > 0 {
+			fields[4] = formatPerfValue(perf.max)
+		}
+		perfData = append(perfData, perf.label+"="+strings.TrimRight(strings.Join(fields, ";"), ";"))
+	}
+	return perfData, breached
+}
+
+// nagiosValue returns a label's value, or false when the run did not
+// measure it
+func (s *SystemSummary) nagiosValue(perf nagiosPerf, measurements map[string]float64) (float64, bool) {
+	if perf.metric != "" {
+		value, ok := measurements[perf.metric]
+		return value, ok
+	}
+	r := s.ResourceStatus
+	switch perf.label {
+	case "health_score":
+		return float64(s.HealthScore), true
+	case "disk_used_pct":
+		if len(r.DiskUsage) == 0 {
+			return 0, false
+		}
+		fullest := 0.0
+		for _, disk := range r.DiskUsage {
+			if disk.UsedPercent > fullest {
+				fullest = disk.UsedPercent
+			}
+		}
+		return fullest, true
+	case "mem_used_pct":
+		return r.MemoryPercent, true
+	case "swap_used_pct":
+		return r.SwapPercent, true
+	case "load1":
+		return r.LoadAverage[0], true
+	}
+	return 0, false
+}
+
+// formatPerfValue renders a value with at most two decimals
+func formatPerfValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// nagiosText keeps plugin output parseable: the pipe separates performance
+// data and a newline would end the status line
+func nagiosText(text string) string {
+	return strings.NewReplacer("|", "/", "\n", " ").Replace(text)
+}
@@ -0,0 +1,108 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+)
+
+func TestFormatNagios(t *testing.T) {
+	healthy := func() *SystemSummary {
+		results := checks.NewResults()
+		packages := checks.CheckResult{Name: "Package System", Message: "Package system analysis completed"}
+		packages.SetMetric("upgradable_packages", 12)
+		packages.SetMetric("broken_packages", 0)
+		results.AddResult(packages)
+		return &SystemSummary{
+			CheckResults:   results,
+			HealthScore:    100,
+			ResourceStatus: ResourceStatus{MemoryPercent: 41.236, DiskUsage: []DiskInfo{{Path: "/", UsedPercent: 70}, {Path: "/var", UsedPercent: 81.5}}},
+			thresholds:     config.DefaultThresholds(),
+		}
+	}
+	withDisk := func(s *SystemSummary, percent float64) *SystemSummary {
+		s.ResourceStatus.DiskUsage[1].UsedPercent = percent
+		return s
+	}
+	withMemory := func(s *SystemSummary, percent float64) *SystemSummary {
+		s.ResourceStatus.MemoryPercent = percent
+		return s
+	}
+	noLimits := NagiosLimits{}
+
+	tests := []struct {
+		name    string
+		summary *SystemSummary
+		limits  NagiosLimits
+		state   int
+		status  string
+	}{
+		{"healthy", healthy(), noLimits, NagiosOK, "DEBIAN-DOCTOR OK - 1 checks OK, health score 100 | "},
+		{"problems", ciSummary(), noLimits, NagiosCritical, "DEBIAN-DOCTOR CRITICAL - Root / full; disk_used_pct above critical level | "},
+		{"warning level", healthy(), NagiosLimits{Warning: map[string]float64{"upgradeable": 10}}, NagiosWarning,
+			"DEBIAN-DOCTOR WARNING - upgradeable above warning level | "},
+		{"critical level", healthy(), NagiosLimits{Warning: map[string]float64{"upgradeable": 5}, Critical: map[string]float64{"upgradeable": 10}},
+			NagiosCritical, "DEBIAN-DOCTOR CRITICAL - upgradeable above critical level | "},
+		{"level not reached", healthy(), NagiosLimits{Critical: map[string]float64{"upgradeable": 12}}, NagiosOK, "DEBIAN-DOCTOR OK"},
+		{"fullest disk above threshold", withDisk(healthy(), 87.41), noLimits, NagiosWarning,
+			"DEBIAN-DOCTOR WARNING - disk_used_pct above warning level | "},
+		{"memory above threshold", withMemory(healthy(), 93), noLimits, NagiosCritical,
+			"DEBIAN-DOCTOR CRITICAL - mem_used_pct above critical level | "},
+		{"nothing ran", &SystemSummary{}, noLimits, NagiosUnknown, "DEBIAN-DOCTOR UNKNOWN - No check ran | "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, state := tt.summary.FormatNagios(tt.limits, false)
+			if state != tt.state || !strings.HasPrefix(string(output), tt.status) {
+				t.Errorf("FormatNagios() = %d, %q, want %d, %q", state, output, tt.state, tt.status)
+			}
+			if lines := strings.Count(string(output), "\n"); lines != 1 {
+				t.Errorf("Expected a single status line, got %d lines", lines)
+			}
+		})
+	}
+
+	output, _ := healthy().FormatNagios(NagiosLimits{Critical: map[string]float64{"upgradeable": 20}}, true)
+	for _, want := range []string{
+		" disk_used_pct=81.5%;85;95;0;100 mem_used_pct=41.24%;80;90;0;100 ",
+		" upgradeable=12;;20;0 broken_packages=0;;;0\n",
+		"[OK] Package System: Package system analysis completed\n",
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Output is missing %q\n%s", want, output)
+		}
+	}
+}
+
+func TestNagiosState(t *testing.T) {
+	for _, tt := range []struct {
+		result checks.CheckResult
+		want   int
+	}{
+		{checks.CheckResult{Severity: checks.SeverityInfo}, NagiosOK},
+		{checks.CheckResult{Severity: checks.SeverityWarning}, NagiosWarning},
+		{checks.CheckResult{Severity: checks.SeverityError}, NagiosCritical},
+		{checks.CheckResult{Severity: checks.SeverityCritical}, NagiosCritical},
+		{checks.CheckResult{Severity: checks.SeverityWarning, Status: checks.StatusTimedOut}, NagiosUnknown},
+		{checks.CheckResult{Status: checks.StatusSkipped}, NagiosOK},
+	} {
+		if got := NagiosState(tt.result); got != tt.want {
+			t.Errorf("NagiosState(%+v) = %d, want %d", tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestNagiosThresholdKey(t *testing.T) {
+	if key, err := NagiosThresholdKey("disk_used_pct", true); err != nil || key != "thresholds.disk_critical_percent" {
+		t.Errorf("NagiosThresholdKey(disk_used_pct) = %q, %v", key, err)
+	}
+	if key, err := NagiosThresholdKey("upgradeable", false); err != nil || key != "" {
+		t.Errorf("NagiosThresholdKey(upgradeable) = %q, %v", key, err)
+	}
+	if _, err := NagiosThresholdKey("health_score", false); err == nil {
+		t.Error("Expected an error for a label without levels")
+	}
+}
@@ -0,0 +1,176 @@
+package summary
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+)
+
+// metricPrefix starts the name of every exported metric
+const metricPrefix = "debian_doctor_"
+
+// measurementHelp describes the measurements checks report. Measurements
+// not listed get a generic description
+var measurementHelp = map[string]string{
+	"broken_packages":        "Packages dpkg or apt consider broken",
+	"held_packages":          "Packages held back with apt-mark",
+	"upgradable_packages":    "Packages with a newer version available",
+	"autoremovable_packages": "Packages apt autoremove would remove",
+	"package_cache_mb":       "Size of the downloaded package cache in megabytes",
+	"failed_units":           "systemd units in the failed state",
+	"journal_size_mb":        "Disk space used by the systemd journal in megabytes",
+	"core_dumps":             "Crash dumps kept by systemd-coredump",
+}
+
+// FormatPrometheus generates the report in the Prometheus text exposition
+// format: the health score, one severity, duration and finding count per
+// check, the measurements checks report and resource usage. Plugin metrics
+// share one family labelled by check and name, so plugins cannot add or
+// overwrite families. Every metric is a gauge describing the run the report
+// came from
+func (s *SystemSummary) FormatPrometheus() []byte {
+	w := &promWriter{}
+
+	w.gauge("health_score", "Overall health score from 0 to 100", float64(s.HealthScore))
+	w.gauge("critical_issues", "Checks that reported an error or critical problem", float64(len(s.CriticalIssues)))
+	w.gauge("warnings", "Checks that reported a warning", float64(len(s.Warnings)))
+	w.gauge("last_run_timestamp_seconds", "When the checks last ran, as a Unix timestamp", float64(s.Timestamp.Unix()))
+	w.gauge("run_duration_seconds", "How long the last run took", s.Duration.Seconds())
+	w.family("info", "Host the report describes; always 1")
+	w.sample("info", 1, "hostname", s.SystemInfo.Hostname, "os", s.SystemInfo.OS,
+		"kernel", s.SystemInfo.Kernel, "architecture", s.SystemInfo.Architecture)
+
+	all := s.CheckResults.GetAllChecks()
+	w.family("check_severity", "Severity of each check: 0 info, 1 warning, 2 error, 3 critical")
+	for _, result := range all {
+		w.sample("check_severity", float64(result.Severity), "check", result.Name)
+	}
+	w.family("check_completed", "Whether each check ran to completion; 0 when it was skipped, timed out or cancelled")
+	for _, result := range all {
+		completed := 0.0
+		if result.Status == "" {
+			completed = 1
+		}
+		w.sample("check_completed", completed, "check", result.Name)
+	}
+	w.family("check_duration_seconds", "How long each check ran")
+	for _, result := range all {
+		w.sample("check_duration_seconds", result.Duration.Seconds(), "check", result.Name)
+	}
+	w.family("check_findings", "Findings reported by each check")
+	for _, result := range all {
+		w.sample("check_findings", float64(len(result.Findings)), "check", result.Name)
+	}
+
+	measurements, sources := checkMeasurements(all)
+	names := make([]string, 0, len(measurements))
+	for name := range measurements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		help, ok := measurementHelp[name]
+		if !ok {
+			help = fmt.Sprintf("Measurement reported by the %s check", sources[name])
+		}
+		w.gauge(name, help, measurements[name])
+	}
+	w.family("plugin_metric", "Measurement reported by an external check plugin")
+	for _, result := range all {
+		if !result.Plugin {
+			continue
+		}
+		names := make([]string, 0, len(result.Metrics))
+		for name := range result.Metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			w.sample("plugin_metric", result.Metrics[name], "check", result.Name, "name", name)
+		}
+	}
+
+	r := s.ResourceStatus
+	w.gauge("cpu_usage_percent", "CPU usage in percent", r.CPUUsage)
+	w.gauge("memory_usage_percent", "Memory usage in percent", r.MemoryPercent)
+	w.gauge("swap_usage_percent", "Swap usage in percent", r.SwapPercent)
+	w.gauge("processes", "Running processes", float64(r.ProcessCount))
+	w.gauge("uptime_seconds", "Time since the system booted", s.SystemInfo.Uptime.Seconds())
+	w.family("load_average", "System load average")
+	for i, period := range []string{"1m", "5m", "15m"} {
+		w.sample("load_average", r.LoadAverage[i], "period", period)
+	}
+	w.family("disk_usage_percent", "Used space of each filesystem in percent")
+	for _, disk := range r.DiskUsage {
+		w.sample("disk_usage_percent", disk.UsedPercent, "path", disk.Path, "device", disk.Device)
+	}
+	w.family("disk_free_bytes", "Free space of each filesystem")
+	for _, disk := range r.DiskUsage {
+		w.sample("disk_free_bytes", float64(disk.Free), "path", disk.Path, "device", disk.Device)
+	}
+
+	return []byte(w.String())
+}
+
+// checkMeasurements merges the measurements of every built-in check, keyed
+// by metric name, and remembers which check reported each
+func checkMeasurements(results []checks.CheckResult) (map[string]float64, map[string]string) {
+	values := make(map[string]float64)
+	sources := make(map[string]string)
+	for _, result := range results {
+		if result.Plugin {
+			continue
+		}
+		for name, value := range result.Metrics {
+			name = metricName(name)
+			values[name] = value
+			sources[name] = result.Name
+		}
+	}
+	return values, sources
+}
+
+// metricName replaces the characters Prometheus does not allow in metric
+// names with underscores
+func metricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// promWriter writes metric families in the text exposition format
+type promWriter struct {
+	strings.Builder
+}
+
+// family starts a metric family; its samples must follow directly
+func (w *promWriter) family(name, help string) {
+	fmt.Fprintf(w, "# HELP %s%s %s\n# TYPE %s%s gauge\n", metricPrefix, name, help, metricPrefix, name)
+}
+
+// gauge writes a family with a single unlabelled sample
+func (w *promWriter) gauge(name, help string, value float64) {
+	w.family(name, help)
+	w.sample(name, value)
+}
+
+// sample writes one sample. labels are name, value pairs
+func (w *promWriter) sample(name string, value float64, labels ...string) {
+	w.WriteString(metricPrefix + name)
+	if len(labels) > 0 {
+		pairs := make([]string, 0, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1])))
+		}
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	w.WriteString(" " + strconv.FormatFloat(value, 'f', -1, 64) + "\n")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,72 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+)
+
+func TestFormatPrometheus(t *testing.T) {
+	s := ciSummary()
+	s.Timestamp = time.Unix(1790000000, 0)
+	s.ResourceStatus.DiskUsage[0].Device = "/dev/sda1"
+	logs := checks.CheckResult{Name: `Logs "tail"`, Severity: checks.SeverityWarning, Duration: 1500 * time.Millisecond}
+	logs.SetMetric("failed_units", 2)
+	logs.SetMetric("odd-name", 1)
+	s.CheckResults.AddResult(logs)
+	plugin := checks.CheckResult{Name: "hsm", Plugin: true}
+	plugin.SetMetric("failed_units", 5)
+	plugin.SetMetric("health_score", 1)
+	s.CheckResults.AddResult(plugin)
+
+	data, err := s.Render(FormatPrometheus)
+	if err != nil {
+		t.Fatalf("Render(prometheus) failed: %v", err)
+	}
+	report := string(data)
+
+	for _, want := range []string{
+		"# TYPE debian_doctor_health_score gauge\ndebian_doctor_health_score 65\n",
+		"debian_doctor_last_run_timestamp_seconds 1790000000\n",
+		`debian_doctor_check_severity{check="Disk"} 3`,
+		`debian_doctor_check_completed{check="Services"} 0`,
+		`debian_doctor_check_duration_seconds{check="Logs \"tail\""} 1.5`,
+		`debian_doctor_check_findings{check="Package System"} 1`,
+		"# HELP debian_doctor_failed_units systemd units in the failed state\n# TYPE debian_doctor_failed_units gauge\ndebian_doctor_failed_units 2\n",
+		"# HELP debian_doctor_odd_name Measurement reported by the Logs \"tail\" check\n",
+		`debian_doctor_plugin_metric{check="hsm",name="failed_units"} 5`,
+		`debian_doctor_plugin_metric{check="hsm",name="health_score"} 1`,
+		`debian_doctor_disk_usage_percent{path="/",device="/dev/sda1"} 97`,
+		`debian_doctor_load_average{period="15m"} 0`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Prometheus report is missing %q\n%s", want, report)
+		}
+	}
+
+	if strings.Contains(report, "debian_doctor_failed_units 5") {
+		t.Error("A plugin overwrote a built-in measurement")
+	}
+
+	// Every family is declared once, before its samples
+	declared := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(report), "\n") {
+		if name, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			name = strings.Fields(name)[0]
+			if declared[name] {
+				t.Errorf("Family %s declared twice", name)
+			}
+			declared[name] = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.Fields(line)[0], "{")
+		if !declared[name] {
+			t.Errorf("Sample %q has no TYPE line before it", line)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package summary
+
+import (
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+	"github.com/debian-doctor/debian-doctor/internal/redact"
+)
+
+// Redact anonymises every string in the summary. It runs before rendering
+// so that the encoding of a format, such as JSON quotes or HTML entities,
+// can neither hide a value from the redactor nor be broken by it. Check
+// results are copied, so the results the summary was generated from keep
+// their original text
+func (s *SystemSummary) Redact(r *redact.Redactor) {
+	if r == nil {
+		return
+	}
+	info := &s.SystemInfo
+	info.Hostname = r.String(info.Hostname)
+	info.OS = r.String(info.OS)
+	info.Kernel = r.String(info.Kernel)
+	info.CPUModel = r.String(info.CPUModel)
+	info.Virtualization = r.String(info.Virtualization)
+
+	disks := make([]DiskInfo, len(s.ResourceStatus.DiskUsage))
+	for i, disk := range s.ResourceStatus.DiskUsage {
+		disk.Path = r.String(disk.Path)
+		disk.Device = r.String(disk.Device)
+		disks[i] = disk
+	}
+	s.ResourceStatus.DiskUsage = disks
+
+	network := &s.NetworkStatus
+	interfaces := make([]NetworkInterface, len(network.Interfaces))
+	for i, iface := range network.Interfaces {
+		iface.Name = r.String(iface.Name)
+		iface.Addresses = redactStrings(r, iface.Addresses)
+		interfaces[i] = iface
+	}
+	network.Interfaces = interfaces
+	network.DNSServers = redactStrings(r, network.DNSServers)
+	network.Gateway = r.String(network.Gateway)
+	network.Hostname = r.String(network.Hostname)
+
+	results := checks.NewResults()
+	for _, result := range s.CheckResults.GetAllChecks() {
+		results.AddResult(redactResult(r, result))
+	}
+	s.CheckResults = results
+
+	s.Recommendations = redactStrings(r, s.Recommendations)
+	s.CriticalIssues = redactStrings(r, s.CriticalIssues)
+	s.Warnings = redactStrings(r, s.Warnings)
+	s.Skipped = redactStrings(r, s.Skipped)
+}
+
+// redactStrings returns a redacted copy of values, keeping nil and empty
+// slices apart so the JSON output does not change shape
+func redactStrings(r *redact.Redactor, values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return r.Strings(append([]string{}, values...))
+}
+
+func redactResult(r *redact.Redactor, result checks.CheckResult) checks.CheckResult {
+	result.Message = r.String(result.Message)
+	result.Details = redactStrings(r, result.Details)
+
+	findings := make([]finding.Finding, len(result.Findings))
+	for i, f := range result.Findings {
+		f.Summary = r.String(f.Summary)
+		f.Excerpt = r.String(f.Excerpt)
+		if f.Evidence != nil {
+			evidence := make(map[string]string, len(f.Evidence))
+			for key, value := range f.Evidence {
+				evidence[key] = r.String(value)
+			}
+			f.Evidence = evidence
+		}
+		resources := make([]finding.Resource, len(f.Resources))
+		for j, resource := range f.Resources {
+			resource.Name = r.String(resource.Name)
+			resources[j] = resource
+		}
+		if f.Resources != nil {
+			f.Resources = resources
+		}
+		findings[i] = f
+	}
+	if result.Findings != nil {
+		result.Findings = findings
+	}
+	return result
+}
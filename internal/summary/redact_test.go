@@ -0,0 +1,57 @@
+package summary
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/redact"
+)
+
+func TestRedactBeforeRendering(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatHTML, FormatMarkdown, FormatText} {
+		t.Run(format, func(t *testing.T) {
+			s := testSummary()
+			s.CheckResults.AddResult(checks.CheckResult{
+				Name:     "Config",
+				Severity: checks.SeverityWarning,
+				Message:  "Secret in config",
+				Details:  []string{`password="hunter2 more" in /etc/app.conf`},
+			})
+			original := s.CheckResults.GetAllChecks()
+
+			r, err := redact.New(nil, "web01")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Redact(r)
+			data, err := s.Render(format)
+			if err != nil {
+				t.Fatalf("Render(%s) error = %v", format, err)
+			}
+			if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "web01") {
+				t.Errorf("Secret or hostname left in the %s report:\n%s", format, data)
+			}
+
+			if format == FormatJSON {
+				var decoded struct {
+					SystemInfo   SystemInfo     `json:"system_info"`
+					CheckResults checks.Results `json:"check_results"`
+				}
+				if err := json.Unmarshal(data, &decoded); err != nil {
+					t.Fatalf("Redacted report is not valid JSON: %v\n%s", err, data)
+				}
+				results := decoded.CheckResults.GetAllChecks()
+				if details := results[len(results)-1].Details; len(details) != 1 || details[0] != "password=secret-1 in /etc/app.conf" {
+					t.Errorf("Details = %q", details)
+				}
+			}
+
+			// The results the summary came from are left alone
+			if details := original[len(original)-1].Details; details[0] != `password="hunter2 more" in /etc/app.conf` {
+				t.Errorf("Redact() changed the original results: %q", details)
+			}
+		})
+	}
+}
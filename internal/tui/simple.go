@@ -2,7 +2,9 @@ package tui
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -11,16 +13,20 @@ import (
 
 	"github.com/debian-doctor/debian-doctor/internal/checks"
 	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/finding"
 	"github.com/debian-doctor/debian-doctor/internal/fixes"
+	"github.com/debian-doctor/debian-doctor/internal/history"
+	"github.com/debian-doctor/debian-doctor/internal/redact"
 	"github.com/debian-doctor/debian-doctor/internal/summary"
 	"github.com/debian-doctor/debian-doctor/pkg/config"
 	"github.com/debian-doctor/debian-doctor/pkg/logger"
 )
 
 type SimpleUI struct {
-	config  *config.Config
-	logger  *logger.Logger
-	scanner *bufio.Scanner
+	config   *config.Config
+	logger   *logger.Logger
+	scanner  *bufio.Scanner
+	redactor *redact.Redactor
 }
 
 func NewSimpleUI(cfg *config.Config, log *logger.Logger) *SimpleUI {
@@ -31,6 +37,11 @@ func NewSimpleUI(cfg *config.Config, log *logger.Logger) *SimpleUI {
 	}
 }
 
+// SetRedactor anonymises the system report before it is shown or saved
+func (ui *SimpleUI) SetRedactor(r *redact.Redactor) {
+	ui.redactor = r
+}
+
 func (ui *SimpleUI) Run() error {
 	ui.clearScreen()
 	ui.showHeader()
@@ -93,11 +104,21 @@ func (ui *SimpleUI) showMainMenu() {
 }
 
 func (ui *SimpleUI) getInput(prompt string) string {
-	fmt.Print(prompt)
+	answer, _ := ui.Prompt(prompt)
+	return answer
+}
+
+// Prompt asks a question on the terminal. Fix confirmations are asked
+// through it too, so they share the menu's buffered input
+func (ui *SimpleUI) Prompt(question string) (string, error) {
+	fmt.Print(question)
 	if ui.scanner.Scan() {
-		return strings.TrimSpace(ui.scanner.Text())
+		return strings.TrimSpace(ui.scanner.Text()), nil
 	}
-	return ""
+	if err := ui.scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
 }
 
 func (ui *SimpleUI) showError(message string) {
@@ -121,21 +142,19 @@ func (ui *SimpleUI) runSystemCheck() {
 	fmt.Println("=====================================")
 	fmt.Println()
 	
-	allChecks := checks.GetAllChecks()
-	results := checks.NewResults()
+	allChecks, err := checks.GetChecks(ui.config)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	ui.showProgress(fmt.Sprintf("SCANNING: %d CHECKS", len(allChecks)), 0)
 	
-	for i, check := range allChecks {
-		// Show progress
-		percent := float64(i) / float64(len(allChecks)) * 100
-		ui.showProgress(fmt.Sprintf("SCANNING: %s", strings.ToUpper(check.Name())), percent)
-		
-		// Run the check
-		result := check.Run()
-		results.AddResult(result)
-		
-		// Small delay for visual effect
-		time.Sleep(100 * time.Millisecond)
+	runner := checks.NewRunner(ui.config)
+	runner.Progress = func(result checks.CheckResult, done, total int) {
+		percent := float64(done) / float64(total) * 100
+		ui.showProgress(fmt.Sprintf("CHECKED: %s", strings.ToUpper(result.Name)), percent)
 	}
+	results := runner.Run(context.Background(), allChecks)
 	
 	// Final progress
 	ui.showProgress("SCAN COMPLETE", 100)
@@ -220,6 +239,14 @@ func (ui *SimpleUI) showResults(results checks.Results) {
 		fmt.Println()
 	}
 	
+	if skipped := results.GetSkipped(); len(skipped) > 0 {
+		fmt.Println("SKIPPED CHECKS:")
+		for i, item := range skipped {
+			fmt.Printf("  %d. %s\n", i+1, item)
+		}
+		fmt.Println()
+	}
+	
 	if len(errors) == 0 && len(warnings) == 0 {
 		fmt.Println("All diagnostic checks passed successfully.")
 		fmt.Println("Your Debian-based system is running optimally.")
@@ -319,9 +346,11 @@ func (ui *SimpleUI) runDiagnosis(issueType string) {
 		diagnosis = diagnose.DiagnoseCustomIssue("General system troubleshooting requested")
 	default:
 		diagnosis = diagnose.Diagnosis{
-			Issue:    issueType,
-			Findings: []string{"Diagnosis not yet implemented for this issue type"},
-			Fixes:    []*fixes.Fix{},
+			Issue: issueType,
+			Findings: []finding.Finding{
+				finding.New("tui.not_implemented", finding.SeverityInfo, "Diagnosis not yet implemented for this issue type"),
+			},
+			Fixes: []*fixes.Fix{},
 		}
 	}
 	
@@ -383,8 +412,8 @@ func (ui *SimpleUI) showDiagnosisResults(diagnosis diagnose.Diagnosis) {
 	
 	if len(diagnosis.Findings) > 0 {
 		fmt.Println("DIAGNOSTIC FINDINGS:")
-		for i, finding := range diagnosis.Findings {
-			fmt.Printf("  %d. %s\n", i+1, finding)
+		for i, summary := range diagnosis.Summaries() {
+			fmt.Printf("  %d. %s\n", i+1, summary)
 		}
 		fmt.Println()
 	} else {
@@ -406,8 +435,11 @@ func (ui *SimpleUI) showDiagnosisResults(diagnosis diagnose.Diagnosis) {
 			fmt.Println()
 		}
 		
-		if ui.askYesNo("Apply the first available fix? (y/n): ") {
-			ui.applyFix(diagnosis.Fixes[0])
+		switch strings.ToLower(ui.getInput("Apply the first available fix? (y = apply, d = dry run, n = skip): ")) {
+		case "y", "yes":
+			ui.applyFix(&diagnosis, diagnosis.Fixes[0], ui.config.DryRun)
+		case "d", "dry", "dry run":
+			ui.applyFix(&diagnosis, diagnosis.Fixes[0], true)
 		}
 	} else {
 		fmt.Println("NO AUTOMATED FIXES AVAILABLE")
@@ -423,22 +455,87 @@ func (ui *SimpleUI) askYesNo(prompt string) bool {
 	return strings.ToLower(response) == "y" || strings.ToLower(response) == "yes"
 }
 
-func (ui *SimpleUI) applyFix(fix *fixes.Fix) {
+// applyFix runs a fix through the executor. With dryRun set it first shows
+// what the fix would do and only continues if the user then confirms.
+func (ui *SimpleUI) applyFix(diagnosis *diagnose.Diagnosis, fix *fixes.Fix, dryRun bool) diagnose.DiagnosisResult {
+	result := diagnose.DiagnosisResult{Diagnosis: diagnosis}
+	executor := fixes.NewExecutor(ui.config, ui.logger)
+	executor.SetConfirmer(fixes.NewPromptConfirmer(os.Stdout, ui))
+	
+	if dryRun {
+		plan, err := executor.Plan(fix)
+		if err != nil {
+			result.ExecutionError = err
+			ui.showError(fmt.Sprintf("Cannot plan fix: %v", err))
+			return result
+		}
+		fmt.Println()
+		plan.Render(os.Stdout)
+		fmt.Println()
+		
+		if ui.config.DryRun || !ui.askYesNo("Apply this fix now? (y/n): ") {
+			fmt.Println("Dry run only. No changes were made.")
+			return result
+		}
+	}
+	
 	fmt.Printf("Applying fix: %s\n", fix.Description)
 	fmt.Println()
 	
-	// Show progress
-	ui.showProgress("PREPARING FIX", 0)
-	time.Sleep(300 * time.Millisecond)
-	ui.showProgress("EXECUTING COMMANDS", 50)
-	time.Sleep(1000 * time.Millisecond)
-	ui.showProgress("FIX APPLIED", 100)
-	fmt.Println()
+	run, err := executor.ExecuteFix(fix)
+	result.FixExecuted = run != nil && len(run.Steps) > 0
+	result.ExecutionError = err
+	result.Run = run
+	
+	if run != nil {
+		ui.showFixRun(run)
+	}
+	
+	if err != nil {
+		ui.showError(fmt.Sprintf("Fix failed: %v", err))
+		return result
+	}
+	
+	if run.Status == fixes.RunCancelled {
+		fmt.Println("Fix cancelled. No changes were made.")
+		return result
+	}
 	
 	ui.showSuccess("Fix applied successfully!")
+	fmt.Printf("Run %s recorded in %s\n", run.ID, executor.Journal().Path())
 	
 	// Log the fix application
 	ui.logger.Info("Applied fix: %s", fix.Description)
+	return result
+}
+
+// showFixRun prints the outcome of each step of a fix run
+func (ui *SimpleUI) showFixRun(run *fixes.FixRun) {
+	if len(run.Steps) == 0 {
+		return
+	}
+	
+	fmt.Println()
+	fmt.Println("FIX STEPS:")
+	for _, step := range run.Steps {
+		status := "OK"
+		if step.Error != "" {
+			status = fmt.Sprintf("FAILED (exit %d)", step.ExitCode)
+		}
+		fmt.Printf("  %d. %s [%s, %s]\n", step.Step, step.Command, status, step.EndedAt.Sub(step.StartedAt).Round(time.Millisecond))
+	}
+	
+	if run.Reversal != nil {
+		switch {
+		case !run.Reversal.Attempted:
+			fmt.Println("  Reversal was offered but not attempted")
+		case run.Reversal.Succeeded:
+			fmt.Println("  Partial changes were reversed")
+		default:
+			fmt.Println("  Reversal attempted but some steps failed")
+		}
+	}
+	fmt.Println()
 }
 
 func (ui *SimpleUI) showSystemLogs() {
@@ -481,6 +578,9 @@ func (ui *SimpleUI) showComprehensiveSummary(results checks.Results) {
 		ui.showError(fmt.Sprintf("Failed to generate summary: %v", err))
 		return
 	}
+	if _, err := history.Record(ui.config, systemSummary); err != nil {
+		ui.logger.Warning(fmt.Sprintf("Failed to record run history: %v", err))
+	}
 	
 	ui.showProgress("ANALYZING DATA", 50)
 	time.Sleep(300 * time.Millisecond)
@@ -492,21 +592,34 @@ func (ui *SimpleUI) showComprehensiveSummary(results checks.Results) {
 	fmt.Println()
 	
 	// Display the report
+	systemSummary.Redact(ui.redactor)
 	report := systemSummary.FormatReport()
 	fmt.Println(report)
 	
 	// Offer to save the report
 	fmt.Println()
 	if ui.askYesNo("Save report to file? (y/n): ") {
-		ui.saveReport(report)
+		ui.saveReport(systemSummary)
 	}
 }
 
-func (ui *SimpleUI) saveReport(report string) {
-	filename := fmt.Sprintf("debian_doctor_report_%s.txt", 
-		time.Now().Format("20060102_150405"))
+// saveReport writes the report in a format the user picks, named after it
+func (ui *SimpleUI) saveReport(systemSummary *summary.SystemSummary) {
+	format := strings.ToLower(ui.getInput(fmt.Sprintf("Report format (%s) [%s]: ",
+		strings.Join(summary.Formats(), "/"), summary.FormatText)))
+	if format == "" {
+		format = summary.FormatText
+	}
+	report, err := systemSummary.Render(format)
+	if err != nil {
+		ui.showError(err.Error())
+		return
+	}
+	
+	filename := fmt.Sprintf("debian_doctor_report_%s.%s", 
+		time.Now().Format("20060102_150405"), summary.FileExtension(format))
 	
-	err := os.WriteFile(filename, []byte(report), 0644)
+	err = os.WriteFile(filename, report, 0644)
 	if err != nil {
 		ui.showError(fmt.Sprintf("Failed to save report: %v", err))
 		return
@@ -6,26 +6,62 @@ import (
 )
 
 type Config struct {
-	LogDir     string
-	IsRoot     bool
-	Verbose    bool
-	NonInteractive bool
+	LogDir     string     `yaml:"log_dir"`
+	StateDir   string     `yaml:"state_dir"`
+	PolicyFile string     `yaml:"policy_file"`
+	RuleDirs   []string   `yaml:"rule_dirs"` // Extra diagnosis rule packs
+	IsRoot     bool       `yaml:"-"`
+	Verbose    bool       `yaml:"verbose"`
+	NonInteractive bool   `yaml:"-"`
+	DryRun     bool       `yaml:"-"`
+	Thresholds Thresholds `yaml:"thresholds"`
+	Checks     CheckSelection `yaml:"checks"`
+	Runner     RunnerSettings `yaml:"runner"`
+	Redact     RedactSettings `yaml:"redact"`
+	Report     ReportSettings `yaml:"report"`
+	History    HistorySettings `yaml:"history"`
+	
+	// Sources lists where the effective values came from, lowest
+	// precedence first
+	Sources []string `yaml:"-"`
+
+	// Ignored lists restricted settings that were left out because of
+	// where they came from, e.g. "checks.plugin_dirs from the environment"
+	Ignored []string `yaml:"-"`
 }
 
 func New() *Config {
 	homeDir, _ := os.UserHomeDir()
 	logDir := filepath.Join(homeDir, ".debian-doctor", "logs")
 	
+	stateDir := filepath.Join(homeDir, ".debian-doctor", "state")
+	isRoot := os.Geteuid() == 0
+	
 	// If home directory is not accessible, use temp
 	if homeDir == "" {
 		logDir = "/tmp/debian-doctor-logs"
+		stateDir = "/tmp/debian-doctor-state"
+	}
+	
+	// Root keeps snapshots and other state with the rest of the system state
+	if isRoot {
+		stateDir = "/var/lib/debian-doctor"
 	}
 
 	return &Config{
 		LogDir:         logDir,
-		IsRoot:         os.Geteuid() == 0,
+		StateDir:       stateDir,
+		PolicyFile:     DefaultPolicyFile,
+		RuleDirs:       []string{SystemRulesDir},
+		IsRoot:         isRoot,
 		Verbose:        false,
 		NonInteractive: false,
+		DryRun:         false,
+		Thresholds:     DefaultThresholds(),
+		Checks:         CheckSelection{PluginDirs: DefaultPluginDirs()},
+		Runner:         DefaultRunnerSettings(),
+		History:        DefaultHistorySettings(),
+		Sources:        []string{"defaults"},
 	}
 }
 
@@ -37,6 +73,14 @@ func (c *Config) SetNonInteractive(nonInteractive bool) {
 	c.NonInteractive = nonInteractive
 }
 
+func (c *Config) SetDryRun(dryRun bool) {
+	c.DryRun = dryRun
+}
+
 func (c *Config) SetLogDir(logDir string) {
 	c.LogDir = logDir
-}
\ No newline at end of file
+}
+
+func (c *Config) SetStateDir(stateDir string) {
+	c.StateDir = stateDir
+}
@@ -65,6 +65,19 @@ func TestSetNonInteractive(t *testing.T) {
 	}
 }
 
+func TestSetDryRun(t *testing.T) {
+	cfg := New()
+	
+	if cfg.DryRun {
+		t.Error("Expected DryRun to be false by default")
+	}
+	
+	cfg.SetDryRun(true)
+	if !cfg.DryRun {
+		t.Error("Expected DryRun to be true after SetDryRun(true)")
+	}
+}
+
 func TestSetLogDir(t *testing.T) {
 	cfg := New()
 	originalLogDir := cfg.LogDir
@@ -99,6 +112,23 @@ func TestLogDirFallback(t *testing.T) {
 	}
 }
 
+func TestStateDir(t *testing.T) {
+	cfg := New()
+	
+	if cfg.StateDir == "" {
+		t.Error("Expected StateDir to be set")
+	}
+	
+	if cfg.IsRoot && cfg.StateDir != "/var/lib/debian-doctor" {
+		t.Errorf("Expected root StateDir '/var/lib/debian-doctor', got '%s'", cfg.StateDir)
+	}
+	
+	cfg.SetStateDir("/test/state")
+	if cfg.StateDir != "/test/state" {
+		t.Errorf("Expected StateDir '/test/state', got '%s'", cfg.StateDir)
+	}
+}
+
 func TestConfigStructure(t *testing.T) {
 	cfg := Config{
 		LogDir:         "/test/path",
@@ -0,0 +1,468 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SystemConfigFile is read first and applies to every user
+	SystemConfigFile = "/etc/debian-doctor/config.yaml"
+
+	// DefaultPolicyFile is where the fix policy is read from unless the
+	// configuration points elsewhere
+	DefaultPolicyFile = "/etc/debian-doctor/policy.yaml"
+
+	// SystemPluginDir holds external check plugins installed for every user
+	SystemPluginDir = "/etc/debian-doctor/checks.d"
+
+	// SystemRulesDir holds diagnosis rule packs added by the administrator
+	SystemRulesDir = "/etc/debian-doctor/rules.d"
+
+	// EnvPrefix starts every environment variable that overrides a setting,
+	// e.g. DEBIAN_DOCTOR_THRESHOLDS_DISK_WARNING_PERCENT
+	EnvPrefix = "DEBIAN_DOCTOR_"
+)
+
+// Thresholds are the limits that checks and diagnoses compare against
+type Thresholds struct {
+	DiskWarningPercent     int     `yaml:"disk_warning_percent"`
+	DiskCriticalPercent    int     `yaml:"disk_critical_percent"`
+	InodeWarningPercent    int     `yaml:"inode_warning_percent"`
+	MemoryWarningPercent   float64 `yaml:"memory_warning_percent"`
+	MemoryCriticalPercent  float64 `yaml:"memory_critical_percent"`
+	SwapWarningPercent     float64 `yaml:"swap_warning_percent"`
+	CPUWarningPercent      float64 `yaml:"cpu_warning_percent"`
+	LoadPerCPU             float64 `yaml:"load_per_cpu"`
+	JournalMaxMB           float64 `yaml:"journal_max_mb"`
+	LogFileMaxMB           float64 `yaml:"log_file_max_mb"`
+	PackageCacheMaxMB      float64 `yaml:"package_cache_max_mb"`
+	TmpFilesWarning        int     `yaml:"tmp_files_warning"`
+	ServiceRestartsPerHour int     `yaml:"service_restarts_per_hour"`
+}
+
+// DefaultThresholds returns the limits used when nothing is configured
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		DiskWarningPercent:     85,
+		DiskCriticalPercent:    95,
+		InodeWarningPercent:    90,
+		MemoryWarningPercent:   80,
+		MemoryCriticalPercent:  90,
+		SwapWarningPercent:     50,
+		CPUWarningPercent:      80,
+		LoadPerCPU:             2,
+		JournalMaxMB:           1000,
+		LogFileMaxMB:           100,
+		PackageCacheMaxMB:      1000,
+		TmpFilesWarning:        1000,
+		ServiceRestartsPerHour: 6,
+	}
+}
+
+// Limit returns a threshold by its key, e.g. journal_max_mb
+func (t Thresholds) Limit(name string) (float64, bool) {
+	value := reflect.ValueOf(t)
+	for i := 0; i < value.NumField(); i++ {
+		if yamlName(value.Type().Field(i)) != name {
+			continue
+		}
+		switch field := value.Field(i); field.Kind() {
+		case reflect.Int:
+			return float64(field.Int()), true
+		case reflect.Float64:
+			return field.Float(), true
+		}
+	}
+	return 0, false
+}
+
+// Validate rejects percentages outside 0-100, negative limits and warning
+// levels above their critical counterparts
+func (t Thresholds) Validate() error {
+	percents := []struct {
+		name  string
+		value float64
+	}{
+		{"disk_warning_percent", float64(t.DiskWarningPercent)},
+		{"disk_critical_percent", float64(t.DiskCriticalPercent)},
+		{"inode_warning_percent", float64(t.InodeWarningPercent)},
+		{"memory_warning_percent", t.MemoryWarningPercent},
+		{"memory_critical_percent", t.MemoryCriticalPercent},
+		{"swap_warning_percent", t.SwapWarningPercent},
+		{"cpu_warning_percent", t.CPUWarningPercent},
+	}
+	for _, p := range percents {
+		if p.value < 0 || p.value > 100 {
+			return fmt.Errorf("thresholds.%s must be between 0 and 100, got %v", p.name, p.value)
+		}
+	}
+
+	limits := []struct {
+		name  string
+		value float64
+	}{
+		{"load_per_cpu", t.LoadPerCPU},
+		{"journal_max_mb", t.JournalMaxMB},
+		{"log_file_max_mb", t.LogFileMaxMB},
+		{"package_cache_max_mb", t.PackageCacheMaxMB},
+		{"tmp_files_warning", float64(t.TmpFilesWarning)},
+		{"service_restarts_per_hour", float64(t.ServiceRestartsPerHour)},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			return fmt.Errorf("thresholds.%s must not be negative, got %v", l.name, l.value)
+		}
+	}
+
+	if t.DiskWarningPercent > t.DiskCriticalPercent {
+		return fmt.Errorf("thresholds.disk_warning_percent (%d) is above disk_critical_percent (%d)", t.DiskWarningPercent, t.DiskCriticalPercent)
+	}
+	if t.MemoryWarningPercent > t.MemoryCriticalPercent {
+		return fmt.Errorf("thresholds.memory_warning_percent (%v) is above memory_critical_percent (%v)", t.MemoryWarningPercent, t.MemoryCriticalPercent)
+	}
+	return nil
+}
+
+// CheckSelection chooses which checks run. An empty Enabled list enables
+// every check, Tags keeps checks carrying any of the tags, and Disabled is
+// applied last. PluginDirs are searched for external check plugins
+type CheckSelection struct {
+	Enabled    []string `yaml:"enabled"`
+	Disabled   []string `yaml:"disabled"`
+	Tags       []string `yaml:"tags"`
+	PluginDirs []string `yaml:"plugin_dirs"`
+}
+
+// DefaultPluginDirs returns the directories searched for external check
+// plugins: the system directory, then ~/.config/debian-doctor/checks.d
+func DefaultPluginDirs() []string {
+	dirs := []string{SystemPluginDir}
+	if file := UserConfigFile(); file != "" {
+		dirs = append(dirs, filepath.Join(filepath.Dir(file), "checks.d"))
+	}
+	return dirs
+}
+
+// RunnerSettings control how checks are executed
+type RunnerSettings struct {
+	Workers           int           `yaml:"workers"`             // Checks run at the same time
+	CheckTimeout      time.Duration `yaml:"check_timeout"`       // Deadline for each check
+	FixCommandTimeout time.Duration `yaml:"fix_command_timeout"` // Deadline for each fix command
+}
+
+// DefaultRunnerSettings returns the runner settings used when nothing is
+// configured
+func DefaultRunnerSettings() RunnerSettings {
+	return RunnerSettings{
+		Workers:           4,
+		CheckTimeout:      60 * time.Second,
+		FixCommandTimeout: 10 * time.Minute,
+	}
+}
+
+// RedactSettings control how reports, logs and bundles are anonymised
+type RedactSettings struct {
+	Enabled bool     `yaml:"enabled"`
+	Rules   []string `yaml:"rules"` // Rules to apply; empty applies every rule
+	Terms   []string `yaml:"terms"` // Extra words to hide, such as customer names
+}
+
+// ReportSettings control how reports grade check results
+type ReportSettings struct {
+	WarningsFail bool `yaml:"warnings_fail"` // JUnit reports warnings as failures
+}
+
+// HistorySettings control the record of past runs kept in the state directory
+type HistorySettings struct {
+	Enabled bool `yaml:"enabled"`
+	MaxRuns int  `yaml:"max_runs"` // Oldest runs are dropped beyond this; 0 keeps every run
+}
+
+// DefaultHistorySettings returns the history settings used when nothing is
+// configured
+func DefaultHistorySettings() HistorySettings {
+	return HistorySettings{
+		Enabled: true,
+		MaxRuns: 1000,
+	}
+}
+
+// UserConfigFile returns the per-user configuration file, normally
+// ~/.config/debian-doctor/config.yaml
+func UserConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil || dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "debian-doctor", "config.yaml")
+}
+
+// Load builds the effective configuration from the built-in defaults, the
+// system file, the user file and DEBIAN_DOCTOR_* environment variables, in
+// that order. An explicit file is read after the user file and must exist
+//
+// Some settings, such as the fix policy, are not taken from the user file
+// or the environment; see RestrictedKeys
+func Load(explicit string) (*Config, error) {
+	cfg := New()
+
+	if err := cfg.mergeIfExists(SystemConfigFile); err != nil {
+		return nil, err
+	}
+	if path := UserConfigFile(); path != "" {
+		if err := cfg.keepRestricted(path, func() error { return cfg.mergeIfExists(path) }); err != nil {
+			return nil, err
+		}
+	}
+	if explicit != "" {
+		if err := cfg.MergeFile(explicit); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.keepRestricted("the environment", func() error { return cfg.MergeEnv(os.LookupEnv) }); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// RestrictedKeys lists the settings that are only taken from the system
+// config file and the command line. The fix policy is an administrator's
+// decision, so policy_file always is. When running as root, ~/.config and
+// DEBIAN_DOCTOR_* variables may belong to whoever invoked us, so they must
+// not choose the plugins that are run, the rules whose fixes are offered, or
+// the directories that snapshots are restored from and logs written to
+// either
+func RestrictedKeys(isRoot bool) []string {
+	if !isRoot {
+		return []string{"policy_file"}
+	}
+	return []string{"policy_file", "checks.plugin_dirs", "rule_dirs", "state_dir", "log_dir"}
+}
+
+// keepRestricted runs merge and puts back every restricted setting it
+// changed, noting in Ignored where the change came from
+func (c *Config) keepRestricted(source string, merge func() error) error {
+	keys := RestrictedKeys(c.IsRoot)
+	saved := make([]interface{}, len(keys))
+	for i, key := range keys {
+		field, err := c.field(key)
+		if err != nil {
+			return err
+		}
+		saved[i] = field.Interface()
+	}
+
+	if err := merge(); err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		field, _ := c.field(key)
+		if !reflect.DeepEqual(field.Interface(), saved[i]) {
+			field.Set(reflect.ValueOf(saved[i]))
+			c.Ignored = append(c.Ignored, fmt.Sprintf("%s from %s", key, source))
+		}
+	}
+	return nil
+}
+
+// mergeIfExists is MergeFile for files that are optional
+func (c *Config) mergeIfExists(path string) error {
+	if err := c.MergeFile(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// MergeFile overlays the settings in a YAML file. Keys that are left out keep
+// their current values; unknown keys are an error
+func (c *Config) MergeFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && err != io.EOF {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	c.Sources = append(c.Sources, path)
+	return nil
+}
+
+// MergeEnv applies every setting that has a matching environment variable,
+// such as DEBIAN_DOCTOR_LOG_DIR or DEBIAN_DOCTOR_CHECKS_DISABLED=disk,memory
+func (c *Config) MergeEnv(lookup func(string) (string, bool)) error {
+	for _, key := range Keys() {
+		name := EnvName(key)
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		c.Sources = append(c.Sources, "env "+name)
+	}
+	return nil
+}
+
+// Validate checks the merged configuration for values that cannot work
+func (c *Config) Validate() error {
+	if c.LogDir == "" {
+		return fmt.Errorf("log_dir must not be empty")
+	}
+	if c.StateDir == "" {
+		return fmt.Errorf("state_dir must not be empty")
+	}
+	if c.Runner.Workers < 1 {
+		return fmt.Errorf("runner.workers must be at least 1, got %d", c.Runner.Workers)
+	}
+	if c.Runner.CheckTimeout <= 0 {
+		return fmt.Errorf("runner.check_timeout must be positive, got %s", c.Runner.CheckTimeout)
+	}
+	if c.Runner.FixCommandTimeout <= 0 {
+		return fmt.Errorf("runner.fix_command_timeout must be positive, got %s", c.Runner.FixCommandTimeout)
+	}
+	if c.History.MaxRuns < 0 {
+		return fmt.Errorf("history.max_runs must not be negative, got %d", c.History.MaxRuns)
+	}
+	return c.Thresholds.Validate()
+}
+
+// EnvName returns the environment variable that overrides a setting key
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Keys lists every setting by its dotted key, e.g. thresholds.journal_max_mb
+func Keys() []string {
+	var keys []string
+	collectKeys(reflect.TypeOf(Config{}), "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			collectKeys(field.Type, prefix+name+".", keys)
+			continue
+		}
+		*keys = append(*keys, prefix+name)
+	}
+}
+
+// Set assigns a single setting from its text form. Lists are comma separated
+func (c *Config) Set(key, value string) error {
+	field, err := c.field(key)
+	if err != nil {
+		return err
+	}
+
+	value = strings.TrimSpace(value)
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected a duration such as 30s, got %q", key, value)
+		}
+		field.SetInt(int64(parsed))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected true or false, got %q", key, value)
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected a whole number, got %q", key, value)
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: expected a number, got %q", key, value)
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s cannot be set from text", key)
+	}
+	return nil
+}
+
+// field finds the value addressed by a dotted key
+func (c *Config) field(key string) (reflect.Value, error) {
+	value := reflect.ValueOf(c).Elem()
+	for _, part := range strings.Split(key, ".") {
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("unknown setting %q", key)
+		}
+		found := false
+		for i := 0; i < value.NumField(); i++ {
+			if yamlName(value.Type().Field(i)) == part {
+				value = value.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("unknown setting %q", key)
+		}
+	}
+	if value.Kind() == reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%q is a section, not a setting", key)
+	}
+	return value, nil
+}
+
+// yamlName returns the key a field is stored under, or "" when it is not
+// part of the configuration file
+func yamlName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
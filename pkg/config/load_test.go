@@ -0,0 +1,205 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte(`
+log_dir: /srv/logs
+thresholds:
+  disk_warning_percent: 70
+  journal_max_mb: 250.5
+checks:
+  disabled: [network]
+`), 0644)
+
+	cfg := New()
+	if err := cfg.MergeFile(path); err != nil {
+		t.Fatalf("MergeFile() error = %v", err)
+	}
+
+	if cfg.LogDir != "/srv/logs" {
+		t.Errorf("LogDir = %q, want /srv/logs", cfg.LogDir)
+	}
+	if cfg.Thresholds.DiskWarningPercent != 70 || cfg.Thresholds.JournalMaxMB != 250.5 {
+		t.Errorf("Thresholds not merged: %+v", cfg.Thresholds)
+	}
+	if cfg.Thresholds.DiskCriticalPercent != DefaultThresholds().DiskCriticalPercent {
+		t.Errorf("Unset threshold lost its default: %d", cfg.Thresholds.DiskCriticalPercent)
+	}
+	if !reflect.DeepEqual(cfg.Checks.Disabled, []string{"network"}) {
+		t.Errorf("Checks.Disabled = %v", cfg.Checks.Disabled)
+	}
+	if cfg.Sources[len(cfg.Sources)-1] != path {
+		t.Errorf("Expected %s in sources, got %v", path, cfg.Sources)
+	}
+
+	unknown := filepath.Join(t.TempDir(), "typo.yaml")
+	os.WriteFile(unknown, []byte("thresholds:\n  disk_warn: 70\n"), 0644)
+	if err := New().MergeFile(unknown); err == nil {
+		t.Error("Expected unknown key to be rejected")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.yaml")
+	os.WriteFile(empty, nil, 0644)
+	if err := New().MergeFile(empty); err != nil {
+		t.Errorf("Empty file should be accepted, got %v", err)
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	env := map[string]string{
+		"DEBIAN_DOCTOR_STATE_DIR":                            "/srv/state",
+		"DEBIAN_DOCTOR_VERBOSE":                              "true",
+		"DEBIAN_DOCTOR_THRESHOLDS_SERVICE_RESTARTS_PER_HOUR": "10",
+		"DEBIAN_DOCTOR_CHECKS_ENABLED":                       "disk, memory",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	cfg := New()
+	if err := cfg.MergeEnv(lookup); err != nil {
+		t.Fatalf("MergeEnv() error = %v", err)
+	}
+	if cfg.StateDir != "/srv/state" || !cfg.Verbose {
+		t.Errorf("Env not applied: state_dir=%q verbose=%v", cfg.StateDir, cfg.Verbose)
+	}
+	if cfg.Thresholds.ServiceRestartsPerHour != 10 {
+		t.Errorf("ServiceRestartsPerHour = %d, want 10", cfg.Thresholds.ServiceRestartsPerHour)
+	}
+	if !reflect.DeepEqual(cfg.Checks.Enabled, []string{"disk", "memory"}) {
+		t.Errorf("Checks.Enabled = %v", cfg.Checks.Enabled)
+	}
+
+	env = map[string]string{"DEBIAN_DOCTOR_THRESHOLDS_DISK_WARNING_PERCENT": "lots"}
+	if err := New().MergeEnv(lookup); err == nil || !strings.Contains(err.Error(), "DEBIAN_DOCTOR_THRESHOLDS_DISK_WARNING_PERCENT") {
+		t.Errorf("Expected error naming the variable, got %v", err)
+	}
+}
+
+func TestKeepRestricted(t *testing.T) {
+	env := map[string]string{
+		"DEBIAN_DOCTOR_CHECKS_PLUGIN_DIRS":                   "/tmp/x",
+		"DEBIAN_DOCTOR_RULE_DIRS":                            "/tmp/rules",
+		"DEBIAN_DOCTOR_POLICY_FILE":                          "/tmp/policy.yaml",
+		"DEBIAN_DOCTOR_STATE_DIR":                            "/tmp/state",
+		"DEBIAN_DOCTOR_LOG_DIR":                              "/tmp/log",
+		"DEBIAN_DOCTOR_THRESHOLDS_SERVICE_RESTARTS_PER_HOUR": "10",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	cfg := New()
+	cfg.IsRoot = true
+	if err := cfg.keepRestricted("the environment", func() error { return cfg.MergeEnv(lookup) }); err != nil {
+		t.Fatalf("keepRestricted() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Checks.PluginDirs, DefaultPluginDirs()) || cfg.Thresholds.ServiceRestartsPerHour != 10 {
+		t.Errorf("plugin_dirs = %v, service_restarts_per_hour = %d", cfg.Checks.PluginDirs, cfg.Thresholds.ServiceRestartsPerHour)
+	}
+	if !reflect.DeepEqual(cfg.RuleDirs, []string{SystemRulesDir}) {
+		t.Errorf("rule_dirs = %v", cfg.RuleDirs)
+	}
+	if cfg.StateDir == "/tmp/state" || cfg.LogDir == "/tmp/log" {
+		t.Errorf("state_dir = %s, log_dir = %s; want the defaults", cfg.StateDir, cfg.LogDir)
+	}
+	if !reflect.DeepEqual(cfg.Ignored, []string{"policy_file from the environment", "checks.plugin_dirs from the environment", "rule_dirs from the environment",
+		"state_dir from the environment", "log_dir from the environment"}) {
+		t.Errorf("Ignored = %v", cfg.Ignored)
+	}
+
+	cfg = New()
+	cfg.IsRoot = false
+	if err := cfg.keepRestricted("the environment", func() error { return cfg.MergeEnv(lookup) }); err != nil {
+		t.Fatalf("keepRestricted() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Checks.PluginDirs, []string{"/tmp/x"}) || cfg.PolicyFile != DefaultPolicyFile ||
+		!reflect.DeepEqual(cfg.Ignored, []string{"policy_file from the environment"}) {
+		t.Errorf("plugin_dirs = %v, policy_file = %s, ignored = %v; want only the policy file ignored", cfg.Checks.PluginDirs, cfg.PolicyFile, cfg.Ignored)
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"log_dir", "/var/log/dd", false},
+		{"thresholds.memory_warning_percent", "75.5", false},
+		{"thresholds.disk_critical_percent", "98", false},
+		{"checks.disabled", "logs,packages", false},
+		{"thresholds", "1", true},
+		{"thresholds.unknown", "1", true},
+		{"is_root", "true", true},
+		{"verbose", "maybe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := New().Set(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Set(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestThresholdsValidate(t *testing.T) {
+	if err := DefaultThresholds().Validate(); err != nil {
+		t.Fatalf("Default thresholds invalid: %v", err)
+	}
+
+	tooHigh := DefaultThresholds()
+	tooHigh.DiskCriticalPercent = 120
+	if err := tooHigh.Validate(); err == nil {
+		t.Error("Expected percentage above 100 to be rejected")
+	}
+
+	inverted := DefaultThresholds()
+	inverted.MemoryWarningPercent = 95
+	if err := inverted.Validate(); err == nil {
+		t.Error("Expected warning above critical to be rejected")
+	}
+
+	negative := DefaultThresholds()
+	negative.JournalMaxMB = -1
+	if err := negative.Validate(); err == nil {
+		t.Error("Expected negative limit to be rejected")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	want := []string{"checks.disabled", "log_dir", "thresholds.disk_warning_percent"}
+	for _, key := range want {
+		found := false
+		for _, k := range keys {
+			if k == key {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Keys() missing %s", key)
+		}
+	}
+	for _, key := range keys {
+		if key == "is_root" || key == "sources" {
+			t.Errorf("Keys() should not expose %s", key)
+		}
+	}
+
+	if got := EnvName("thresholds.journal_max_mb"); got != "DEBIAN_DOCTOR_THRESHOLDS_JOURNAL_MAX_MB" {
+		t.Errorf("EnvName() = %s", got)
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// CheckTrusted returns why path, or the directory holding it, could be
+// changed by someone other than root or the current user, or nil when it
+// cannot. Plugins and the fix policy run with our privileges, so nobody
+// else may be able to replace them
+func CheckTrusted(path string) error {
+	for _, p := range []string{path, filepath.Dir(path)} {
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Uid != 0 && int(stat.Uid) != os.Geteuid() {
+			return fmt.Errorf("%s is owned by uid %d, neither root nor the current user", p, stat.Uid)
+		}
+		if info.Mode().Perm()&0022 != 0 {
+			return fmt.Errorf("%s is writable by other users (mode %s)", p, info.Mode().Perm())
+		}
+	}
+	return nil
+}
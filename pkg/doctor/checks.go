@@ -0,0 +1,135 @@
+package doctor
+
+import (
+	"context"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+)
+
+// CheckInfo describes a check that can be run
+type CheckInfo struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	Binaries []string `json:"binaries,omitempty"` // External programs the check runs
+	Cost     string   `json:"cost"`
+}
+
+// CheckResult is the outcome of one check. Status is empty for checks that
+// ran to completion, otherwise "skipped", "timed out" or "cancelled"
+type CheckResult struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Severity  Severity           `json:"severity"`
+	Status    string             `json:"status,omitempty"`
+	Message   string             `json:"message"`
+	Details   []string           `json:"details,omitempty"`
+	Findings  []Finding          `json:"findings,omitempty"`
+	Metrics   map[string]float64 `json:"metrics,omitempty"`
+	Timestamp time.Time          `json:"timestamp"`
+	Duration  time.Duration      `json:"duration"`
+}
+
+// Report holds the results of a check run in the order the checks were
+// selected
+type Report struct {
+	Results []CheckResult `json:"results"`
+
+	results checks.Results
+}
+
+// Highest returns the most severe result, as the command line's exit code
+// does. A check that timed out is a warning, since whatever it checks may be
+// hanging; skipped and cancelled checks are info and never raise it
+func (r *Report) Highest() Severity {
+	return Severity(r.results.HighestSeverity())
+}
+
+// RunOptions select the checks to run. Only and Skip hold check IDs; Tags
+// keeps checks carrying at least one of the tags. Empty options use the
+// checks selected by the configuration
+type RunOptions struct {
+	Only []string
+	Skip []string
+	Tags []string
+
+	// Progress, when set, is called after each check finishes. Calls are
+	// never concurrent
+	Progress func(result CheckResult, done, total int)
+}
+
+// Checks lists the built-in checks and the plugins found in the configured
+// plugin directories
+func (d *Doctor) Checks() ([]CheckInfo, error) {
+	registry, err := checks.LoadRegistry(d.cfg)
+	if err != nil {
+		return nil, err
+	}
+	var infos []CheckInfo
+	for _, check := range registry.Checks() {
+		info := check.Info()
+		infos = append(infos, CheckInfo{
+			ID:       info.ID,
+			Name:     check.Name(),
+			Category: info.Category,
+			Tags:     info.Tags,
+			Binaries: info.Binaries,
+			Cost:     info.Cost.String(),
+		})
+	}
+	return infos, nil
+}
+
+// RunChecks runs the selected checks with the configured worker count and
+// timeout. Checks still waiting when ctx is cancelled are reported as
+// cancelled
+func (d *Doctor) RunChecks(ctx context.Context, opts RunOptions) (*Report, error) {
+	registry, err := checks.LoadRegistry(d.cfg)
+	if err != nil {
+		return nil, err
+	}
+	sel := checks.Selector{Only: d.cfg.Checks.Enabled, Skip: d.cfg.Checks.Disabled, Tags: d.cfg.Checks.Tags}
+	if len(opts.Only) > 0 || len(opts.Skip) > 0 || len(opts.Tags) > 0 {
+		sel = checks.Selector{Only: opts.Only, Skip: opts.Skip, Tags: opts.Tags}
+	}
+	selected, err := registry.Select(sel)
+	if err != nil {
+		return nil, err
+	}
+
+	// Results carry the check name; the ID is looked up from it
+	ids := make(map[string]string, len(selected))
+	for _, check := range selected {
+		ids[check.Name()] = check.Info().ID
+	}
+
+	runner := checks.NewRunner(d.cfg)
+	if opts.Progress != nil {
+		runner.Progress = func(result checks.CheckResult, done, total int) {
+			opts.Progress(newCheckResult(ids[result.Name], result), done, total)
+		}
+	}
+
+	report := &Report{results: runner.Run(ctx, selected)}
+	for i, result := range report.results.GetAllChecks() {
+		report.Results = append(report.Results, newCheckResult(selected[i].Info().ID, result))
+	}
+	return report, nil
+}
+
+func newCheckResult(id string, result checks.CheckResult) CheckResult {
+	return CheckResult{
+		ID:        id,
+		Name:      result.Name,
+		Severity:  Severity(result.Severity),
+		Status:    string(result.Status),
+		Message:   result.Message,
+		Details:   result.Details,
+		Findings:  newFindings(result.Findings),
+		Metrics:   result.Metrics,
+		Timestamp: result.Timestamp,
+		Duration:  result.Duration,
+	}
+}
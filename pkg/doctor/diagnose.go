@@ -0,0 +1,51 @@
+package doctor
+
+import (
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+)
+
+// DiagnosisInfo describes a diagnosis that can be run
+type DiagnosisInfo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Diagnosis is what a diagnosis found and the fixes it suggests
+type Diagnosis struct {
+	ID       string    `json:"id"`
+	Issue    string    `json:"issue"`
+	Severity Severity  `json:"severity"`
+	Findings []Finding `json:"findings"`
+	Fixes    []Fix     `json:"fixes,omitempty"`
+}
+
+// Diagnoses lists the diagnoses in the order the interactive mode offers them
+func (d *Doctor) Diagnoses() []DiagnosisInfo {
+	var infos []DiagnosisInfo
+	for _, diagnoser := range diagnose.Diagnosers() {
+		infos = append(infos, DiagnosisInfo{ID: diagnoser.ID, Name: diagnoser.Name, Description: diagnoser.Description})
+	}
+	return infos
+}
+
+// Diagnose runs the diagnosis with the given ID, e.g. "network"
+func (d *Doctor) Diagnose(id string) (*Diagnosis, error) {
+	diagnosers, err := diagnose.LookupDiagnosers([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	diagnoser := diagnosers[0]
+
+	result := diagnoser.Run()
+	diagnosis := &Diagnosis{
+		ID:       diagnoser.ID,
+		Issue:    result.Issue,
+		Severity: Severity(result.Severity()),
+		Findings: newFindings(result.Findings),
+	}
+	for _, fix := range result.Fixes {
+		diagnosis.Fixes = append(diagnosis.Fixes, newFix(fix))
+	}
+	return diagnosis, nil
+}
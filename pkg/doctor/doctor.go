@@ -0,0 +1,124 @@
+// Package doctor lets Go programs embed debian-doctor: run checks and
+// diagnoses, list, plan and apply fixes, and summarize the results without
+// the terminal UI and without reading stdin or writing to stdout.
+//
+// The package is versioned by APIVersion. Within an API version exported
+// names and fields are only ever added, never changed or removed
+package doctor
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/checks"
+	"github.com/debian-doctor/debian-doctor/internal/diagnose"
+	"github.com/debian-doctor/debian-doctor/internal/machine"
+	"github.com/debian-doctor/debian-doctor/internal/rules"
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"github.com/debian-doctor/debian-doctor/pkg/logger"
+)
+
+// APIVersion is the version of this package's API
+const APIVersion = 1
+
+// Doctor runs checks, diagnoses and fixes with one configuration. The
+// thresholds, machine and diagnosis rules it sets up are shared by the whole
+// process, so Doctors open at the same time must agree on them
+type Doctor struct {
+	cfg *config.Config
+	log *logger.Logger
+
+	closeOnce sync.Once
+}
+
+// ErrConfigInUse is matched by errors.Is when New is given thresholds, rule
+// directories or a check timeout that differ from those of a Doctor that is
+// still open
+var ErrConfigInUse = errors.New("another Doctor is open with a different configuration")
+
+// processSetup is the part of a configuration New applies process-wide
+type processSetup struct {
+	thresholds config.Thresholds
+	ruleDirs   []string
+	timeout    time.Duration
+}
+
+var (
+	setupMu sync.Mutex
+	setup   processSetup // What the open Doctors run with
+	open    int          // Doctors not closed yet
+)
+
+// New prepares a Doctor with cfg, or with the configuration files the
+// command line reads when cfg is nil. Messages go to the log file in the
+// configured log directory only. While another Doctor is open, cfg must
+// agree with its thresholds, rule directories and check timeout; otherwise
+// New fails with ErrConfigInUse
+func New(cfg *config.Config) (*Doctor, error) {
+	if cfg == nil {
+		loaded, err := config.Load("")
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	setupMu.Lock()
+	defer setupMu.Unlock()
+	wanted := processSetup{thresholds: cfg.Thresholds, ruleDirs: cfg.RuleDirs, timeout: cfg.Runner.CheckTimeout}
+	if open > 0 && !reflect.DeepEqual(wanted, setup) {
+		return nil, ErrConfigInUse
+	}
+
+	log, err := logger.NewFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	log.SetConsole(nil)
+
+	if open == 0 {
+		ruleSet, err := rules.Load(cfg.RuleDirs)
+		if err == nil {
+			for _, skipped := range ruleSet.Skipped {
+				log.Warning("Skipping %s", skipped)
+			}
+			err = diagnose.SetRules(ruleSet)
+		}
+		if err != nil {
+			log.Close()
+			return nil, err
+		}
+		m := machine.Local(cfg.Runner.CheckTimeout)
+		checks.SetMachine(m)
+		diagnose.SetMachine(m)
+		checks.SetThresholds(cfg.Thresholds)
+		diagnose.SetThresholds(cfg.Thresholds)
+		setup = wanted
+	}
+	open++
+
+	return &Doctor{cfg: cfg, log: log}, nil
+}
+
+// Config returns the configuration the Doctor runs with
+func (d *Doctor) Config() *config.Config {
+	return d.cfg
+}
+
+// Close closes the log file. Once every Doctor is closed, New accepts any
+// configuration again
+func (d *Doctor) Close() error {
+	var err error
+	d.closeOnce.Do(func() {
+		setupMu.Lock()
+		open--
+		setupMu.Unlock()
+		err = d.log.Close()
+	})
+	return err
+}
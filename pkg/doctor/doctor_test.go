@@ -0,0 +1,268 @@
+package doctor_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/pkg/config"
+	"github.com/debian-doctor/debian-doctor/pkg/doctor"
+)
+
+// newDoctor creates a Doctor that keeps its logs and state in the test's
+// temporary directories and finds plugins in pluginDir
+func newDoctor(t *testing.T, pluginDir string) *doctor.Doctor {
+	t.Helper()
+	cfg := config.New()
+	cfg.SetLogDir(t.TempDir())
+	cfg.SetStateDir(t.TempDir())
+	cfg.PolicyFile = filepath.Join(t.TempDir(), "policy.yaml")
+	cfg.RuleDirs = nil
+	cfg.Checks.PluginDirs = []string{pluginDir}
+
+	d, err := doctor.New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func TestNewConfigInUse(t *testing.T) {
+	first := newDoctor(t, t.TempDir())
+
+	cfg := *first.Config()
+	cfg.Thresholds.DiskWarningPercent = 50
+	if _, err := doctor.New(&cfg); !errors.Is(err, doctor.ErrConfigInUse) {
+		t.Fatalf("New() with other thresholds error = %v, want ErrConfigInUse", err)
+	}
+
+	// Agreeing Doctors may coexist, and once all are closed anything goes
+	second := newDoctor(t, t.TempDir())
+	first.Close()
+	second.Close()
+	third, err := doctor.New(&cfg)
+	if err != nil {
+		t.Fatalf("New() after Close() error = %v", err)
+	}
+	third.Close()
+}
+
+func TestRunChecks(t *testing.T) {
+	dir := t.TempDir()
+	plugin := "#!/bin/sh\necho '{\"severity\": \"warning\", \"message\": \"HSM battery low\", \"metrics\": {\"hsm_battery_percent\": 12}}'\n"
+	if err := os.WriteFile(filepath.Join(dir, "hsm"), []byte(plugin), 0755); err != nil {
+		t.Fatal(err)
+	}
+	d := newDoctor(t, dir)
+
+	infos, err := d.Checks()
+	if err != nil {
+		t.Fatalf("Checks() error = %v", err)
+	}
+	if last := infos[len(infos)-1]; last.ID != "plugin.hsm" || last.Category != "plugin" {
+		t.Errorf("last check = %+v, want the plugin", last)
+	}
+
+	var progress []string
+	report, err := d.RunChecks(context.Background(), doctor.RunOptions{
+		Only:     []string{"plugin.hsm"},
+		Progress: func(result doctor.CheckResult, done, total int) { progress = append(progress, result.ID) },
+	})
+	if err != nil {
+		t.Fatalf("RunChecks() error = %v", err)
+	}
+	if len(report.Results) != 1 || len(progress) != 1 || progress[0] != "plugin.hsm" {
+		t.Fatalf("results = %+v, progress = %v", report.Results, progress)
+	}
+	result := report.Results[0]
+	if result.ID != "plugin.hsm" || result.Severity != doctor.SeverityWarning || result.Message != "HSM battery low" || result.Metrics["hsm_battery_percent"] != 12 {
+		t.Errorf("result = %+v", result)
+	}
+	if report.Highest() != doctor.SeverityWarning {
+		t.Errorf("Highest() = %s, want warning", report.Highest())
+	}
+
+	summary, err := d.Summarize(report)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if len(summary.Warnings) != 1 || summary.Warnings[0] != "HSM battery low" {
+		t.Errorf("Warnings = %v", summary.Warnings)
+	}
+	if out, err := summary.Render("markdown"); err != nil || !strings.Contains(string(out), "HSM battery low") {
+		t.Errorf("Render(markdown) = %s, %v", out, err)
+	}
+
+	if _, err := d.RunChecks(context.Background(), doctor.RunOptions{Only: []string{"nonexistent"}}); err == nil {
+		t.Error("RunChecks() with an unknown check succeeded")
+	}
+}
+
+func TestHighestCountsTimedOutChecks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hang"), []byte("#!/bin/sh\nsleep 10\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.New()
+	cfg.SetLogDir(t.TempDir())
+	cfg.SetStateDir(t.TempDir())
+	cfg.RuleDirs = nil
+	cfg.Checks.PluginDirs = []string{dir}
+	cfg.Runner.CheckTimeout = 200 * time.Millisecond
+	d, err := doctor.New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer d.Close()
+
+	report, err := d.RunChecks(context.Background(), doctor.RunOptions{Only: []string{"plugin.hang"}})
+	if err != nil {
+		t.Fatalf("RunChecks() error = %v", err)
+	}
+	if len(report.Results) != 1 || report.Results[0].Status != "timed out" {
+		t.Fatalf("results = %+v, want one timed out check", report.Results)
+	}
+	if report.Highest() != doctor.SeverityWarning {
+		t.Errorf("Highest() = %s, want warning", report.Highest())
+	}
+}
+
+func TestDiagnoses(t *testing.T) {
+	d := newDoctor(t, t.TempDir())
+
+	infos := d.Diagnoses()
+	if len(infos) == 0 || infos[0].ID != "boot" {
+		t.Errorf("Diagnoses() = %+v", infos)
+	}
+	if _, err := d.Diagnose("kernel"); err == nil || !strings.Contains(err.Error(), "unknown diagnosis") {
+		t.Errorf("Diagnose(kernel) error = %v", err)
+	}
+}
+
+func TestExecuteFix(t *testing.T) {
+	d := newDoctor(t, t.TempDir())
+	fix := doctor.Fix{
+		ID:              "two_steps",
+		Title:           "Two steps",
+		Commands:        []string{"true", "false"},
+		Reversible:      true,
+		ReverseCommands: []string{"true"},
+	}
+
+	// Nobody to ask and no auto-approval
+	if _, err := d.ExecuteFix(fix, doctor.FixOptions{}); !errors.Is(err, doctor.ErrNeedsHuman) {
+		t.Errorf("ExecuteFix() error = %v, want ErrNeedsHuman", err)
+	}
+
+	run, err := d.ExecuteFix(fix, doctor.FixOptions{Confirm: func(doctor.Fix) bool { return false }})
+	if err != nil || run.Status != "cancelled" {
+		t.Errorf("declined ExecuteFix() = %+v, %v", run, err)
+	}
+
+	var asked []string
+	var steps []doctor.Step
+	run, err = d.ExecuteFix(fix, doctor.FixOptions{
+		Confirm: func(f doctor.Fix) bool {
+			asked = append(asked, f.ID)
+			return true
+		},
+		ConfirmReverse: func(f doctor.Fix, failedStep int) bool {
+			asked = append(asked, fmt.Sprintf("%s reverse %d", f.ID, failedStep))
+			return true
+		},
+		OnStep: func(step doctor.Step, total int) { steps = append(steps, step) },
+	})
+	if err == nil || run == nil || run.Status != "failed" {
+		t.Fatalf("ExecuteFix() = %+v, %v; want a failed run", run, err)
+	}
+	if strings.Join(asked, ",") != "two_steps,two_steps reverse 2" {
+		t.Errorf("asked = %v", asked)
+	}
+	if len(steps) != 2 || steps[1].ExitCode != 1 || len(run.Steps) != 2 {
+		t.Errorf("steps = %+v, run steps = %+v", steps, run.Steps)
+	}
+	if run.Reversal == nil || !run.Reversal.Succeeded {
+		t.Errorf("Reversal = %+v", run.Reversal)
+	}
+
+	var output strings.Builder
+	run, err = d.ExecuteFix(doctor.Fix{ID: "echo", Title: "Echo", Commands: []string{"echo streamed"}}, doctor.FixOptions{
+		Confirm: func(doctor.Fix) bool { return true },
+		Output:  &output,
+	})
+	if err != nil || output.String() != "streamed\n" || run.Steps[0].Stdout != "streamed\n" {
+		t.Errorf("ExecuteFix() = %+v, %v; output %q", run, err, output.String())
+	}
+
+	// Without an Output nothing reaches stdout
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	_, err = d.ExecuteFix(doctor.Fix{ID: "echo", Title: "Echo", Commands: []string{"echo quiet"}}, doctor.FixOptions{
+		Confirm: func(doctor.Fix) bool { return true },
+	})
+	os.Stdout = stdout
+	w.Close()
+	if written, _ := io.ReadAll(r); err != nil || len(written) != 0 {
+		t.Errorf("ExecuteFix() error = %v, wrote %q to stdout", err, written)
+	}
+
+	data, err := json.Marshal(fix)
+	if err != nil || !strings.Contains(string(data), `"risk":"low"`) {
+		t.Errorf("json.Marshal(fix) = %s, %v", data, err)
+	}
+}
+
+func TestExecuteFixDryRun(t *testing.T) {
+	d := newDoctor(t, t.TempDir())
+	d.Config().DryRun = true
+	target := filepath.Join(t.TempDir(), "marker")
+	fix := doctor.Fix{ID: "touch", Title: "Touch", Commands: []string{"touch " + target}}
+
+	// A dry run refuses what a real run would refuse
+	if _, err := d.ExecuteFix(fix, doctor.FixOptions{}); !errors.Is(err, doctor.ErrNeedsHuman) {
+		t.Errorf("ExecuteFix() without approval error = %v, want ErrNeedsHuman", err)
+	}
+
+	var plan strings.Builder
+	run, err := d.ExecuteFix(fix, doctor.FixOptions{AutoApprove: true, Output: &plan})
+	if err != nil || run.Status != "dry-run" {
+		t.Fatalf("ExecuteFix() = %+v, %v; want a dry run", run, err)
+	}
+	if !strings.Contains(plan.String(), "touch "+target) {
+		t.Errorf("plan = %s", plan.String())
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("Dry run executed the fix")
+	}
+}
+
+func TestPlanFix(t *testing.T) {
+	d := newDoctor(t, t.TempDir())
+	plan, err := d.PlanFix(doctor.Fix{
+		ID:       "hosts",
+		Title:    "Add host",
+		Commands: []string{"echo '10.0.0.1 db' >> /etc/hosts"},
+	})
+	if err != nil {
+		t.Fatalf("PlanFix() error = %v", err)
+	}
+	if len(plan.Files) != 1 || plan.Files[0] != "/etc/hosts" || !plan.RequiresRoot || !plan.Steps[0].Shell {
+		t.Errorf("plan = %+v", plan)
+	}
+
+	if _, err := d.PlanFix(doctor.Fix{Title: "Nothing"}); err == nil {
+		t.Error("PlanFix() without commands succeeded")
+	}
+}
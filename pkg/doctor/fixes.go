@@ -0,0 +1,306 @@
+package doctor
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/debian-doctor/debian-doctor/internal/fixes"
+)
+
+var (
+	// ErrNeedsHuman is matched by errors.Is when a fix may only run after
+	// a person has approved it
+	ErrNeedsHuman = errors.New("fix needs human approval")
+
+	// ErrDenied is matched by errors.Is when the fix policy forbids a fix
+	ErrDenied = errors.New("fix denied by policy")
+)
+
+// RiskLevel is how dangerous a fix is, from least to most
+type RiskLevel int
+
+const (
+	RiskLow RiskLevel = iota
+	RiskMedium
+	RiskHigh
+	RiskCritical
+)
+
+func (r RiskLevel) String() string {
+	return fixes.RiskLevel(r).String()
+}
+
+// MarshalText renders the risk level as its lowercase name
+func (r RiskLevel) MarshalText() ([]byte, error) {
+	return fixes.RiskLevel(r).MarshalText()
+}
+
+// ParseRiskLevel converts a risk level name such as "medium" into a RiskLevel
+func ParseRiskLevel(name string) (RiskLevel, error) {
+	level, err := fixes.ParseRiskLevel(name)
+	return RiskLevel(level), err
+}
+
+// Fix is a set of commands that repairs a problem. Modifies lists the files
+// the fix edits; they are snapshotted before it runs. Informational fixes
+// only gather information and are not meant to be applied unattended
+type Fix struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	Commands        []string  `json:"commands"`
+	RequiresRoot    bool      `json:"requires_root"`
+	Reversible      bool      `json:"reversible"`
+	ReverseCommands []string  `json:"reverse_commands,omitempty"`
+	Risk            RiskLevel `json:"risk"`
+	Modifies        []string  `json:"modifies,omitempty"`
+	Informational   bool      `json:"informational,omitempty"`
+}
+
+func newFix(fix *fixes.Fix) Fix {
+	return Fix{
+		ID:              fix.ID,
+		Title:           fix.Title,
+		Description:     fix.Description,
+		Commands:        fix.Commands,
+		RequiresRoot:    fix.RequiresRoot,
+		Reversible:      fix.Reversible,
+		ReverseCommands: fix.ReverseCommands,
+		Risk:            RiskLevel(fix.RiskLevel),
+		Modifies:        fix.Modifies,
+		Informational:   fix.Informational,
+	}
+}
+
+func (f Fix) internal() *fixes.Fix {
+	return &fixes.Fix{
+		ID:              f.ID,
+		Title:           f.Title,
+		Description:     f.Description,
+		Commands:        f.Commands,
+		RequiresRoot:    f.RequiresRoot,
+		Reversible:      f.Reversible,
+		ReverseCommands: f.ReverseCommands,
+		RiskLevel:       fixes.RiskLevel(f.Risk),
+		Modifies:        f.Modifies,
+		Informational:   f.Informational,
+	}
+}
+
+// Step is the outcome of one fix command
+type Step struct {
+	Step     int           `json:"step"`
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exit_code"`
+	Stdout   string        `json:"stdout,omitempty"`
+	Stderr   string        `json:"stderr,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	TimedOut bool          `json:"timed_out,omitempty"` // Killed at runner.fix_command_timeout
+	Duration time.Duration `json:"duration"`
+}
+
+// Reversal is an attempt to undo a fix that failed part way
+type Reversal struct {
+	Succeeded bool   `json:"succeeded"`
+	Steps     []Step `json:"steps,omitempty"`
+}
+
+// FixRun records one attempt to apply a fix. Status is "succeeded",
+// "failed", "cancelled" or "dry-run"; runs that executed anything are kept
+// in the fix journal under ID
+type FixRun struct {
+	ID        string    `json:"id"`
+	FixID     string    `json:"fix_id"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Steps     []Step    `json:"steps"`
+	Reversal  *Reversal `json:"reversal,omitempty"` // Set when undoing the fix was attempted
+}
+
+func newStep(step fixes.StepResult) Step {
+	return Step{
+		Step:     step.Step,
+		Command:  step.Command,
+		ExitCode: step.ExitCode,
+		Stdout:   step.Stdout,
+		Stderr:   step.Stderr,
+		Error:    step.Error,
+		TimedOut: step.TimedOut,
+		Duration: step.EndedAt.Sub(step.StartedAt),
+	}
+}
+
+func newFixRun(run *fixes.FixRun) *FixRun {
+	converted := &FixRun{
+		ID:        run.ID,
+		FixID:     run.FixID,
+		Status:    string(run.Status),
+		Error:     run.Error,
+		StartedAt: run.StartedAt,
+		EndedAt:   run.EndedAt,
+	}
+	for _, step := range run.Steps {
+		converted.Steps = append(converted.Steps, newStep(step))
+	}
+	if run.Reversal != nil && run.Reversal.Attempted {
+		converted.Reversal = &Reversal{Succeeded: run.Reversal.Succeeded}
+		for _, step := range run.Reversal.Steps {
+			converted.Reversal.Steps = append(converted.Reversal.Steps, newStep(step))
+		}
+	}
+	return converted
+}
+
+// PlannedStep describes what a fix command would do
+type PlannedStep struct {
+	Step     int      `json:"step"`
+	Command  string   `json:"command"`
+	Shell    bool     `json:"shell"`             // Whether it runs through /bin/sh
+	Programs []string `json:"programs"`          // Programs it invokes
+	Touches  []string `json:"touches,omitempty"` // Files it writes to
+}
+
+// FixPlan describes what a fix would do without running it. Problems lists
+// everything, such as missing programs or policy denials, that would stop it
+type FixPlan struct {
+	FixID        string        `json:"fix_id"`
+	RequiresRoot bool          `json:"requires_root"`
+	Reversible   bool          `json:"reversible"`
+	Steps        []PlannedStep `json:"steps"`
+	Files        []string      `json:"files"`
+	Problems     []string      `json:"problems,omitempty"`
+}
+
+// Ready reports whether nothing in the plan would stop the fix from running
+func (p *FixPlan) Ready() bool {
+	return len(p.Problems) == 0
+}
+
+// FixOptions control how ExecuteFix asks for approval and reports progress
+type FixOptions struct {
+	// Confirm is asked before the fix runs. Without it only auto-approved
+	// fixes run; others fail with ErrNeedsHuman
+	Confirm func(fix Fix) bool
+
+	// ConfirmReverse is asked whether to undo a fix that failed at the
+	// given step, counted from 1. Without it partial changes are undone
+	ConfirmReverse func(fix Fix, failedStep int) bool
+
+	// AutoApprove runs fixes at or below MaxRisk, and within the policy's
+	// unattended ceiling, without asking
+	AutoApprove bool
+	MaxRisk     RiskLevel
+
+	// OnStep, when set, is called after each fix command has run with the
+	// number of commands in the fix
+	OnStep func(step Step, total int)
+
+	// Output, when set, receives the output of fix commands as they run.
+	// Steps record it either way
+	Output io.Writer
+}
+
+// callbackConfirmer confirms fixes through the FixOptions callbacks
+type callbackConfirmer struct {
+	opts FixOptions
+}
+
+func (c callbackConfirmer) ConfirmFix(fix *fixes.Fix) bool {
+	return c.opts.Confirm != nil && c.opts.Confirm(newFix(fix))
+}
+
+func (c callbackConfirmer) ConfirmReverse(fix *fixes.Fix, failedAt int) bool {
+	if c.opts.ConfirmReverse == nil {
+		return true
+	}
+	return c.opts.ConfirmReverse(newFix(fix), failedAt+1)
+}
+
+// fixError lets errors.Is tell why a fix was refused
+type fixError struct {
+	kind error
+	err  error
+}
+
+func (e *fixError) Error() string        { return e.err.Error() }
+func (e *fixError) Unwrap() error        { return e.err }
+func (e *fixError) Is(target error) bool { return target == e.kind }
+
+func wrapFixError(err error) error {
+	var needsHuman *fixes.NeedsHumanError
+	var denied *fixes.PolicyError
+	switch {
+	case errors.As(err, &needsHuman):
+		return &fixError{kind: ErrNeedsHuman, err: err}
+	case errors.As(err, &denied):
+		return &fixError{kind: ErrDenied, err: err}
+	}
+	return err
+}
+
+// Fixes lists the common fixes that do not depend on a diagnosis, by ID
+func (d *Doctor) Fixes() []Fix {
+	var list []Fix
+	for _, fix := range fixes.GetCommonFixes() {
+		list = append(list, newFix(fix))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// PlanFix describes what the fix would do without running anything
+func (d *Doctor) PlanFix(fix Fix) (*FixPlan, error) {
+	plan, err := fixes.NewExecutor(d.cfg, d.log).Plan(fix.internal())
+	if err != nil {
+		return nil, err
+	}
+	converted := &FixPlan{
+		FixID:        plan.FixID,
+		RequiresRoot: plan.RequiresRoot,
+		Reversible:   plan.Reversible,
+		Files:        plan.Files,
+		Problems:     plan.Problems,
+	}
+	for _, step := range plan.Steps {
+		planned := PlannedStep{Step: step.Step, Command: step.Command, Shell: step.Shell, Programs: []string{}, Touches: step.Touches}
+		for _, binary := range step.Binaries {
+			planned.Programs = append(planned.Programs, binary.Name)
+		}
+		converted.Steps = append(converted.Steps, planned)
+	}
+	return converted, nil
+}
+
+// ExecuteFix applies the fix after checking it against the fix policy and
+// asking for approval as opts describe. Nothing is read from stdin or
+// written to stdout. In dry-run mode nothing runs: the plan goes to Output,
+// the run has status "dry-run", and a fix a real run would refuse fails
+// with the same error. The returned run is nil only when the fix was
+// rejected before anything could run
+func (d *Doctor) ExecuteFix(fix Fix, opts FixOptions) (*FixRun, error) {
+	// Without a confirmation callback there is nobody to ask
+	cfg := *d.cfg
+	cfg.NonInteractive = opts.Confirm == nil
+
+	executor := fixes.NewExecutor(&cfg, d.log)
+	executor.SetConfirmer(callbackConfirmer{opts})
+	executor.SetOutput(opts.Output)
+	if opts.AutoApprove {
+		executor.SetAutoApprove(fixes.RiskLevel(opts.MaxRisk))
+	}
+	if opts.OnStep != nil {
+		executor.SetProgress(func(step fixes.StepResult, total int) {
+			opts.OnStep(newStep(step), total)
+		})
+	}
+
+	run, err := executor.ExecuteFix(fix.internal())
+	if run == nil {
+		return nil, wrapFixError(err)
+	}
+	return newFixRun(run), wrapFixError(err)
+}
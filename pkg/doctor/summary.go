@@ -0,0 +1,43 @@
+package doctor
+
+import (
+	"github.com/debian-doctor/debian-doctor/internal/summary"
+)
+
+// Summary is the system summary built from a check run: system and
+// resource information, a health score and recommendations
+type Summary struct {
+	HealthScore     int      `json:"health_score"`
+	CriticalIssues  []string `json:"critical_issues"`
+	Warnings        []string `json:"warnings"`
+	Recommendations []string `json:"recommendations"`
+	Skipped         []string `json:"skipped,omitempty"`
+
+	summary *summary.SystemSummary
+}
+
+// Render produces the full report in one of ReportFormats
+func (s *Summary) Render(format string) ([]byte, error) {
+	return s.summary.Render(format)
+}
+
+// ReportFormats lists the formats Summary.Render accepts
+func ReportFormats() []string {
+	return summary.Formats()
+}
+
+// Summarize gathers system information and builds the summary of a report
+func (d *Doctor) Summarize(report *Report) (*Summary, error) {
+	generated, err := summary.NewGenerator(d.cfg).Generate(report.results)
+	if err != nil {
+		return nil, err
+	}
+	return &Summary{
+		HealthScore:     generated.HealthScore,
+		CriticalIssues:  generated.CriticalIssues,
+		Warnings:        generated.Warnings,
+		Recommendations: generated.Recommendations,
+		Skipped:         generated.Skipped,
+		summary:         generated,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package doctor
+
+import (
+	"github.com/debian-doctor/debian-doctor/internal/finding"
+)
+
+// Severity of a check result or finding, from least to most severe
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	return finding.Severity(s).String()
+}
+
+// MarshalText renders the severity as its lowercase name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Resource is something a finding is about, such as a package or a unit
+type Resource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Finding is one problem or observation. ID names the kind of finding and
+// FixIDs the fixes that address it
+type Finding struct {
+	ID        string            `json:"id"`
+	Severity  Severity          `json:"severity"`
+	Category  string            `json:"category"`
+	Summary   string            `json:"summary"`
+	Evidence  map[string]string `json:"evidence,omitempty"`
+	Excerpt   string            `json:"excerpt,omitempty"`
+	Resources []Resource        `json:"resources,omitempty"`
+	FixIDs    []string          `json:"fix_ids,omitempty"`
+}
+
+func newFindings(found []finding.Finding) []Finding {
+	if len(found) == 0 {
+		return nil
+	}
+	converted := make([]Finding, len(found))
+	for i, f := range found {
+		converted[i] = Finding{
+			ID:       f.ID,
+			Severity: Severity(f.Severity),
+			Category: f.Category,
+			Summary:  f.Summary,
+			Evidence: f.Evidence,
+			Excerpt:  f.Excerpt,
+			FixIDs:   f.FixIDs,
+		}
+		for _, r := range f.Resources {
+			converted[i].Resources = append(converted[i].Resources, Resource{Kind: r.Kind, Name: r.Name})
+		}
+	}
+	return converted
+}
@@ -14,6 +14,7 @@ import (
 type Logger struct {
 	file   *os.File
 	logger *log.Logger
+	filter func(string) string
 }
 
 // NewFromConfig creates a new logger using configuration
@@ -47,19 +48,42 @@ func New(logDir string) (*Logger, error) {
 }
 
 func (l *Logger) Info(format string, args ...interface{}) {
-	l.logger.Printf("[INFO] "+format, args...)
+	l.print("INFO", format, args...)
 }
 
 func (l *Logger) Warning(format string, args ...interface{}) {
-	l.logger.Printf("[WARNING] "+format, args...)
+	l.print("WARNING", format, args...)
 }
 
 func (l *Logger) Error(format string, args ...interface{}) {
-	l.logger.Printf("[ERROR] "+format, args...)
+	l.print("ERROR", format, args...)
 }
 
 func (l *Logger) Debug(format string, args ...interface{}) {
-	l.logger.Printf("[DEBUG] "+format, args...)
+	l.print("DEBUG", format, args...)
+}
+
+// SetFilter rewrites every message before it is written, e.g. to redact it
+func (l *Logger) SetFilter(filter func(string) string) {
+	l.filter = filter
+}
+
+// SetConsole changes where messages are echoed besides the log file, which
+// is stdout by default; nil writes to the log file only
+func (l *Logger) SetConsole(w io.Writer) {
+	if w == nil {
+		l.logger.SetOutput(l.file)
+		return
+	}
+	l.logger.SetOutput(io.MultiWriter(l.file, w))
+}
+
+func (l *Logger) print(level, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if l.filter != nil {
+		message = l.filter(message)
+	}
+	l.logger.Printf("[%s] %s", level, message)
 }
 
 func (l *Logger) Close() error {
@@ -146,6 +146,54 @@ func TestFormattedLogging(t *testing.T) {
 	}
 }
 
+func TestSetFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logger, err := New(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.SetFilter(func(message string) string {
+		return strings.ReplaceAll(message, "secret", "[hidden]")
+	})
+	logger.Warning("token is %s", "secret")
+
+	content, err := ioutil.ReadFile(logger.GetLogPath())
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "[WARNING] token is [hidden]") {
+		t.Errorf("Expected filtered message in log file, got %q", content)
+	}
+}
+
+func TestSetConsole(t *testing.T) {
+	logger, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	var console strings.Builder
+	logger.SetConsole(&console)
+	logger.Info("echoed")
+	logger.SetConsole(nil)
+	logger.Info("file only")
+
+	if !strings.Contains(console.String(), "[INFO] echoed") || strings.Contains(console.String(), "file only") {
+		t.Errorf("console = %q", console.String())
+	}
+	content, err := ioutil.ReadFile(logger.GetLogPath())
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "[INFO] file only") {
+		t.Errorf("Expected message in log file, got %q", content)
+	}
+}
+
 func TestClose(t *testing.T) {
 	// Create temporary directory for testing
 	tmpDir, err := ioutil.TempDir("", "debian-doctor-test")